@@ -0,0 +1,13 @@
+package main
+
+import "fmt"
+
+func asmfn()
+
+func gofn() {
+	fmt.Println("gofn")
+}
+
+func main() {
+	asmfn()
+}
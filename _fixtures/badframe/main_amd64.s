@@ -0,0 +1,14 @@
+#include "textflag.h"
+
+// asmfn moves the stack pointer without telling the assembler before
+// calling gofn, the frame descriptor entry generated for it is wrong.
+// The assembler saves BP and sets up the frame pointer for us.
+TEXT ·asmfn(SB),NOSPLIT,$0-0
+	LEAQ -64(SP), SP
+	MOVQ $0xdead, 0(SP)
+	MOVQ $0xdead, 8(SP)
+	MOVQ $0xdead, 16(SP)
+	MOVQ $0xdead, 24(SP)
+	CALL ·gofn(SB)
+	MOVQ BP, SP
+	RET
@@ -0,0 +1,32 @@
+package main
+
+// #cgo CFLAGS: -g -Wall -O2 -fomit-frame-pointer
+/*
+#include <signal.h>
+#include <string.h>
+
+volatile int handled;
+
+__attribute__((noinline)) void sighandler(int sig) {
+	handled = sig;
+}
+
+__attribute__((noinline)) void raiser(int sig) {
+	struct sigaction sa;
+	memset(&sa, 0, sizeof(sa));
+	sa.sa_handler = sighandler;
+	sigaction(sig, &sa, NULL);
+	raise(sig);
+	handled++;
+}
+
+__attribute__((noinline)) void testfn(void) {
+	raiser(SIGUSR1);
+	handled++;
+}
+*/
+import "C"
+
+func main() {
+	C.testfn()
+}
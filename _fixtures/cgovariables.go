@@ -0,0 +1,59 @@
+package main
+
+// #cgo CFLAGS: -g -Wall -O0
+/*
+#include <stdlib.h>
+#include <string.h>
+
+struct flags {
+	unsigned int a : 3;
+	unsigned int b : 5;
+	int c : 4;
+	unsigned char d;
+};
+
+union num {
+	int i;
+	float f;
+	unsigned char bytes[4];
+};
+
+enum color { RED, GREEN = 5, BLUE };
+
+struct buf {
+	int len;
+	char data[];
+};
+
+typedef int (*binop)(int, int);
+
+int add(int a, int b) {
+	return a + b;
+}
+
+int testfn(struct flags *f, union num *n, enum color c, const char *s, struct buf *b, binop op, void *p) {
+	return op(f->a, c) + n->bytes[3] + s[0] + b->data[0] + (p != NULL);
+}
+
+int run(void) {
+	struct flags f = { 5, 17, -3, 'x' };
+	union num n;
+	struct buf *b;
+	int r;
+
+	n.i = 0x3f800000;
+	b = malloc(sizeof(struct buf) + 4);
+	b->len = 4;
+	memcpy(b->data, "abc", 4);
+	r = testfn(&f, &n, GREEN, "hello", b, add, &f);
+	free(b);
+	return r;
+}
+*/
+import "C"
+
+import "fmt"
+
+func main() {
+	fmt.Println(C.run())
+}
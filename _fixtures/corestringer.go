@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type point struct {
+	X, Y int
+}
+
+func (p point) String() string {
+	return "(" + strconv.Itoa(p.X) + ", " + strconv.Itoa(p.Y) + ")"
+}
+
+var origin = point{1, -200}
+
+func main() {
+	fmt.Println(origin)
+	panic("panic!!!")
+}
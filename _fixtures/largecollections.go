@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+type point struct {
+	X, Y int
+}
+
+func main() {
+	s := make([]int, 1000)
+	for i := range s {
+		s[i] = i
+	}
+	m := make(map[int]point, 300)
+	for i := 0; i < 300; i++ {
+		m[i] = point{i, -i}
+	}
+	str := strings.Repeat("0123456789", 100)
+	big := make([]int, 100000)
+	runtime.Breakpoint()
+	fmt.Println(len(s), len(m), len(str), len(big))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type myError struct {
+	code int
+}
+
+func (err *myError) Error() string {
+	return fmt.Sprintf("error code %d", err.code)
+}
+
+func try(f func()) {
+	defer func() {
+		recover()
+	}()
+	f()
+}
+
+func main() {
+	try(func() { panic(errors.New("first failure")) })
+	try(func() { panic("second failure") })
+	try(func() { panic(&myError{42}) })
+	fmt.Println("done")
+}
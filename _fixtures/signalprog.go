@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func main() {
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	fmt.Println("survived")
+}
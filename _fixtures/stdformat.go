@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+func main() {
+	tm := time.Date(2021, time.March, 4, 5, 6, 7, 8, time.UTC)
+	d := 90 * time.Second
+	bi, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	ip := net.ParseIP("192.168.1.1")
+	ip6 := net.ParseIP("2001:db8::1")
+	var mu sync.Mutex
+	mu.Lock()
+	var sb strings.Builder
+	sb.WriteString("hello")
+	var buf bytes.Buffer
+	buf.WriteString("skip:world")
+	buf.Next(5)
+	rv := reflect.ValueOf(42)
+	var sm sync.Map
+	sm.Store("a", 1)
+	l := list.New()
+	l.PushBack(1)
+	l.PushBack("two")
+	runtime.Breakpoint()
+	fmt.Println(tm, d, bi, ip, ip6, &mu, sb.String(), buf.String(), rv, &sm, l)
+}
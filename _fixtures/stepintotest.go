@@ -0,0 +1,24 @@
+package main
+
+import "fmt"
+
+//go:noinline
+func double(x int) int {
+	return x * 2
+}
+
+//go:noinline
+func square(x int) int {
+	return x * x
+}
+
+//go:noinline
+func sum(a, b int) int {
+	return a + b
+}
+
+func main() {
+	x := 3
+	r := sum(double(x), square(x))
+	fmt.Println(r)
+}
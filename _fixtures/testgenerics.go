@@ -0,0 +1,26 @@
+package main
+
+import "fmt"
+
+type Pair[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+func MakePair[K comparable, V any](k K, v V) Pair[K, V] {
+	p := Pair[K, V]{k, v}
+	fmt.Println(p)
+	return p
+}
+
+func main() {
+	MakePair(1, "a")
+	MakePair("x", 2.5)
+	Escape(3)
+}
+
+func Escape[T any](x T) []*T {
+	s := []*T{&x}
+	fmt.Println(s)
+	return s
+}
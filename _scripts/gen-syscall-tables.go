@@ -0,0 +1,95 @@
+// +build ignore
+
+// This script generates pkg/proc/linutil/syscalls.go from the zsysnum
+// files of golang.org/x/sys/unix.
+// Usage: go run _scripts/gen-syscall-tables.go <path to golang.org/x/sys/unix> <output file>
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var arches = []struct{ goarch, file string }{
+	{"386", "zsysnum_linux_386.go"},
+	{"amd64", "zsysnum_linux_amd64.go"},
+	{"arm64", "zsysnum_linux_arm64.go"},
+}
+
+type syscallEntry struct {
+	name string
+	num  int
+}
+
+func readSysnum(path string) []syscallEntry {
+	fh, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer fh.Close()
+
+	r := []syscallEntry{}
+	s := bufio.NewScanner(fh)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 3 || fields[1] != "=" || !strings.HasPrefix(fields[0], "SYS_") {
+			continue
+		}
+		num, err := strconv.Atoi(fields[2])
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		r = append(r, syscallEntry{strings.ToLower(fields[0][len("SYS_"):]), num})
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+	sort.SliceStable(r, func(i, j int) bool { return r[i].num < r[j].num })
+	return r
+}
+
+func main() {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `// THIS FILE IS AUTOGENERATED, RUN _scripts/gen-syscall-tables.go INSTEAD OF EDITING IT
+
+package linutil
+
+// syscallNames maps GOARCH to a table of system call names indexed by
+// system call number.
+var syscallNames = map[string]map[uint64]string{
+`)
+
+	for _, arch := range arches {
+		fmt.Fprintf(&buf, "%q: {\n", arch.goarch)
+		for _, e := range readSysnum(filepath.Join(os.Args[1], arch.file)) {
+			fmt.Fprintf(&buf, "%d: %q,\n", e.num, e.name)
+		}
+		fmt.Fprintf(&buf, "},\n")
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	outfh := os.Stdout
+	if os.Args[2] != "-" {
+		outfh, err = os.Create(os.Args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer outfh.Close()
+	}
+	outfh.Write(src)
+}
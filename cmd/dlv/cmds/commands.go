@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -12,11 +13,14 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-delve/delve/pkg/config"
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/debuginfod"
 	"github.com/go-delve/delve/pkg/terminal"
 	"github.com/go-delve/delve/pkg/version"
 	"github.com/go-delve/delve/service"
@@ -414,6 +418,10 @@ func dapCmd(cmd *cobra.Command, args []string) {
 				DebugInfoDirectories: conf.DebugInfoDirectories,
 				CheckGoVersion:       checkGoVersion,
 				TTY:                  tty,
+				Signals:              conf.Signals,
+				PrettyPrinters:       convertPrettyPrinters(conf.PrettyPrinters),
+				DebugInfoSources:     debugInfoSources(conf),
+				IndexCacheDir:        conf.IndexCacheDirectory,
 			},
 		})
 		defer server.Stop()
@@ -763,6 +771,10 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 				DebugInfoDirectories: conf.DebugInfoDirectories,
 				CheckGoVersion:       checkGoVersion,
 				TTY:                  tty,
+				Signals:              conf.Signals,
+				PrettyPrinters:       convertPrettyPrinters(conf.PrettyPrinters),
+				DebugInfoSources:     debugInfoSources(conf),
+				IndexCacheDir:        conf.IndexCacheDirectory,
 			},
 		})
 	default:
@@ -804,3 +816,55 @@ func execute(attachPid int, processArgs []string, conf *config.Config, coreFile
 
 	return connect(listener.Addr().String(), clientConn, conf, kind)
 }
+
+func convertPrettyPrinters(pps []config.PrettyPrinter) []api.PrettyPrinter {
+	r := make([]api.PrettyPrinter, 0, len(pps))
+	for _, pp := range pps {
+		app := api.PrettyPrinter{Type: pp.Type, Value: pp.Value}
+		for _, child := range pp.Children {
+			app.Children = append(app.Children, api.PrettyPrinterChild{Name: child.Name, Expr: child.Expr})
+		}
+		r = append(r, app)
+	}
+	return r
+}
+
+// debugInfoSources returns the sources of external debug info files
+// configured in conf.
+func debugInfoSources(conf *config.Config) []proc.DebugInfoSource {
+	if len(conf.DebuginfodURLs) == 0 {
+		return nil
+	}
+	cacheDir, err := config.GetConfigFilePath("debuginfod")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: debuginfod disabled: %v\n", err)
+		return nil
+	}
+	client := debuginfod.New(conf.DebuginfodURLs, cacheDir)
+	timeout := debuginfod.DefaultTimeout
+	if conf.DebuginfodTimeout > 0 {
+		timeout = time.Duration(conf.DebuginfodTimeout) * time.Second
+	}
+	client.HTTPClient = &http.Client{Timeout: timeout}
+	var lastID string
+	var lastStep int64
+	client.Progress = func(buildID string, received, total int64) {
+		// report every 10% of the download, or every 10MB if the size is unknown
+		var step int64
+		if total > 0 {
+			step = received * 10 / total
+		} else {
+			step = received / (10 << 20)
+		}
+		if buildID == lastID && step == lastStep {
+			return
+		}
+		lastID, lastStep = buildID, step
+		if total > 0 {
+			fmt.Fprintf(os.Stderr, "Downloading debug info for %s: %d/%d bytes\n", buildID, received, total)
+		} else {
+			fmt.Fprintf(os.Stderr, "Downloading debug info for %s: %d bytes\n", buildID, received)
+		}
+	}
+	return []proc.DebugInfoSource{client}
+}
@@ -64,6 +64,45 @@ type Config struct {
 	// DebugFileDirectories is the list of directories Delve will use
 	// in order to resolve external debug info files.
 	DebugInfoDirectories []string `yaml:"debug-info-directories"`
+
+	// DebuginfodURLs is the list of debuginfod servers Delve will query,
+	// by build ID, for separate debug info files that can not be found in
+	// DebugInfoDirectories.
+	DebuginfodURLs []string `yaml:"debuginfod-urls,omitempty"`
+
+	// DebuginfodTimeout is the maximum time, in seconds, Delve will wait for
+	// a debuginfod server to send a debug info file. If zero
+	// debuginfod.DefaultTimeout is used.
+	DebuginfodTimeout int `yaml:"debuginfod-timeout,omitempty"`
+
+	// IndexCacheDirectory is the directory where Delve saves the index of
+	// the debug info of executables, to load them faster the next time they
+	// are debugged. If empty the index is not saved.
+	IndexCacheDirectory string `yaml:"index-cache-directory,omitempty"`
+
+	// Signals maps signal names to the keywords describing how they are
+	// handled by the debugger (see the handle command).
+	Signals map[string]string `yaml:"signals,omitempty"`
+
+	// PrettyPrinters describe how variables of user types are displayed.
+	PrettyPrinters []PrettyPrinter `yaml:"pretty-printers,omitempty"`
+}
+
+// PrettyPrinter describes how variables whose type name matches the
+// regular expression Type are displayed, Type must match the whole type
+// name. Expressions enclosed in curly braces in Value, and the expressions
+// of Children, are evaluated with the variable bound to x.
+type PrettyPrinter struct {
+	Type     string               `yaml:"type"`
+	Value    string               `yaml:"value"`
+	Children []PrettyPrinterChild `yaml:"children,omitempty"`
+}
+
+// PrettyPrinterChild is a synthetic child of the variables displayed by a
+// pretty-printer.
+type PrettyPrinterChild struct {
+	Name string `yaml:"name"`
+	Expr string `yaml:"expr"`
 }
 
 func (c *Config) GetSourceListLineCount() int {
@@ -250,6 +289,36 @@ substitute-path:
 
 # List of directories to use when searching for separate debug info files.
 debug-info-directories: ["/usr/lib/debug/.build-id"]
+
+# List of debuginfod servers used to download separate debug info files that
+# can not be found in debug-info-directories. Downloaded files are cached in
+# the debuginfod subdirectory of the configuration directory.
+# debuginfod-urls: ["https://debuginfod.elfutils.org/"]
+
+# Maximum time, in seconds, to wait for a debuginfod server to send a debug
+# info file (default 120).
+# debuginfod-timeout: 120
+
+# Directory where the index of the debug info of executables is saved, by
+# build ID, so that they load faster the next time they are debugged. The
+# index is not saved if this is not set.
+# index-cache-directory: "/home/user/.cache/dlv/index"
+
+# How signals received by the target process are handled, using the same
+# keywords as the handle command (stop/nostop, print/noprint, pass/nopass).
+# Signals not listed here are passed to the target process silently.
+signals:
+  # SIGSEGV: stop print pass
+
+# Pretty-printers for user types. Expressions enclosed in curly braces in
+# value, and the expressions of children, are evaluated with the variable
+# being displayed bound to x.
+pretty-printers:
+  # - type: "^main\\.Money$"
+  #   value: "{x.units}.{x.cents} {x.currency}"
+  #   children:
+  #     - name: cents
+  #       expr: x.units*100 + x.cents
 `)
 	return err
 }
@@ -10,15 +10,22 @@ import (
 )
 
 type Builder struct {
+	version  uint16
 	info     bytes.Buffer
 	loc      bytes.Buffer
+	rnglists bytes.Buffer
+	addr     bytes.Buffer
 	abbrevs  []tagDescr
 	tagStack []*tagState
+
+	// subprogramRanges contains the address ranges of all subprograms, they
+	// are used as the address ranges of the compile unit in DWARF 5.
+	subprogramRanges [][2]uint64
 }
 
 // New creates a new DWARF builder.
 func New() *Builder {
-	b := &Builder{}
+	b := &Builder{version: 4}
 
 	b.info.Write([]byte{
 		0x0, 0x0, 0x0, 0x0, // length
@@ -33,8 +40,49 @@ func New() *Builder {
 	return b
 }
 
+// NewDwarf5 creates a new DWARF builder producing DWARF version 5. Location
+// lists will be written to debug_loclists, using debug_addr for their base
+// addresses, and the address ranges of the compile unit will be written to
+// debug_rnglists.
+func NewDwarf5() *Builder {
+	b := &Builder{version: 5}
+
+	b.info.Write([]byte{
+		0x0, 0x0, 0x0, 0x0, // length
+		0x5, 0x0, // version
+		0x1,                // unit_type (DW_UT_compile)
+		0x8,                // address_size
+		0x0, 0x0, 0x0, 0x0, // debug_abbrev_offset
+	})
+
+	b.loc.Write([]byte{
+		0x0, 0x0, 0x0, 0x0, // length
+		0x5, 0x0, // version
+		0x8,                // address_size
+		0x0,                // segment_selector_size
+		0x0, 0x0, 0x0, 0x0, // offset_entry_count
+	})
+	b.rnglists.Write(b.loc.Bytes())
+
+	b.addr.Write([]byte{
+		0x0, 0x0, 0x0, 0x0, // length
+		0x5, 0x0, // version
+		0x8, // address_size
+		0x0, // segment_selector_size
+	})
+
+	b.TagOpen(dwarf.TagCompileUnit, "go")
+	b.Attr(dwarf.AttrLanguage, uint8(22))
+	b.attrSecOffset(dwarf.AttrAddrBase, uint32(b.addr.Len()))
+	b.attrSecOffset(dwarf.AttrRanges, uint32(b.rnglists.Len()))
+
+	return b
+}
+
 // Build closes b and returns all the dwarf sections.
-func (b *Builder) Build() (abbrev, aranges, frame, info, line, pubnames, ranges, str, loc []byte, err error) {
+// For builders created with NewDwarf5 ranges and loc will contain the
+// debug_rnglists and debug_loclists sections respectively.
+func (b *Builder) Build() (abbrev, aranges, frame, info, line, pubnames, ranges, str, loc, addr []byte, err error) {
 	b.TagClose()
 
 	if len(b.tagStack) > 0 {
@@ -47,5 +95,19 @@ func (b *Builder) Build() (abbrev, aranges, frame, info, line, pubnames, ranges,
 	binary.LittleEndian.PutUint32(info, uint32(len(info)-4))
 	loc = b.loc.Bytes()
 
+	if b.version >= 5 {
+		for _, rng := range b.subprogramRanges {
+			b.rnglists.WriteByte(_DW_RLE_start_end)
+			binary.Write(&b.rnglists, binary.LittleEndian, rng[0])
+			binary.Write(&b.rnglists, binary.LittleEndian, rng[1])
+		}
+		b.rnglists.WriteByte(_DW_RLE_end_of_list)
+		ranges = b.rnglists.Bytes()
+		addr = b.addr.Bytes()
+		for _, sec := range [][]byte{loc, ranges, addr} {
+			binary.LittleEndian.PutUint32(sec, uint32(len(sec)-4))
+		}
+	}
+
 	return
 }
@@ -162,6 +162,11 @@ func (b *Builder) Attr(attr dwarf.Attr, val interface{}) {
 		tag.form = append(tag.form, DW_FORM_sec_offset)
 		binary.Write(&b.info, binary.LittleEndian, uint32(b.loc.Len()))
 
+		if b.version >= 5 {
+			b.writeLoclist5(x)
+			break
+		}
+
 		// base address
 		binary.Write(&b.loc, binary.LittleEndian, ^uint64(0))
 		binary.Write(&b.loc, binary.LittleEndian, uint64(0))
@@ -181,6 +186,39 @@ func (b *Builder) Attr(attr dwarf.Attr, val interface{}) {
 	}
 }
 
+// attrSecOffset adds an attribute with form DW_FORM_sec_offset to the
+// current DIE.
+func (b *Builder) attrSecOffset(attr dwarf.Attr, off uint32) {
+	tag := b.tagStack[len(b.tagStack)-1]
+	tag.attr = append(tag.attr, attr)
+	tag.form = append(tag.form, DW_FORM_sec_offset)
+	binary.Write(&b.info, binary.LittleEndian, off)
+}
+
+// writeLoclist5 writes x to debug_loclists, the address of the first entry
+// is written to debug_addr and used as the base address for the list.
+func (b *Builder) writeLoclist5(x []LocEntry) {
+	var base uint64
+	if len(x) > 0 {
+		base = x[0].Lowpc
+	}
+	addrIdx := (b.addr.Len() - 8) / 8
+	binary.Write(&b.addr, binary.LittleEndian, base)
+
+	b.loc.WriteByte(_DW_LLE_base_addressx)
+	util.EncodeULEB128(&b.loc, uint64(addrIdx))
+
+	for _, locentry := range x {
+		b.loc.WriteByte(_DW_LLE_offset_pair)
+		util.EncodeULEB128(&b.loc, locentry.Lowpc-base)
+		util.EncodeULEB128(&b.loc, locentry.Highpc-base)
+		util.EncodeULEB128(&b.loc, uint64(len(locentry.Loc)))
+		b.loc.Write(locentry.Loc)
+	}
+
+	b.loc.WriteByte(_DW_LLE_end_of_list)
+}
+
 func sameTagDescr(a, b tagDescr) bool {
 	if a.tag != b.tag {
 		return false
@@ -234,6 +272,9 @@ func (b *Builder) makeAbbrevTable() []byte {
 		util.EncodeULEB128(&abbrev, 0)
 	}
 
+	// end of abbreviations table
+	util.EncodeULEB128(&abbrev, 0)
+
 	return abbrev.Bytes()
 }
 
@@ -251,6 +292,7 @@ func (b *Builder) AddSubprogram(fnname string, lowpc, highpc uint64) dwarf.Offse
 	r := b.TagOpen(dwarf.TagSubprogram, fnname)
 	b.Attr(dwarf.AttrLowpc, Address(lowpc))
 	b.Attr(dwarf.AttrHighpc, Address(highpc))
+	b.subprogramRanges = append(b.subprogramRanges, [2]uint64{lowpc, highpc})
 	return r
 }
 
@@ -7,6 +7,17 @@ import (
 	"github.com/go-delve/delve/pkg/dwarf/util"
 )
 
+// Location list and range list entry kinds used by DWARF 5 (see sections
+// 7.7.3 and 7.25, DWARF v5).
+const (
+	_DW_LLE_end_of_list   = 0x0
+	_DW_LLE_base_addressx = 0x1
+	_DW_LLE_offset_pair   = 0x4
+
+	_DW_RLE_end_of_list = 0x0
+	_DW_RLE_start_end   = 0x6
+)
+
 // LocEntry represents one entry of debug_loc.
 type LocEntry struct {
 	Lowpc  uint64
@@ -18,6 +18,10 @@ type CommonInformationEntry struct {
 	ReturnAddressRegister uint64
 	InitialInstructions   []byte
 	staticBase            uint64
+
+	// ptrEncAddr is the encoding of the begin and size fields of the FDEs
+	// using this CIE, always ptrEncAbs for .debug_frame.
+	ptrEncAddr ptrEnc
 }
 
 // Represents a Frame Descriptor Entry in the
@@ -53,6 +57,39 @@ func (fde *FrameDescriptionEntry) EstablishFrame(pc uint64) *FrameContext {
 
 type FrameDescriptionEntries []*FrameDescriptionEntry
 
+// ptrEnc is a pointer encoding, as used by .eh_frame.
+type ptrEnc uint8
+
+const (
+	ptrEncAbs    ptrEnc = 0x00 // pointer-sized unsigned integer
+	ptrEncOmit   ptrEnc = 0xff // omitted
+	ptrEncUleb   ptrEnc = 0x01 // ULEB128
+	ptrEncUdata2 ptrEnc = 0x02 // 2 bytes
+	ptrEncUdata4 ptrEnc = 0x03 // 4 bytes
+	ptrEncUdata8 ptrEnc = 0x04 // 8 bytes
+	ptrEncSigned ptrEnc = 0x08 // pointer-sized signed integer
+	ptrEncSleb   ptrEnc = 0x09 // SLEB128
+	ptrEncSdata2 ptrEnc = 0x0a // 2 bytes, signed
+	ptrEncSdata4 ptrEnc = 0x0b // 4 bytes, signed
+	ptrEncSdata8 ptrEnc = 0x0c // 8 bytes, signed
+
+	ptrEncPCRel    ptrEnc = 0x10 // value is relative to the address where it appears
+	ptrEncIndirect ptrEnc = 0x80 // value is the address where the real value is stored
+)
+
+// supported returns true if this pointer encoding is supported, only
+// absolute and PC relative pointers are.
+func (ptrEnc ptrEnc) supported() bool {
+	if ptrEnc == ptrEncOmit {
+		return true
+	}
+	szenc := ptrEnc & 0x0f
+	if (szenc > ptrEncUdata8 && szenc < ptrEncSigned) || szenc > ptrEncSdata8 {
+		return false
+	}
+	return ptrEnc&0xf0 == ptrEncAbs || ptrEnc&0xf0 == ptrEncPCRel
+}
+
 func newFrameIndex() FrameDescriptionEntries {
 	return make(FrameDescriptionEntries, 0, 1000)
 }
@@ -77,10 +114,43 @@ func (fdes FrameDescriptionEntries) FDEForPC(pc uint64) (*FrameDescriptionEntry,
 }
 
 // Append appends otherFDEs to fdes and returns the result.
+// Entries of otherFDEs that overlap the address range of an entry of fdes
+// are dropped, the entries of fdes take precedence (for example the
+// entries of .debug_frame over the ones of .eh_frame).
 func (fdes FrameDescriptionEntries) Append(otherFDEs FrameDescriptionEntries) FrameDescriptionEntries {
-	r := append(fdes, otherFDEs...)
-	sort.Slice(r, func(i, j int) bool {
+	sort.SliceStable(fdes, func(i, j int) bool {
+		return fdes[i].Begin() < fdes[j].Begin()
+	})
+	// maxEnd[i] is the end of the address range covered by fdes[:i+1]
+	maxEnd := make([]uint64, len(fdes))
+	for i, fde := range fdes {
+		maxEnd[i] = fde.End()
+		if i > 0 && maxEnd[i-1] > maxEnd[i] {
+			maxEnd[i] = maxEnd[i-1]
+		}
+	}
+	r := fdes
+	for _, fde := range otherFDEs {
+		idx := sort.Search(len(fdes), func(i int) bool {
+			return fdes[i].Begin() >= fde.End()
+		})
+		if idx > 0 && maxEnd[idx-1] > fde.Begin() {
+			continue
+		}
+		r = append(r, fde)
+	}
+	sort.SliceStable(r, func(i, j int) bool {
 		return r[i].Begin() < r[j].Begin()
 	})
-	return r
+	uniq := r[:0]
+	for _, fde := range r {
+		if len(uniq) > 0 {
+			last := uniq[len(uniq)-1]
+			if last.Begin() == fde.Begin() && last.End() == fde.End() {
+				continue
+			}
+		}
+		uniq = append(uniq, fde)
+	}
+	return uniq
 }
@@ -56,6 +56,33 @@ func TestFDEForPC(t *testing.T) {
 	}
 }
 
+func TestAppend(t *testing.T) {
+	debugFrame := FrameDescriptionEntries{
+		&FrameDescriptionEntry{begin: 100, size: 100},
+		&FrameDescriptionEntry{begin: 10, size: 40},
+	}
+	ehFrame := FrameDescriptionEntries{
+		&FrameDescriptionEntry{begin: 0, size: 10},   // before all entries
+		&FrameDescriptionEntry{begin: 10, size: 40},  // same range
+		&FrameDescriptionEntry{begin: 40, size: 20},  // overlaps the end of [10, 50)
+		&FrameDescriptionEntry{begin: 50, size: 50},  // between [10, 50) and [100, 200)
+		&FrameDescriptionEntry{begin: 90, size: 20},  // overlaps the start of [100, 200)
+		&FrameDescriptionEntry{begin: 150, size: 10}, // inside [100, 200)
+		&FrameDescriptionEntry{begin: 200, size: 10}, // after all entries
+	}
+	fdes := FrameDescriptionEntries(nil).Append(debugFrame).Append(ehFrame)
+
+	expected := []*FrameDescriptionEntry{ehFrame[0], debugFrame[1], ehFrame[3], debugFrame[0], ehFrame[6]}
+	if len(fdes) != len(expected) {
+		t.Fatalf("wrong number of entries %d, expected %d", len(fdes), len(expected))
+	}
+	for i := range expected {
+		if fdes[i] != expected[i] {
+			t.Errorf("entry %d: got [%d, %d) expected [%d, %d)", i, fdes[i].Begin(), fdes[i].End(), expected[i].Begin(), expected[i].End())
+		}
+	}
+}
+
 func BenchmarkFDEForPC(b *testing.B) {
 	f, err := os.Open("testdata/frame")
 	if err != nil {
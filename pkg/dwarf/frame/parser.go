@@ -1,11 +1,12 @@
 // Package frame contains data structures and
 // related functions for parsing and searching
-// through Dwarf .debug_frame data.
+// through Dwarf .debug_frame and .eh_frame data.
 package frame
 
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 
 	"github.com/go-delve/delve/pkg/dwarf/util"
 )
@@ -21,6 +22,13 @@ type parseContext struct {
 	frame   *FrameDescriptionEntry
 	length  uint32
 	ptrSize int
+
+	// ehFrameAddr is the address of the .eh_frame section, zero when parsing
+	// a .debug_frame section.
+	ehFrameAddr uint64
+	totalLen    int
+	ciemap      map[int]*CommonInformationEntry
+	err         error
 }
 
 // Parse takes in data (a byte slice) and returns a slice of
@@ -43,11 +51,49 @@ func Parse(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int)
 	return pctx.entries
 }
 
-func cieEntry(data []byte) bool {
+// ParseEhFrame parses the contents of a .eh_frame section, which is a
+// minor variant of the .debug_frame format, located at address
+// ehFrameAddr of the image.
+// Unlike .debug_frame, .eh_frame is part of the loaded image and is
+// present in stripped executables and shared libraries.
+func ParseEhFrame(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int, ehFrameAddr uint64) (FrameDescriptionEntries, error) {
+	var (
+		buf  = bytes.NewBuffer(data)
+		pctx = &parseContext{buf: buf, entries: newFrameIndex(), staticBase: staticBase, ptrSize: ptrSize, ehFrameAddr: ehFrameAddr, totalLen: len(data), ciemap: make(map[int]*CommonInformationEntry)}
+	)
+
+	for fn := parselength; buf.Len() != 0; {
+		fn = fn(pctx)
+		if pctx.err != nil {
+			return nil, pctx.err
+		}
+	}
+
+	for i := range pctx.entries {
+		pctx.entries[i].order = order
+	}
+
+	return pctx.entries, nil
+}
+
+func (ctx *parseContext) parsingEhFrame() bool {
+	return ctx.ehFrameAddr != 0
+}
+
+// offset returns the offset of the read cursor from the start of the section.
+func (ctx *parseContext) offset() int {
+	return ctx.totalLen - ctx.buf.Len()
+}
+
+func (ctx *parseContext) cieEntry(data []byte) bool {
+	if ctx.parsingEhFrame() {
+		return bytes.Equal(data, []byte{0x00, 0x00, 0x00, 0x00})
+	}
 	return bytes.Equal(data, []byte{0xff, 0xff, 0xff, 0xff})
 }
 
 func parselength(ctx *parseContext) parsefunc {
+	start := ctx.offset()
 	binary.Read(ctx.buf, binary.LittleEndian, &ctx.length)
 
 	if ctx.length == 0 {
@@ -59,38 +105,103 @@ func parselength(ctx *parseContext) parsefunc {
 
 	ctx.length -= 4 // take off the length of the CIE id / CIE pointer.
 
-	if cieEntry(data) {
+	if ctx.cieEntry(data) {
 		ctx.common = &CommonInformationEntry{Length: ctx.length, staticBase: ctx.staticBase}
+		if ctx.parsingEhFrame() {
+			ctx.ciemap[start] = ctx.common
+		}
 		return parseCIE
 	}
 
-	ctx.frame = &FrameDescriptionEntry{Length: ctx.length, CIE: ctx.common}
+	common := ctx.common
+	if ctx.parsingEhFrame() {
+		// In .eh_frame the CIE pointer is the offset of the CIE from the CIE
+		// pointer field itself.
+		cieptr := int(binary.LittleEndian.Uint32(data))
+		common = ctx.ciemap[start+4-cieptr]
+		if common == nil {
+			ctx.err = fmt.Errorf("unknown CIE pointer %#x at %#x", cieptr, start)
+			return nil
+		}
+	}
+
+	ctx.frame = &FrameDescriptionEntry{Length: ctx.length, CIE: common}
 	return parseFDE
 }
 
 func parseFDE(ctx *parseContext) parsefunc {
-	var num uint64
+	start := ctx.offset()
 	r := ctx.buf.Next(int(ctx.length))
 
-	reader := bytes.NewReader(r)
-	num, _ = util.ReadUintRaw(reader, binary.LittleEndian, ctx.ptrSize)
-	ctx.frame.begin = num + ctx.staticBase
-	num, _ = util.ReadUintRaw(reader, binary.LittleEndian, ctx.ptrSize)
-	ctx.frame.size = num
+	buf := bytes.NewBuffer(r)
+	ptrEncAddr := ptrEncAbs
+	if ctx.frame.CIE != nil {
+		ptrEncAddr = ctx.frame.CIE.ptrEncAddr
+	}
+	ctx.frame.begin = ctx.readEncodedPtr(ctx.ehFrameAddr+uint64(start), buf, len(r), ptrEncAddr) + ctx.staticBase
+	// Only the size part of the pointer encoding applies to the size field.
+	ctx.frame.size = ctx.readEncodedPtr(0, buf, len(r), ptrEncAddr&0x0f)
 
 	// Insert into the tree after setting address range begin
 	// otherwise compares won't work.
 	ctx.entries = append(ctx.entries, ctx.frame)
 
+	if ctx.parsingEhFrame() && len(ctx.frame.CIE.Augmentation) > 0 {
+		// Skip the augmentation data, we don't use any of it.
+		n, _ := util.DecodeULEB128(buf)
+		buf.Next(int(n))
+	}
+
 	// The rest of this entry consists of the instructions
 	// so we can just grab all of the data from the buffer
 	// cursor to length.
-	ctx.frame.Instructions = r[2*ctx.ptrSize:]
+	ctx.frame.Instructions = buf.Bytes()
 	ctx.length = 0
 
 	return parselength
 }
 
+// readEncodedPtr reads a pointer encoded as specified by ptrEnc from buf,
+// addr is the address of buf's first byte in the image and n is the
+// length of buf when it was created.
+func (ctx *parseContext) readEncodedPtr(addr uint64, buf *bytes.Buffer, n int, ptrEnc ptrEnc) uint64 {
+	if ptrEnc == ptrEncOmit {
+		return 0
+	}
+
+	addr += uint64(n - buf.Len())
+
+	var ptr uint64
+
+	switch ptrEnc & 0x0f {
+	case ptrEncAbs, ptrEncSigned:
+		ptr, _ = util.ReadUintRaw(buf, binary.LittleEndian, ctx.ptrSize)
+	case ptrEncUleb:
+		ptr, _ = util.DecodeULEB128(buf)
+	case ptrEncUdata2:
+		ptr, _ = util.ReadUintRaw(buf, binary.LittleEndian, 2)
+	case ptrEncSdata2:
+		ptr, _ = util.ReadUintRaw(buf, binary.LittleEndian, 2)
+		ptr = uint64(int16(ptr))
+	case ptrEncUdata4:
+		ptr, _ = util.ReadUintRaw(buf, binary.LittleEndian, 4)
+	case ptrEncSdata4:
+		ptr, _ = util.ReadUintRaw(buf, binary.LittleEndian, 4)
+		ptr = uint64(int32(ptr))
+	case ptrEncUdata8, ptrEncSdata8:
+		ptr, _ = util.ReadUintRaw(buf, binary.LittleEndian, 8)
+	case ptrEncSleb:
+		x, _ := util.DecodeSLEB128(buf)
+		ptr = uint64(x)
+	}
+
+	if ptrEnc&0xf0 == ptrEncPCRel {
+		ptr += addr
+	}
+
+	return ptr
+}
+
 func parseCIE(ctx *parseContext) parsefunc {
 	data := ctx.buf.Next(int(ctx.length))
 	buf := bytes.NewBuffer(data)
@@ -100,6 +211,11 @@ func parseCIE(ctx *parseContext) parsefunc {
 	// parse augmentation
 	ctx.common.Augmentation, _ = util.ParseString(buf)
 
+	if ctx.parsingEhFrame() && len(ctx.common.Augmentation) > 0 && ctx.common.Augmentation[0] != 'z' {
+		ctx.err = fmt.Errorf("unsupported augmentation %q at %#x", ctx.common.Augmentation, ctx.offset())
+		return nil
+	}
+
 	// parse code alignment factor
 	ctx.common.CodeAlignmentFactor, _ = util.DecodeULEB128(buf)
 
@@ -107,7 +223,48 @@ func parseCIE(ctx *parseContext) parsefunc {
 	ctx.common.DataAlignmentFactor, _ = util.DecodeSLEB128(buf)
 
 	// parse return address register
-	ctx.common.ReturnAddressRegister, _ = util.DecodeULEB128(buf)
+	if ctx.parsingEhFrame() && ctx.common.Version == 1 {
+		b, _ := buf.ReadByte()
+		ctx.common.ReturnAddressRegister = uint64(b)
+	} else {
+		ctx.common.ReturnAddressRegister, _ = util.DecodeULEB128(buf)
+	}
+
+	ctx.common.ptrEncAddr = ptrEncAbs
+
+	if ctx.parsingEhFrame() && len(ctx.common.Augmentation) > 0 {
+		n, _ := util.DecodeULEB128(buf) // augmentation data length
+		augdata := bytes.NewBuffer(buf.Next(int(n)))
+		for _, c := range ctx.common.Augmentation[1:] {
+			switch c {
+			case 'R':
+				// Encoding of the begin and size fields of FDEs.
+				b, _ := augdata.ReadByte()
+				ctx.common.ptrEncAddr = ptrEnc(b)
+				if !ctx.common.ptrEncAddr.supported() {
+					ctx.err = fmt.Errorf("unsupported pointer encoding %#x at %#x", b, ctx.offset())
+					return nil
+				}
+			case 'L':
+				// Encoding of the LSDA pointer, which we don't use.
+				augdata.ReadByte()
+			case 'P':
+				// Personality routine, which we don't use.
+				b, _ := augdata.ReadByte()
+				e := ptrEnc(b) &^ ptrEncIndirect
+				if !e.supported() {
+					ctx.err = fmt.Errorf("unsupported pointer encoding %#x at %#x", b, ctx.offset())
+					return nil
+				}
+				ctx.readEncodedPtr(0, augdata, augdata.Len(), e)
+			case 'S':
+				// Signal handler frame, there's no associated data.
+			default:
+				ctx.err = fmt.Errorf("unsupported augmentation %q at %#x", ctx.common.Augmentation, ctx.offset())
+				return nil
+			}
+		}
+	}
 
 	// parse initial instructions
 	// The rest of this entry consists of the instructions
@@ -39,6 +39,71 @@ func TestParseCIE(t *testing.T) {
 	}
 }
 
+func TestParseEhFrame(t *testing.T) {
+	const ehFrameAddr = 0x1000
+	var buf bytes.Buffer
+	w := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+
+	// CIE with a "zR" augmentation, FDE addresses are encoded as PC relative
+	// signed 4 byte values.
+	w(uint32(20))
+	w(uint32(0))                           // CIE id
+	buf.Write([]byte{1, 'z', 'R', 0})      // version, augmentation
+	buf.Write([]byte{1, 0x78, 16})         // code alignment, data alignment (-8), return address register
+	buf.Write([]byte{1, 0x1b})             // augmentation data
+	buf.Write([]byte{0x0c, 7, 8, 0x90, 1}) // DW_CFA_def_cfa rsp+8, DW_CFA_offset rip at cfa-8
+	buf.Write([]byte{0, 0})                // padding
+
+	// FDE for [0x400, 0x420)
+	fdeoff := buf.Len()
+	w(uint32(20))
+	w(uint32(fdeoff + 4)) // CIE pointer
+	w(int32(0x400 - (ehFrameAddr + fdeoff + 8)))
+	w(int32(0x20))
+	buf.Write([]byte{0}) // augmentation data length
+	buf.Write([]byte{
+		0x41,     // DW_CFA_advance_loc 1
+		0x0a,     // DW_CFA_remember_state
+		0x0e, 16, // DW_CFA_def_cfa_offset 16
+		0x41, // DW_CFA_advance_loc 1
+		0x0b, // DW_CFA_restore_state
+		0,    // padding
+	})
+
+	w(uint32(0)) // terminator
+
+	const staticBase = 0x10000
+	fdes, err := ParseEhFrame(buf.Bytes(), binary.LittleEndian, staticBase, 8, ehFrameAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fdes) != 1 {
+		t.Fatalf("expected 1 FDE got %d", len(fdes))
+	}
+	fde, err := fdes.FDEForPC(staticBase + 0x410)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fde.Begin() != staticBase+0x400 || fde.End() != staticBase+0x420 {
+		t.Fatalf("wrong FDE range %#x-%#x", fde.Begin(), fde.End())
+	}
+	if fde.CIE.ReturnAddressRegister != 16 || fde.CIE.DataAlignmentFactor != -8 {
+		t.Fatalf("wrong CIE %#v", fde.CIE)
+	}
+	if fctx := fde.EstablishFrame(staticBase + 0x400); fctx.CFA.Reg != 7 || fctx.CFA.Offset != 8 {
+		t.Fatalf("wrong CFA at entry %#v", fctx.CFA)
+	}
+	if fctx := fde.EstablishFrame(staticBase + 0x401); fctx.CFA.Reg != 7 || fctx.CFA.Offset != 16 {
+		t.Fatalf("wrong CFA after advance %#v", fctx.CFA)
+	}
+	if fctx := fde.EstablishFrame(staticBase + 0x410); fctx.CFA.Reg != 7 || fctx.CFA.Offset != 8 {
+		t.Fatalf("wrong CFA after restore state %#v", fctx.CFA)
+	}
+	if fctx := fde.EstablishFrame(staticBase + 0x410); fctx.Regs[16].Rule != RuleOffset || fctx.Regs[16].Offset != -8 {
+		t.Fatalf("wrong return address rule %#v", fctx.Regs[16])
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	f, err := os.Open("testdata/frame")
 	if err != nil {
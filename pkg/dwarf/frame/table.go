@@ -22,7 +22,7 @@ type FrameContext struct {
 	CFA           DWRule
 	Regs          map[uint64]DWRule
 	initialRegs   map[uint64]DWRule
-	prevRegs      map[uint64]DWRule
+	savedStates   []rowState
 	buf           *bytes.Buffer
 	cie           *CommonInformationEntry
 	RetAddrReg    uint64
@@ -30,6 +30,12 @@ type FrameContext struct {
 	dataAlignment int64
 }
 
+// rowState is the state saved by DW_CFA_remember_state.
+type rowState struct {
+	cfa  DWRule
+	regs map[uint64]DWRule
+}
+
 // Instructions used to recreate the table from the .debug_frame data.
 const (
 	DW_CFA_nop                = 0x0        // No ops
@@ -121,13 +127,15 @@ func executeCIEInstructions(cie *CommonInformationEntry) *FrameContext {
 		Regs:          make(map[uint64]DWRule),
 		RetAddrReg:    cie.ReturnAddressRegister,
 		initialRegs:   make(map[uint64]DWRule),
-		prevRegs:      make(map[uint64]DWRule),
 		codeAlignment: cie.CodeAlignmentFactor,
 		dataAlignment: cie.DataAlignmentFactor,
 		buf:           bytes.NewBuffer(initialInstructions),
 	}
 
 	frame.executeDwarfProgram()
+	for reg, rule := range frame.Regs {
+		frame.initialRegs[reg] = rule
+	}
 	return frame
 }
 
@@ -306,11 +314,22 @@ func register(frame *FrameContext) {
 }
 
 func rememberstate(frame *FrameContext) {
-	frame.prevRegs = frame.Regs
+	regs := make(map[uint64]DWRule, len(frame.Regs))
+	for reg, rule := range frame.Regs {
+		regs[reg] = rule
+	}
+	// Like GDB we also save the CFA rule, GCC relies on this.
+	frame.savedStates = append(frame.savedStates, rowState{cfa: frame.CFA, regs: regs})
 }
 
 func restorestate(frame *FrameContext) {
-	frame.Regs = frame.prevRegs
+	if len(frame.savedStates) == 0 {
+		return
+	}
+	state := frame.savedStates[len(frame.savedStates)-1]
+	frame.savedStates = frame.savedStates[:len(frame.savedStates)-1]
+	frame.CFA = state.cfa
+	frame.Regs = state.regs
 }
 
 func restoreextended(frame *FrameContext) {
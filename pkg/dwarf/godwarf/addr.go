@@ -0,0 +1,53 @@
+package godwarf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// DebugAddrSection represents the debug_addr section of DWARFv5.
+// See DWARFv5 section 7.27 page 241 and following.
+type DebugAddrSection struct {
+	byteOrder binary.ByteOrder
+	ptrSz     int
+	data      []byte
+}
+
+// ParseAddr returns a DebugAddrSection for the contents of the debug_addr
+// section, or nil if data is empty.
+func ParseAddr(data []byte, ptrSz int) *DebugAddrSection {
+	if len(data) == 0 {
+		return nil
+	}
+	return &DebugAddrSection{byteOrder: binary.LittleEndian, ptrSz: ptrSz, data: data}
+}
+
+// GetSubsection returns the subsection of debug_addr starting at addrBase.
+func (addr *DebugAddrSection) GetSubsection(addrBase uint64) *DebugAddr {
+	if addr == nil {
+		return nil
+	}
+	return &DebugAddr{DebugAddrSection: addr, addrBase: addrBase}
+}
+
+// DebugAddr represents a subsection of the debug_addr section with a
+// specific base address.
+type DebugAddr struct {
+	*DebugAddrSection
+	addrBase uint64
+}
+
+// Get returns the address at index idx starting from addrBase.
+func (addr *DebugAddr) Get(idx uint64) (uint64, error) {
+	if addr == nil || addr.DebugAddrSection == nil {
+		return 0, errors.New("debug_addr section not present")
+	}
+	off := idx*uint64(addr.ptrSz) + addr.addrBase
+	if off+uint64(addr.ptrSz) > uint64(len(addr.data)) {
+		return 0, errors.New("debug_addr index out of bounds")
+	}
+	return util.ReadUintRaw(bytes.NewReader(addr.data[off:]), addr.byteOrder, addr.ptrSz)
+}
@@ -3,14 +3,96 @@ package godwarf
 import (
 	"bytes"
 	"compress/zlib"
+	"debug/dwarf"
 	"debug/elf"
 	"debug/macho"
 	"debug/pe"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sync"
 )
 
+// DebugSections gives access to the debug sections of an executable,
+// each section is read (and decompressed if necessary) the first time it is
+// requested and then cached.
+type DebugSections struct {
+	get func(name string) ([]byte, error)
+
+	mu    sync.Mutex
+	cache map[string]*debugSection
+}
+
+type debugSection struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// NewDebugSectionsElf returns a DebugSections object for f, see GetDebugSectionElf.
+func NewDebugSectionsElf(f *elf.File) *DebugSections {
+	return newDebugSections(func(name string) ([]byte, error) { return GetDebugSectionElf(f, name) })
+}
+
+// NewDebugSectionsPE returns a DebugSections object for f, see GetDebugSectionPE.
+func NewDebugSectionsPE(f *pe.File) *DebugSections {
+	return newDebugSections(func(name string) ([]byte, error) { return GetDebugSectionPE(f, name) })
+}
+
+// NewDebugSectionsMacho returns a DebugSections object for f, see GetDebugSectionMacho.
+func NewDebugSectionsMacho(f *macho.File) *DebugSections {
+	return newDebugSections(func(name string) ([]byte, error) { return GetDebugSectionMacho(f, name) })
+}
+
+func newDebugSections(get func(string) ([]byte, error)) *DebugSections {
+	return &DebugSections{get: get, cache: make(map[string]*debugSection)}
+}
+
+// Get returns the decompressed contents of the specified debug section,
+// for example Get("line") returns the contents of .debug_line.
+// It is safe to call Get concurrently.
+func (s *DebugSections) Get(name string) ([]byte, error) {
+	s.mu.Lock()
+	sec := s.cache[name]
+	if sec == nil {
+		sec = &debugSection{}
+		s.cache[name] = sec
+	}
+	s.mu.Unlock()
+	sec.once.Do(func() {
+		sec.data, sec.err = s.get(name)
+	})
+	return sec.data, sec.err
+}
+
+// DWARF returns the DWARF data of the executable, built from the cached
+// sections so that they are only decompressed once.
+func (s *DebugSections) DWARF() (*dwarf.Data, error) {
+	var dat [5][]byte
+	for i, name := range []string{"abbrev", "info", "line", "ranges", "str"} {
+		dat[i], _ = s.Get(name)
+	}
+	d, err := dwarf.New(dat[0], nil, nil, dat[1], dat[2], nil, dat[3], dat[4])
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"addr", "line_str", "loclists", "rnglists", "str_offsets"} {
+		b, err := s.Get(name)
+		if err != nil || len(b) == 0 {
+			continue
+		}
+		if err := d.AddSection(".debug_"+name, b); err != nil {
+			return nil, err
+		}
+	}
+	if b, err := s.Get("types"); err == nil && len(b) > 0 {
+		if err := d.AddTypes("types", b); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
 // GetDebugSectionElf returns the data contents of the specified debug
 // section, decompressing it if it is compressed.
 // For example GetDebugSectionElf("line") will return the contents of
@@ -19,7 +101,12 @@ import (
 func GetDebugSectionElf(f *elf.File, name string) ([]byte, error) {
 	sec := f.Section(".debug_" + name)
 	if sec != nil {
-		return sec.Data()
+		b, err := sec.Data()
+		if err != nil && sec.Flags&elf.SHF_COMPRESSED != 0 {
+			// sections with the SHF_COMPRESSED flag are decompressed by debug/elf
+			return nil, fmt.Errorf("could not decompress %s section: %v", sec.Name, err)
+		}
+		return b, err
 	}
 	sec = f.Section(".zdebug_" + name)
 	if sec == nil {
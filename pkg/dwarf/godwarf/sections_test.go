@@ -0,0 +1,102 @@
+package godwarf
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"debug/elf"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const sectionsTestSource = `#include <stdio.h>
+
+int f(int x) {
+	int y = x * 2;
+	printf("%d\n", y);
+	return y + 1;
+}
+
+int main(int argc, char **argv) {
+	return f(argc);
+}
+`
+
+func TestCompressedSectionsElf(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("ELF only")
+	}
+	dir, err := ioutil.TempDir("", "sections")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "test.c")
+	if err := ioutil.WriteFile(src, []byte(sectionsTestSource), 0600); err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(dir, "test")
+	// -fno-asynchronous-unwind-tables makes gcc emit .debug_frame instead of .eh_frame
+	if out, err := exec.Command("gcc", "-gdwarf-4", "-O2", "-fno-asynchronous-unwind-tables", "-o", exe, src).CombinedOutput(); err != nil {
+		t.Skipf("could not compile test program: %v\n%s", err, out)
+	}
+
+	open := func(path string) *elf.File {
+		t.Helper()
+		f, err := elf.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	f := open(exe)
+	defer f.Close()
+	names := []string{"info", "abbrev", "line", "frame", "str", "loc", "ranges"}
+	want := make(map[string][]byte)
+	for _, name := range names {
+		want[name], err = GetDebugSectionElf(f, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+
+	for _, compression := range []string{"zlib-gnu", "zlib", "zstd"} {
+		t.Run(compression, func(t *testing.T) {
+			cexe := exe + "." + compression
+			if out, err := exec.Command("objcopy", "--compress-debug-sections="+compression, exe, cexe).CombinedOutput(); err != nil {
+				t.Skipf("objcopy does not support %s: %v\n%s", compression, err, out)
+			}
+			cf := open(cexe)
+			defer cf.Close()
+			sections := NewDebugSectionsElf(cf)
+			for _, name := range names {
+				got, err := sections.Get(name)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if !bytes.Equal(got, want[name]) {
+					t.Errorf("%s: contents mismatch", name)
+				}
+			}
+			dw, err := sections.DWARF()
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			rdr := dw.Reader()
+			for e, err := rdr.Next(); e != nil && err == nil; e, err = rdr.Next() {
+				if name, _ := e.Val(dwarf.AttrName).(string); name == "f" {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("could not find function f")
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/dwarf/util"
@@ -27,6 +28,7 @@ const (
 	AttrGoEmbeddedField dwarf.Attr = 0x2903
 	AttrGoRuntimeType   dwarf.Attr = 0x2904
 	AttrGoPackageName   dwarf.Attr = 0x2905
+	AttrGoDictIndex     dwarf.Attr = 0x2906
 )
 
 // Basic type encodings -- the value for AttrEncoding in a TagBaseType Entry.
@@ -223,6 +225,9 @@ func (t *ArrayType) sizeAlignIntl(recCheck recCheck) (int64, int64) {
 	if t.CommonType.ByteSize != 0 {
 		return t.CommonType.ByteSize, align
 	}
+	if t.Count < 0 {
+		return 0, align
+	}
 	return sz * t.Count, align
 }
 
@@ -270,6 +275,11 @@ type StructField struct {
 	BitOffset  int64 // within the ByteSize bytes at ByteOffset
 	BitSize    int64 // zero if not a bit field
 	Embedded   bool
+
+	// DataBitOffset is the offset of the field in bits from the start of
+	// the struct, for bit fields it is computed from BitOffset when the
+	// producer doesn't emit DW_AT_data_bit_offset.
+	DataBitOffset int64
 }
 
 func (t *StructType) String() string { return t.stringIntl(make(recCheck)) }
@@ -304,7 +314,7 @@ func (t *StructType) Defn(recCheck recCheck) string {
 		s += "@" + strconv.FormatInt(f.ByteOffset, 10)
 		if f.BitSize > 0 {
 			s += " : " + strconv.FormatInt(f.BitSize, 10)
-			s += "@" + strconv.FormatInt(f.BitOffset, 10)
+			s += "@" + strconv.FormatInt(f.DataBitOffset, 10)
 		}
 	}
 	s += "}"
@@ -471,6 +481,15 @@ func (t *TypedefType) sizeAlignIntl(recCheck recCheck) (int64, int64) {
 	return t.Type.sizeAlignIntl(recCheck)
 }
 
+// A ParametricType represents a type parameter of a generic function. It
+// is a typedef of the shape type of the instantiation, the concrete type
+// is described by the entry at DictIndex of the dictionary passed to the
+// function.
+type ParametricType struct {
+	TypedefType
+	DictIndex int64
+}
+
 // A MapType represents a Go map type. It looks like a TypedefType, describing
 // the runtime-internal structure, with extra fields.
 type MapType struct {
@@ -757,6 +776,7 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 		//		AttrByteSize: size in bytes
 		//		AttrBitOffset: bit offset within bytes for bit fields
 		//		AttrBitSize: bit size for bit fields
+		//		AttrDataBitOffset: bit offset from the start of the struct for bit fields (DWARF 4)
 		//		AttrDataMemberLoc: location within struct [required for struct, class]
 		// There is much more to handle C++, all ignored for now.
 		t := new(StructType)
@@ -786,6 +806,7 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 		}
 		t.Name, _ = e.Val(dwarf.AttrName).(string)
 		t.StructName, _ = e.Val(dwarf.AttrName).(string)
+		t.Name = cTypeName(t.Kind, t.Name, getKind(e))
 		t.Incomplete = e.Val(dwarf.AttrDeclaration) != nil
 		t.Field = make([]*StructField, 0, 8)
 		var lastFieldType Type
@@ -840,10 +861,21 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 				f.Embedded, _ = kid.Val(AttrGoEmbeddedField).(bool)
 				t.Field = append(t.Field, f)
 
-				bito := f.BitOffset
-				if !haveBitOffset {
-					bito = f.ByteOffset * 8
+				if dataBitOffset, ok := kid.Val(dwarf.AttrDataBitOffset).(int64); ok {
+					f.DataBitOffset = dataBitOffset
+				} else if haveBitOffset && f.BitSize > 0 {
+					// DWARF 2 bit offsets count from the most significant bit of
+					// the storage unit, which on little endian machines is the
+					// last byte.
+					byteSize := f.ByteSize
+					if byteSize == 0 {
+						byteSize = f.Type.Size()
+					}
+					f.DataBitOffset = f.ByteOffset*8 + byteSize*8 - f.BitOffset - f.BitSize
+				} else {
+					f.DataBitOffset = f.ByteOffset * 8
 				}
+				bito := f.DataBitOffset
 				if bito == lastFieldBitOffset && t.Kind != "union" {
 					// Last field was zero width.  Fix array length.
 					// (DWARF writes out 0-length arrays as if they were 1-length arrays.)
@@ -897,6 +929,7 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 		typeCache[off] = t
 		t.Name, _ = e.Val(dwarf.AttrName).(string)
 		t.EnumName, _ = e.Val(dwarf.AttrName).(string)
+		t.Name = cTypeName("enum", t.Name, t.ReflectKind)
 		t.Val = make([]*EnumValue, 0, 8)
 		for kid := next(); kid != nil; kid = next() {
 			if kid.Tag == dwarf.TagEnumerator {
@@ -972,6 +1005,7 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 		//	AttrType: type definition [required]
 		//	AttrGoKey: present for maps.
 		//	AttrGoElem: present for maps and channels.
+		//	AttrGoDictIndex: present for type parameters.
 		t := new(TypedefType)
 		t.ReflectKind = getKind(e)
 		switch t.ReflectKind {
@@ -994,7 +1028,13 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 			typeCache[off] = it
 			t = &it.TypedefType
 		default:
-			typ = t
+			if dictIndex, ok := e.Val(AttrGoDictIndex).(int64); ok {
+				pt := &ParametricType{DictIndex: dictIndex}
+				typ = pt
+				t = &pt.TypedefType
+			} else {
+				typ = t
+			}
 		}
 		typeCache[off] = typ
 		t.Name, _ = e.Val(dwarf.AttrName).(string)
@@ -1029,6 +1069,10 @@ func readType(d *dwarf.Data, name string, r *dwarf.Reader, off dwarf.Offset, typ
 				*delayedSizes = append(*delayedSizes, delayedSize{typ.Common(), t.Type})
 			case *InterfaceType:
 				*delayedSizes = append(*delayedSizes, delayedSize{typ.Common(), t.Type})
+			case *ArrayType:
+				// C compilers do not always emit the size of arrays, it will be
+				// computed from the size of the elements by sizeAlignIntl.
+				b = 0
 			case *PtrType:
 				b = int64(addressSize)
 			case *FuncType:
@@ -1048,6 +1092,16 @@ Error:
 	return nil, err
 }
 
+// cTypeName returns the name used to refer to a C struct, union or enum
+// type: in C tags live in their own namespace and they are always prefixed
+// by the kind of type. Types without AttrGoKind are assumed to be C types.
+func cTypeName(kind, name string, reflectKind reflect.Kind) string {
+	if name == "" || reflectKind != reflect.Invalid || kind == "class" || strings.Contains(name, ".") {
+		return name
+	}
+	return kind + " " + name
+}
+
 func zeroArray(t Type) {
 	for {
 		at, ok := t.(*ArrayType)
@@ -0,0 +1,60 @@
+package godwarf
+
+import (
+	"debug/dwarf"
+	"encoding/binary"
+)
+
+const (
+	_DW_UT_type          = 0x2
+	_DW_UT_skeleton      = 0x4
+	_DW_UT_split_compile = 0x5
+	_DW_UT_split_type    = 0x6
+)
+
+// UnitVersions returns the DWARF version of each unit of the debug_info
+// section data, keyed by the offset of the first entry of the unit (the
+// Offset field of the dwarf.Entry of a compile unit).
+// See DWARFv5 section 7.5.1 page 199 and following.
+func UnitVersions(data []byte, byteOrder binary.ByteOrder) map[dwarf.Offset]uint16 {
+	r := make(map[dwarf.Offset]uint16)
+	off := 0
+	for off+4 <= len(data) {
+		unitLength := uint64(byteOrder.Uint32(data[off:]))
+		hdrsz, offsz := 4, 4
+		switch {
+		case unitLength == 0xffffffff:
+			if off+12 > len(data) {
+				return r
+			}
+			unitLength = byteOrder.Uint64(data[off+4:])
+			hdrsz, offsz = 12, 8
+		case unitLength >= 0xfffffff0:
+			// reserved
+			return r
+		}
+		start := off + hdrsz
+		if unitLength < 2 || uint64(len(data)-start) < unitLength {
+			return r
+		}
+		version := byteOrder.Uint16(data[start:])
+		var sz int
+		if version >= 5 {
+			sz = 2 + 1 + 1 + offsz // version, unit_type, address_size, debug_abbrev_offset
+			if unitLength < 3 {
+				return r
+			}
+			switch data[start+2] {
+			case _DW_UT_skeleton, _DW_UT_split_compile:
+				sz += 8 // dwo_id
+			case _DW_UT_type, _DW_UT_split_type:
+				sz += 8 + offsz // type_signature, type_offset
+			}
+		} else {
+			sz = 2 + offsz + 1 // version, debug_abbrev_offset, address_size
+		}
+		r[dwarf.Offset(start+sz)] = version
+		off = start + int(unitLength)
+	}
+	return r
+}
@@ -0,0 +1,49 @@
+package godwarf
+
+import (
+	"debug/dwarf"
+	"encoding/binary"
+	"testing"
+)
+
+func TestUnitVersions(t *testing.T) {
+	data := []byte{
+		// DWARF 4 compile unit
+		0x8, 0x0, 0x0, 0x0, // length
+		0x4, 0x0, // version
+		0x0, 0x0, 0x0, 0x0, // debug_abbrev_offset
+		0x8, // address_size
+		0x0, // null entry
+
+		// DWARF 5 compile unit
+		0x9, 0x0, 0x0, 0x0, // length
+		0x5, 0x0, // version
+		0x1,                // unit_type (DW_UT_compile)
+		0x8,                // address_size
+		0x0, 0x0, 0x0, 0x0, // debug_abbrev_offset
+		0x0, // null entry
+
+		// DWARF 5 skeleton unit
+		0x11, 0x0, 0x0, 0x0, // length
+		0x5, 0x0, // version
+		0x4,                // unit_type (DW_UT_skeleton)
+		0x8,                // address_size
+		0x0, 0x0, 0x0, 0x0, // debug_abbrev_offset
+		0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8, // dwo_id
+		0x0, // null entry
+
+		// truncated unit
+		0xff, 0x0, 0x0, 0x0,
+		0x5, 0x0,
+	}
+	got := UnitVersions(data, binary.LittleEndian)
+	want := map[dwarf.Offset]uint16{11: 4, 24: 5, 45: 5}
+	if len(got) != len(want) {
+		t.Fatalf("got %v expected %v", got, want)
+	}
+	for off, v := range want {
+		if got[off] != v {
+			t.Errorf("got %v expected %v", got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package line
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"path/filepath"
 	"strings"
 
@@ -12,6 +13,8 @@ import (
 type DebugLinePrologue struct {
 	UnitLength     uint32
 	Version        uint16
+	AddrSize       uint8 // only present in DWARF 5
+	SegSelSize     uint8 // only present in DWARF 5
 	Length         uint32
 	MinInstrLength uint8
 	MaxOpPerInstr  uint8
@@ -43,6 +46,14 @@ type DebugLineInfo struct {
 	// if normalizeBackslash is true all backslashes (\) will be converted into forward slashes (/)
 	normalizeBackslash bool
 	ptrSize            int
+
+	// debugLineStr is the contents of the .debug_line_str section, used to
+	// resolve DW_FORM_line_strp attributes in DWARF 5 line tables.
+	debugLineStr []byte
+
+	// noFile0 is true if entry 0 of a DWARF 5 file names table was a
+	// placeholder and has been left out of FileNames, see parseFileEntries5.
+	noFile0 bool
 }
 
 type FileEntry struct {
@@ -55,7 +66,7 @@ type FileEntry struct {
 type DebugLines []*DebugLineInfo
 
 // ParseAll parses all debug_line segments found in data
-func ParseAll(data []byte, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) DebugLines {
+func ParseAll(data []byte, debugLineStr []byte, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) DebugLines {
 	var (
 		lines = make(DebugLines, 0)
 		buf   = bytes.NewBuffer(data)
@@ -63,47 +74,76 @@ func ParseAll(data []byte, logfn func(string, ...interface{}), staticBase uint64
 
 	// We have to parse multiple file name tables here.
 	for buf.Len() > 0 {
-		lines = append(lines, Parse("", buf, logfn, staticBase, normalizeBackslash, ptrSize))
+		lines = append(lines, Parse("", buf, debugLineStr, logfn, staticBase, normalizeBackslash, ptrSize))
 	}
 
 	return lines
 }
 
 // Parse parses a single debug_line segment from buf. Compdir is the
-// DW_AT_comp_dir attribute of the associated compile unit.
-func Parse(compdir string, buf *bytes.Buffer, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) *DebugLineInfo {
+// DW_AT_comp_dir attribute of the associated compile unit, debugLineStr
+// is the contents of the .debug_line_str section (only used by DWARF 5).
+func Parse(compdir string, buf *bytes.Buffer, debugLineStr []byte, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) *DebugLineInfo {
 	dbl := new(DebugLineInfo)
 	dbl.Logf = logfn
 	dbl.staticBase = staticBase
 	dbl.ptrSize = ptrSize
 	dbl.Lookup = make(map[string]*FileEntry)
-	dbl.IncludeDirs = append(dbl.IncludeDirs, compdir)
+	dbl.debugLineStr = debugLineStr
 
 	dbl.stateMachineCache = make(map[uint64]*StateMachine)
 	dbl.lastMachineCache = make(map[uint64]*StateMachine)
 	dbl.normalizeBackslash = normalizeBackslash
 
-	parseDebugLinePrologue(dbl, buf)
-	parseIncludeDirs(dbl, buf)
-	parseFileEntries(dbl, buf)
+	unitLen := buf.Len()
+	headerEnd := parseDebugLinePrologue(dbl, buf)
+	if dbl.Prologue.Version >= 5 {
+		if err := parseIncludeDirs5(dbl, compdir, buf); err != nil {
+			dbl.logf("error reading include directories of debug_line version %d: %v", dbl.Prologue.Version, err)
+		} else if err := parseFileEntries5(dbl, buf); err != nil {
+			dbl.logf("error reading file entries of debug_line version %d: %v", dbl.Prologue.Version, err)
+		}
+	} else {
+		dbl.IncludeDirs = append(dbl.IncludeDirs, compdir)
+		parseIncludeDirs(dbl, buf)
+		parseFileEntries(dbl, buf)
+	}
+
+	// Skip anything left in the prologue that we didn't understand.
+	if n := buf.Len() - headerEnd; n > 0 {
+		buf.Next(n)
+	}
 
 	// Instructions size calculation breakdown:
 	//   - dbl.Prologue.UnitLength is the length of the entire unit, not including the 4 bytes to represent that length.
-	//   - dbl.Prologue.Length is the length of the prologue not including unit length, version or prologue length itself.
-	//   - So you have UnitLength - PrologueLength - (version_length_bytes(2) + prologue_length_bytes(4)).
-	dbl.Instructions = buf.Next(int(dbl.Prologue.UnitLength - dbl.Prologue.Length - 6))
+	//   - unitLen - buf.Len() is the number of bytes read so far, including the unit length field.
+	dbl.Instructions = buf.Next(int(dbl.Prologue.UnitLength) + 4 - (unitLen - buf.Len()))
 
 	return dbl
 }
 
-func parseDebugLinePrologue(dbl *DebugLineInfo, buf *bytes.Buffer) {
+func (dbl *DebugLineInfo) logf(fmtstr string, args ...interface{}) {
+	if dbl.Logf != nil {
+		dbl.Logf(fmtstr, args...)
+	}
+}
+
+// parseDebugLinePrologue parses the prologue of a debug_line segment and
+// returns the number of bytes that will be left in buf at the end of the
+// prologue.
+func parseDebugLinePrologue(dbl *DebugLineInfo, buf *bytes.Buffer) int {
 	p := new(DebugLinePrologue)
 
 	p.UnitLength = binary.LittleEndian.Uint32(buf.Next(4))
 	p.Version = binary.LittleEndian.Uint16(buf.Next(2))
+	if p.Version >= 5 {
+		p.AddrSize = uint8(buf.Next(1)[0])
+		p.SegSelSize = uint8(buf.Next(1)[0])
+	}
 	p.Length = binary.LittleEndian.Uint32(buf.Next(4))
+	headerEnd := buf.Len() - int(p.Length)
 	p.MinInstrLength = uint8(buf.Next(1)[0])
-	if p.Version == 4 {
+	if p.Version >= 4 {
 		p.MaxOpPerInstr = uint8(buf.Next(1)[0])
 	} else {
 		p.MaxOpPerInstr = 1
@@ -117,6 +157,7 @@ func parseDebugLinePrologue(dbl *DebugLineInfo, buf *bytes.Buffer) {
 	binary.Read(buf, binary.LittleEndian, &p.StdOpLengths)
 
 	dbl.Prologue = p
+	return headerEnd
 }
 
 func parseIncludeDirs(info *DebugLineInfo, buf *bytes.Buffer) {
@@ -165,3 +206,206 @@ func readFileEntry(info *DebugLineInfo, buf *bytes.Buffer, exitOnEmptyPath bool)
 
 	return entry
 }
+
+// Content type codes used in DWARF 5 directory and file name entry formats
+// (see section 6.2.4.1, DWARF v5).
+const (
+	_DW_LNCT_path            = 0x1
+	_DW_LNCT_directory_index = 0x2
+	_DW_LNCT_timestamp       = 0x3
+	_DW_LNCT_size            = 0x4
+	_DW_LNCT_MD5             = 0x5
+)
+
+// Forms that can appear in DWARF 5 directory and file name entry formats.
+const (
+	_DW_FORM_block     = 0x09
+	_DW_FORM_block1    = 0x0a
+	_DW_FORM_block2    = 0x03
+	_DW_FORM_block4    = 0x04
+	_DW_FORM_data1     = 0x0b
+	_DW_FORM_data2     = 0x05
+	_DW_FORM_data4     = 0x06
+	_DW_FORM_data8     = 0x07
+	_DW_FORM_data16    = 0x1e
+	_DW_FORM_string    = 0x08
+	_DW_FORM_strp      = 0x0e
+	_DW_FORM_line_strp = 0x1f
+	_DW_FORM_udata     = 0x0f
+	_DW_FORM_strx      = 0x1a
+	_DW_FORM_strx1     = 0x25
+	_DW_FORM_strx2     = 0x26
+	_DW_FORM_strx3     = 0x27
+	_DW_FORM_strx4     = 0x28
+)
+
+var errUnsupportedForm = errors.New("unsupported form")
+
+type entryFormat struct {
+	contentType uint64
+	form        uint64
+}
+
+func readEntryFormats(buf *bytes.Buffer) []entryFormat {
+	n := int(buf.Next(1)[0])
+	formats := make([]entryFormat, n)
+	for i := range formats {
+		formats[i].contentType, _ = util.DecodeULEB128(buf)
+		formats[i].form, _ = util.DecodeULEB128(buf)
+	}
+	return formats
+}
+
+// readEntry5 reads a single directory or file name entry of a DWARF 5 line
+// table, described by formats. Integer values are returned in ints, string
+// values in strs, both indexed by content type.
+func (dbl *DebugLineInfo) readEntry5(buf *bytes.Buffer, formats []entryFormat) (ints map[uint64]uint64, strs map[uint64]string, err error) {
+	ints = make(map[uint64]uint64)
+	strs = make(map[uint64]string)
+	for _, format := range formats {
+		switch format.form {
+		case _DW_FORM_string:
+			strs[format.contentType], _ = util.ParseString(buf)
+		case _DW_FORM_line_strp:
+			off := uint64(binary.LittleEndian.Uint32(buf.Next(4)))
+			if off >= uint64(len(dbl.debugLineStr)) {
+				return nil, nil, errors.New("invalid .debug_line_str offset")
+			}
+			strs[format.contentType], _ = util.ParseString(bytes.NewBuffer(dbl.debugLineStr[off:]))
+		case _DW_FORM_strp:
+			// .debug_str is not available to the line table parser
+			buf.Next(4)
+		case _DW_FORM_strx, _DW_FORM_udata:
+			ints[format.contentType], _ = util.DecodeULEB128(buf)
+		case _DW_FORM_strx1, _DW_FORM_data1:
+			ints[format.contentType] = uint64(buf.Next(1)[0])
+		case _DW_FORM_strx2, _DW_FORM_data2:
+			ints[format.contentType] = uint64(binary.LittleEndian.Uint16(buf.Next(2)))
+		case _DW_FORM_strx3:
+			b := buf.Next(3)
+			ints[format.contentType] = uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16
+		case _DW_FORM_strx4, _DW_FORM_data4:
+			ints[format.contentType] = uint64(binary.LittleEndian.Uint32(buf.Next(4)))
+		case _DW_FORM_data8:
+			ints[format.contentType] = binary.LittleEndian.Uint64(buf.Next(8))
+		case _DW_FORM_data16:
+			buf.Next(16)
+		case _DW_FORM_block:
+			n, _ := util.DecodeULEB128(buf)
+			buf.Next(int(n))
+		case _DW_FORM_block1:
+			buf.Next(int(buf.Next(1)[0]))
+		case _DW_FORM_block2:
+			buf.Next(int(binary.LittleEndian.Uint16(buf.Next(2))))
+		case _DW_FORM_block4:
+			buf.Next(int(binary.LittleEndian.Uint32(buf.Next(4))))
+		default:
+			return nil, nil, errUnsupportedForm
+		}
+	}
+	return ints, strs, nil
+}
+
+func parseIncludeDirs5(info *DebugLineInfo, compdir string, buf *bytes.Buffer) error {
+	formats := readEntryFormats(buf)
+	n, _ := util.DecodeULEB128(buf)
+	for i := uint64(0); i < n; i++ {
+		_, strs, err := info.readEntry5(buf, formats)
+		if err != nil {
+			return err
+		}
+		dir := strs[_DW_LNCT_path]
+		if info.normalizeBackslash {
+			dir = strings.Replace(dir, "\\", "/", -1)
+		}
+		// Directory 0 is the compilation directory, all other relative
+		// directories are relative to it.
+		if !filepath.IsAbs(dir) && !isWindowsAbs(dir) {
+			if i == 0 {
+				if compdir != "" {
+					dir = filepath.Join(compdir, dir)
+				}
+			} else {
+				dir = filepath.Join(info.IncludeDirs[0], dir)
+			}
+		}
+		info.IncludeDirs = append(info.IncludeDirs, dir)
+	}
+	return nil
+}
+
+func parseFileEntries5(info *DebugLineInfo, buf *bytes.Buffer) error {
+	formats := readEntryFormats(buf)
+	n, _ := util.DecodeULEB128(buf)
+	for i := uint64(0); i < n; i++ {
+		ints, strs, err := info.readEntry5(buf, formats)
+		if err != nil {
+			return err
+		}
+		if i == 0 && strs[_DW_LNCT_path] == "?" {
+			// Entry 0 is the primary source file of the compile unit, the Go
+			// linker doesn't have one and writes "?" instead. Go line programs
+			// never refer to it, leave it out so that it doesn't end up in the
+			// list of sources.
+			info.noFile0 = true
+			continue
+		}
+		entry := new(FileEntry)
+		entry.Path = strs[_DW_LNCT_path]
+		if info.normalizeBackslash {
+			entry.Path = strings.Replace(entry.Path, "\\", "/", -1)
+		}
+		entry.DirIdx = ints[_DW_LNCT_directory_index]
+		entry.LastModTime = ints[_DW_LNCT_timestamp]
+		entry.Length = ints[_DW_LNCT_size]
+		if !filepath.IsAbs(entry.Path) && !isWindowsAbs(entry.Path) {
+			if entry.DirIdx < uint64(len(info.IncludeDirs)) {
+				entry.Path = filepath.Join(info.IncludeDirs[entry.DirIdx], entry.Path)
+			}
+		}
+
+		info.FileNames = append(info.FileNames, entry)
+		info.Lookup[entry.Path] = entry
+	}
+	return nil
+}
+
+// isWindowsAbs returns true if path is an absolute windows path, after
+// backslashes have been normalized.
+func isWindowsAbs(path string) bool {
+	return len(path) >= 3 && path[1] == ':' && path[2] == '/'
+}
+
+// FileAt returns the entry of the file names table corresponding to the
+// file index i, as used by DW_LNS_set_file and DW_AT_decl_file/DW_AT_call_file
+// attributes, or nil if no such entry exists.
+// File indexes start at 1 in DWARF 2 to 4 and at 0 in DWARF 5.
+func (dbl *DebugLineInfo) FileAt(i uint64) *FileEntry {
+	if dbl.firstFileIndex() == 1 {
+		if i == 0 {
+			return nil
+		}
+		i--
+	}
+	if i >= uint64(len(dbl.FileNames)) {
+		return nil
+	}
+	return dbl.FileNames[i]
+}
+
+// firstFileIndex returns the file index of FileNames[0].
+func (dbl *DebugLineInfo) firstFileIndex() uint64 {
+	if dbl.Prologue.Version < 5 || dbl.noFile0 {
+		return 1
+	}
+	return 0
+}
+
+// defaultFile returns the path of the file corresponding to the initial
+// value of the file register of the state machine.
+func (dbl *DebugLineInfo) defaultFile() string {
+	if entry := dbl.FileAt(1); entry != nil {
+		return entry.Path
+	}
+	return ""
+}
@@ -27,7 +27,7 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func grabDebugLineSection(p string, t *testing.T) []byte {
+func grabDebugLineSection(p string, t *testing.T) (debugLine, debugLineStr []byte) {
 	f, err := os.Open(p)
 	if err != nil {
 		t.Fatal(err)
@@ -36,23 +36,26 @@ func grabDebugLineSection(p string, t *testing.T) []byte {
 
 	ef, err := elf.NewFile(f)
 	if err == nil {
-		data, _ := godwarf.GetDebugSectionElf(ef, "line")
-		return data
+		debugLine, _ = godwarf.GetDebugSectionElf(ef, "line")
+		debugLineStr, _ = godwarf.GetDebugSectionElf(ef, "line_str")
+		return
 	}
 
 	pf, err := pe.NewFile(f)
 	if err == nil {
-		data, _ := godwarf.GetDebugSectionPE(pf, "line")
-		return data
+		debugLine, _ = godwarf.GetDebugSectionPE(pf, "line")
+		debugLineStr, _ = godwarf.GetDebugSectionPE(pf, "line_str")
+		return
 	}
 
 	mf, err := macho.NewFile(f)
 	if err == nil {
-		data, _ := godwarf.GetDebugSectionMacho(mf, "line")
-		return data
+		debugLine, _ = godwarf.GetDebugSectionMacho(mf, "line")
+		debugLineStr, _ = godwarf.GetDebugSectionMacho(mf, "line_str")
+		return
 	}
 
-	return nil
+	return nil, nil
 }
 
 const (
@@ -62,6 +65,7 @@ const (
 	lineRangeGo18   uint8  = 10
 	versionGo14     uint16 = 2
 	versionGo111    uint16 = 3
+	versionGo5      uint16 = 5
 	opcodeBaseGo14  uint8  = 10
 	opcodeBaseGo111 uint8  = 11
 )
@@ -71,14 +75,14 @@ func ptrSizeByRuntimeArch() int {
 }
 
 func testDebugLinePrologueParser(p string, t *testing.T) {
-	data := grabDebugLineSection(p, t)
-	debugLines := ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+	data, debugLineStr := grabDebugLineSection(p, t)
+	debugLines := ParseAll(data, debugLineStr, nil, 0, true, ptrSizeByRuntimeArch())
 	mainFileFound := false
 
 	for _, dbl := range debugLines {
 		prologue := dbl.Prologue
 
-		if prologue.Version != versionGo14 && prologue.Version != versionGo111 {
+		if prologue.Version != versionGo14 && prologue.Version != versionGo111 && prologue.Version != versionGo5 {
 			t.Fatal("Version not parsed correctly", prologue.Version)
 		}
 
@@ -113,7 +117,7 @@ func testDebugLinePrologueParser(p string, t *testing.T) {
 			}
 		}
 
-		if len(dbl.IncludeDirs) != 1 {
+		if prologue.Version < 5 && len(dbl.IncludeDirs) != 1 {
 			t.Fatal("Include dirs not parsed correctly")
 		}
 
@@ -172,11 +176,11 @@ func BenchmarkLineParser(b *testing.B) {
 	}
 	defer os.Remove(p)
 
-	data := grabDebugLineSection(p, nil)
+	data, debugLineStr := grabDebugLineSection(p, nil)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+		_ = ParseAll(data, debugLineStr, nil, 0, true, ptrSizeByRuntimeArch())
 	}
 }
 
@@ -191,7 +195,7 @@ func loadBenchmarkData(tb testing.TB) DebugLines {
 		tb.Fatal("Could not read test data", err)
 	}
 
-	return ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+	return ParseAll(data, nil, nil, 0, true, ptrSizeByRuntimeArch())
 }
 
 func BenchmarkStateMachine(b *testing.B) {
@@ -311,7 +315,7 @@ func TestDebugLineC(t *testing.T) {
 		t.Fatal("Could not read test data", err)
 	}
 
-	parsed := ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+	parsed := ParseAll(data, nil, nil, 0, true, ptrSizeByRuntimeArch())
 
 	if len(parsed) == 0 {
 		t.Fatal("Parser result is empty")
@@ -360,7 +364,7 @@ func TestDebugLineDwarf4(t *testing.T) {
 		t.Fatal("Could not read test data", err)
 	}
 
-	debugLines := ParseAll(data, nil, 0, true, 8)
+	debugLines := ParseAll(data, nil, nil, 0, true, 8)
 
 	for _, dbl := range debugLines {
 		if dbl.Prologue.Version == 4 {
@@ -113,7 +113,7 @@ func newStateMachine(dbl *DebugLineInfo, instructions []byte, ptrSize int) *Stat
 	}
 	sm := &StateMachine{
 		dbl:         dbl,
-		file:        dbl.FileNames[0].Path,
+		file:        dbl.defaultFile(),
 		line:        1,
 		buf:         bytes.NewBuffer(instructions),
 		opcodes:     opcodes,
@@ -433,7 +433,7 @@ func (sm *StateMachine) next() error {
 	}
 	if sm.endSeq {
 		sm.endSeq = false
-		sm.file = sm.dbl.FileNames[0].Path
+		sm.file = sm.dbl.defaultFile()
 		sm.line = 1
 		sm.column = 0
 		sm.isa = 0
@@ -501,10 +501,10 @@ func advanceline(sm *StateMachine, buf *bytes.Buffer) {
 
 func setfile(sm *StateMachine, buf *bytes.Buffer) {
 	i, _ := util.DecodeULEB128(buf)
-	if i-1 < uint64(len(sm.dbl.FileNames)) {
-		sm.file = sm.dbl.FileNames[i-1].Path
+	if entry := sm.dbl.FileAt(i); entry != nil {
+		sm.file = entry.Path
 	} else {
-		j := (i - 1) - uint64(len(sm.dbl.FileNames))
+		j := i - sm.dbl.firstFileIndex() - uint64(len(sm.dbl.FileNames))
 		if j < uint64(len(sm.definedFiles)) {
 			sm.file = sm.definedFiles[j].Path
 		} else {
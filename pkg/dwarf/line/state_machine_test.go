@@ -4,15 +4,19 @@ import (
 	"bytes"
 	"compress/gzip"
 	"debug/dwarf"
+	"debug/elf"
 	"debug/macho"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/util"
 )
 
@@ -79,7 +83,7 @@ func TestGrafana(t *testing.T) {
 		}
 		cuname, _ := e.Val(dwarf.AttrName).(string)
 
-		lineInfo := Parse(e.Val(dwarf.AttrCompDir).(string), debugLineBuffer, t.Logf, 0, false, 8)
+		lineInfo := Parse(e.Val(dwarf.AttrCompDir).(string), debugLineBuffer, nil, t.Logf, 0, false, 8)
 		sm := newStateMachine(lineInfo, lineInfo.Instructions, 8)
 
 		lnrdr, err := data.LineReader(e)
@@ -268,3 +272,105 @@ func TestMultipleSequences(t *testing.T) {
 		}
 	}
 }
+
+func TestDwarf5(t *testing.T) {
+	// Compares a full execution of our state machine on DWARF 5 line tables
+	// produced by the go compiler and by gcc to the output generated using
+	// debug/dwarf.LineReader on the same section.
+
+	if runtime.GOOS != "linux" {
+		t.Skip("test only supported on linux")
+	}
+
+	dir, err := ioutil.TempDir("", "dwarf5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var exes []string
+
+	gofile, err := filepath.Abs("../../../_fixtures/testnextprog.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goexe := filepath.Join(dir, "testnextprog")
+	if out, err := exec.Command("go", "build", "-gcflags=-N -l", "-o", goexe, gofile).CombinedOutput(); err != nil {
+		t.Fatalf("could not compile %s: %v\n%s", gofile, err, out)
+	}
+	exes = append(exes, goexe)
+
+	if _, err := exec.LookPath("gcc"); err == nil {
+		cfile := filepath.Join(dir, "main.c")
+		cexe := filepath.Join(dir, "cprog")
+		err := ioutil.WriteFile(cfile, []byte("#include <stdio.h>\n\nint main(void) {\n\tprintf(\"hello\\n\");\n\treturn 0;\n}\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command("gcc", "-gdwarf-5", "-O0", "-o", cexe, cfile).CombinedOutput(); err != nil {
+			t.Logf("could not compile %s with -gdwarf-5, skipping: %v\n%s", cfile, err, out)
+		} else {
+			exes = append(exes, cexe)
+		}
+	}
+
+	found := false
+
+	for _, exepath := range exes {
+		exe, err := elf.Open(exepath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer exe.Close()
+
+		debugLineBytes, err := godwarf.GetDebugSectionElf(exe, "line")
+		if err != nil {
+			t.Fatal(err)
+		}
+		debugLineStrBytes, _ := godwarf.GetDebugSectionElf(exe, "line_str")
+
+		data, err := exe.DWARF()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rdr := data.Reader()
+		for {
+			e, err := rdr.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e == nil {
+				break
+			}
+			rdr.SkipChildren()
+			if e.Tag != dwarf.TagCompileUnit {
+				continue
+			}
+			off, ok := e.Val(dwarf.AttrStmtList).(int64)
+			if !ok {
+				continue
+			}
+			cuname, _ := e.Val(dwarf.AttrName).(string)
+			compdir, _ := e.Val(dwarf.AttrCompDir).(string)
+
+			lineInfo := Parse(compdir, bytes.NewBuffer(debugLineBytes[off:]), debugLineStrBytes, t.Logf, 0, false, 8)
+			if lineInfo.Prologue.Version < 5 {
+				continue
+			}
+			found = true
+			sm := newStateMachine(lineInfo, lineInfo.Instructions, 8)
+
+			lnrdr, err := data.LineReader(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkCompileUnit(t, cuname, lnrdr, sm)
+		}
+	}
+
+	if !found {
+		t.Skip("no DWARF 5 line tables found")
+	}
+}
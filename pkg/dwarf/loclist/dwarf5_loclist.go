@@ -0,0 +1,193 @@
+package loclist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// Location list entry kinds (see section 7.7.3, DWARF v5).
+const (
+	_DW_LLE_end_of_list      uint8 = 0x0
+	_DW_LLE_base_addressx    uint8 = 0x1
+	_DW_LLE_startx_endx      uint8 = 0x2
+	_DW_LLE_startx_length    uint8 = 0x3
+	_DW_LLE_offset_pair      uint8 = 0x4
+	_DW_LLE_default_location uint8 = 0x5
+	_DW_LLE_base_address     uint8 = 0x6
+	_DW_LLE_start_end        uint8 = 0x7
+	_DW_LLE_start_length     uint8 = 0x8
+)
+
+// Dwarf5Reader parses and presents DWARF loclist information for DWARF version 5 and later.
+// See DWARFv5 section 7.29 page 243 and following.
+type Dwarf5Reader struct {
+	byteOrder binary.ByteOrder
+	ptrSz     int
+	data      []byte
+}
+
+// NewDwarf5Reader returns an initialized loclist Reader for DWARF version 5
+// and later, reading from the contents of the debug_loclists section.
+func NewDwarf5Reader(data []byte, ptrSz int) *Dwarf5Reader {
+	if len(data) == 0 {
+		return nil
+	}
+	return &Dwarf5Reader{byteOrder: binary.LittleEndian, ptrSz: ptrSz, data: data}
+}
+
+// Empty returns true if this reader has no data.
+func (rdr *Dwarf5Reader) Empty() bool {
+	return rdr == nil
+}
+
+// Find returns the loclist entry for the specified PC address, inside the
+// loclist starting at off. Base is the base address of the compile unit and
+// staticBase is the static base at which the image is loaded.
+// If no entry covers pc the default location, if any, is returned.
+func (rdr *Dwarf5Reader) Find(off int, staticBase, base, pc uint64, debugAddr *godwarf.DebugAddr) (*Entry, error) {
+	it := &loclistsIterator{rdr: rdr, debugAddr: debugAddr, buf: bytes.NewBuffer(rdr.data), base: base}
+	it.buf.Next(off)
+
+	var defaultEntry *Entry
+
+	for it.next() {
+		if !it.onRange {
+			continue
+		}
+		if it.isDefault {
+			defaultEntry = &Entry{LowPC: 0, HighPC: ^uint64(0), Instr: it.instr}
+			continue
+		}
+		if pc >= it.start+staticBase && pc < it.end+staticBase {
+			return &Entry{LowPC: it.start, HighPC: it.end, Instr: it.instr}, nil
+		}
+	}
+
+	if it.err != nil {
+		return nil, it.err
+	}
+	return defaultEntry, nil
+}
+
+// Covers returns the list of address ranges covered by the loclist
+// starting at off.
+func (rdr *Dwarf5Reader) Covers(off int, base uint64, debugAddr *godwarf.DebugAddr) ([][2]uint64, error) {
+	it := &loclistsIterator{rdr: rdr, debugAddr: debugAddr, buf: bytes.NewBuffer(rdr.data), base: base}
+	it.buf.Next(off)
+
+	r := [][2]uint64{}
+	for it.next() {
+		if !it.onRange {
+			continue
+		}
+		r = append(r, [2]uint64{it.start, it.end})
+	}
+	return r, it.err
+}
+
+type loclistsIterator struct {
+	rdr       *Dwarf5Reader
+	debugAddr *godwarf.DebugAddr
+	buf       *bytes.Buffer
+	base      uint64 // base for offsets in the list
+
+	onRange   bool
+	atEnd     bool
+	isDefault bool
+	start     uint64
+	end       uint64
+	instr     []byte
+
+	err error
+}
+
+// next reads the next entry of the location list, returning false at the
+// end of the list or if an error occurs.
+func (it *loclistsIterator) next() bool {
+	if it.err != nil || it.atEnd {
+		return false
+	}
+	if it.buf.Len() == 0 {
+		it.err = errors.New("unexpected end of debug_loclists section")
+		return false
+	}
+	opcode, _ := it.buf.ReadByte()
+	it.onRange = false
+	it.isDefault = false
+	switch opcode {
+	case _DW_LLE_end_of_list:
+		it.atEnd = true
+		return false
+
+	case _DW_LLE_base_addressx:
+		baseIdx, _ := util.DecodeULEB128(it.buf)
+		it.base, it.err = it.debugAddr.Get(baseIdx)
+
+	case _DW_LLE_startx_endx:
+		startIdx, _ := util.DecodeULEB128(it.buf)
+		endIdx, _ := util.DecodeULEB128(it.buf)
+		it.readInstr()
+
+		it.start, it.err = it.debugAddr.Get(startIdx)
+		if it.err == nil {
+			it.end, it.err = it.debugAddr.Get(endIdx)
+		}
+		it.onRange = true
+
+	case _DW_LLE_startx_length:
+		startIdx, _ := util.DecodeULEB128(it.buf)
+		length, _ := util.DecodeULEB128(it.buf)
+		it.readInstr()
+
+		it.start, it.err = it.debugAddr.Get(startIdx)
+		it.end = it.start + length
+		it.onRange = true
+
+	case _DW_LLE_offset_pair:
+		off1, _ := util.DecodeULEB128(it.buf)
+		off2, _ := util.DecodeULEB128(it.buf)
+		it.readInstr()
+
+		it.start = it.base + off1
+		it.end = it.base + off2
+		it.onRange = true
+
+	case _DW_LLE_default_location:
+		it.readInstr()
+		it.isDefault = true
+		it.onRange = true
+
+	case _DW_LLE_base_address:
+		it.base, it.err = util.ReadUintRaw(it.buf, it.rdr.byteOrder, it.rdr.ptrSz)
+
+	case _DW_LLE_start_end:
+		it.start, it.err = util.ReadUintRaw(it.buf, it.rdr.byteOrder, it.rdr.ptrSz)
+		if it.err == nil {
+			it.end, it.err = util.ReadUintRaw(it.buf, it.rdr.byteOrder, it.rdr.ptrSz)
+		}
+		it.readInstr()
+		it.onRange = true
+
+	case _DW_LLE_start_length:
+		it.start, it.err = util.ReadUintRaw(it.buf, it.rdr.byteOrder, it.rdr.ptrSz)
+		length, _ := util.DecodeULEB128(it.buf)
+		it.readInstr()
+		it.end = it.start + length
+		it.onRange = true
+
+	default:
+		it.err = fmt.Errorf("unknown debug_loclists entry kind %#x", opcode)
+	}
+
+	return it.err == nil
+}
+
+func (it *loclistsIterator) readInstr() {
+	length, _ := util.DecodeULEB128(it.buf)
+	it.instr = it.buf.Next(int(length))
+}
@@ -2,33 +2,51 @@ package loclist
 
 import (
 	"encoding/binary"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 )
 
-// Reader parses and presents DWARF loclist information.
-type Reader struct {
+// Reader represents a loclist reader, for either the debug_loc section
+// (DWARF 2 to 4) or the debug_loclists section (DWARF 5).
+type Reader interface {
+	// Find returns the loclist entry, in the location list starting at off,
+	// that applies to address pc. Addresses in the location list are
+	// relative to base and are relocated by staticBase.
+	Find(off int, staticBase, base, pc uint64, debugAddr *godwarf.DebugAddr) (*Entry, error)
+	// Covers returns the list of address ranges covered by the location
+	// list starting at off.
+	Covers(off int, base uint64, debugAddr *godwarf.DebugAddr) ([][2]uint64, error)
+	// Empty returns true if this reader has no data.
+	Empty() bool
+}
+
+// Dwarf2Reader parses and presents DWARF loclist information for DWARF
+// versions 2 through 4.
+type Dwarf2Reader struct {
 	data  []byte
 	cur   int
 	ptrSz int
 }
 
-// New returns an initialized loclist Reader.
-func New(data []byte, ptrSz int) *Reader {
-	return &Reader{data: data, ptrSz: ptrSz}
+// NewDwarf2Reader returns an initialized loclist Reader for DWARF versions
+// 2 through 4.
+func NewDwarf2Reader(data []byte, ptrSz int) *Dwarf2Reader {
+	return &Dwarf2Reader{data: data, ptrSz: ptrSz}
 }
 
 // Empty returns true if this reader has no data.
-func (rdr *Reader) Empty() bool {
+func (rdr *Dwarf2Reader) Empty() bool {
 	return rdr.data == nil
 }
 
 // Seek moves the data pointer to the specified offset.
-func (rdr *Reader) Seek(off int) {
+func (rdr *Dwarf2Reader) Seek(off int) {
 	rdr.cur = off
 }
 
 // Next advances the reader to the next loclist entry, returning
 // the entry and true if successful, or nil, false if not.
-func (rdr *Reader) Next(e *Entry) bool {
+func (rdr *Dwarf2Reader) Next(e *Entry) bool {
 	e.LowPC = rdr.oneAddr()
 	e.HighPC = rdr.oneAddr()
 
@@ -46,13 +64,47 @@ func (rdr *Reader) Next(e *Entry) bool {
 	return true
 }
 
-func (rdr *Reader) read(sz int) []byte {
+// Find returns the loclist entry for the specified PC address, inside the
+// loclist starting at off. Base is the base address of the compile unit and
+// staticBase is the static base at which the image is loaded.
+func (rdr *Dwarf2Reader) Find(off int, staticBase, base, pc uint64, debugAddr *godwarf.DebugAddr) (*Entry, error) {
+	rdr.Seek(off)
+	var e Entry
+	for rdr.Next(&e) {
+		if e.BaseAddressSelection() {
+			base = e.HighPC
+			continue
+		}
+		if pc >= e.LowPC+base+staticBase && pc < e.HighPC+base+staticBase {
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+// Covers returns the list of address ranges covered by the loclist
+// starting at off.
+func (rdr *Dwarf2Reader) Covers(off int, base uint64, debugAddr *godwarf.DebugAddr) ([][2]uint64, error) {
+	r := [][2]uint64{}
+	var e Entry
+	rdr.Seek(off)
+	for rdr.Next(&e) {
+		if e.BaseAddressSelection() {
+			base = e.HighPC
+			continue
+		}
+		r = append(r, [2]uint64{e.LowPC + base, e.HighPC + base})
+	}
+	return r, nil
+}
+
+func (rdr *Dwarf2Reader) read(sz int) []byte {
 	r := rdr.data[rdr.cur : rdr.cur+sz]
 	rdr.cur += sz
 	return r
 }
 
-func (rdr *Reader) oneAddr() uint64 {
+func (rdr *Dwarf2Reader) oneAddr() uint64 {
 	switch rdr.ptrSz {
 	case 4:
 		addr := binary.LittleEndian.Uint32(rdr.read(rdr.ptrSz))
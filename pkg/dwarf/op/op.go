@@ -34,6 +34,9 @@ type Piece struct {
 	Addr       int64
 	RegNum     uint64
 	IsRegister bool
+	// IsEmpty is true if the piece isn't stored anywhere, for example
+	// because it is padding between two fields of a struct.
+	IsEmpty bool
 }
 
 // ExecuteStackProgram executes a DWARF location expression and returns
@@ -203,7 +206,9 @@ func piece(opcode Opcode, ctxt *context) error {
 	}
 
 	if len(ctxt.stack) == 0 {
-		return errors.New("empty OP stack")
+		// nothing describes where the piece is stored
+		ctxt.pieces = append(ctxt.pieces, Piece{Size: int(sz), IsEmpty: true})
+		return nil
 	}
 
 	addr := ctxt.stack[len(ctxt.stack)-1]
@@ -22,8 +22,15 @@ type DwarfRegisters struct {
 
 	FloatLoadError   error // error produced when loading floating point registers
 	loadMoreCallback func()
+
+	// ChangeFunc, if set, is called to change the value of a register of
+	// the target.
+	ChangeFunc RegisterChangeFunc
 }
 
+// RegisterChangeFunc changes the value of the register regNum to reg.
+type RegisterChangeFunc func(regNum uint64, reg *DwarfRegister) error
+
 type DwarfRegister struct {
 	Uint64Val uint64
 	Bytes     []byte
@@ -152,3 +159,28 @@ func DwarfRegisterFromBytes(bytes []byte) *DwarfRegister {
 	}
 	return &DwarfRegister{Uint64Val: v, Bytes: bytes}
 }
+
+// FillBytes fills the Bytes slice of reg using Uint64Val.
+func (reg *DwarfRegister) FillBytes() {
+	if reg.Bytes != nil {
+		return
+	}
+	reg.Bytes = make([]byte, 8)
+	binary.LittleEndian.PutUint64(reg.Bytes, reg.Uint64Val)
+}
+
+// Overwrite takes the contents of reg1 and overwrites them with the contents
+// of reg2 in little-endian order, returning a new register. The new register
+// may have a larger size than reg1 if reg2 is larger.
+func (reg1 *DwarfRegister) Overwrite(reg2 *DwarfRegister) *DwarfRegister {
+	reg1.FillBytes()
+	reg2.FillBytes()
+	width := len(reg1.Bytes)
+	if len(reg2.Bytes) > len(reg1.Bytes) {
+		width = len(reg2.Bytes)
+	}
+	b := make([]byte, width)
+	copy(b, reg1.Bytes)
+	copy(b, reg2.Bytes)
+	return DwarfRegisterFromBytes(b)
+}
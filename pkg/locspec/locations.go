@@ -61,6 +61,10 @@ type FuncLocationSpec struct {
 	ReceiverName          string
 	PackageOrReceiverName string
 	BaseName              string
+	// TypeArgs are the type arguments of an instantiation of a generic
+	// function, for example "int" for "pkg.Fn[int]". If it is empty all
+	// instantiations match.
+	TypeArgs []string
 }
 
 // Parse will turn locStr into a parsed LocationSpec.
@@ -170,6 +174,11 @@ func readRegex(in string) (rx string, rest string) {
 }
 
 func parseFuncLocationSpec(in string) *FuncLocationSpec {
+	in, typeArgs, ok := splitTypeArgs(in)
+	if !ok {
+		return nil
+	}
+
 	var v []string
 	pathend := strings.LastIndex(in, "/")
 	if pathend < 0 {
@@ -181,7 +190,7 @@ func parseFuncLocationSpec(in string) *FuncLocationSpec {
 		}
 	}
 
-	var spec FuncLocationSpec
+	spec := FuncLocationSpec{TypeArgs: typeArgs}
 	switch len(v) {
 	case 1:
 		spec.BaseName = v[0]
@@ -218,6 +227,53 @@ func parseFuncLocationSpec(in string) *FuncLocationSpec {
 	return &spec
 }
 
+// splitTypeArgs removes the type arguments from the name of a generic
+// function or of a method of a generic type and returns the first list of
+// type arguments found.
+func splitTypeArgs(in string) (name string, typeArgs []string, ok bool) {
+	if !strings.Contains(in, "[") {
+		return in, nil, true
+	}
+	var buf strings.Builder
+	depth := 0
+	argstart := 0
+	first := true
+	for i := 0; i < len(in); i++ {
+		ch := in[i]
+		if depth == 0 {
+			switch ch {
+			case '[':
+				depth = 1
+				argstart = i + 1
+			case ']':
+				return "", nil, false
+			default:
+				buf.WriteByte(ch)
+			}
+			continue
+		}
+		switch ch {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+			if depth == 0 && first {
+				typeArgs = append(typeArgs, strings.TrimSpace(in[argstart:i]))
+				first = false
+			}
+		case ',':
+			if depth == 1 && first {
+				typeArgs = append(typeArgs, strings.TrimSpace(in[argstart:i]))
+				argstart = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return "", nil, false
+	}
+	return buf.String(), typeArgs, true
+}
+
 func stripReceiverDecoration(in string) string {
 	if len(in) < 3 {
 		return in
@@ -253,9 +309,49 @@ func (spec *FuncLocationSpec) Match(sym proc.Function, packageMap map[string][]s
 	if spec.PackageOrReceiverName != "" && !packageMatch(spec.PackageOrReceiverName, sym.PackageName(), packageMap) && spec.PackageOrReceiverName != recv {
 		return false
 	}
+	if len(spec.TypeArgs) > 0 && !typeArgsMatch(spec.TypeArgs, sym.TypeParams()) {
+		return false
+	}
 	return true
 }
 
+// typeArgsMatch returns true if the type arguments specified by the user
+// match the type parameters of an instantiation of a generic function.
+// Instantiations are compiled for shape types, which are named after the
+// underlying type of the type argument and are shared by all pointer
+// types, therefore "pkg.Fn[int]" matches "pkg.Fn[go.shape.int]" and
+// "pkg.Fn[*T]" matches "pkg.Fn[go.shape.*uint8]".
+func typeArgsMatch(specArgs, symParams []string) bool {
+	if len(specArgs) != len(symParams) {
+		return false
+	}
+	for i := range specArgs {
+		param := symParams[i]
+		if !strings.HasPrefix(param, shapePrefix) {
+			if specArgs[i] != param {
+				return false
+			}
+			continue
+		}
+		param = param[len(shapePrefix):]
+		// shape names used to have a numeric suffix, for example go.shape.int_0
+		if j := strings.LastIndex(param, "_"); j >= 0 {
+			if _, err := strconv.Atoi(param[j+1:]); err == nil {
+				param = param[:j]
+			}
+		}
+		switch {
+		case specArgs[i] == param:
+		case strings.HasPrefix(specArgs[i], "*") && param == "*uint8":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+const shapePrefix = "go.shape."
+
 func packageMatch(specPkg, symPkg string, packageMap map[string][]string) bool {
 	for _, pkg := range packageMap[specPkg] {
 		if partialPackageMatch(pkg, symPkg) {
@@ -391,12 +487,26 @@ func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope
 				break
 			}
 			candidateFuncs = append(candidateFuncs, f.Name)
-			if len(candidateFuncs) >= limit {
+			if len(candidateFuncs) >= limit && !strings.Contains(f.Name, "[") {
 				break
 			}
 		}
 	}
 
+	if len(candidateFiles) == 0 && len(candidateFuncs) > 1 && sameGenericFunction(candidateFuncs) {
+		// all instantiations of a generic function, the location is the
+		// corresponding line of every instantiation
+		var addrs []uint64
+		for _, fname := range candidateFuncs {
+			faddrs, err := proc.FindFunctionLocation(t, fname, loc.LineOffset)
+			if err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, faddrs...)
+		}
+		return []api.Location{addressesToLocation(addrs)}, nil
+	}
+
 	if matching := len(candidateFiles) + len(candidateFuncs); matching == 0 {
 		// if no result was found this locations string could be an
 		// expression that the user forgot to prefix with '*', try treating it as
@@ -434,6 +544,24 @@ func (loc *NormalLocationSpec) Find(t *proc.Target, processArgs []string, scope
 	return []api.Location{addressesToLocation(addrs)}, nil
 }
 
+// sameGenericFunction returns true if fnnames are all instantiations of the
+// same generic function.
+func sameGenericFunction(fnnames []string) bool {
+	var name0 string
+	for i, fnname := range fnnames {
+		if !strings.Contains(fnname, "[") {
+			return false
+		}
+		name, _, _ := splitTypeArgs(fnname)
+		if i == 0 {
+			name0 = name
+		} else if name != name0 {
+			return false
+		}
+	}
+	return true
+}
+
 func addressesToLocation(addrs []uint64) api.Location {
 	if len(addrs) <= 0 {
 		return api.Location{}
@@ -1,7 +1,10 @@
 package locspec
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
 )
 
 func parseLocationSpecNoError(t *testing.T, locstr string) LocationSpec {
@@ -36,7 +39,7 @@ func assertNormalLocationSpec(t *testing.T, locstr string, tgt NormalLocationSpe
 		t.Fatalf("Location %q: expected non-nil 'FuncBase'", locstr)
 	}
 
-	if *(tgt.FuncBase) != *(nls.FuncBase) {
+	if !reflect.DeepEqual(*(tgt.FuncBase), *(nls.FuncBase)) {
 		t.Fatalf("Location %q: expected 'FuncBase':\n%#v\ngot:\n%#v", locstr, tgt.FuncBase, nls.FuncBase)
 	}
 }
@@ -66,3 +69,40 @@ func TestFunctionLocationParsing(t *testing.T) {
 	assertNormalLocationSpec(t, "github.com/go-delve/delve/pkg/proc.Process.Continue:10", NormalLocationSpec{"github.com/go-delve/delve/pkg/proc.Process.Continue", &FuncLocationSpec{PackageName: "github.com/go-delve/delve/pkg/proc", ReceiverName: "Process", BaseName: "Continue"}, 10})
 	assertNormalLocationSpec(t, "github.com/go-delve/delve/pkg/proc.Continue:10", NormalLocationSpec{"github.com/go-delve/delve/pkg/proc.Continue", &FuncLocationSpec{PackageName: "github.com/go-delve/delve/pkg/proc", BaseName: "Continue"}, 10})
 }
+
+func TestGenericFunctionLocationParsing(t *testing.T) {
+	assertNormalLocationSpec(t, "pkg.Fn[int]", NormalLocationSpec{"pkg.Fn[int]", &FuncLocationSpec{PackageOrReceiverName: "pkg", BaseName: "Fn", TypeArgs: []string{"int"}}, -1})
+	assertNormalLocationSpec(t, "pkg.Fn[int, map[string]int]:3", NormalLocationSpec{"pkg.Fn[int, map[string]int]", &FuncLocationSpec{PackageOrReceiverName: "pkg", BaseName: "Fn", TypeArgs: []string{"int", "map[string]int"}}, 3})
+	assertNormalLocationSpec(t, "github.com/a/pkg.(*List[int]).Push", NormalLocationSpec{"github.com/a/pkg.(*List[int]).Push", &FuncLocationSpec{PackageName: "github.com/a/pkg", ReceiverName: "List", BaseName: "Push", TypeArgs: []string{"int"}}, -1})
+	assertNormalLocationSpec(t, "pkg.Fn[github.com/a/b.T]", NormalLocationSpec{"pkg.Fn[github.com/a/b.T]", &FuncLocationSpec{PackageOrReceiverName: "pkg", BaseName: "Fn", TypeArgs: []string{"github.com/a/b.T"}}, -1})
+}
+
+func TestGenericFunctionLocationMatch(t *testing.T) {
+	fns := []proc.Function{
+		{Name: "main.MakePair[go.shape.int,go.shape.string]"},
+		{Name: "main.MakePair[go.shape.string_0,go.shape.float64_1]"},
+		{Name: "main.(*List[go.shape.*uint8]).Push"},
+	}
+	for _, tc := range []struct {
+		loc   string
+		match []bool
+	}{
+		{"main.MakePair", []bool{true, true, false}},
+		{"main.MakePair[int, string]", []bool{true, false, false}},
+		{"main.MakePair[string,float64]", []bool{false, true, false}},
+		{"main.MakePair[int]", []bool{false, false, false}},
+		{"main.(*List).Push", []bool{false, false, true}},
+		{"main.(*List[*main.T]).Push", []bool{false, false, true}},
+		{"main.(*List[int]).Push", []bool{false, false, false}},
+	} {
+		spec := parseFuncLocationSpec(tc.loc)
+		if spec == nil {
+			t.Fatalf("could not parse %q", tc.loc)
+		}
+		for i := range fns {
+			if got := spec.Match(fns[i], nil); got != tc.match[i] {
+				t.Errorf("%q matching %q: got %v, expected %v", tc.loc, fns[i].Name, got, tc.match[i])
+			}
+		}
+	}
+}
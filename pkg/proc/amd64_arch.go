@@ -39,9 +39,44 @@ func AMD64Arch(goos string) *Arch {
 		DwarfRegisterToString:            amd64DwarfRegisterToString,
 		inhibitStepInto:                  func(*BinaryInfo, uint64) bool { return false },
 		asmDecode:                        amd64AsmDecode,
+		argumentRegs:                     []uint64{0, 3, 2, 5, 4, 8, 9, 10, 11},
+		floatArgumentRegs:                []uint64{17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31},
+		maxRegArgBytes:                   9*8 + 15*8,
+		linuxSigframe:                    linuxSigframeIf(goos, amd64LinuxSigframe),
 	}
 }
 
+// amd64LinuxSigframe is the layout of the signal frame on linux/amd64, see
+// struct rt_sigframe in $LINUX_SOURCE/arch/x86/include/asm/sigframe.h.
+// When the signal return trampoline runs the return address has already
+// been popped and SP points to the ucontext_t, the interrupted registers
+// are saved in its uc_mcontext field (struct sigcontext_64) at offset 40.
+var amd64LinuxSigframe = &sigframeLayout{
+	trampolines: [][]byte{
+		{0x48, 0xc7, 0xc0, 0x0f, 0x00, 0x00, 0x00, 0x0f, 0x05}, // mov $SYS_rt_sigreturn, %rax; syscall
+	},
+	sigcontextOff: 40,
+	regs: map[uint64]uint64{
+		0:  13 * 8, // rax
+		1:  12 * 8, // rdx
+		2:  14 * 8, // rcx
+		3:  11 * 8, // rbx
+		4:  9 * 8,  // rsi
+		5:  8 * 8,  // rdi
+		6:  10 * 8, // rbp
+		7:  15 * 8, // rsp
+		8:  0 * 8,  // r8
+		9:  1 * 8,  // r9
+		10: 2 * 8,  // r10
+		11: 3 * 8,  // r11
+		12: 4 * 8,  // r12
+		13: 5 * 8,  // r13
+		14: 6 * 8,  // r14
+		15: 7 * 8,  // r15
+		16: 16 * 8, // rip
+	},
+}
+
 func amd64FixFrameUnwindContext(fctxt *frame.FrameContext, pc uint64, bi *BinaryInfo) *frame.FrameContext {
 	a := bi.Arch
 	if a.sigreturnfn == nil {
@@ -16,7 +16,8 @@ func amd64AsmDecode(asmInst *AsmInstruction, mem []byte, regs Registers, memrw M
 // $GOROOT/src/cmd/internal/obj/x86/obj6.go.
 // The stacksplit prologue will always begin with loading curg in CX, this
 // instruction is added by load_g_cx in the same file and is either 1 or 2
-// MOVs.
+// MOVs, or nothing with the register based calling convention which keeps
+// curg in R14.
 var prologuesAMD64 []opcodeSeq
 
 func init() {
@@ -25,9 +26,10 @@ func init() {
 	var bigStacksplit = opcodeSeq{uint64(x86asm.MOV), uint64(x86asm.CMP), uint64(x86asm.JE), uint64(x86asm.LEA), uint64(x86asm.SUB), uint64(x86asm.CMP), uint64(x86asm.JBE)}
 	var unixGetG = opcodeSeq{uint64(x86asm.MOV)}
 	var windowsGetG = opcodeSeq{uint64(x86asm.MOV), uint64(x86asm.MOV)}
+	var regabiGetG = opcodeSeq{}
 
-	prologuesAMD64 = make([]opcodeSeq, 0, 2*3)
-	for _, getG := range []opcodeSeq{unixGetG, windowsGetG} {
+	prologuesAMD64 = make([]opcodeSeq, 0, 3*3)
+	for _, getG := range []opcodeSeq{unixGetG, windowsGetG, regabiGetG} {
 		for _, stacksplit := range []opcodeSeq{tinyStacksplit, smallStacksplit, bigStacksplit} {
 			prologue := make(opcodeSeq, 0, len(getG)+len(stacksplit))
 			prologue = append(prologue, getG...)
@@ -38,6 +38,19 @@ type Arch struct {
 	// inhibitStepInto returns whether StepBreakpoint can be set at pc.
 	inhibitStepInto func(bi *BinaryInfo, pc uint64) bool
 
+	// argumentRegs are the registers used to pass integer arguments and
+	// return values with the register based calling convention
+	// (ABIInternal), as DWARF register numbers, in assignment order.
+	argumentRegs []uint64
+	// floatArgumentRegs are the registers used to pass floating point
+	// arguments and return values with the register based calling
+	// convention, as DWARF register numbers, in assignment order.
+	floatArgumentRegs []uint64
+	// maxRegArgBytes is the maximum number of bytes of arguments that can be
+	// passed in registers, this is also the size of the spill area reserved
+	// by the caller for them.
+	maxRegArgBytes int
+
 	// crosscall2fn is the DIE of crosscall2, a function used by the go runtime
 	// to call C functions. This function in go 1.9 (and previous versions) had
 	// a bad frame descriptor which needs to be fixed to generate good stack
@@ -48,6 +61,11 @@ type Arch struct {
 	// the signal handler. See comment in FixFrameUnwindContext for a
 	// description of why this is needed.
 	sigreturnfn *Function
+
+	// linuxSigframe describes the stack frame created by the linux kernel
+	// when it delivers a signal, nil if the target isn't linux or the
+	// layout is unknown for this architecture.
+	linuxSigframe *sigframeLayout
 }
 
 // PtrSize returns the size of a pointer for the architecture.
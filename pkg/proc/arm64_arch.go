@@ -40,9 +40,33 @@ func ARM64Arch(goos string) *Arch {
 		DwarfRegisterToString:            arm64DwarfRegisterToString,
 		inhibitStepInto:                  func(*BinaryInfo, uint64) bool { return false },
 		asmDecode:                        arm64AsmDecode,
+		argumentRegs:                     []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		floatArgumentRegs:                []uint64{64, 65, 66, 67, 68, 69, 70, 71, 72, 73, 74, 75, 76, 77, 78, 79},
+		maxRegArgBytes:                   16*8 + 16*8,
+		linuxSigframe:                    linuxSigframeIf(goos, arm64LinuxSigframe),
 	}
 }
 
+// arm64LinuxSigframe is the layout of the signal frame on linux/arm64, see
+// struct rt_sigframe in $LINUX_SOURCE/arch/arm64/kernel/signal.c.
+// When the signal return trampoline runs SP points to the signal frame,
+// which starts with a 128 bytes siginfo_t followed by a ucontext_t, whose
+// uc_mcontext field (struct sigcontext) is at offset 176. The sigcontext
+// contains fault_address followed by x0-x30, sp and pc.
+var arm64LinuxSigframe = &sigframeLayout{
+	trampolines: [][]byte{
+		{0x68, 0x11, 0x80, 0xd2, 0x01, 0x00, 0x00, 0xd4}, // mov x8, #SYS_rt_sigreturn; svc #0
+	},
+	sigcontextOff: 128 + 176,
+	regs: func() map[uint64]uint64 {
+		r := make(map[uint64]uint64)
+		for i := uint64(0); i <= arm64DwarfIPRegNum; i++ {
+			r[i] = 8 + i*8
+		}
+		return r
+	}(),
+}
+
 func arm64FixFrameUnwindContext(fctxt *frame.FrameContext, pc uint64, bi *BinaryInfo) *frame.FrameContext {
 	a := bi.Arch
 	if a.sigreturnfn == nil {
@@ -27,7 +27,6 @@ import (
 	"github.com/go-delve/delve/pkg/dwarf/loclist"
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
-	"github.com/go-delve/delve/pkg/dwarf/util"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/hashicorp/golang-lru/simplelru"
@@ -49,6 +48,10 @@ type BinaryInfo struct {
 	GOOS string
 
 	debugInfoDirectories []string
+	debugInfoSources     []DebugInfoSource
+	// indexCacheDir is the directory where the indexes of debug_info are
+	// saved, empty if the cache is disabled.
+	indexCacheDir string
 
 	// Functions is a list of all DW_TAG_subprogram entries in debug_info, sorted by entry point
 	Functions []Function
@@ -89,6 +92,10 @@ type BinaryInfo struct {
 
 	gStructOffset uint64
 
+	// regabi is true if the binary was compiled with the register based
+	// calling convention (ABIInternal).
+	regabi bool
+
 	// nameOfRuntimeType maps an address of a runtime._type struct to its
 	// decoded name. Used with versions of Go <= 1.10 to figure out the DIE of
 	// the concrete type of interfaces.
@@ -102,6 +109,11 @@ type BinaryInfo struct {
 	// function starts.
 	inlinedCallLines map[fileLine][]uint64
 
+	// pendingTypes are functions adding the types of loaded images to types,
+	// consts and Image.runtimeTypeToDIE, see loadPendingTypes.
+	pendingTypesMu sync.Mutex
+	pendingTypes   []func()
+
 	logger *logrus.Entry
 }
 
@@ -274,6 +286,7 @@ type compileUnit struct {
 
 	entry     *dwarf.Entry        // debug_info entry describing this compile unit
 	isgo      bool                // true if this is the go compile unit
+	version   uint16              // DWARF version of this compile unit, as reported by its unit header, 0 if unknown
 	lineInfo  *line.DebugLineInfo // debug_line segment associated with this compile unit
 	optimized bool                // this compile unit is optimized
 	producer  string              // producer attribute
@@ -315,7 +328,7 @@ type Function struct {
 // or the empty string if there is none.
 // Borrowed from $GOROOT/debug/gosym/symtab.go
 func (fn *Function) PackageName() string {
-	return packageName(fn.Name)
+	return packageName(fn.NameWithoutTypeParams())
 }
 
 func packageName(name string) string {
@@ -334,25 +347,79 @@ func packageName(name string) string {
 // or the empty string if there is none.
 // Borrowed from $GOROOT/debug/gosym/symtab.go
 func (fn *Function) ReceiverName() string {
-	pathend := strings.LastIndex(fn.Name, "/")
+	name := fn.NameWithoutTypeParams()
+	pathend := strings.LastIndex(name, "/")
 	if pathend < 0 {
 		pathend = 0
 	}
-	l := strings.Index(fn.Name[pathend:], ".")
-	r := strings.LastIndex(fn.Name[pathend:], ".")
+	l := strings.Index(name[pathend:], ".")
+	r := strings.LastIndex(name[pathend:], ".")
 	if l == -1 || r == -1 || l == r {
 		return ""
 	}
-	return fn.Name[pathend+l+1 : pathend+r]
+	return name[pathend+l+1 : pathend+r]
 }
 
 // BaseName returns the symbol name without the package or receiver name.
 // Borrowed from $GOROOT/debug/gosym/symtab.go
 func (fn *Function) BaseName() string {
-	if i := strings.LastIndex(fn.Name, "."); i != -1 {
-		return fn.Name[i+1:]
+	name := fn.NameWithoutTypeParams()
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// NameWithoutTypeParams returns the name of the function without the type
+// parameters of generic instantiations, for example the name of
+// "pkg.(*List[go.shape.int]).Push" is "pkg.(*List).Push".
+func (fn *Function) NameWithoutTypeParams() string {
+	if !strings.Contains(fn.Name, "[") {
+		return fn.Name
+	}
+	var buf strings.Builder
+	depth := 0
+	for _, ch := range fn.Name {
+		switch {
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		case depth == 0:
+			buf.WriteRune(ch)
+		}
+	}
+	return buf.String()
+}
+
+// TypeParams returns the type parameters of a generic instantiation, for
+// example the type parameters of "pkg.Fn[go.shape.int,go.shape.string]"
+// are "go.shape.int" and "go.shape.string". For methods of generic types
+// the type parameters of the receiver are returned.
+func (fn *Function) TypeParams() []string {
+	start := strings.Index(fn.Name, "[")
+	if start < 0 {
+		return nil
 	}
-	return fn.Name
+	var r []string
+	depth := 0
+	for i := start; i < len(fn.Name); i++ {
+		switch fn.Name[i] {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+			if depth == 0 {
+				return append(r, fn.Name[start+1:i])
+			}
+		case ',':
+			if depth == 1 {
+				r = append(r, fn.Name[start+1:i])
+				start = i
+			}
+		}
+	}
+	return nil
 }
 
 // Optimized returns true if the function was optimized by the compiler.
@@ -438,13 +505,15 @@ func NewBinaryInfo(goos, goarch string) *BinaryInfo {
 }
 
 // LoadBinaryInfo will load and store the information from the binary at 'path'.
-func (bi *BinaryInfo) LoadBinaryInfo(path string, entryPoint uint64, debugInfoDirs []string) error {
+func (bi *BinaryInfo) LoadBinaryInfo(path string, entryPoint uint64, debugInfo DebugInfoConfig) error {
 	fi, err := os.Stat(path)
 	if err == nil {
 		bi.lastModified = fi.ModTime()
 	}
 
-	bi.debugInfoDirectories = debugInfoDirs
+	bi.debugInfoDirectories = debugInfo.Dirs
+	bi.debugInfoSources = debugInfo.Sources
+	bi.indexCacheDir = debugInfo.IndexCacheDir
 
 	return bi.AddImage(path, entryPoint)
 }
@@ -482,6 +551,7 @@ func (so *Image) DwarfReader() *reader.Reader {
 
 // Types returns list of types present in the debugged program.
 func (bi *BinaryInfo) Types() ([]string, error) {
+	bi.loadPendingTypes()
 	types := make([]string, 0, len(bi.types))
 	for k := range bi.types {
 		types = append(types, k)
@@ -634,6 +704,25 @@ func (bi *BinaryInfo) PCToImage(pc uint64) *Image {
 	return bi.funcToImage(fn)
 }
 
+// pcInText returns true if pc belongs to a known function or to the
+// executable segment of one of the loaded images.
+func (bi *BinaryInfo) pcInText(pc uint64) bool {
+	if bi.PCToFunc(pc) != nil {
+		return true
+	}
+	if _, err := bi.frameEntries.FDEForPC(pc); err == nil {
+		return true
+	}
+	for _, image := range bi.Images {
+		for _, rng := range image.text {
+			if pc >= rng[0] && pc < rng[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Image represents a loaded library file (shared object on linux, DLL on windows).
 type Image struct {
 	Path       string
@@ -642,12 +731,20 @@ type Image struct {
 
 	index int // index of this object in BinaryInfo.SharedObjects
 
+	buildID string // build ID of the image, used as the key of its cached index
+
 	closer         io.Closer
 	sepDebugCloser io.Closer
 
 	dwarf       *dwarf.Data
 	dwarfReader *dwarf.Reader
-	loclist     *loclist.Reader
+	loclist2    *loclist.Dwarf2Reader
+	loclist5    *loclist.Dwarf5Reader
+	debugAddr   *godwarf.DebugAddrSection
+
+	// unitVersions maps the offset of the first entry of each unit of
+	// debug_info to the DWARF version of the unit.
+	unitVersions map[dwarf.Offset]uint16
 
 	typeCache map[dwarf.Offset]godwarf.Type
 
@@ -661,18 +758,18 @@ type Image struct {
 	// which was added in go 1.11.
 	runtimeTypeToDIE map[uint64]runtimeTypeDIE
 
+	// symbols maps the names of the data symbols of the image to their
+	// addresses, it is only loaded for images without debug_info.
+	symbols map[string]uint64
+
+	// text is the list of address ranges of the executable segments of the
+	// image, only loaded for ELF files.
+	text [][2]uint64
+
 	loadErrMu sync.Mutex
 	loadErr   error
 }
 
-func (image *Image) registerRuntimeTypeToDIE(entry *dwarf.Entry, ardr *reader.Reader) {
-	if off, ok := entry.Val(godwarf.AttrGoRuntimeType).(uint64); ok {
-		if _, ok := image.runtimeTypeToDIE[off]; !ok {
-			image.runtimeTypeToDIE[off+image.StaticBase] = runtimeTypeDIE{entry.Offset, -1}
-		}
-	}
-}
-
 // AddImage adds the specified image to bi, loading data asynchronously.
 // Addr is the relocated entry point for the executable and staticBase (i.e.
 // the relocation offset) for all other images.
@@ -795,7 +892,7 @@ func (c *nilCloser) Close() error { return nil }
 
 // LoadImageFromData creates a new Image, using the specified data, and adds it to bi.
 // This is used for debugging BinaryInfo, you should use LoadBinary instead.
-func (bi *BinaryInfo) LoadImageFromData(dwdata *dwarf.Data, debugFrameBytes, debugLineBytes, debugLocBytes []byte) {
+func (bi *BinaryInfo) LoadImageFromData(dwdata *dwarf.Data, debugFrameBytes, debugLineBytes, debugLocBytes, debugLoclistsBytes, debugAddrBytes []byte) {
 	image := &Image{}
 	image.closer = (*nilCloser)(nil)
 	image.sepDebugCloser = (*nilCloser)(nil)
@@ -807,9 +904,11 @@ func (bi *BinaryInfo) LoadImageFromData(dwdata *dwarf.Data, debugFrameBytes, deb
 		bi.frameEntries = frame.Parse(debugFrameBytes, frame.DwarfEndian(debugFrameBytes), 0, bi.Arch.PtrSize())
 	}
 
-	image.loclist = loclist.New(debugLocBytes, bi.Arch.PtrSize())
+	image.loclist2 = loclist.NewDwarf2Reader(debugLocBytes, bi.Arch.PtrSize())
+	image.loclist5 = loclist.NewDwarf5Reader(debugLoclistsBytes, bi.Arch.PtrSize())
+	image.debugAddr = godwarf.ParseAddr(debugAddrBytes, bi.Arch.PtrSize())
 
-	bi.loadDebugInfoMaps(image, debugLineBytes, nil, nil)
+	bi.loadDebugInfoMaps(image, debugLineBytes, nil, nil, nil)
 
 	bi.Images = append(bi.Images, image)
 }
@@ -882,22 +981,15 @@ func (bi *BinaryInfo) LocationCovers(entry *dwarf.Entry, attr dwarf.Attr) ([][2]
 	}
 
 	image := cu.image
-	base := cu.lowPC
-	if image == nil || image.loclist.Empty() {
+	if image == nil {
 		return nil, errors.New("malformed executable")
 	}
-
-	r := [][2]uint64{}
-	var e loclist.Entry
-	image.loclist.Seek(int(off))
-	for image.loclist.Next(&e) {
-		if e.BaseAddressSelection() {
-			base = e.HighPC
-			continue
-		}
-		r = append(r, [2]uint64{e.LowPC + base, e.HighPC + base})
+	rdr, debugAddr := image.loclistReader(cu)
+	if rdr == nil {
+		return nil, errors.New("malformed executable")
 	}
-	return r, nil
+
+	return rdr.Covers(int(off), cu.lowPC, debugAddr)
 }
 
 // Location returns the location described by attribute attr of entry.
@@ -918,29 +1010,57 @@ func (bi *BinaryInfo) Location(entry godwarf.Entry, attr dwarf.Attr, pc uint64,
 func (bi *BinaryInfo) loclistEntry(off int64, pc uint64) []byte {
 	var base uint64
 	image := bi.Images[0]
-	if cu := bi.findCompileUnit(pc); cu != nil {
+	cu := bi.findCompileUnit(pc)
+	if cu != nil {
 		base = cu.lowPC
 		image = cu.image
 	}
-	if image == nil || image.loclist.Empty() {
+	if image == nil {
 		return nil
 	}
 
-	image.loclist.Seek(int(off))
-	var e loclist.Entry
-	for image.loclist.Next(&e) {
-		if e.BaseAddressSelection() {
-			base = e.HighPC
-			continue
-		}
-		if pc >= e.LowPC+base+image.StaticBase && pc < e.HighPC+base+image.StaticBase {
-			return e.Instr
-		}
+	rdr, debugAddr := image.loclistReader(cu)
+	if rdr == nil {
+		return nil
+	}
+
+	e, err := rdr.Find(int(off), image.StaticBase, base, pc, debugAddr)
+	if err != nil {
+		bi.logger.Errorf("error reading loclist section: %v", err)
+		return nil
+	}
+	if e != nil {
+		return e.Instr
 	}
 
 	return nil
 }
 
+// loclistReader returns the reader for the location lists of cu and the
+// debug_addr subsection used to resolve them. Location lists of DWARF 5
+// compile units are stored in debug_loclists, older versions use
+// debug_loc.
+// Returns a nil reader if image has no location lists.
+func (image *Image) loclistReader(cu *compileUnit) (loclist.Reader, *godwarf.DebugAddr) {
+	use5 := image.loclist2.Empty()
+	if cu != nil && cu.version >= 5 {
+		use5 = true
+	}
+	if use5 && !image.loclist5.Empty() {
+		var debugAddr *godwarf.DebugAddr
+		if cu != nil {
+			if addrBase, ok := cu.entry.Val(dwarf.AttrAddrBase).(int64); ok {
+				debugAddr = image.debugAddr.GetSubsection(uint64(addrBase))
+			}
+		}
+		return image.loclist5, debugAddr
+	}
+	if image.loclist2.Empty() {
+		return nil, nil
+	}
+	return image.loclist2, nil
+}
+
 // findCompileUnit returns the compile unit containing address pc.
 func (bi *BinaryInfo) findCompileUnit(pc uint64) *compileUnit {
 	for _, image := range bi.Images {
@@ -989,6 +1109,61 @@ func (bi *BinaryInfo) funcToImage(fn *Function) *Image {
 	return fn.cu.image
 }
 
+// loadLocationSections loads the sections used to evaluate location
+// lists, .debug_loc, .debug_loclists and .debug_addr, into image, as well
+// as the DWARF versions of the units of .debug_info which determine which
+// of them is used.
+func (bi *BinaryInfo) loadLocationSections(image *Image, sections *godwarf.DebugSections) {
+	if debugInfoBytes, err := sections.Get("info"); err == nil {
+		image.unitVersions = godwarf.UnitVersions(debugInfoBytes, frame.DwarfEndian(debugInfoBytes))
+	}
+	debugLocBytes, _ := sections.Get("loc")
+	image.loclist2 = loclist.NewDwarf2Reader(debugLocBytes, bi.Arch.PtrSize())
+	debugLoclistBytes, _ := sections.Get("loclists")
+	image.loclist5 = loclist.NewDwarf5Reader(debugLoclistBytes, bi.Arch.PtrSize())
+	debugAddrBytes, _ := sections.Get("addr")
+	image.debugAddr = godwarf.ParseAddr(debugAddrBytes, bi.Arch.PtrSize())
+}
+
+func (bi *BinaryInfo) parseDebugFrame(image *Image, sections *godwarf.DebugSections, exe *elf.File, wg *sync.WaitGroup) {
+	defer wg.Done()
+	bi.loadFrameEntries(image, sections, exe)
+}
+
+// loadFrameEntries loads the frame descriptor entries of image from its
+// .debug_frame section and, if exe is not nil, from the .eh_frame section
+// of exe. Entries in .debug_frame take precedence over the ones in
+// .eh_frame.
+func (bi *BinaryInfo) loadFrameEntries(image *Image, sections *godwarf.DebugSections, exe *elf.File) {
+	var ehFrame frame.FrameDescriptionEntries
+	var ehFrameErr error
+	if exe != nil {
+		ehFrame, ehFrameErr = bi.parseEhFrameElf(image, exe)
+	}
+
+	debugFrameBytes, err := sections.Get("frame")
+	if err != nil {
+		if ehFrame == nil {
+			image.setLoadError("could not get .debug_frame section: %v", err)
+			return
+		}
+		bi.frameEntries = bi.frameEntries.Append(ehFrame)
+		return
+	}
+	debugInfoBytes, err := sections.Get("info")
+	if err != nil {
+		image.setLoadError("could not get .debug_info section: %v", err)
+		return
+	}
+
+	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameBytes, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize()))
+
+	if ehFrameErr != nil {
+		bi.logger.Warnf("could not parse .eh_frame section of %s: %v", image.Path, ehFrameErr)
+	}
+	bi.frameEntries = bi.frameEntries.Append(ehFrame)
+}
+
 // ELF ///////////////////////////////////////////////////////////////
 
 // ErrNoBuildIDNote is used in openSeparateDebugInfo to signal there's no
@@ -1000,6 +1175,28 @@ func (e *ErrNoBuildIDNote) Error() string {
 	return "can't find build-id note on binary"
 }
 
+// DebugInfoConfig describes where the debug info of the images loaded by
+// a target is looked up and where its index is cached.
+type DebugInfoConfig struct {
+	// Dirs are the directories searched for separate debug info files.
+	Dirs []string
+	// Sources are queried for separate debug info files that can not be
+	// found in Dirs.
+	Sources []DebugInfoSource
+	// IndexCacheDir is the directory where the indexes of debug_info are
+	// saved, empty to disable the cache.
+	IndexCacheDir string
+}
+
+// DebugInfoSource is a source of separate debug info files, for example
+// a debuginfod server, consulted when the debug info for a binary can not
+// be found in the debug info directories.
+type DebugInfoSource interface {
+	// FindDebugInfo returns the path of a local file containing the debug
+	// info for the binary with the specified build ID (in hexadecimal).
+	FindDebugInfo(buildID string) (string, error)
+}
+
 // openSeparateDebugInfo searches for a file containing the separate
 // debug info for the binary using the "build ID" method as described
 // in GDB's documentation [1], and if found returns two handles, one
@@ -1008,6 +1205,8 @@ func (e *ErrNoBuildIDNote) Error() string {
 //
 // Alternatively, if the debug file cannot be found be the build-id, Delve
 // will look in directories specified by the debug-info-directories config value.
+// As a last resort the build-id is looked up in the debug info sources,
+// queried in order.
 func (bi *BinaryInfo) openSeparateDebugInfo(image *Image, exe *elf.File, debugInfoDirectories []string) (*os.File, *elf.File, error) {
 	var debugFilePath string
 	for _, dir := range debugInfoDirectories {
@@ -1027,6 +1226,9 @@ func (bi *BinaryInfo) openSeparateDebugInfo(image *Image, exe *elf.File, debugIn
 			break
 		}
 	}
+	if debugFilePath == "" {
+		debugFilePath = bi.findDebugInfoFromSources(exe)
+	}
 	if debugFilePath == "" {
 		return nil, nil, ErrNoDebugInfoFound
 	}
@@ -1049,6 +1251,26 @@ func (bi *BinaryInfo) openSeparateDebugInfo(image *Image, exe *elf.File, debugIn
 	return sepFile, elfFile, nil
 }
 
+// findDebugInfoFromSources asks the debug info sources for the separate
+// debug info file of exe, returns the empty string if none of them have it.
+func (bi *BinaryInfo) findDebugInfoFromSources(exe *elf.File) string {
+	if len(bi.debugInfoSources) == 0 {
+		return ""
+	}
+	desc1, desc2, err := parseBuildID(exe)
+	if err != nil {
+		return ""
+	}
+	for _, src := range bi.debugInfoSources {
+		path, err := src.FindDebugInfo(desc1 + desc2)
+		if err == nil {
+			return path
+		}
+		bi.logger.Debugf("could not find debug info for build-id %s%s: %v", desc1, desc2, err)
+	}
+	return ""
+}
+
 func parseBuildID(exe *elf.File) (string, string, error) {
 	buildid := exe.Section(".note.gnu.build-id")
 	if buildid == nil {
@@ -1112,18 +1334,39 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 		image.StaticBase = addr
 	}
 
+	if desc1, desc2, err := parseBuildID(elfFile); err == nil {
+		image.buildID = desc1 + desc2
+	}
+
+	for _, prog := range elfFile.Progs {
+		if prog.Type == elf.PT_LOAD && prog.Flags&elf.PF_X != 0 {
+			image.text = append(image.text, [2]uint64{prog.Vaddr + image.StaticBase, prog.Vaddr + prog.Memsz + image.StaticBase})
+		}
+	}
+
 	dwarfFile := elfFile
+	sections := godwarf.NewDebugSectionsElf(elfFile)
 
-	image.dwarf, err = elfFile.DWARF()
+	image.dwarf, err = sections.DWARF()
 	if err != nil {
 		var sepFile *os.File
 		var serr error
 		sepFile, dwarfFile, serr = bi.openSeparateDebugInfo(image, elfFile, bi.debugInfoDirectories)
 		if serr != nil {
+			if perr := bi.loadBinaryInfoPclntabElf(image, elfFile, wg); perr == nil {
+				return nil
+			}
+			// Even without debug info the symbol table and .eh_frame can be used
+			// to unwind the stack through the functions of this image. They are
+			// loaded before returning so that nothing modifies image after it
+			// has been marked as failed to load.
+			bi.loadFrameEntries(image, sections, elfFile)
+			bi.loadSymbolNames(image, elfFile)
 			return serr
 		}
 		image.sepDebugCloser = sepFile
-		image.dwarf, err = dwarfFile.DWARF()
+		sections = godwarf.NewDebugSectionsElf(dwarfFile)
+		image.dwarf, err = sections.DWARF()
 		if err != nil {
 			return err
 		}
@@ -1131,16 +1374,16 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 
 	image.dwarfReader = image.dwarf.Reader()
 
-	debugLineBytes, err := godwarf.GetDebugSectionElf(dwarfFile, "line")
+	debugLineBytes, err := sections.Get("line")
 	if err != nil {
 		return err
 	}
-	debugLocBytes, _ := godwarf.GetDebugSectionElf(dwarfFile, "loc")
-	image.loclist = loclist.New(debugLocBytes, bi.Arch.PtrSize())
+	debugLineStrBytes, _ := sections.Get("line_str")
+	bi.loadLocationSections(image, sections)
 
 	wg.Add(3)
-	go bi.parseDebugFrameElf(image, dwarfFile, wg)
-	go bi.loadDebugInfoMaps(image, debugLineBytes, wg, nil)
+	go bi.parseDebugFrame(image, sections, elfFile, wg)
+	go bi.loadDebugInfoMaps(image, debugLineBytes, debugLineStrBytes, wg, nil)
 	go bi.loadSymbolName(image, elfFile, wg)
 	if image.index == 0 {
 		// determine g struct offset only when loading the executable file
@@ -1150,11 +1393,106 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 	return nil
 }
 
+// loadBinaryInfoPclntabElf loads information from an ELF binary that does
+// not contain any debug info (for example because it was linked with
+// -ldflags=-w), using the symbol table and pclntab of the Go runtime.
+// Functions, line tables and stack unwinding will work but no variables
+// will be available.
+func (bi *BinaryInfo) loadBinaryInfoPclntabElf(image *Image, exe *elf.File, wg *sync.WaitGroup) error {
+	image.symbols = make(map[string]uint64)
+	symbols, _ := exe.Symbols()
+	for _, sym := range symbols {
+		if sym.Section != elf.SHN_UNDEF && sym.Name != "" {
+			image.symbols[sym.Name] = sym.Value
+		}
+	}
+
+	sectionData := make(map[*elf.Section][]byte)
+	read := func(addr uint64, size int) []byte {
+		for _, sec := range exe.Sections {
+			if sec.Type == elf.SHT_NOBITS || addr < sec.Addr || addr+uint64(size) > sec.Addr+sec.Size {
+				continue
+			}
+			data, ok := sectionData[sec]
+			if !ok {
+				data, _ = sec.Data()
+				sectionData[sec] = data
+			}
+			if addr-sec.Addr+uint64(size) > uint64(len(data)) {
+				return nil
+			}
+			return data[addr-sec.Addr:][:size]
+		}
+		return nil
+	}
+
+	var pclntabData []byte
+	if sec := exe.Section(".gopclntab"); sec != nil {
+		pclntabData = read(sec.Addr, int(sec.Size))
+	} else if start, end := image.symbols["runtime.pclntab"], image.symbols["runtime.epclntab"]; start != 0 && end > start {
+		pclntabData = read(start, int(end-start))
+	}
+	if pclntabData == nil {
+		return errors.New("could not find pclntab")
+	}
+	textStart := image.symbols["runtime.text"]
+	if sec := exe.Section(".text"); textStart == 0 && sec != nil {
+		textStart = sec.Addr
+	}
+	tab, err := parsePclntab(pclntabData, textStart)
+	if err != nil {
+		return err
+	}
+
+	image.dwarf = emptyDwarf(bi.Arch.PtrSize())
+	image.dwarfReader = image.dwarf.Reader()
+	image.loclist2 = loclist.NewDwarf2Reader(nil, bi.Arch.PtrSize())
+
+	bi.loadDebugInfoMapsPclntab(image, tab)
+	if ehFrame, err := bi.parseEhFrameElf(image, exe); err != nil {
+		bi.logger.Warnf("could not parse .eh_frame section of %s: %v", image.Path, err)
+	} else {
+		bi.frameEntries = bi.frameEntries.Append(ehFrame)
+	}
+	if image.index == 0 {
+		if err := bi.loadRuntimeTypesPclntab(image, tab, read); err != nil {
+			bi.logger.Warnf("could not load runtime types, goroutines will not be available: %v", err)
+		}
+	}
+
+	wg.Add(1)
+	go bi.loadSymbolName(image, exe, wg)
+	if image.index == 0 {
+		// determine g struct offset only when loading the executable file
+		wg.Add(1)
+		go bi.setGStructOffsetElf(image, exe, wg)
+	}
+	return nil
+}
+
+// parseEhFrameElf parses the .eh_frame section of exe, returns nil if exe
+// doesn't have one.
+func (bi *BinaryInfo) parseEhFrameElf(image *Image, exe *elf.File) (frame.FrameDescriptionEntries, error) {
+	sec := exe.Section(".eh_frame")
+	if sec == nil || sec.Type == elf.SHT_NOBITS || sec.Addr == 0 {
+		return nil, nil
+	}
+	data, err := sec.Data()
+	if err != nil {
+		return nil, err
+	}
+	return frame.ParseEhFrame(data, exe.ByteOrder, image.StaticBase, bi.Arch.PtrSize(), sec.Addr)
+}
+
 //  STT_FUNC is a code object, see /usr/include/elf.h for a full definition.
 const STT_FUNC = 2
 
 func (bi *BinaryInfo) loadSymbolName(image *Image, file *elf.File, wg *sync.WaitGroup) {
 	defer wg.Done()
+	bi.loadSymbolNames(image, file)
+}
+
+func (bi *BinaryInfo) loadSymbolNames(image *Image, file *elf.File) {
 	if bi.SymNames == nil {
 		bi.SymNames = make(map[uint64]*elf.Symbol)
 	}
@@ -1169,23 +1507,6 @@ func (bi *BinaryInfo) loadSymbolName(image *Image, file *elf.File, wg *sync.Wait
 	}
 }
 
-func (bi *BinaryInfo) parseDebugFrameElf(image *Image, exe *elf.File, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	debugFrameData, err := godwarf.GetDebugSectionElf(exe, "frame")
-	if err != nil {
-		image.setLoadError("could not get .debug_frame section: %v", err)
-		return
-	}
-	debugInfoData, err := godwarf.GetDebugSectionElf(exe, "info")
-	if err != nil {
-		image.setLoadError("could not get .debug_info section: %v", err)
-		return
-	}
-
-	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameData, frame.DwarfEndian(debugInfoData), image.StaticBase, bi.Arch.PtrSize()))
-}
-
 func (bi *BinaryInfo) setGStructOffsetElf(image *Image, exe *elf.File, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -1248,7 +1569,8 @@ func loadBinaryInfoPE(bi *BinaryInfo, image *Image, path string, entryPoint uint
 	if !supportedWindowsArch[cpuArch] {
 		return &ErrUnsupportedArch{os: "windows", cpuArch: cpuArch}
 	}
-	image.dwarf, err = peFile.DWARF()
+	sections := godwarf.NewDebugSectionsPE(peFile)
+	image.dwarf, err = sections.DWARF()
 	if err != nil {
 		return err
 	}
@@ -1265,16 +1587,16 @@ func loadBinaryInfoPE(bi *BinaryInfo, image *Image, path string, entryPoint uint
 
 	image.dwarfReader = image.dwarf.Reader()
 
-	debugLineBytes, err := godwarf.GetDebugSectionPE(peFile, "line")
+	debugLineBytes, err := sections.Get("line")
 	if err != nil {
 		return err
 	}
-	debugLocBytes, _ := godwarf.GetDebugSectionPE(peFile, "loc")
-	image.loclist = loclist.New(debugLocBytes, bi.Arch.PtrSize())
+	debugLineStrBytes, _ := sections.Get("line_str")
+	bi.loadLocationSections(image, sections)
 
 	wg.Add(2)
-	go bi.parseDebugFramePE(image, peFile, wg)
-	go bi.loadDebugInfoMaps(image, debugLineBytes, wg, nil)
+	go bi.parseDebugFrame(image, sections, nil, wg)
+	go bi.loadDebugInfoMaps(image, debugLineBytes, debugLineStrBytes, wg, nil)
 
 	// Use ArbitraryUserPointer (0x28) as pointer to pointer
 	// to G struct per:
@@ -1297,23 +1619,6 @@ func openExecutablePathPE(path string) (*pe.File, io.Closer, error) {
 	return peFile, f, nil
 }
 
-func (bi *BinaryInfo) parseDebugFramePE(image *Image, exe *pe.File, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	debugFrameBytes, err := godwarf.GetDebugSectionPE(exe, "frame")
-	if err != nil {
-		image.setLoadError("could not get .debug_frame section: %v", err)
-		return
-	}
-	debugInfoBytes, err := godwarf.GetDebugSectionPE(exe, "info")
-	if err != nil {
-		image.setLoadError("could not get .debug_info section: %v", err)
-		return
-	}
-
-	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameBytes, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize()))
-}
-
 // Borrowed from https://golang.org/src/cmd/internal/objfile/pe.go
 func findPESymbol(f *pe.File, name string) (*pe.Symbol, error) {
 	for _, s := range f.Symbols {
@@ -1343,23 +1648,26 @@ func loadBinaryInfoMacho(bi *BinaryInfo, image *Image, path string, entryPoint u
 	if !supportedDarwinArch[exe.Cpu] {
 		return &ErrUnsupportedArch{os: "darwin", cpuArch: exe.Cpu}
 	}
-	image.dwarf, err = exe.DWARF()
+	image.buildID = machoUUID(exe)
+
+	sections := godwarf.NewDebugSectionsMacho(exe)
+	image.dwarf, err = sections.DWARF()
 	if err != nil {
 		return err
 	}
 
 	image.dwarfReader = image.dwarf.Reader()
 
-	debugLineBytes, err := godwarf.GetDebugSectionMacho(exe, "line")
+	debugLineBytes, err := sections.Get("line")
 	if err != nil {
 		return err
 	}
-	debugLocBytes, _ := godwarf.GetDebugSectionMacho(exe, "loc")
-	image.loclist = loclist.New(debugLocBytes, bi.Arch.PtrSize())
+	debugLineStrBytes, _ := sections.Get("line_str")
+	bi.loadLocationSections(image, sections)
 
 	wg.Add(2)
-	go bi.parseDebugFrameMacho(image, exe, wg)
-	go bi.loadDebugInfoMaps(image, debugLineBytes, wg, bi.setGStructOffsetMacho)
+	go bi.parseDebugFrame(image, sections, nil, wg)
+	go bi.loadDebugInfoMaps(image, debugLineBytes, debugLineStrBytes, wg, bi.setGStructOffsetMacho)
 	return nil
 }
 
@@ -1375,25 +1683,9 @@ func (bi *BinaryInfo) setGStructOffsetMacho() {
 	bi.gStructOffset = 0x8a0
 }
 
-func (bi *BinaryInfo) parseDebugFrameMacho(image *Image, exe *macho.File, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	debugFrameBytes, err := godwarf.GetDebugSectionMacho(exe, "frame")
-	if err != nil {
-		image.setLoadError("could not get __debug_frame section: %v", err)
-		return
-	}
-	debugInfoBytes, err := godwarf.GetDebugSectionMacho(exe, "info")
-	if err != nil {
-		image.setLoadError("could not get .debug_info section: %v", err)
-		return
-	}
-
-	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameBytes, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize()))
-}
-
 // Do not call this function directly it isn't able to deal correctly with package paths
 func (bi *BinaryInfo) findType(name string) (godwarf.Type, error) {
+	bi.loadPendingTypes()
 	ref, found := bi.types[name]
 	if !found {
 		return nil, reader.TypeNotFoundErr
@@ -1420,7 +1712,15 @@ func (bi *BinaryInfo) findTypeExpr(expr ast.Expr) (godwarf.Type, error) {
 				}
 			}
 		}
-		return bi.findType(typn)
+		typ, err := bi.findType(typn)
+		if err != nil && strings.HasPrefix(typn, "C.") {
+			for _, name := range cIntTypeNames(typn[len("C."):]) {
+				if typ, err := bi.findType("C." + name); err == nil {
+					return typ, nil
+				}
+			}
+		}
+		return typ, err
 	}
 	bi.expandPackagesInType(expr)
 	if snode, ok := expr.(*ast.StarExpr); ok {
@@ -1466,6 +1766,44 @@ func (bi *BinaryInfo) findArrayType(n int, etyp string) (godwarf.Type, error) {
 	}
 }
 
+// cIntTypeNames returns the names that C compilers use for the integer type
+// typn in debug info, gcc and clang spell them differently (for example
+// "long unsigned int" and "unsigned long").
+func cIntTypeNames(typn string) []string {
+	unsigned, short, longs := false, false, 0
+	for _, w := range strings.Fields(typn) {
+		switch w {
+		case "unsigned":
+			unsigned = true
+		case "signed", "int":
+			// nothing to do
+		case "short":
+			short = true
+		case "long":
+			longs++
+		default:
+			return nil
+		}
+	}
+	var base string
+	switch {
+	case short:
+		base = "short"
+	case longs == 1:
+		base = "long"
+	case longs == 2:
+		base = "long long"
+	case unsigned:
+		return []string{"unsigned int"}
+	default:
+		return []string{"int"}
+	}
+	if unsigned {
+		return []string{base + " unsigned int", "unsigned " + base}
+	}
+	return []string{base + " int", base}
+}
+
 func complexType(typename string) bool {
 	for _, ch := range typename {
 		switch ch {
@@ -1476,30 +1814,7 @@ func complexType(typename string) bool {
 	return false
 }
 
-func (bi *BinaryInfo) registerTypeToPackageMap(entry *dwarf.Entry) {
-	if entry.Tag != dwarf.TagTypedef && entry.Tag != dwarf.TagBaseType && entry.Tag != dwarf.TagClassType && entry.Tag != dwarf.TagStructType {
-		return
-	}
-
-	typename, ok := entry.Val(dwarf.AttrName).(string)
-	if !ok || complexType(typename) {
-		return
-	}
-
-	dot := strings.LastIndex(typename, ".")
-	if dot < 0 {
-		return
-	}
-	path := typename[:dot]
-	slash := strings.LastIndex(path, "/")
-	if slash < 0 || slash+1 >= len(path) {
-		return
-	}
-	name := path[slash+1:]
-	bi.PackageMap[name] = []string{path}
-}
-
-func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugLineBytes []byte, wg *sync.WaitGroup, cont func()) {
+func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugLineBytes, debugLineStrBytes []byte, wg *sync.WaitGroup, cont func()) {
 	if wg != nil {
 		defer wg.Done()
 	}
@@ -1519,76 +1834,12 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugLineBytes []byte, wg
 
 	image.runtimeTypeToDIE = make(map[uint64]runtimeTypeDIE)
 
-	ctxt := newLoadDebugInfoMapsContext(bi, image)
-
-	reader := image.DwarfReader()
-
-	for entry, err := reader.Next(); entry != nil; entry, err = reader.Next() {
-		if err != nil {
-			image.setLoadError("error reading debug_info: %v", err)
-			break
-		}
-		switch entry.Tag {
-		case dwarf.TagCompileUnit:
-			cu := &compileUnit{}
-			cu.image = image
-			cu.entry = entry
-			cu.offset = entry.Offset
-			if lang, _ := entry.Val(dwarf.AttrLanguage).(int64); lang == dwarfGoLanguage {
-				cu.isgo = true
-			}
-			cu.name, _ = entry.Val(dwarf.AttrName).(string)
-			compdir, _ := entry.Val(dwarf.AttrCompDir).(string)
-			if compdir != "" {
-				cu.name = filepath.Join(compdir, cu.name)
-			}
-			cu.ranges, _ = image.dwarf.Ranges(entry)
-			for i := range cu.ranges {
-				cu.ranges[i][0] += image.StaticBase
-				cu.ranges[i][1] += image.StaticBase
-			}
-			if len(cu.ranges) >= 1 {
-				cu.lowPC = cu.ranges[0][0]
-			}
-			lineInfoOffset, hasLineInfo := entry.Val(dwarf.AttrStmtList).(int64)
-			if hasLineInfo && lineInfoOffset >= 0 && lineInfoOffset < int64(len(debugLineBytes)) {
-				var logfn func(string, ...interface{})
-				if logflags.DebugLineErrors() {
-					logger := logrus.New().WithFields(logrus.Fields{"layer": "dwarf-line"})
-					logger.Logger.Level = logrus.DebugLevel
-					logfn = func(fmt string, args ...interface{}) {
-						logger.Printf(fmt, args)
-					}
-				}
-				cu.lineInfo = line.Parse(compdir, bytes.NewBuffer(debugLineBytes[lineInfoOffset:]), logfn, image.StaticBase, bi.GOOS == "windows", bi.Arch.PtrSize())
-			}
-			cu.producer, _ = entry.Val(dwarf.AttrProducer).(string)
-			if cu.isgo && cu.producer != "" {
-				semicolon := strings.Index(cu.producer, ";")
-				if semicolon < 0 {
-					cu.optimized = goversion.ProducerAfterOrEqual(cu.producer, 1, 10)
-				} else {
-					cu.optimized = !strings.Contains(cu.producer[semicolon:], "-N") || !strings.Contains(cu.producer[semicolon:], "-l")
-					cu.producer = cu.producer[:semicolon]
-				}
-			}
-			gopkg, _ := entry.Val(godwarf.AttrGoPackageName).(string)
-			if cu.isgo && gopkg != "" {
-				bi.PackageMap[gopkg] = append(bi.PackageMap[gopkg], escapePackagePath(strings.Replace(cu.name, "\\", "/", -1)))
-			}
-			image.compileUnits = append(image.compileUnits, cu)
-			if entry.Children {
-				bi.loadDebugInfoMapsCompileUnit(ctxt, image, reader, cu)
-			}
-
-		case dwarf.TagPartialUnit:
-			reader.SkipChildren()
-
-		default:
-			// ignore unknown tags
-			reader.SkipChildren()
-		}
+	index := bi.loadCachedIndex(image, debugLineBytes)
+	if index == nil {
+		index = bi.buildIndex(image, debugLineBytes, debugLineStrBytes)
+		bi.saveCachedIndex(image, index)
 	}
+	bi.applyIndex(image, index, debugLineBytes, debugLineStrBytes)
 
 	sort.Sort(compileUnitsByOffset(image.compileUnits))
 	sort.Sort(functionsDebugInfoByEntry(bi.Functions))
@@ -1615,286 +1866,6 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugLineBytes []byte, wg
 	}
 }
 
-// loadDebugInfoMapsCompileUnit loads entry from a single compile unit.
-func (bi *BinaryInfo) loadDebugInfoMapsCompileUnit(ctxt *loadDebugInfoMapsContext, image *Image, reader *reader.Reader, cu *compileUnit) {
-	hasAttrGoPkgName := goversion.ProducerAfterOrEqual(cu.producer, 1, 13)
-
-	for entry, err := reader.Next(); entry != nil; entry, err = reader.Next() {
-		if err != nil {
-			image.setLoadError("error reading debug_info: %v", err)
-			return
-		}
-		switch entry.Tag {
-		case 0:
-			return
-		case dwarf.TagImportedUnit:
-			bi.loadDebugInfoMapsImportedUnit(entry, ctxt, image, cu)
-			reader.SkipChildren()
-
-		case dwarf.TagArrayType, dwarf.TagBaseType, dwarf.TagClassType, dwarf.TagStructType, dwarf.TagUnionType, dwarf.TagConstType, dwarf.TagVolatileType, dwarf.TagRestrictType, dwarf.TagEnumerationType, dwarf.TagPointerType, dwarf.TagSubroutineType, dwarf.TagTypedef, dwarf.TagUnspecifiedType:
-			if name, ok := entry.Val(dwarf.AttrName).(string); ok {
-				if !cu.isgo {
-					name = "C." + name
-				}
-				if _, exists := bi.types[name]; !exists {
-					bi.types[name] = dwarfRef{image.index, entry.Offset}
-				}
-			}
-			if cu != nil && cu.isgo && !hasAttrGoPkgName {
-				bi.registerTypeToPackageMap(entry)
-			}
-			image.registerRuntimeTypeToDIE(entry, ctxt.ardr)
-			reader.SkipChildren()
-
-		case dwarf.TagVariable:
-			if n, ok := entry.Val(dwarf.AttrName).(string); ok {
-				var addr uint64
-				if loc, ok := entry.Val(dwarf.AttrLocation).([]byte); ok {
-					if len(loc) == bi.Arch.PtrSize()+1 && op.Opcode(loc[0]) == op.DW_OP_addr {
-						addr, _ = util.ReadUintRaw(bytes.NewReader(loc[1:]), binary.LittleEndian, bi.Arch.PtrSize())
-					}
-				}
-				if !cu.isgo {
-					n = "C." + n
-				}
-				if _, known := ctxt.knownPackageVars[n]; !known {
-					bi.packageVars = append(bi.packageVars, packageVar{n, cu, entry.Offset, addr + image.StaticBase})
-				}
-			}
-			reader.SkipChildren()
-
-		case dwarf.TagConstant:
-			name, okName := entry.Val(dwarf.AttrName).(string)
-			typ, okType := entry.Val(dwarf.AttrType).(dwarf.Offset)
-			val, okVal := entry.Val(dwarf.AttrConstValue).(int64)
-			if okName && okType && okVal {
-				if !cu.isgo {
-					name = "C." + name
-				}
-				ct := bi.consts[dwarfRef{image.index, typ}]
-				if ct == nil {
-					ct = &constantType{}
-					bi.consts[dwarfRef{image.index, typ}] = ct
-				}
-				ct.values = append(ct.values, constantValue{name: name, fullName: name, value: val})
-			}
-			reader.SkipChildren()
-
-		case dwarf.TagSubprogram:
-			inlined := false
-			if inval, ok := entry.Val(dwarf.AttrInline).(int64); ok {
-				inlined = inval == 1
-			}
-
-			if inlined {
-				bi.addAbstractSubprogram(entry, ctxt, reader, image, cu)
-			} else {
-				originOffset, hasAbstractOrigin := entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
-				if hasAbstractOrigin {
-					bi.addConcreteInlinedSubprogram(entry, originOffset, ctxt, reader, cu)
-				} else {
-					bi.addConcreteSubprogram(entry, ctxt, reader, cu)
-				}
-			}
-		}
-	}
-}
-
-// loadDebugInfoMapsImportedUnit loads entries into cu from the partial unit
-// referenced in a DW_TAG_imported_unit entry.
-func (bi *BinaryInfo) loadDebugInfoMapsImportedUnit(entry *dwarf.Entry, ctxt *loadDebugInfoMapsContext, image *Image, cu *compileUnit) {
-	off, ok := entry.Val(dwarf.AttrImport).(dwarf.Offset)
-	if !ok {
-		return
-	}
-	reader := image.DwarfReader()
-	reader.Seek(off)
-	imentry, err := reader.Next()
-	if err != nil {
-		return
-	}
-	if imentry.Tag != dwarf.TagPartialUnit {
-		return
-	}
-	bi.loadDebugInfoMapsCompileUnit(ctxt, image, reader, cu)
-}
-
-// addAbstractSubprogram adds the abstract entry for an inlined function.
-func (bi *BinaryInfo) addAbstractSubprogram(entry *dwarf.Entry, ctxt *loadDebugInfoMapsContext, reader *reader.Reader, image *Image, cu *compileUnit) {
-	name, ok := subprogramEntryName(entry, cu)
-	if !ok {
-		bi.logger.Warnf("reading debug_info: abstract subprogram without name at %#x", entry.Offset)
-		if entry.Children {
-			reader.SkipChildren()
-		}
-		return
-	}
-
-	fn := Function{
-		Name:   name,
-		offset: entry.Offset,
-		cu:     cu,
-	}
-
-	if entry.Children {
-		bi.loadDebugInfoMapsInlinedCalls(ctxt, reader, cu)
-	}
-
-	bi.Functions = append(bi.Functions, fn)
-	ctxt.abstractOriginTable[entry.Offset] = len(bi.Functions) - 1
-}
-
-// addConcreteInlinedSubprogram adds the concrete entry of a subprogram that was also inlined.
-func (bi *BinaryInfo) addConcreteInlinedSubprogram(entry *dwarf.Entry, originOffset dwarf.Offset, ctxt *loadDebugInfoMapsContext, reader *reader.Reader, cu *compileUnit) {
-	lowpc, highpc, ok := subprogramEntryRange(entry, cu.image)
-	if !ok {
-		bi.logger.Warnf("reading debug_info: concrete inlined subprogram without address range at %#x", entry.Offset)
-		if entry.Children {
-			reader.SkipChildren()
-		}
-		return
-	}
-
-	originIdx, ok := ctxt.abstractOriginTable[originOffset]
-	if !ok {
-		bi.logger.Warnf("reading debug_info: could not find abstract origin of concrete inlined subprogram at %#x (origin offset %#x)", entry.Offset, originOffset)
-		if entry.Children {
-			reader.SkipChildren()
-		}
-		return
-	}
-
-	fn := &bi.Functions[originIdx]
-	fn.offset = entry.Offset
-	fn.Entry = lowpc
-	fn.End = highpc
-
-	if entry.Children {
-		bi.loadDebugInfoMapsInlinedCalls(ctxt, reader, cu)
-	}
-}
-
-// addConcreteSubprogram adds a concrete subprogram (a normal subprogram
-// that doesn't have abstract or inlined entries)
-func (bi *BinaryInfo) addConcreteSubprogram(entry *dwarf.Entry, ctxt *loadDebugInfoMapsContext, reader *reader.Reader, cu *compileUnit) {
-	lowpc, highpc, ok := subprogramEntryRange(entry, cu.image)
-	if !ok {
-		bi.logger.Warnf("reading debug_info: concrete subprogram without address range at %#x", entry.Offset)
-		if entry.Children {
-			reader.SkipChildren()
-		}
-		return
-	}
-
-	name, ok := subprogramEntryName(entry, cu)
-	if !ok {
-		bi.logger.Warnf("reading debug_info: concrete subprogram without name at %#x", entry.Offset)
-		if entry.Children {
-			reader.SkipChildren()
-		}
-		return
-	}
-
-	fn := Function{
-		Name:   name,
-		Entry:  lowpc,
-		End:    highpc,
-		offset: entry.Offset,
-		cu:     cu,
-	}
-	bi.Functions = append(bi.Functions, fn)
-
-	if entry.Children {
-		bi.loadDebugInfoMapsInlinedCalls(ctxt, reader, cu)
-	}
-}
-
-func subprogramEntryName(entry *dwarf.Entry, cu *compileUnit) (string, bool) {
-	name, ok := entry.Val(dwarf.AttrName).(string)
-	if !ok {
-		return "", false
-	}
-	if !cu.isgo {
-		name = "C." + name
-	}
-	return name, true
-}
-
-func subprogramEntryRange(entry *dwarf.Entry, image *Image) (lowpc, highpc uint64, ok bool) {
-	ok = false
-	if ranges, _ := image.dwarf.Ranges(entry); len(ranges) >= 1 {
-		ok = true
-		lowpc = ranges[0][0] + image.StaticBase
-		highpc = ranges[0][1] + image.StaticBase
-	}
-	return lowpc, highpc, ok
-}
-
-func (bi *BinaryInfo) loadDebugInfoMapsInlinedCalls(ctxt *loadDebugInfoMapsContext, reader *reader.Reader, cu *compileUnit) {
-	for {
-		entry, err := reader.Next()
-		if err != nil {
-			cu.image.setLoadError("error reading debug_info: %v", err)
-			return
-		}
-		switch entry.Tag {
-		case 0:
-			return
-		case dwarf.TagInlinedSubroutine:
-			originOffset, ok := entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
-			if !ok {
-				bi.logger.Warnf("reading debug_info: inlined call without origin offset at %#x", entry.Offset)
-				reader.SkipChildren()
-				continue
-			}
-
-			originIdx, ok := ctxt.abstractOriginTable[originOffset]
-			if !ok {
-				bi.logger.Warnf("reading debug_info: could not find abstract origin (%#x) of inlined call at %#x", originOffset, entry.Offset)
-				reader.SkipChildren()
-				continue
-			}
-			fn := &bi.Functions[originIdx]
-
-			lowpc, highpc, ok := subprogramEntryRange(entry, cu.image)
-			if !ok {
-				bi.logger.Warnf("reading debug_info: inlined call without address range at %#x", entry.Offset)
-				reader.SkipChildren()
-				continue
-			}
-
-			callfileidx, ok1 := entry.Val(dwarf.AttrCallFile).(int64)
-			callline, ok2 := entry.Val(dwarf.AttrCallLine).(int64)
-			if !ok1 || !ok2 {
-				bi.logger.Warnf("reading debug_info: inlined call without CallFile/CallLine at %#x", entry.Offset)
-				reader.SkipChildren()
-				continue
-			}
-			if cu.lineInfo == nil {
-				bi.logger.Warnf("reading debug_info: inlined call on a compilation unit without debug_line section at %#x", entry.Offset)
-				reader.SkipChildren()
-				continue
-			}
-			if int(callfileidx-1) >= len(cu.lineInfo.FileNames) {
-				bi.logger.Warnf("reading debug_info: CallFile (%d) of inlined call does not exist in compile unit file table at %#x", callfileidx, entry.Offset)
-				reader.SkipChildren()
-				continue
-			}
-			callfile := cu.lineInfo.FileNames[callfileidx-1].Path
-
-			fn.InlinedCalls = append(fn.InlinedCalls, InlinedCall{
-				cu:     cu,
-				LowPC:  lowpc,
-				HighPC: highpc,
-			})
-
-			fl := fileLine{callfile, int(callline)}
-			bi.inlinedCallLines[fl] = append(bi.inlinedCallLines[fl], lowpc)
-		}
-		reader.SkipChildren()
-	}
-}
-
 func uniq(s []string) []string {
 	if len(s) <= 0 {
 		return s
@@ -2004,31 +1975,34 @@ type PackageBuildInfo struct {
 // files constituting the package.
 func (bi *BinaryInfo) ListPackagesBuildInfo(includeFiles bool) []*PackageBuildInfo {
 	m := make(map[string]*PackageBuildInfo)
-	for _, cu := range bi.Images[0].compileUnits {
-		if cu.image != bi.Images[0] || !cu.isgo || cu.lineInfo == nil {
-			//TODO(aarzilli): what's the correct thing to do for plugins?
-			continue
-		}
-
-		ip := strings.Replace(cu.name, "\\", "/", -1)
-		if _, ok := m[ip]; !ok {
-			path := cu.lineInfo.FirstFile()
-			if ext := filepath.Ext(path); ext != ".go" && ext != ".s" {
+	for _, image := range bi.Images {
+		// packages linked in the executable as well as in plugins are listed
+		// once, with the directory of the first image.
+		for _, cu := range image.compileUnits {
+			if cu.image != image || !cu.isgo || cu.lineInfo == nil {
 				continue
 			}
-			dp := filepath.Dir(path)
-			m[ip] = &PackageBuildInfo{
-				ImportPath:    ip,
-				DirectoryPath: dp,
-				Files:         make(map[string]struct{}),
+
+			ip := strings.Replace(cu.name, "\\", "/", -1)
+			if _, ok := m[ip]; !ok {
+				path := cu.lineInfo.FirstFile()
+				if ext := filepath.Ext(path); ext != ".go" && ext != ".s" {
+					continue
+				}
+				dp := filepath.Dir(path)
+				m[ip] = &PackageBuildInfo{
+					ImportPath:    ip,
+					DirectoryPath: dp,
+					Files:         make(map[string]struct{}),
+				}
 			}
-		}
 
-		if includeFiles {
-			pbi := m[ip]
+			if includeFiles {
+				pbi := m[ip]
 
-			for _, file := range cu.lineInfo.FileNames {
-				pbi.Files[file.Path] = struct{}{}
+				for _, file := range cu.lineInfo.FileNames {
+					pbi.Files[file.Path] = struct{}{}
+				}
 			}
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"go/ast"
 	"go/constant"
 	"reflect"
+	"regexp"
 )
 
 const (
@@ -18,6 +19,10 @@ const (
 
 	unrecoveredPanicID = -1
 	fatalThrowID       = -2
+
+	// panicFunction is the function called by the runtime for every panic,
+	// recovered or not. Panic breakpoints are set on its entry point.
+	panicFunction = "runtime.gopanic"
 )
 
 // Breakpoint represents a physical breakpoint. Stores information on the break
@@ -66,6 +71,11 @@ type Breakpoint struct {
 	Cond ast.Expr
 	// internalCond is the same as Cond but used for the condition of internal breakpoints
 	internalCond ast.Expr
+	// PanicFilter: if not nil this is a panic breakpoint, set on the entry
+	// point of runtime.gopanic, and it will be triggered only if the name of
+	// the dynamic type of the panic argument, or its error message, matches
+	// the regular expression.
+	PanicFilter *regexp.Regexp
 
 	// ReturnInfo describes how to collect return variables when this
 	// breakpoint is hit as a return breakpoint.
@@ -91,6 +101,14 @@ const (
 	// Continue will set a new breakpoint (of NextBreakpoint kind) on the
 	// destination of CALL, delete this breakpoint and then continue again
 	StepBreakpoint
+	// ImageLoadBreakpoint is a breakpoint set on a function called when new
+	// images are loaded (the function that registers the module data of a
+	// newly loaded plugin, or the dynamic loader notification function), it
+	// never stops the target process: Continue reports the new images (see
+	// Target.SetNewImagesCallback) and resumes execution.
+	// Image load breakpoints do not consume a logical ID, their LogicalID is
+	// zero unless a user breakpoint is set at the same address.
+	ImageLoadBreakpoint
 )
 
 func (bp *Breakpoint) String() string {
@@ -129,7 +147,10 @@ type returnBreakpointInfo struct {
 // CheckCondition evaluates bp's condition on thread.
 func (bp *Breakpoint) CheckCondition(thread Thread) BreakpointState {
 	bpstate := BreakpointState{Breakpoint: bp, Active: false, Internal: false, CondError: nil}
-	if bp.Cond == nil && bp.internalCond == nil {
+	if bp.Kind == ImageLoadBreakpoint {
+		return bpstate
+	}
+	if bp.Cond == nil && bp.internalCond == nil && bp.PanicFilter == nil {
 		bpstate.Active = true
 		bpstate.Internal = bp.IsInternal()
 		return bpstate
@@ -157,6 +178,9 @@ func (bp *Breakpoint) CheckCondition(thread Thread) BreakpointState {
 	if bp.IsUser() {
 		// Check normal condition if this is also a user breakpoint
 		bpstate.Active, bpstate.CondError = evalBreakpointCondition(thread, bp.Cond)
+		if bpstate.Active && bpstate.CondError == nil && bp.PanicFilter != nil {
+			bpstate.Active, bpstate.CondError = evalPanicFilter(thread, bp.PanicFilter)
+		}
 	}
 	return bpstate
 }
@@ -179,8 +203,10 @@ func isDeferReturnCall(frames []Stackframe, deferReturns []uint64) (bool, uint64
 // IsInternal returns true if bp is an internal breakpoint.
 // User-set breakpoints can overlap with internal breakpoints, in that case
 // both IsUser and IsInternal will be true.
+// Image load breakpoints are not considered internal breakpoints since
+// they are never cleared.
 func (bp *Breakpoint) IsInternal() bool {
-	return bp.Kind != UserBreakpoint
+	return bp.Kind&^(UserBreakpoint|ImageLoadBreakpoint) != 0
 }
 
 // IsUser returns true if bp is a user-set breakpoint.
@@ -212,6 +238,73 @@ func evalBreakpointCondition(thread Thread, cond ast.Expr) (bool, error) {
 	return constant.BoolVal(v.Value), nil
 }
 
+// FindPanicLocation returns the address where panic breakpoints should be
+// set.
+func FindPanicLocation(p Process) ([]uint64, error) {
+	return FindFunctionLocation(p, panicFunction, 0)
+}
+
+var panicArgLoadConfig = LoadConfig{FollowPointers: true, MaxVariableRecurse: 2, MaxStringLen: 1024, MaxArrayValues: 0, MaxStructFields: -1}
+
+// evalPanicFilter returns true if the argument of the call to
+// runtime.gopanic executing on thread matches filter.
+func evalPanicFilter(thread Thread, filter *regexp.Regexp) (bool, error) {
+	scope, err := GoroutineScope(thread)
+	if err != nil {
+		return true, err
+	}
+	v, err := scope.EvalVariable("e", panicArgLoadConfig)
+	if err != nil {
+		return true, fmt.Errorf("could not read panic argument: %v", err)
+	}
+	if v.Unreadable != nil {
+		return true, fmt.Errorf("panic argument unreadable: %v", v.Unreadable)
+	}
+	typ, msg := describePanicValue(v)
+	return filter.MatchString(typ) || (msg != "" && filter.MatchString(msg)), nil
+}
+
+// describePanicValue returns the name of the dynamic type of v, the
+// interface{} argument of runtime.gopanic, and a description of its value.
+// The description is the value itself for strings and numbers and the
+// message for error types that store it in a string field (errors.New,
+// fmt.Errorf).
+// Since function calls are not possible while evaluating breakpoint
+// conditions the message of other error types is not available.
+func describePanicValue(v *Variable) (typ, msg string) {
+	if v.Kind != reflect.Interface || len(v.Children) == 0 {
+		return v.TypeString(), ""
+	}
+	data := &v.Children[0]
+	typ = data.TypeString()
+	if data.Kind == reflect.Ptr && len(data.Children) > 0 {
+		data = &data.Children[0]
+	}
+	switch data.Kind {
+	case reflect.String:
+		if data.Value != nil {
+			msg = constant.StringVal(data.Value)
+		}
+	case reflect.Struct:
+		for _, name := range []string{"s", "msg"} {
+			field, err := data.structMember(name)
+			if err != nil || field.Kind != reflect.String {
+				continue
+			}
+			field.loadValue(panicArgLoadConfig)
+			if field.Unreadable == nil && field.Value != nil {
+				msg = constant.StringVal(field.Value)
+				break
+			}
+		}
+	default:
+		if data.Value != nil {
+			msg = data.Value.String()
+		}
+	}
+	return typ, msg
+}
+
 // NoBreakpointError is returned when trying to
 // clear a breakpoint that does not exist.
 type NoBreakpointError struct {
@@ -255,6 +348,11 @@ func (t *Target) SetBreakpoint(addr uint64, kind BreakpointKind, cond ast.Expr)
 		if kind != UserBreakpoint {
 			bp.internalCond = cond
 		} else {
+			if bp.LogicalID == 0 {
+				// image load breakpoint, see ImageLoadBreakpoint
+				bpmap.breakpointIDCounter++
+				bp.LogicalID = bpmap.breakpointIDCounter
+			}
 			bp.Cond = cond
 		}
 		return bp, nil
@@ -280,14 +378,17 @@ func (t *Target) SetBreakpoint(addr uint64, kind BreakpointKind, cond ast.Expr)
 		HitCount:     map[int]uint64{},
 	}
 
-	if kind != UserBreakpoint {
-		bpmap.internalBreakpointIDCounter++
-		newBreakpoint.LogicalID = bpmap.internalBreakpointIDCounter
-		newBreakpoint.internalCond = cond
-	} else {
+	switch kind {
+	case UserBreakpoint:
 		bpmap.breakpointIDCounter++
 		newBreakpoint.LogicalID = bpmap.breakpointIDCounter
 		newBreakpoint.Cond = cond
+	case ImageLoadBreakpoint:
+		newBreakpoint.internalCond = cond
+	default:
+		bpmap.internalBreakpointIDCounter++
+		newBreakpoint.LogicalID = bpmap.internalBreakpointIDCounter
+		newBreakpoint.internalCond = cond
 	}
 
 	bpmap.M[addr] = newBreakpoint
@@ -295,17 +396,35 @@ func (t *Target) SetBreakpoint(addr uint64, kind BreakpointKind, cond ast.Expr)
 	return newBreakpoint, nil
 }
 
-// setBreakpointWithID creates a breakpoint at addr, with the specified logical ID.
-func (t *Target) setBreakpointWithID(id int, addr uint64) (*Breakpoint, error) {
+// SetBreakpointWithID creates a user breakpoint at addr, with the specified
+// logical ID. The ID is not checked for uniqueness, IDs of breakpoints set
+// by the user should be reserved with NewLogicalID.
+func (t *Target) SetBreakpointWithID(id int, addr uint64) (*Breakpoint, error) {
 	bpmap := t.Breakpoints()
+	counter := bpmap.breakpointIDCounter
 	bp, err := t.SetBreakpoint(addr, UserBreakpoint, nil)
 	if err == nil {
 		bp.LogicalID = id
-		bpmap.breakpointIDCounter--
+		bpmap.breakpointIDCounter = counter
 	}
 	return bp, err
 }
 
+// NewLogicalID reserves a logical ID for a user breakpoint that will be
+// created later with SetBreakpointWithID.
+func (bpmap *BreakpointMap) NewLogicalID() int {
+	bpmap.breakpointIDCounter++
+	return bpmap.breakpointIDCounter
+}
+
+// ReserveLogicalIDs makes sure that the logical IDs reserved by NewLogicalID,
+// or assigned to new user breakpoints, are greater than id.
+func (bpmap *BreakpointMap) ReserveLogicalIDs(id int) {
+	if bpmap.breakpointIDCounter < id {
+		bpmap.breakpointIDCounter = id
+	}
+}
+
 // ClearBreakpoint clears the breakpoint at addr.
 func (t *Target) ClearBreakpoint(addr uint64) (*Breakpoint, error) {
 	if valid, err := t.Valid(); !valid {
@@ -319,6 +438,10 @@ func (t *Target) ClearBreakpoint(addr uint64) (*Breakpoint, error) {
 
 	bp.Kind &= ^UserBreakpoint
 	bp.Cond = nil
+	if bp.Kind&ImageLoadBreakpoint != 0 {
+		// the logical ID belonged to the user breakpoint
+		bp.LogicalID = 0
+	}
 	if bp.Kind != 0 {
 		return bp, nil
 	}
@@ -338,7 +461,7 @@ func (t *Target) ClearInternalBreakpoints() error {
 	bpmap := t.Breakpoints()
 	threads := t.ThreadList()
 	for addr, bp := range bpmap.M {
-		bp.Kind = bp.Kind & UserBreakpoint
+		bp.Kind = bp.Kind & (UserBreakpoint | ImageLoadBreakpoint)
 		bp.internalCond = nil
 		bp.returnInfo = nil
 		if bp.Kind != 0 {
@@ -439,6 +562,17 @@ func (rbpi *returnBreakpointInfo) Collect(thread Thread) []*Variable {
 
 	oldFrameOffset := rbpi.frameOffset + int64(g.stack.hi)
 	oldSP := uint64(rbpi.spOffset + int64(g.stack.hi))
+
+	if scope.BinInfo.regabi {
+		// the function just returned, its return values are in registers and
+		// in its argument frame, which starts at its CFA.
+		vars, err := regabiReturnValues(rbpi.fn, scope.BinInfo, scope.Mem, scope.Regs, uint64(oldFrameOffset))
+		if err != nil {
+			return returnInfoError("could not evaluate return variables", err, thread)
+		}
+		return vars
+	}
+
 	err = fakeFunctionEntryScope(scope, rbpi.fn, oldFrameOffset, oldSP)
 	if err != nil {
 		return returnInfoError("could not read function entry", err, thread)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -199,7 +200,7 @@ var ErrUnrecognizedFormat = errors.New("unrecognized core format")
 // OpenCore will open the core file and return a Process struct.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func OpenCore(corePath, exePath string, debugInfoDirs []string) (*proc.Target, error) {
+func OpenCore(corePath, exePath string, debugInfo proc.DebugInfoConfig) (*proc.Target, error) {
 	var p *process
 	var err error
 	for _, openFn := range openFns {
@@ -214,7 +215,7 @@ func OpenCore(corePath, exePath string, debugInfoDirs []string) (*proc.Target, e
 
 	return proc.NewTarget(p, proc.NewTargetConfig{
 		Path:                exePath,
-		DebugInfoDirs:       debugInfoDirs,
+		DebugInfo:           debugInfo,
 		DisableAsyncPreempt: false,
 		StopReason:          proc.StopAttached})
 }
@@ -358,6 +359,12 @@ func (t *thread) SetDX(uint64) error {
 	return ErrChangeRegisterCore
 }
 
+// SetReg will always return an error, you cannot
+// change register values when debugging core files.
+func (t *thread) SetReg(uint64, *op.DwarfRegister) error {
+	return ErrChangeRegisterCore
+}
+
 // Breakpoints will return all breakpoints for the process.
 func (p *process) Breakpoints() *proc.BreakpointMap {
 	return &p.breakpoints
@@ -149,6 +149,10 @@ func TestSplicedReader(t *testing.T) {
 }
 
 func withCoreFile(t *testing.T, name, args string) *proc.Target {
+	return withCoreFileFlags(t, name, args, 0)
+}
+
+func withCoreFileFlags(t *testing.T, name, args string, buildFlags test.BuildFlags) *proc.Target {
 	// This is all very fragile and won't work on hosts with non-default core patterns.
 	// Might be better to check in the binary and core?
 	tempDir, err := ioutil.TempDir("", "")
@@ -156,9 +160,8 @@ func withCoreFile(t *testing.T, name, args string) *proc.Target {
 		t.Fatal(err)
 	}
 	test.PathsToRemove = append(test.PathsToRemove, tempDir)
-	var buildFlags test.BuildFlags
 	if buildMode == "pie" {
-		buildFlags = test.BuildModePIE
+		buildFlags |= test.BuildModePIE
 	}
 	fix := test.BuildFixture(name, buildFlags)
 	bashCmd := fmt.Sprintf("cd %v && ulimit -c unlimited && GOTRACEBACK=crash %v %s", tempDir, fix.Path, args)
@@ -173,7 +176,7 @@ func withCoreFile(t *testing.T, name, args string) *proc.Target {
 	}
 	corePath := cores[0]
 
-	p, err := OpenCore(corePath, fix.Path, []string{})
+	p, err := OpenCore(corePath, fix.Path, proc.DebugInfoConfig{})
 	if err != nil {
 		t.Errorf("OpenCore(%q) failed: %v", corePath, err)
 		pat, err := ioutil.ReadFile("/proc/sys/kernel/core_pattern")
@@ -259,6 +262,58 @@ func TestCore(t *testing.T) {
 	logRegisters(t, regs, p.BinInfo().Arch)
 }
 
+func TestCoreNoDWARF(t *testing.T) {
+	// Goroutines and stacktraces should be available in core files of
+	// executables built without DWARF.
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return
+	}
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 20) {
+		t.Skip("reading runtime.g without DWARF requires go1.20 or later")
+	}
+	if buildMode == "pie" {
+		t.Skip("not enabled with buildmode=PIE")
+	}
+	p := withCoreFileFlags(t, "panic", "", test.LinkNoDWARF)
+
+	gs, _, err := proc.GoroutinesInfo(p, 0, 0)
+	if err != nil || len(gs) == 0 {
+		t.Fatalf("GoroutinesInfo() = %v, %v; wanted at least one goroutine", gs, err)
+	}
+
+	found := false
+	for _, g := range gs {
+		t.Logf("Goroutine %d", g.ID)
+		stack, err := g.Stacktrace(20, 0)
+		if err != nil {
+			t.Errorf("Stacktrace() on goroutine %v = %v", g, err)
+		}
+		panicking, main := false, false
+		for _, frame := range stack {
+			fnname := ""
+			if frame.Call.Fn != nil {
+				fnname = frame.Call.Fn.Name
+			}
+			t.Logf("\tframe %s:%d in %s %#x (systemstack: %v)", frame.Call.File, frame.Call.Line, fnname, frame.Call.PC, frame.SystemStack)
+			switch {
+			case strings.Contains(fnname, "panic"):
+				panicking = true
+			case fnname == "main.main":
+				main = true
+				if frame.Call.Line != 5 {
+					t.Errorf("wrong line for main.main %s:%d", frame.Call.File, frame.Call.Line)
+				}
+			}
+		}
+		if panicking && main {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Didn't find a call to panic from main.main in goroutine stacks: %v", gs)
+	}
+}
+
 func TestCoreFpRegisters(t *testing.T) {
 	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
 		return
@@ -383,6 +438,27 @@ mainSearch:
 	t.Logf("s = %#v\n", v2)
 }
 
+func TestCoreFunctionCall(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return
+	}
+	p := withCoreFile(t, "corestringer", "")
+
+	// point.String allocates its result, the interpreter must serve the
+	// allocation from its own heap.
+	loadConfig := proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	err := proc.EvalExpressionWithCalls(p, p.SelectedGoroutine(), "main.origin.String()", loadConfig, true)
+	assertNoError(err, t, "EvalExpressionWithCalls(main.origin.String())")
+	retvals := p.CurrentThread().Common().ReturnValues(loadConfig)
+	if len(retvals) != 1 {
+		t.Fatalf("wrong number of return values %d", len(retvals))
+	}
+	assertNoError(retvals[0].Unreadable, t, "unreadable return value")
+	if s := constant.StringVal(retvals[0].Value); s != "(1, -200)" {
+		t.Errorf("main.origin.String() returned %q, expected %q", s, "(1, -200)")
+	}
+}
+
 func TestMinidump(t *testing.T) {
 	if runtime.GOOS != "windows" {
 		t.Skip("minidumps can only be produced on windows")
@@ -394,7 +470,7 @@ func TestMinidump(t *testing.T) {
 	fix := test.BuildFixture("sleep", buildFlags)
 	mdmpPath := procdump(t, fix.Path)
 
-	p, err := OpenCore(mdmpPath, fix.Path, []string{})
+	p, err := OpenCore(mdmpPath, fix.Path, proc.DebugInfoConfig{})
 	if err != nil {
 		t.Fatalf("OpenCore: %v", err)
 	}
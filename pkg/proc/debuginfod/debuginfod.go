@@ -0,0 +1,140 @@
+// Package debuginfod implements a client for servers speaking the
+// debuginfod HTTP protocol, used to retrieve separate debug info files for
+// executables and shared libraries by their build ID.
+//
+// See: https://sourceware.org/elfutils/Debuginfod.html
+package debuginfod
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by FindDebugInfo when none of the servers has
+// the debug info for the requested build ID.
+var ErrNotFound = errors.New("debug info not found on any debuginfod server")
+
+// DefaultTimeout is the time limit for downloading a debug info file used
+// by clients that do not specify an HTTPClient.
+const DefaultTimeout = 2 * time.Minute
+
+// Client retrieves debug info files from a list of debuginfod servers,
+// caching them on the local file system.
+type Client struct {
+	// URLs is the list of debuginfod servers, queried in order.
+	URLs []string
+	// CacheDir is the directory where downloaded files are stored.
+	CacheDir string
+	// HTTPClient is the client used to contact the servers, if nil a client
+	// with a timeout of DefaultTimeout is used.
+	HTTPClient *http.Client
+	// Progress, if not nil, is called while a debug info file is being
+	// downloaded with the number of bytes received so far and the total size
+	// of the file (-1 if the server did not report it).
+	Progress func(buildID string, received, total int64)
+}
+
+// New returns a client for the specified servers that caches debug info
+// files in cacheDir.
+func New(urls []string, cacheDir string) *Client {
+	return &Client{URLs: urls, CacheDir: cacheDir}
+}
+
+// FindDebugInfo returns the path of a local file containing the debug info
+// for the executable with the specified build ID, downloading it if it
+// isn't in the cache already.
+func (c *Client) FindDebugInfo(buildID string) (string, error) {
+	buildID = strings.ToLower(buildID)
+	if _, err := hex.DecodeString(buildID); err != nil || buildID == "" {
+		return "", fmt.Errorf("invalid build ID %q", buildID)
+	}
+
+	cachePath := filepath.Join(c.CacheDir, buildID, "debuginfo")
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+
+	var errs []string
+	for _, url := range c.URLs {
+		err := c.download(strings.TrimRight(url, "/")+"/buildid/"+buildID+"/debuginfo", buildID, cachePath)
+		if err == nil {
+			return cachePath, nil
+		}
+		if err != ErrNotFound {
+			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
+		}
+	}
+	if len(errs) > 0 {
+		return "", fmt.Errorf("could not download debug info: %s", strings.Join(errs, "; "))
+	}
+	return "", ErrNotFound
+}
+
+// download fetches url into dest, the file is written to a temporary file
+// first so that interrupted downloads never end up in the cache.
+func (c *Client) download(url, buildID, dest string) error {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), "debuginfo.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var body io.Reader = resp.Body
+	if c.Progress != nil {
+		body = &progressReader{r: resp.Body, buildID: buildID, total: resp.ContentLength, progress: c.Progress}
+	}
+	_, err = io.Copy(tmp, body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// progressReader wraps a reader reporting every read to a progress
+// callback.
+type progressReader struct {
+	r        io.Reader
+	buildID  string
+	received int64
+	total    int64
+	progress func(buildID string, received, total int64)
+}
+
+func (pr *progressReader) Read(buf []byte) (int, error) {
+	n, err := pr.r.Read(buf)
+	pr.received += int64(n)
+	if n > 0 {
+		pr.progress(pr.buildID, pr.received, pr.total)
+	}
+	return n, err
+}
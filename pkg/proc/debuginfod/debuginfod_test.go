@@ -0,0 +1,114 @@
+package debuginfod
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newServer(files map[string]string, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		const prefix, suffix = "/buildid/", "/debuginfo"
+		if !strings.HasPrefix(r.URL.Path, prefix) || !strings.HasSuffix(r.URL.Path, suffix) {
+			http.NotFound(w, r)
+			return
+		}
+		content, ok := files[r.URL.Path[len(prefix):len(r.URL.Path)-len(suffix)]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+}
+
+func TestFindDebugInfo(t *testing.T) {
+	var requests1, requests2 int
+	srv1 := newServer(map[string]string{"aabb": "first"}, &requests1)
+	defer srv1.Close()
+	srv2 := newServer(map[string]string{"aabb": "second", "ccdd": "other"}, &requests2)
+	defer srv2.Close()
+
+	cacheDir, err := ioutil.TempDir("", "debuginfod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	c := New([]string{srv1.URL, srv2.URL + "/"}, cacheDir)
+	var progress []int64
+	c.Progress = func(buildID string, received, total int64) {
+		progress = append(progress, received)
+	}
+
+	check := func(buildID, tgt string) {
+		t.Helper()
+		path, err := c.FindDebugInfo(buildID)
+		if err != nil {
+			t.Fatalf("FindDebugInfo(%q): %v", buildID, err)
+		}
+		if path != filepath.Join(cacheDir, strings.ToLower(buildID), "debuginfo") {
+			t.Errorf("FindDebugInfo(%q): wrong path %q", buildID, path)
+		}
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != tgt {
+			t.Errorf("FindDebugInfo(%q): got %q expected %q", buildID, string(buf), tgt)
+		}
+	}
+
+	check("aabb", "first")
+	check("ccdd", "other")
+	if requests1 != 2 || requests2 != 1 {
+		t.Errorf("wrong number of requests %d %d", requests1, requests2)
+	}
+	if len(progress) == 0 || progress[len(progress)-1] != int64(len("other")) {
+		t.Errorf("wrong progress reports %v", progress)
+	}
+
+	// cached
+	check("AABB", "first")
+	if requests1 != 2 || requests2 != 1 {
+		t.Errorf("cache not used %d %d", requests1, requests2)
+	}
+
+	if _, err := c.FindDebugInfo("eeff"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound got %v", err)
+	}
+	if _, err := c.FindDebugInfo("../../etc"); err == nil {
+		t.Errorf("invalid build ID accepted")
+	}
+}
+
+func TestFindDebugInfoTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	cacheDir, err := ioutil.TempDir("", "debuginfod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	c := New([]string{srv.URL}, cacheDir)
+	c.HTTPClient = &http.Client{Timeout: 100 * time.Millisecond}
+	t0 := time.Now()
+	if _, err := c.FindDebugInfo("aabb"); err == nil || err == ErrNotFound {
+		t.Errorf("expected timeout error got %v", err)
+	}
+	if d := time.Since(t0); d > 10*time.Second {
+		t.Errorf("FindDebugInfo took %v", d)
+	}
+}
@@ -0,0 +1,211 @@
+package proc
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DiffKind is the kind of a difference between two values.
+type DiffKind uint8
+
+const (
+	// DiffChanged means that the value at the path is different.
+	DiffChanged DiffKind = iota
+	// DiffAdded means that the element at the path only exists in the new
+	// value.
+	DiffAdded
+	// DiffRemoved means that the element at the path only exists in the old
+	// value.
+	DiffRemoved
+)
+
+// VariableDiff is a difference between two values, found by DiffVariables.
+type VariableDiff struct {
+	// Path is the path from the root of the compared values to the differing
+	// element, for example `.Items[3].Name` or `["key"]`, it is empty if
+	// the root values are different.
+	Path string
+	Kind DiffKind
+	// Old is the element of the old value, nil if Kind is DiffAdded.
+	Old *Variable
+	// New is the element of the new value, nil if Kind is DiffRemoved.
+	New *Variable
+}
+
+// DiffVariables compares oldv and newv structurally and returns the list
+// of their differences: changed struct fields, slice and array elements
+// that differ by index, and map keys that were added, removed or whose
+// value changed. Values of different types are reported as a single
+// change of the whole value.
+// Only the parts of the values that were loaded are compared.
+func DiffVariables(oldv, newv *Variable) []VariableDiff {
+	var r []VariableDiff
+	diffVariables("", oldv, newv, &r)
+	return r
+}
+
+func diffVariables(path string, oldv, newv *Variable, r *[]VariableDiff) {
+	changed := func() {
+		*r = append(*r, VariableDiff{Path: path, Kind: DiffChanged, Old: oldv, New: newv})
+	}
+
+	if oldv.Unreadable != nil || newv.Unreadable != nil {
+		if oldv.Unreadable == nil || newv.Unreadable == nil || oldv.Unreadable.Error() != newv.Unreadable.Error() {
+			changed()
+		}
+		return
+	}
+	if oldv.Kind != newv.Kind || oldv.TypeString() != newv.TypeString() {
+		changed()
+		return
+	}
+
+	switch oldv.Kind {
+	case reflect.Struct:
+		if len(oldv.Children) != len(newv.Children) {
+			changed()
+			return
+		}
+		for i := range oldv.Children {
+			diffVariables(path+"."+oldv.Children[i].Name, &oldv.Children[i], &newv.Children[i], r)
+		}
+
+	case reflect.Slice, reflect.Array:
+		n := len(oldv.Children)
+		if len(newv.Children) < n {
+			n = len(newv.Children)
+		}
+		for i := 0; i < n; i++ {
+			diffVariables(fmt.Sprintf("%s[%d]", path, i), &oldv.Children[i], &newv.Children[i], r)
+		}
+		for i := n; i < len(oldv.Children); i++ {
+			*r = append(*r, VariableDiff{Path: fmt.Sprintf("%s[%d]", path, i), Kind: DiffRemoved, Old: &oldv.Children[i]})
+		}
+		for i := n; i < len(newv.Children); i++ {
+			*r = append(*r, VariableDiff{Path: fmt.Sprintf("%s[%d]", path, i), Kind: DiffAdded, New: &newv.Children[i]})
+		}
+
+	case reflect.Map:
+		newkeys := make(map[string]int, len(newv.Children)/2)
+		for i := 0; i+1 < len(newv.Children); i += 2 {
+			newkeys[diffMapKey(&newv.Children[i])] = i
+		}
+		seen := make(map[string]bool, len(oldv.Children)/2)
+		for i := 0; i+1 < len(oldv.Children); i += 2 {
+			key := diffMapKey(&oldv.Children[i])
+			seen[key] = true
+			keypath := fmt.Sprintf("%s[%s]", path, key)
+			if j, ok := newkeys[key]; ok {
+				diffVariables(keypath, &oldv.Children[i+1], &newv.Children[j+1], r)
+			} else {
+				*r = append(*r, VariableDiff{Path: keypath, Kind: DiffRemoved, Old: &oldv.Children[i+1]})
+			}
+		}
+		for i := 0; i+1 < len(newv.Children); i += 2 {
+			key := diffMapKey(&newv.Children[i])
+			if !seen[key] {
+				*r = append(*r, VariableDiff{Path: fmt.Sprintf("%s[%s]", path, key), Kind: DiffAdded, New: &newv.Children[i+1]})
+			}
+		}
+
+	case reflect.Ptr:
+		oldnil, newnil := isNilPtr(oldv), isNilPtr(newv)
+		switch {
+		case oldnil && newnil:
+		case oldnil || newnil:
+			changed()
+		case oldv.Children[0].OnlyAddr || newv.Children[0].OnlyAddr:
+			if oldv.Children[0].Addr != newv.Children[0].Addr {
+				changed()
+			}
+		default:
+			diffVariables(path, &oldv.Children[0], &newv.Children[0], r)
+		}
+
+	case reflect.UnsafePointer:
+		if isNilPtr(oldv) != isNilPtr(newv) || (!isNilPtr(oldv) && oldv.Children[0].Addr != newv.Children[0].Addr) {
+			changed()
+		}
+
+	case reflect.Interface:
+		if len(oldv.Children) == 0 || len(newv.Children) == 0 {
+			if len(oldv.Children) != len(newv.Children) {
+				changed()
+			}
+			return
+		}
+		oldc, newc := &oldv.Children[0], &newv.Children[0]
+		if oldc.Kind != newc.Kind || oldc.TypeString() != newc.TypeString() {
+			changed()
+			return
+		}
+		diffVariables(path, oldc, newc, r)
+
+	case reflect.Chan, reflect.Func:
+		if oldv.Base != newv.Base {
+			changed()
+		}
+
+	default:
+		if oldv.FloatSpecial != 0 || newv.FloatSpecial != 0 {
+			if oldv.FloatSpecial != newv.FloatSpecial {
+				changed()
+			}
+			return
+		}
+		if !constantEqual(oldv.Value, newv.Value) || oldv.Len != newv.Len {
+			changed()
+		}
+	}
+}
+
+func isNilPtr(v *Variable) bool {
+	return len(v.Children) == 0 || v.Children[0].Addr == 0
+}
+
+// constantEqual returns true if a and b are the same value.
+func constantEqual(a, b constant.Value) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind() != b.Kind() || a.Kind() == constant.Unknown {
+		return false
+	}
+	return constant.Compare(a, token.EQL, b)
+}
+
+// diffMapKey returns a string identifying the map key v, used both to
+// match the keys of the two maps being compared and in the path of their
+// differences.
+func diffMapKey(v *Variable) string {
+	switch v.Kind {
+	case reflect.String:
+		if v.Value != nil {
+			return strconv.Quote(constant.StringVal(v.Value))
+		}
+	case reflect.Struct, reflect.Array:
+		keys := make([]string, len(v.Children))
+		for i := range v.Children {
+			keys[i] = diffMapKey(&v.Children[i])
+		}
+		return v.TypeString() + "{" + strings.Join(keys, ", ") + "}"
+	case reflect.Interface:
+		if len(v.Children) > 0 {
+			return diffMapKey(&v.Children[0])
+		}
+	case reflect.Ptr, reflect.UnsafePointer:
+		if len(v.Children) > 0 {
+			return fmt.Sprintf("(%s)(%#x)", v.TypeString(), v.Children[0].Addr)
+		}
+	case reflect.Chan:
+		return fmt.Sprintf("(%s)(%#x)", v.TypeString(), v.Base)
+	}
+	if v.Value != nil {
+		return v.Value.String()
+	}
+	return fmt.Sprintf("(%s)(%#x)", v.TypeString(), v.Addr)
+}
@@ -36,13 +36,28 @@ func fakeCFA() uint64 {
 }
 
 func fakeBinaryInfo(t *testing.T, dwb *dwarfbuilder.Builder) (*proc.BinaryInfo, *dwarf.Data) {
-	abbrev, aranges, frame, info, line, pubnames, ranges, str, loc, err := dwb.Build()
+	abbrev, aranges, frame, info, line, pubnames, ranges, str, loc, addr, err := dwb.Build()
 	assertNoError(err, t, "dwarfbuilder.Build")
+
+	var loclists []byte
+	if addr != nil {
+		// DWARF 5, ranges and loc are actually debug_rnglists and debug_loclists
+		dwdata, err := dwarf.New(abbrev, aranges, frame, info, line, pubnames, nil, str)
+		assertNoError(err, t, "creating dwarf")
+		assertNoError(dwdata.AddSection(".debug_rnglists", ranges), t, "adding debug_rnglists")
+		assertNoError(dwdata.AddSection(".debug_addr", addr), t, "adding debug_addr")
+		loclists, loc = loc, nil
+
+		bi := proc.NewBinaryInfo("linux", "amd64")
+		bi.LoadImageFromData(dwdata, frame, line, loc, loclists, addr)
+		return bi, dwdata
+	}
+
 	dwdata, err := dwarf.New(abbrev, aranges, frame, info, line, pubnames, ranges, str)
 	assertNoError(err, t, "creating dwarf")
 
 	bi := proc.NewBinaryInfo("linux", "amd64")
-	bi.LoadImageFromData(dwdata, frame, line, loc)
+	bi.LoadImageFromData(dwdata, frame, line, loc, nil, nil)
 
 	return bi, dwdata
 }
@@ -236,6 +251,47 @@ func TestDwarfExprLoclist(t *testing.T) {
 	uintExprCheck(t, scope, "a", after)
 }
 
+func TestDwarfExprLoclist5(t *testing.T) {
+	const before = 0x1234
+	const after = 0x4321
+
+	dwb := dwarfbuilder.NewDwarf5()
+
+	uint16off := dwb.AddBaseType("uint16", dwarfbuilder.DW_ATE_unsigned, 2)
+
+	dwb.AddSubprogram("main.main", 0x40100, 0x41000)
+	aOff := dwb.AddVariable("a", uint16off, []dwarfbuilder.LocEntry{
+		{Lowpc: 0x40100, Highpc: 0x40700, Loc: dwarfbuilder.LocationBlock(op.DW_OP_call_frame_cfa)},
+		{Lowpc: 0x40700, Highpc: 0x41000, Loc: dwarfbuilder.LocationBlock(op.DW_OP_call_frame_cfa, op.DW_OP_consts, int(2), op.DW_OP_plus)},
+	})
+	dwb.TagClose()
+
+	bi, dwdata := fakeBinaryInfo(t, dwb)
+
+	mainfn := bi.LookupFunc["main.main"]
+
+	mem := newFakeMemory(fakeCFA(), uint16(before), uint16(after))
+	const PC = 0x40100
+	regs := linutil.AMD64Registers{Regs: &linutil.AMD64PtraceRegs{Rip: PC}}
+
+	scope := &proc.EvalScope{Location: proc.Location{PC: PC, Fn: mainfn}, Regs: dwarfRegisters(bi, &regs), Mem: mem, BinInfo: bi}
+
+	uintExprCheck(t, scope, "a", before)
+	scope.PC = 0x40800
+	scope.Regs.Reg(scope.Regs.PCRegNum).Uint64Val = scope.PC
+	uintExprCheck(t, scope, "a", after)
+
+	dwrdr := dwdata.Reader()
+	dwrdr.Seek(aOff)
+	aEntry, err := dwrdr.Next()
+	assertNoError(err, t, "reading 'a' entry")
+	ranges, err := bi.LocationCovers(aEntry, dwarf.AttrLocation)
+	assertNoError(err, t, "LocationCovers")
+	if fmt.Sprintf("%x", ranges) != "[[40100 40700] [40700 41000]]" {
+		t.Errorf("wrong value returned by LocationCover: %x", ranges)
+	}
+}
+
 func TestIssue1419(t *testing.T) {
 	// trying to read a slice variable with a location list that tries to read
 	// from registers we don't have should not cause a panic.
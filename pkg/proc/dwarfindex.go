@@ -0,0 +1,749 @@
+package proc
+
+// Indexing of debug_info.
+//
+// To load an image all the entries of its debug_info section must be read
+// to find its functions, package variables, types and constants. For large
+// executables this is most of the time spent starting a debug session, to
+// reduce it:
+//
+//  1. compile units are indexed in parallel and the results merged in the
+//     order in which compile units appear in debug_info,
+//  2. the index of an image is saved, keyed by the build ID of the image,
+//     in the index cache directory, if one is configured, and read back the next
+//     time the same image is loaded, skipping the walk of debug_info
+//     entirely,
+//  3. types, constants and runtime types are only added to the maps of
+//     BinaryInfo the first time they are needed (see loadPendingTypes).
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"debug/macho"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/line"
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/dwarf/reader"
+	"github.com/go-delve/delve/pkg/dwarf/util"
+	"github.com/go-delve/delve/pkg/goversion"
+	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/sirupsen/logrus"
+)
+
+// indexVersion is the version of the format of debugInfoIndex, it must be
+// incremented every time debugInfoIndex or the way it is built changes.
+const indexVersion = 3
+
+// indexCacheMaxEntries is the maximum number of indexes kept in the index
+// cache directory, older indexes are deleted.
+const indexCacheMaxEntries = 32
+
+// debugInfoIndex is the result of indexing the debug_info section of an
+// image. All addresses are not relocated.
+type debugInfoIndex struct {
+	Version       int
+	PtrSize       int
+	DebugLineSize int
+
+	Regabi           bool
+	CompileUnits     []indexCompileUnit
+	Functions        []indexFunction
+	PackageVars      []indexPackageVar
+	InlinedCallLines []indexInlinedCallLine
+	Packages         []indexPackage
+	Types            []indexType
+	Consts           []indexConst
+	RuntimeTypes     []indexRuntimeType
+
+	// lineInfos are the line tables of compile units, parsed while building
+	// the index. Not saved.
+	lineInfos []*line.DebugLineInfo
+}
+
+type indexCompileUnit struct {
+	Offset         dwarf.Offset
+	Name           string
+	CompDir        string
+	Producer       string
+	GoPackage      string // value of godwarf.AttrGoPackageName
+	Ranges         [][2]uint64
+	IsGo           bool
+	Optimized      bool
+	Version        uint16 // DWARF version of the compile unit, 0 if unknown
+	LineInfoOffset int64  // -1 if the compile unit doesn't have a line table
+}
+
+type indexFunction struct {
+	Name         string
+	Entry, End   uint64 // both zero for abstract functions that were never instantiated
+	Offset       dwarf.Offset
+	CU           int // index in CompileUnits
+	InlinedCalls []indexInlinedCall
+}
+
+type indexInlinedCall struct {
+	CU            int
+	LowPC, HighPC uint64
+}
+
+type indexPackageVar struct {
+	Name   string
+	CU     int
+	Offset dwarf.Offset
+	Addr   uint64
+}
+
+type indexInlinedCallLine struct {
+	File string
+	Line int
+	PC   uint64
+}
+
+// indexPackage is a package name to package path association, if Replace
+// is set the path replaces all other paths for the package name.
+type indexPackage struct {
+	Name, Path string
+	Replace    bool
+}
+
+type indexType struct {
+	Name   string
+	Offset dwarf.Offset
+}
+
+type indexConst struct {
+	Type  dwarf.Offset
+	Name  string
+	Value int64
+}
+
+type indexRuntimeType struct {
+	Off    uint64 // offset of runtime._type in runtime.moduledata.types
+	Offset dwarf.Offset
+}
+
+// cuIndex is the result of indexing a single compile unit, function
+// references by abstract origin are resolved when all compile units are
+// merged.
+type cuIndex struct {
+	functions       []indexFunction
+	abstract        []bool // abstract[i] is true if functions[i] is an abstract function
+	concreteInlined []cuConcreteInlined
+	inlinedCalls    []cuInlinedCall
+	packageVars     []indexPackageVar
+	packages        []indexPackage
+	types           []indexType
+	consts          []indexConst
+	runtimeTypes    []indexRuntimeType
+}
+
+type cuConcreteInlined struct {
+	origin, offset dwarf.Offset
+	entry, end     uint64
+}
+
+type cuInlinedCall struct {
+	origin, offset dwarf.Offset
+	lowpc, highpc  uint64
+	file           string
+	line           int
+}
+
+// parallelFor calls f(i) for every i in [0, n) using multiple goroutines.
+func parallelFor(n int, f func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	work := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+}
+
+// buildIndex indexes the debug_info section of image.
+func (bi *BinaryInfo) buildIndex(image *Image, debugLineBytes, debugLineStrBytes []byte) *debugInfoIndex {
+	index := &debugInfoIndex{Version: indexVersion, PtrSize: bi.Arch.PtrSize(), DebugLineSize: len(debugLineBytes)}
+
+	// Skipping the children of a compile unit does not read them, listing
+	// compile units is fast.
+	var entries []*dwarf.Entry
+	rdr := image.DwarfReader()
+	for entry, err := rdr.Next(); entry != nil; entry, err = rdr.Next() {
+		if err != nil {
+			image.setLoadError("error reading debug_info: %v", err)
+			break
+		}
+		if entry.Tag == dwarf.TagCompileUnit {
+			index.CompileUnits = append(index.CompileUnits, bi.indexCompileUnit(image, entry, index))
+			entries = append(entries, entry)
+		}
+		rdr.SkipChildren()
+	}
+
+	index.lineInfos = make([]*line.DebugLineInfo, len(entries))
+	cuidxs := make([]cuIndex, len(entries))
+	parallelFor(len(entries), func(i int) {
+		icu := &index.CompileUnits[i]
+		index.lineInfos[i] = bi.parseLineInfo(image, icu, debugLineBytes, debugLineStrBytes)
+		if !entries[i].Children {
+			return
+		}
+		rdr := image.DwarfReader()
+		rdr.Seek(entries[i].Offset)
+		rdr.Next()
+		bi.indexCompileUnitEntries(image, rdr, icu, index.lineInfos[i], i, &cuidxs[i])
+	})
+
+	bi.mergeIndex(index, cuidxs)
+	return index
+}
+
+// indexCompileUnit reads the attributes of a compile unit entry.
+func (bi *BinaryInfo) indexCompileUnit(image *Image, entry *dwarf.Entry, index *debugInfoIndex) indexCompileUnit {
+	icu := indexCompileUnit{Offset: entry.Offset, LineInfoOffset: -1, Version: image.unitVersions[entry.Offset]}
+	if lang, _ := entry.Val(dwarf.AttrLanguage).(int64); lang == dwarfGoLanguage {
+		icu.IsGo = true
+	}
+	icu.Name, _ = entry.Val(dwarf.AttrName).(string)
+	icu.CompDir, _ = entry.Val(dwarf.AttrCompDir).(string)
+	if icu.CompDir != "" {
+		icu.Name = filepath.Join(icu.CompDir, icu.Name)
+	}
+	icu.Ranges, _ = image.dwarf.Ranges(entry)
+	if lineInfoOffset, ok := entry.Val(dwarf.AttrStmtList).(int64); ok && lineInfoOffset >= 0 && lineInfoOffset < int64(index.DebugLineSize) {
+		icu.LineInfoOffset = lineInfoOffset
+	}
+	icu.Producer, _ = entry.Val(dwarf.AttrProducer).(string)
+	if icu.IsGo && icu.Producer != "" {
+		semicolon := strings.Index(icu.Producer, ";")
+		if semicolon < 0 {
+			icu.Optimized = goversion.ProducerAfterOrEqual(icu.Producer, 1, 10)
+		} else {
+			icu.Optimized = !strings.Contains(icu.Producer[semicolon:], "-N") || !strings.Contains(icu.Producer[semicolon:], "-l")
+			if strings.Contains(icu.Producer[semicolon:], "regabi") && len(bi.Arch.argumentRegs) > 0 {
+				index.Regabi = true
+			}
+			icu.Producer = icu.Producer[:semicolon]
+		}
+	}
+	if icu.IsGo {
+		icu.GoPackage, _ = entry.Val(godwarf.AttrGoPackageName).(string)
+	}
+	return icu
+}
+
+// parseLineInfo parses the line table of a compile unit.
+func (bi *BinaryInfo) parseLineInfo(image *Image, icu *indexCompileUnit, debugLineBytes, debugLineStrBytes []byte) *line.DebugLineInfo {
+	if icu.LineInfoOffset < 0 || icu.LineInfoOffset >= int64(len(debugLineBytes)) {
+		return nil
+	}
+	var logfn func(string, ...interface{})
+	if logflags.DebugLineErrors() {
+		logger := logrus.New().WithFields(logrus.Fields{"layer": "dwarf-line"})
+		logger.Logger.Level = logrus.DebugLevel
+		logfn = func(fmt string, args ...interface{}) {
+			logger.Printf(fmt, args...)
+		}
+	}
+	return line.Parse(icu.CompDir, bytes.NewBuffer(debugLineBytes[icu.LineInfoOffset:]), debugLineStrBytes, logfn, image.StaticBase, bi.GOOS == "windows", bi.Arch.PtrSize())
+}
+
+// indexCompileUnitEntries indexes the children of a compile unit entry, rdr
+// must be positioned on the first child.
+func (bi *BinaryInfo) indexCompileUnitEntries(image *Image, rdr *reader.Reader, icu *indexCompileUnit, lineInfo *line.DebugLineInfo, cuIdx int, out *cuIndex) {
+	hasAttrGoPkgName := goversion.ProducerAfterOrEqual(icu.Producer, 1, 13)
+
+	for entry, err := rdr.Next(); entry != nil; entry, err = rdr.Next() {
+		if err != nil {
+			image.setLoadError("error reading debug_info: %v", err)
+			return
+		}
+		switch entry.Tag {
+		case 0:
+			return
+		case dwarf.TagImportedUnit:
+			if off, ok := entry.Val(dwarf.AttrImport).(dwarf.Offset); ok {
+				irdr := image.DwarfReader()
+				irdr.Seek(off)
+				if imentry, err := irdr.Next(); err == nil && imentry.Tag == dwarf.TagPartialUnit {
+					bi.indexCompileUnitEntries(image, irdr, icu, lineInfo, cuIdx, out)
+				}
+			}
+			rdr.SkipChildren()
+
+		case dwarf.TagArrayType, dwarf.TagBaseType, dwarf.TagClassType, dwarf.TagStructType, dwarf.TagUnionType, dwarf.TagConstType, dwarf.TagVolatileType, dwarf.TagRestrictType, dwarf.TagEnumerationType, dwarf.TagPointerType, dwarf.TagSubroutineType, dwarf.TagTypedef, dwarf.TagUnspecifiedType:
+			if name, ok := entry.Val(dwarf.AttrName).(string); ok {
+				if !icu.IsGo {
+					// struct, union and enum tags are also available with the names
+					// used by cgo, these are what C style casts are translated to.
+					// Declarations are skipped so that the names resolve to the
+					// complete type.
+					switch {
+					case entry.Val(dwarf.AttrDeclaration) != nil:
+						// nothing to do
+					case entry.Tag == dwarf.TagStructType:
+						out.types = append(out.types, indexType{"C.struct_" + name, entry.Offset})
+					case entry.Tag == dwarf.TagUnionType:
+						out.types = append(out.types, indexType{"C.union_" + name, entry.Offset})
+					case entry.Tag == dwarf.TagEnumerationType:
+						out.types = append(out.types, indexType{"C.enum_" + name, entry.Offset})
+					}
+					name = "C." + name
+				}
+				out.types = append(out.types, indexType{name, entry.Offset})
+			}
+			if icu.IsGo && !hasAttrGoPkgName {
+				if name, path, ok := typeToPackage(entry); ok {
+					out.packages = append(out.packages, indexPackage{Name: name, Path: path, Replace: true})
+				}
+			}
+			if off, ok := entry.Val(godwarf.AttrGoRuntimeType).(uint64); ok {
+				out.runtimeTypes = append(out.runtimeTypes, indexRuntimeType{off, entry.Offset})
+			}
+			rdr.SkipChildren()
+
+		case dwarf.TagVariable:
+			if n, ok := entry.Val(dwarf.AttrName).(string); ok {
+				var addr uint64
+				if loc, ok := entry.Val(dwarf.AttrLocation).([]byte); ok {
+					if len(loc) == bi.Arch.PtrSize()+1 && op.Opcode(loc[0]) == op.DW_OP_addr {
+						addr, _ = util.ReadUintRaw(bytes.NewReader(loc[1:]), binary.LittleEndian, bi.Arch.PtrSize())
+					}
+				}
+				if !icu.IsGo {
+					n = "C." + n
+				}
+				out.packageVars = append(out.packageVars, indexPackageVar{n, cuIdx, entry.Offset, addr})
+			}
+			rdr.SkipChildren()
+
+		case dwarf.TagConstant:
+			name, okName := entry.Val(dwarf.AttrName).(string)
+			typ, okType := entry.Val(dwarf.AttrType).(dwarf.Offset)
+			val, okVal := entry.Val(dwarf.AttrConstValue).(int64)
+			if okName && okType && okVal {
+				if !icu.IsGo {
+					name = "C." + name
+				}
+				out.consts = append(out.consts, indexConst{typ, name, val})
+			}
+			rdr.SkipChildren()
+
+		case dwarf.TagSubprogram:
+			bi.indexSubprogram(image, rdr, entry, icu, lineInfo, cuIdx, out)
+		}
+	}
+}
+
+// indexSubprogram indexes a DW_TAG_subprogram entry and the inlined calls it contains.
+func (bi *BinaryInfo) indexSubprogram(image *Image, rdr *reader.Reader, entry *dwarf.Entry, icu *indexCompileUnit, lineInfo *line.DebugLineInfo, cuIdx int, out *cuIndex) {
+	skip := func() {
+		if entry.Children {
+			rdr.SkipChildren()
+		}
+	}
+
+	inlined := false
+	if inval, ok := entry.Val(dwarf.AttrInline).(int64); ok {
+		inlined = inval == 1
+	}
+
+	name, hasName := entry.Val(dwarf.AttrName).(string)
+	if hasName && !icu.IsGo {
+		name = "C." + name
+	}
+
+	switch {
+	case inlined:
+		// abstract entry for an inlined function
+		if !hasName {
+			bi.logger.Warnf("reading debug_info: abstract subprogram without name at %#x", entry.Offset)
+			skip()
+			return
+		}
+		out.functions = append(out.functions, indexFunction{Name: name, Offset: entry.Offset, CU: cuIdx})
+		out.abstract = append(out.abstract, true)
+
+	default:
+		lowpc, highpc, ok := subprogramEntryRange(entry, image)
+		if !ok {
+			bi.logger.Warnf("reading debug_info: concrete subprogram without address range at %#x", entry.Offset)
+			skip()
+			return
+		}
+		if originOffset, hasAbstractOrigin := entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset); hasAbstractOrigin {
+			// concrete entry of a subprogram that was also inlined
+			out.concreteInlined = append(out.concreteInlined, cuConcreteInlined{originOffset, entry.Offset, lowpc, highpc})
+			break
+		}
+		if !hasName {
+			bi.logger.Warnf("reading debug_info: concrete subprogram without name at %#x", entry.Offset)
+			skip()
+			return
+		}
+		out.functions = append(out.functions, indexFunction{Name: name, Entry: lowpc, End: highpc, Offset: entry.Offset, CU: cuIdx})
+		out.abstract = append(out.abstract, false)
+	}
+
+	if entry.Children {
+		bi.indexInlinedCalls(image, rdr, lineInfo, out)
+	}
+}
+
+// indexInlinedCalls indexes the DW_TAG_inlined_subroutine children of a subprogram.
+func (bi *BinaryInfo) indexInlinedCalls(image *Image, rdr *reader.Reader, lineInfo *line.DebugLineInfo, out *cuIndex) {
+	for {
+		entry, err := rdr.Next()
+		if err != nil {
+			image.setLoadError("error reading debug_info: %v", err)
+			return
+		}
+		switch entry.Tag {
+		case 0:
+			return
+		case dwarf.TagInlinedSubroutine:
+			originOffset, ok := entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+			if !ok {
+				bi.logger.Warnf("reading debug_info: inlined call without origin offset at %#x", entry.Offset)
+				break
+			}
+
+			lowpc, highpc, ok := subprogramEntryRange(entry, image)
+			if !ok {
+				bi.logger.Warnf("reading debug_info: inlined call without address range at %#x", entry.Offset)
+				break
+			}
+
+			callfileidx, ok1 := entry.Val(dwarf.AttrCallFile).(int64)
+			callline, ok2 := entry.Val(dwarf.AttrCallLine).(int64)
+			if !ok1 || !ok2 {
+				bi.logger.Warnf("reading debug_info: inlined call without CallFile/CallLine at %#x", entry.Offset)
+				break
+			}
+			if lineInfo == nil {
+				bi.logger.Warnf("reading debug_info: inlined call on a compilation unit without debug_line section at %#x", entry.Offset)
+				break
+			}
+			callfileEntry := lineInfo.FileAt(uint64(callfileidx))
+			if callfileEntry == nil {
+				bi.logger.Warnf("reading debug_info: CallFile (%d) of inlined call does not exist in compile unit file table at %#x", callfileidx, entry.Offset)
+				break
+			}
+
+			out.inlinedCalls = append(out.inlinedCalls, cuInlinedCall{originOffset, entry.Offset, lowpc, highpc, callfileEntry.Path, int(callline)})
+		}
+		rdr.SkipChildren()
+	}
+}
+
+// subprogramEntryRange returns the first address range of entry, not relocated.
+func subprogramEntryRange(entry *dwarf.Entry, image *Image) (lowpc, highpc uint64, ok bool) {
+	if ranges, _ := image.dwarf.Ranges(entry); len(ranges) >= 1 {
+		return ranges[0][0], ranges[0][1], true
+	}
+	return 0, 0, false
+}
+
+// typeToPackage derives a package name to package path association from
+// the name of a type, used for Go versions that do not have
+// godwarf.AttrGoPackageName.
+func typeToPackage(entry *dwarf.Entry) (name, path string, ok bool) {
+	if entry.Tag != dwarf.TagTypedef && entry.Tag != dwarf.TagBaseType && entry.Tag != dwarf.TagClassType && entry.Tag != dwarf.TagStructType {
+		return "", "", false
+	}
+
+	typename, ok := entry.Val(dwarf.AttrName).(string)
+	if !ok || complexType(typename) {
+		return "", "", false
+	}
+
+	dot := strings.LastIndex(typename, ".")
+	if dot < 0 {
+		return "", "", false
+	}
+	path = typename[:dot]
+	slash := strings.LastIndex(path, "/")
+	if slash < 0 || slash+1 >= len(path) {
+		return "", "", false
+	}
+	return path[slash+1:], path, true
+}
+
+// mergeIndex merges the indexes of all compile units into index, in order,
+// resolving references to abstract functions.
+func (bi *BinaryInfo) mergeIndex(index *debugInfoIndex, cuidxs []cuIndex) {
+	abstractOriginTable := make(map[dwarf.Offset]int)
+	for i := range cuidxs {
+		cuidx := &cuidxs[i]
+		for j := range cuidx.functions {
+			if cuidx.abstract[j] {
+				abstractOriginTable[cuidx.functions[j].Offset] = len(index.Functions)
+			}
+			index.Functions = append(index.Functions, cuidx.functions[j])
+		}
+		if gopkg := index.CompileUnits[i].GoPackage; gopkg != "" {
+			index.Packages = append(index.Packages, indexPackage{Name: gopkg, Path: escapePackagePath(strings.Replace(index.CompileUnits[i].Name, "\\", "/", -1))})
+		}
+		index.Packages = append(index.Packages, cuidx.packages...)
+		index.PackageVars = append(index.PackageVars, cuidx.packageVars...)
+		index.Types = append(index.Types, cuidx.types...)
+		index.Consts = append(index.Consts, cuidx.consts...)
+		index.RuntimeTypes = append(index.RuntimeTypes, cuidx.runtimeTypes...)
+	}
+
+	for i := range cuidxs {
+		for _, ci := range cuidxs[i].concreteInlined {
+			originIdx, ok := abstractOriginTable[ci.origin]
+			if !ok {
+				bi.logger.Warnf("reading debug_info: could not find abstract origin of concrete inlined subprogram at %#x (origin offset %#x)", ci.offset, ci.origin)
+				continue
+			}
+			fn := &index.Functions[originIdx]
+			fn.Offset = ci.offset
+			fn.Entry = ci.entry
+			fn.End = ci.end
+		}
+		for _, call := range cuidxs[i].inlinedCalls {
+			originIdx, ok := abstractOriginTable[call.origin]
+			if !ok {
+				bi.logger.Warnf("reading debug_info: could not find abstract origin (%#x) of inlined call at %#x", call.origin, call.offset)
+				continue
+			}
+			fn := &index.Functions[originIdx]
+			fn.InlinedCalls = append(fn.InlinedCalls, indexInlinedCall{CU: i, LowPC: call.lowpc, HighPC: call.highpc})
+			index.InlinedCallLines = append(index.InlinedCallLines, indexInlinedCallLine{call.file, call.line, call.lowpc})
+		}
+	}
+}
+
+// applyIndex adds the contents of index to bi and image.
+func (bi *BinaryInfo) applyIndex(image *Image, index *debugInfoIndex, debugLineBytes, debugLineStrBytes []byte) {
+	cus := make([]*compileUnit, len(index.CompileUnits))
+	parallelFor(len(cus), func(i int) {
+		icu := &index.CompileUnits[i]
+		cu := &compileUnit{
+			name:      icu.Name,
+			isgo:      icu.IsGo,
+			optimized: icu.Optimized,
+			producer:  icu.Producer,
+			offset:    icu.Offset,
+			version:   icu.Version,
+			image:     image,
+		}
+		rdr := image.DwarfReader()
+		rdr.Seek(icu.Offset)
+		cu.entry, _ = rdr.Next()
+		cu.ranges = make([][2]uint64, len(icu.Ranges))
+		for j := range icu.Ranges {
+			cu.ranges[j] = [2]uint64{icu.Ranges[j][0] + image.StaticBase, icu.Ranges[j][1] + image.StaticBase}
+		}
+		if len(cu.ranges) >= 1 {
+			cu.lowPC = cu.ranges[0][0]
+		}
+		if index.lineInfos != nil {
+			cu.lineInfo = index.lineInfos[i]
+		} else {
+			cu.lineInfo = bi.parseLineInfo(image, icu, debugLineBytes, debugLineStrBytes)
+		}
+		cus[i] = cu
+	})
+	image.compileUnits = append(image.compileUnits, cus...)
+
+	if image.index == 0 && index.Regabi {
+		bi.regabi = true
+	}
+
+	for _, pkg := range index.Packages {
+		if pkg.Replace {
+			bi.PackageMap[pkg.Name] = []string{pkg.Path}
+		} else {
+			bi.PackageMap[pkg.Name] = append(bi.PackageMap[pkg.Name], pkg.Path)
+		}
+	}
+
+	knownPackageVars := make(map[string]struct{})
+	for _, v := range bi.packageVars {
+		knownPackageVars[v.name] = struct{}{}
+	}
+	for _, v := range index.PackageVars {
+		if _, known := knownPackageVars[v.Name]; !known {
+			bi.packageVars = append(bi.packageVars, packageVar{v.Name, cus[v.CU], v.Offset, v.Addr + image.StaticBase})
+		}
+	}
+
+	for _, ifn := range index.Functions {
+		fn := Function{Name: ifn.Name, offset: ifn.Offset, cu: cus[ifn.CU]}
+		if ifn.End != 0 {
+			fn.Entry = ifn.Entry + image.StaticBase
+			fn.End = ifn.End + image.StaticBase
+		}
+		for _, call := range ifn.InlinedCalls {
+			fn.InlinedCalls = append(fn.InlinedCalls, InlinedCall{cu: cus[call.CU], LowPC: call.LowPC + image.StaticBase, HighPC: call.HighPC + image.StaticBase})
+		}
+		bi.Functions = append(bi.Functions, fn)
+	}
+
+	for _, icl := range index.InlinedCallLines {
+		fl := fileLine{icl.File, icl.Line}
+		bi.inlinedCallLines[fl] = append(bi.inlinedCallLines[fl], icl.PC+image.StaticBase)
+	}
+
+	bi.pendingTypesMu.Lock()
+	bi.pendingTypes = append(bi.pendingTypes, func() {
+		for _, t := range index.Types {
+			if _, exists := bi.types[t.Name]; !exists {
+				bi.types[t.Name] = dwarfRef{image.index, t.Offset}
+			}
+		}
+		for _, c := range index.Consts {
+			ref := dwarfRef{image.index, c.Type}
+			ct := bi.consts[ref]
+			if ct == nil {
+				ct = &constantType{}
+				bi.consts[ref] = ct
+			}
+			ct.values = append(ct.values, constantValue{name: c.Name, fullName: c.Name, value: c.Value})
+		}
+		for _, rt := range index.RuntimeTypes {
+			if _, ok := image.runtimeTypeToDIE[rt.Off]; !ok {
+				image.runtimeTypeToDIE[rt.Off+image.StaticBase] = runtimeTypeDIE{rt.Offset, -1}
+			}
+		}
+	})
+	bi.pendingTypesMu.Unlock()
+}
+
+// loadPendingTypes adds the types, constants and runtime types of all
+// loaded images to bi.types, bi.consts and Image.runtimeTypeToDIE. It must
+// be called before using any of those maps.
+func (bi *BinaryInfo) loadPendingTypes() {
+	bi.pendingTypesMu.Lock()
+	defer bi.pendingTypesMu.Unlock()
+	for _, f := range bi.pendingTypes {
+		f()
+	}
+	bi.pendingTypes = nil
+}
+
+// indexCachePath returns the path of the cached index for image, or the
+// empty string if image can not be cached.
+func (bi *BinaryInfo) indexCachePath(image *Image) string {
+	if bi.indexCacheDir == "" || image.buildID == "" {
+		return ""
+	}
+	return filepath.Join(bi.indexCacheDir, image.buildID+".index")
+}
+
+// loadCachedIndex returns the cached index for image, or nil if there
+// isn't one.
+func (bi *BinaryInfo) loadCachedIndex(image *Image, debugLineBytes []byte) *debugInfoIndex {
+	path := bi.indexCachePath(image)
+	if path == "" {
+		return nil
+	}
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer fh.Close()
+	index := &debugInfoIndex{}
+	if err := gob.NewDecoder(fh).Decode(index); err != nil {
+		bi.logger.Debugf("could not read index %s: %v", path, err)
+		return nil
+	}
+	if index.Version != indexVersion || index.PtrSize != bi.Arch.PtrSize() || index.DebugLineSize != len(debugLineBytes) {
+		return nil
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now) // used to delete least recently used indexes
+	return index
+}
+
+// saveCachedIndex saves index to the cache directory, deleting the least
+// recently used indexes if there are more than indexCacheMaxEntries.
+func (bi *BinaryInfo) saveCachedIndex(image *Image, index *debugInfoIndex) {
+	path := bi.indexCachePath(image)
+	if path == "" {
+		return
+	}
+	err := func() error {
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		tmp, err := ioutil.TempFile(dir, "index.tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		err = gob.NewEncoder(tmp).Encode(index)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), path)
+	}()
+	if err != nil {
+		bi.logger.Debugf("could not save index %s: %v", path, err)
+		return
+	}
+
+	fis, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return
+	}
+	var indexes []os.FileInfo
+	for _, fi := range fis {
+		if strings.HasSuffix(fi.Name(), ".index") {
+			indexes = append(indexes, fi)
+		}
+	}
+	if len(indexes) <= indexCacheMaxEntries {
+		return
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].ModTime().After(indexes[j].ModTime()) })
+	for _, fi := range indexes[indexCacheMaxEntries:] {
+		os.Remove(filepath.Join(filepath.Dir(path), fi.Name()))
+	}
+}
+
+// machoUUID returns the contents of the LC_UUID load command of a Mach-O
+// file, formatted as an hexadecimal string.
+func machoUUID(exe *macho.File) string {
+	const _LC_UUID = 0x1b
+	for _, load := range exe.Loads {
+		raw := load.Raw()
+		if len(raw) < 24 || exe.ByteOrder.Uint32(raw) != _LC_UUID {
+			continue
+		}
+		return fmt.Sprintf("%x", raw[8:24])
+	}
+	return ""
+}
@@ -51,6 +51,19 @@ type EvalScope struct {
 	// The goroutine executing the expression evaluation shall signal that the
 	// evaluation is complete by closing the continueRequest channel.
 	callCtx *callContext
+
+	// When the following pointer is not nil function calls are evaluated by
+	// interpreting the called function, see interp.go.
+	interp *interpContext
+
+	// queryVars are the values bound to the parameters of the collection
+	// query lambdas currently being evaluated.
+	queryVars map[string]*Variable
+
+	// loadCfg is the configuration that will be used to load the result
+	// of the expression being evaluated, nil if it isn't known (e.g. for
+	// breakpoint conditions).
+	loadCfg *LoadConfig
 }
 
 // ConvertEvalScope returns a new EvalScope in the context of the
@@ -165,7 +178,7 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 		// makes sure that the other goroutine won't wait forever if we make a mistake
 		defer close(scope.callCtx.continueRequest)
 	}
-	t, err := parser.ParseExpr(expr)
+	t, err := ParseExpr(expr)
 	if eqOff, isAs := isAssignment(err); scope.callCtx != nil && isAs {
 		lexpr := expr[:eqOff]
 		rexpr := expr[eqOff+1:]
@@ -178,6 +191,7 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 		return nil, err
 	}
 
+	scope.loadCfg = &cfg
 	ev, err := scope.evalToplevelTypeCast(t, cfg)
 	if ev == nil && err == nil {
 		ev, err = scope.evalAST(t)
@@ -194,6 +208,287 @@ func (scope *EvalScope) EvalExpression(expr string, cfg LoadConfig) (*Variable,
 	return ev, nil
 }
 
+// queryMapFunc is the name map( is rewritten to by ParseExpr, since map is
+// a keyword in Go.
+const queryMapFunc = "__map"
+
+// queryElemVar is the name of the lambda parameter used by ParseExpr when
+// rewriting an expression containing s[?].
+const queryElemVar = "__elem"
+
+// maxQueryResultLen is the maximum number of elements of the result of
+// filter and map that are kept. The length of the result counts all the
+// elements, the ones that were not kept can not be indexed and are
+// displayed as if they were not loaded.
+const maxQueryResultLen = 64 * 1024
+
+// ParseExpr parses expr as a Go expression extended with the syntax used by
+// collection queries:
+//
+//	x => body		lambda with one parameter
+//	(k, v) => body		lambda with two parameters
+//	map(s, x => body)	map is a keyword in Go
+//	s[?].Field == 42	shorthand for filter(s, x => x.Field == 42)
+//
+// Lambdas are represented as function literals without parameter types
+// whose body is a single expression statement.
+//
+// C style casts, like (struct foo*)ptr or (unsigned long)x, are also
+// accepted and rewritten into Go conversions, see cCastType.
+func ParseExpr(expr string) (ast.Expr, error) {
+	rewritten, query, err := rewriteQueryExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	if !query {
+		return parser.ParseExpr(rewritten)
+	}
+
+	// Find s[?] and turn the whole expression into a filter over s.
+	fset := token.NewFileSet()
+	t, err := parser.ParseExprFrom(fset, "", rewritten, 0)
+	if err != nil {
+		return nil, err
+	}
+	var queryNode *ast.IndexExpr
+	ast.Inspect(t, func(n ast.Node) bool {
+		if idx, ok := n.(*ast.IndexExpr); ok && queryNode == nil {
+			if ident, ok := idx.Index.(*ast.Ident); ok && ident.Name == queryElemVar {
+				queryNode = idx
+				return false
+			}
+		}
+		return queryNode == nil
+	})
+	if queryNode == nil {
+		return nil, errors.New("misplaced [?]")
+	}
+	off := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	coll := rewritten[off(queryNode.X.Pos()):off(queryNode.X.End())]
+	body := rewritten[:off(queryNode.Pos())] + queryElemVar + rewritten[off(queryNode.End()):]
+	return parser.ParseExpr(fmt.Sprintf("filter(%s, func(%s) { %s })", coll, queryElemVar, body))
+}
+
+// rewriteQueryExpr rewrites the collection query syntax and C style casts
+// in expr into valid Go syntax, s[?] is rewritten to s[__elem] and query is
+// set to true.
+func rewriteQueryExpr(expr string) (rewritten string, query bool, err error) {
+	type tok struct {
+		off, end int
+		tok      token.Token
+	}
+	type edit struct {
+		start, end int
+		text       string
+	}
+
+	var toks []tok
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(expr))
+	var s scanner.Scanner
+	s.Init(file, []byte(expr), nil, 0)
+	for {
+		pos, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		if t == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		if lit == "" {
+			lit = t.String()
+		}
+		off := file.Offset(pos)
+		toks = append(toks, tok{off, off + len(lit), t})
+	}
+	toks = append(toks, tok{len(expr), len(expr), token.EOF})
+
+	var edits []edit
+	for i := 0; i < len(toks)-1; i++ {
+		switch {
+		case toks[i].tok == token.ASSIGN && toks[i+1].tok == token.GTR && toks[i+1].off == toks[i].end:
+			// lambda
+			if i == 0 {
+				return "", false, errors.New("lambda without parameters")
+			}
+			var start int
+			var params string
+			switch toks[i-1].tok {
+			case token.IDENT:
+				start = toks[i-1].off
+				params = expr[toks[i-1].off:toks[i-1].end]
+			case token.RPAREN:
+				depth := 0
+				j := i - 1
+				for ; j >= 0; j-- {
+					if toks[j].tok == token.RPAREN {
+						depth++
+					} else if toks[j].tok == token.LPAREN {
+						depth--
+						if depth == 0 {
+							break
+						}
+					}
+				}
+				if j < 0 {
+					return "", false, errors.New("unbalanced parenthesis in lambda parameters")
+				}
+				start = toks[j].off
+				params = expr[toks[j].end:toks[i-1].off]
+			default:
+				return "", false, errors.New("lambda without parameters")
+			}
+
+			// the body extends until the end of the enclosing argument list
+			depth := 0
+			k := i + 2
+		bodyLoop:
+			for ; k < len(toks); k++ {
+				switch toks[k].tok {
+				case token.LPAREN, token.LBRACK, token.LBRACE:
+					depth++
+				case token.RPAREN, token.RBRACK, token.RBRACE:
+					if depth == 0 {
+						break bodyLoop
+					}
+					depth--
+				case token.COMMA:
+					if depth == 0 {
+						break bodyLoop
+					}
+				case token.EOF:
+					break bodyLoop
+				}
+			}
+			edits = append(edits, edit{start, toks[i+1].end, "func(" + params + ") {"}, edit{toks[k].off, toks[k].off, "}"})
+
+		case toks[i].tok == token.MAP && toks[i+1].tok == token.LPAREN:
+			edits = append(edits, edit{toks[i].off, toks[i].end, queryMapFunc})
+
+		case toks[i].tok == token.LPAREN:
+			// C style cast: '(' type ')' operand, where type is a sequence of
+			// words followed by stars.
+			var words []string
+			j := i + 1
+			for ; toks[j].tok == token.IDENT || toks[j].tok == token.STRUCT || toks[j].tok == token.CONST; j++ {
+				if w := expr[toks[j].off:toks[j].end]; w != "const" && w != "volatile" {
+					words = append(words, w)
+				}
+			}
+			stars := 0
+			for ; toks[j].tok == token.MUL; j++ {
+				stars++
+			}
+			if len(words) == 0 || toks[j].tok != token.RPAREN {
+				break
+			}
+			rparen := j
+
+			// the operand is a primary expression, optionally preceded by unary
+			// operators and followed by selectors, index expressions and calls.
+			k := rparen + 1
+			for toks[k].tok == token.MUL || toks[k].tok == token.AND || toks[k].tok == token.SUB || toks[k].tok == token.ADD || toks[k].tok == token.NOT || toks[k].tok == token.XOR {
+				k++
+			}
+			skipGroup := func(k int) int {
+				depth := 0
+				for ; toks[k].tok != token.EOF; k++ {
+					switch toks[k].tok {
+					case token.LPAREN, token.LBRACK:
+						depth++
+					case token.RPAREN, token.RBRACK:
+						depth--
+						if depth == 0 {
+							return k + 1
+						}
+					}
+				}
+				return k
+			}
+			switch toks[k].tok {
+			case token.IDENT, token.INT, token.FLOAT, token.CHAR, token.STRING:
+				k++
+			case token.LPAREN:
+				k = skipGroup(k)
+			default:
+				k = -1
+			}
+			if k < 0 {
+				break
+			}
+			for {
+				if toks[k].tok == token.PERIOD && toks[k+1].tok == token.IDENT {
+					k += 2
+				} else if toks[k].tok == token.LBRACK || toks[k].tok == token.LPAREN {
+					k = skipGroup(k)
+				} else {
+					break
+				}
+			}
+
+			// Only rewrite expressions that aren't valid Go: more than one
+			// word, a type followed by stars or a single word followed directly
+			// by an operand.
+			if len(words) == 1 && stars == 0 {
+				switch toks[rparen+1].tok {
+				case token.IDENT, token.INT, token.FLOAT, token.CHAR, token.STRING:
+					// not valid Go
+				default:
+					continue
+				}
+			}
+			typ := cCastType(words, stars)
+			if typ == "" {
+				break
+			}
+			edits = append(edits, edit{toks[i].off, toks[rparen].end, "(" + typ + ")("}, edit{toks[k-1].end, toks[k-1].end, ")"})
+
+		case toks[i].tok == token.ILLEGAL && expr[toks[i].off:toks[i].end] == "?":
+			if i == 0 || toks[i-1].tok != token.LBRACK || toks[i+1].tok != token.RBRACK {
+				return "", false, errors.New("misplaced ?")
+			}
+			if query {
+				return "", false, errors.New("only one [?] is allowed in an expression")
+			}
+			query = true
+			edits = append(edits, edit{toks[i].off, toks[i].end, queryElemVar})
+		}
+	}
+
+	if len(edits) == 0 {
+		return expr, false, nil
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		expr = expr[:e.start] + e.text + expr[e.end:]
+	}
+	return expr, query, nil
+}
+
+// cCastType returns the Go syntax for the C type described by words
+// followed by stars. Struct, union and enum tags use the names used by cgo,
+// other types are quoted so that names containing spaces, like "unsigned
+// int", can be used.
+func cCastType(words []string, stars int) string {
+	var name string
+	switch words[0] {
+	case "struct", "union", "enum":
+		if len(words) != 2 {
+			return ""
+		}
+		name = "C." + words[0] + "_" + words[1]
+	case "void":
+		if len(words) != 1 || stars == 0 {
+			return ""
+		}
+		return strings.Repeat("*", stars-1) + "unsafe.Pointer"
+	default:
+		name = "C." + strings.Join(words, " ")
+	}
+	return strings.Repeat("*", stars) + strconv.Quote(name)
+}
+
 func isAssignment(err error) (int, bool) {
 	el, isScannerErr := err.(scanner.ErrorList)
 	if isScannerErr && el[0].Msg == "expected '==', found '='" {
@@ -248,6 +543,8 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 		depths = append(depths, depth)
 	}
 
+	vars, depths = scope.resolveParametricTypes(dwarfTree, vars, depths)
+
 	if len(vars) <= 0 {
 		return vars, nil
 	}
@@ -280,6 +577,89 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 	return vars, nil
 }
 
+// dictVariableName is the name of the argument that the compiler passes
+// to generic functions, it points to the dictionary of the instantiation.
+const dictVariableName = ".dict"
+
+// resolveParametricTypes replaces the type parameters of a generic
+// function with the concrete type arguments of the instantiation, read
+// from its dictionary, in the types of vars. The type parameters are also
+// replaced inside composite types (e.g. *T, []T and map[K]T) and so are
+// the shape types the compiler uses for them (e.g. in the type of escaped
+// variables). The dictionary argument itself is removed from vars.
+func (scope *EvalScope) resolveParametricTypes(dwarfTree *godwarf.Tree, vars []*Variable, depths []int) ([]*Variable, []int) {
+	var dictAddr uint64
+	for i, v := range vars {
+		if v.Name != dictVariableName {
+			continue
+		}
+		if dv := v.maybeDereference(); dv.Unreadable == nil {
+			dictAddr = uint64(dv.Addr)
+		}
+		vars = append(vars[:i], vars[i+1:]...)
+		depths = append(depths[:i], depths[i+1:]...)
+		break
+	}
+	image := scope.image()
+	params := map[dwarf.Offset]godwarf.Type{}
+	shapes := map[dwarf.Offset]godwarf.Type{}
+	for _, child := range dwarfTree.Children {
+		if child.Tag != dwarf.TagTypedef {
+			continue
+		}
+		typ, err := godwarf.ReadType(image.dwarf, image.index, child.Offset, image.typeCache)
+		if err != nil {
+			continue
+		}
+		pt, ok := typ.(*godwarf.ParametricType)
+		if !ok {
+			continue
+		}
+		ctyp, err := resolveParametricType(scope.BinInfo, scope.Mem, pt, dictAddr)
+		if err != nil {
+			// the variables are displayed using the shape type of the
+			// instantiation
+			continue
+		}
+		params[pt.Offset] = ctyp
+		// type parameters with the same shape can have different type
+		// arguments, in which case their shape is left alone
+		shapeOff := pt.TypedefType.Type.Common().Offset
+		if other, ok := shapes[shapeOff]; ok && (other == nil || other.String() != ctyp.String()) {
+			shapes[shapeOff] = nil
+		} else {
+			shapes[shapeOff] = ctyp
+		}
+	}
+
+	subst := func(t godwarf.Type) godwarf.Type {
+		if pt, ok := t.(*godwarf.ParametricType); ok {
+			if ctyp := params[pt.Offset]; ctyp != nil {
+				return ctyp
+			}
+			return pt.TypedefType.Type
+		}
+		if ctyp := shapes[t.Common().Offset]; ctyp != nil {
+			return ctyp
+		}
+		return t
+	}
+
+	for i, v := range vars {
+		typ := substituteTypes(v.DwarfType, subst)
+		if typ == v.DwarfType {
+			continue
+		}
+		nv := newVariable(v.Name, v.Addr, typ, scope.BinInfo, v.mem)
+		nv.Flags = v.Flags
+		nv.LocationExpr = v.LocationExpr
+		nv.DeclLine = v.DeclLine
+		nv.Unreadable = v.Unreadable
+		vars[i] = nv
+	}
+	return vars, depths
+}
+
 func afterLastArgAddr(vars []*Variable) uintptr {
 	for i := len(vars) - 1; i >= 0; i-- {
 		v := vars[i]
@@ -318,6 +698,10 @@ func (scope *EvalScope) setValue(dstv, srcv *Variable, srcExpr string) error {
 		return fmt.Errorf("Expression \"%s\" is unreadable: %v", srcExpr, srcv.Unreadable)
 	}
 
+	if dstv.bitFieldSize > 0 {
+		return errors.New("can not set bit fields")
+	}
+
 	// Numerical types
 	switch dstv.Kind {
 	case reflect.Float32, reflect.Float64:
@@ -375,7 +759,7 @@ func (scope *EvalScope) EvalVariable(name string, cfg LoadConfig) (*Variable, er
 
 // SetVariable sets the value of the named variable
 func (scope *EvalScope) SetVariable(name, value string) error {
-	t, err := parser.ParseExpr(name)
+	t, err := ParseExpr(name)
 	if err != nil {
 		return err
 	}
@@ -393,7 +777,7 @@ func (scope *EvalScope) SetVariable(name, value string) error {
 		return fmt.Errorf("Expression \"%s\" is unreadable: %v", name, xv.Unreadable)
 	}
 
-	t, err = parser.ParseExpr(value)
+	t, err = ParseExpr(value)
 	if err != nil {
 		return err
 	}
@@ -522,6 +906,7 @@ func (scope *EvalScope) findGlobalInternal(name string) (*Variable, error) {
 			return r, nil
 		}
 	}
+	scope.BinInfo.loadPendingTypes()
 	for dwref, ctyp := range scope.BinInfo.consts {
 		for _, cval := range ctyp.values {
 			if cval.fullName == name || strings.HasSuffix(cval.fullName, "/"+name) {
@@ -655,38 +1040,11 @@ func (scope *EvalScope) evalToplevelTypeCast(t ast.Expr, cfg LoadConfig) (*Varia
 			v.Len = int64(len(s))
 			return v, nil
 		case reflect.Slice, reflect.Array:
-			var elem godwarf.Type
-			if argv.Kind == reflect.Slice {
-				elem = argv.RealType.(*godwarf.SliceType).ElemType
-			} else {
-				elem = argv.RealType.(*godwarf.ArrayType).Type
-			}
-			switch elemType := elem.(type) {
-			case *godwarf.UintType:
-				if elemType.Name != "uint8" && elemType.Name != "byte" {
-					return nil, nil
-				}
-				bytes := make([]byte, len(argv.Children))
-				for i := range argv.Children {
-					n, _ := constant.Int64Val(argv.Children[i].Value)
-					bytes[i] = byte(n)
-				}
-				v.Value = constant.MakeString(string(bytes))
-
-			case *godwarf.IntType:
-				if elemType.Name != "int32" && elemType.Name != "rune" {
-					return nil, nil
-				}
-				runes := make([]rune, len(argv.Children))
-				for i := range argv.Children {
-					n, _ := constant.Int64Val(argv.Children[i].Value)
-					runes[i] = rune(n)
-				}
-				v.Value = constant.MakeString(string(runes))
-
-			default:
+			str := stringConversion(argv)
+			if str == nil {
 				return nil, nil
 			}
+			v.Value = str
 			v.Len = int64(len(constant.StringVal(v.Value)))
 			return v, nil
 
@@ -799,26 +1157,29 @@ func removeParen(n ast.Expr) ast.Expr {
 
 // Eval type cast expressions
 func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
-	argv, err := scope.evalAST(node.Args[0])
-	if err != nil {
-		return nil, err
-	}
-	argv.loadValue(loadSingleValue)
-	if argv.Unreadable != nil {
-		return nil, argv.Unreadable
-	}
-
 	fnnode := node.Fun
 
 	// remove all enclosing parenthesis from the type name
 	fnnode = removeParen(fnnode)
 
+	// The type is resolved before evaluating the argument so that calls to
+	// builtins taking a type as argument (like sizeof) fall through to
+	// evalBuiltinCall.
 	styp, err := scope.BinInfo.findTypeExpr(fnnode)
 	if err != nil {
 		return nil, err
 	}
 	typ := resolveTypedef(styp)
 
+	argv, err := scope.evalAST(node.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	argv.loadValue(loadSingleValue)
+	if argv.Unreadable != nil {
+		return nil, argv.Unreadable
+	}
+
 	converr := fmt.Errorf("can not convert %q to %s", exprToString(node.Args[0]), typ.String())
 
 	v := newVariable("", 0, styp, scope.BinInfo, scope.Mem)
@@ -826,21 +1187,26 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 
 	switch ttyp := typ.(type) {
 	case *godwarf.PtrType:
+		var n int64
 		switch argv.Kind {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			// ok
+			n, _ = constant.Int64Val(argv.Value)
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			// ok
+			n, _ = constant.Int64Val(argv.Value)
+		case reflect.Ptr, reflect.UnsafePointer:
+			// pointer to pointer conversions, as done by C casts
+			n = int64(argv.Children[0].Addr)
+		case reflect.Array:
+			// arrays decay to pointers in C
+			n = int64(argv.Addr)
 		default:
 			return nil, converr
 		}
 
-		n, _ := constant.Int64Val(argv.Value)
-
 		v.Children = []Variable{*(newVariable("", uintptr(n), ttyp.Type, scope.BinInfo, scope.Mem))}
 		return v, nil
 
-	case *godwarf.UintType:
+	case *godwarf.UintType, *godwarf.UcharType:
 		switch argv.Kind {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			n, _ := constant.Int64Val(argv.Value)
@@ -858,7 +1224,7 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 			v.Value = constant.MakeUint64(uint64(argv.Children[0].Addr))
 			return v, nil
 		}
-	case *godwarf.IntType:
+	case *godwarf.IntType, *godwarf.CharType, *godwarf.EnumType:
 		switch argv.Kind {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			n, _ := constant.Int64Val(argv.Value)
@@ -893,11 +1259,88 @@ func (scope *EvalScope) evalTypeCast(node *ast.CallExpr) (*Variable, error) {
 			v.Value = argv.Value
 			return v, nil
 		}
+	case *godwarf.StringType:
+		switch argv.Kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, _ := constant.Int64Val(argv.Value)
+			v.Value = constant.MakeString(string(rune(n)))
+			v.Len = int64(len(constant.StringVal(v.Value)))
+			return v, nil
+		case reflect.Slice, reflect.Array:
+			if argv.Len > maxStringConversionLen {
+				return nil, fmt.Errorf("can not convert %d elements to string, maximum is %d", argv.Len, maxStringConversionLen)
+			}
+			if int64(len(argv.Children)) < argv.Len {
+				// argv was loaded without its elements
+				argv = argv.clone()
+				argv.loaded = false
+				argv.Children = nil
+				argv.loadValue(stringConversionLoadConfig)
+				if argv.Unreadable != nil {
+					return nil, argv.Unreadable
+				}
+				if int64(len(argv.Children)) < argv.Len {
+					return nil, fmt.Errorf("can not convert %q to string, only %d of its %d elements are available", exprToString(node.Args[0]), len(argv.Children), argv.Len)
+				}
+			}
+			str := stringConversion(argv)
+			if str == nil {
+				return nil, converr
+			}
+			v.Value = str
+			v.Len = int64(len(constant.StringVal(v.Value)))
+			return v, nil
+		}
 	}
 
 	return nil, converr
 }
 
+// maxStringConversionLen is the maximum length of a slice or array that
+// can be converted to a string inside an expression.
+const maxStringConversionLen = 64 * 1024
+
+// stringConversionLoadConfig is the configuration used to load the
+// elements of a slice or array converted to a string inside an expression.
+var stringConversionLoadConfig = LoadConfig{false, 0, 0, maxStringConversionLen, 0, 0, false}
+
+// stringConversion converts argv, a slice or array of bytes or runes, to a
+// string using its loaded elements. Nothing is allocated in the target
+// process.
+// Returns nil if argv doesn't have a convertible element type.
+func stringConversion(argv *Variable) constant.Value {
+	var elem godwarf.Type
+	if argv.Kind == reflect.Slice {
+		elem = argv.RealType.(*godwarf.SliceType).ElemType
+	} else {
+		elem = argv.RealType.(*godwarf.ArrayType).Type
+	}
+	switch elemType := elem.(type) {
+	case *godwarf.UintType:
+		if elemType.Name != "uint8" && elemType.Name != "byte" {
+			return nil
+		}
+		bytes := make([]byte, len(argv.Children))
+		for i := range argv.Children {
+			n, _ := constant.Int64Val(argv.Children[i].Value)
+			bytes[i] = byte(n)
+		}
+		return constant.MakeString(string(bytes))
+
+	case *godwarf.IntType:
+		if elemType.Name != "int32" && elemType.Name != "rune" {
+			return nil
+		}
+		runes := make([]rune, len(argv.Children))
+		for i := range argv.Children {
+			n, _ := constant.Int64Val(argv.Children[i].Value)
+			runes[i] = rune(n)
+		}
+		return constant.MakeString(string(runes))
+	}
+	return nil
+}
+
 func convertInt(n uint64, signed bool, size int64) uint64 {
 	buf := make([]byte, 64/8)
 	binary.BigEndian.PutUint64(buf, n)
@@ -913,8 +1356,17 @@ func convertInt(n uint64, signed bool, size int64) uint64 {
 }
 
 func (scope *EvalScope) evalBuiltinCall(node *ast.CallExpr) (*Variable, error) {
-	fnnode, ok := node.Fun.(*ast.Ident)
-	if !ok {
+	var fnname string
+	switch fnnode := node.Fun.(type) {
+	case *ast.Ident:
+		fnname = fnnode.Name
+	case *ast.SelectorExpr:
+		pkg, ok := fnnode.X.(*ast.Ident)
+		if !ok || pkg.Name != "runtime" || fnnode.Sel.Name != "typeof" {
+			return nil, nil
+		}
+		fnname = "runtime.typeof"
+	default:
 		return nil, nil
 	}
 
@@ -932,7 +1384,7 @@ func (scope *EvalScope) evalBuiltinCall(node *ast.CallExpr) (*Variable, error) {
 		return builtin(args, node.Args)
 	}
 
-	switch fnnode.Name {
+	switch fnname {
 	case "cap":
 		return callBuiltinWithArgs(capBuiltin)
 	case "len":
@@ -943,6 +1395,24 @@ func (scope *EvalScope) evalBuiltinCall(node *ast.CallExpr) (*Variable, error) {
 		return callBuiltinWithArgs(imagBuiltin)
 	case "real":
 		return callBuiltinWithArgs(realBuiltin)
+	case "min":
+		return callBuiltinWithArgs(minBuiltin)
+	case "max":
+		return callBuiltinWithArgs(maxBuiltin)
+	case "hex":
+		return callBuiltinWithArgs(hexBuiltin)
+	case "deref":
+		return callBuiltinWithArgs(func(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+			return derefBuiltin(args, nodeargs, scope.loadCfg)
+		})
+	case "runtime.typeof":
+		return callBuiltinWithArgs(typeofBuiltin)
+	case "sizeof":
+		return scope.sizeofBuiltin(node)
+	case "offsetof":
+		return scope.offsetofBuiltin(node)
+	case "filter", queryMapFunc, "count", "any", "all":
+		return scope.evalQueryBuiltin(fnname, node)
 	}
 
 	return nil, nil
@@ -1110,6 +1580,483 @@ func realBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	return newConstant(constant.Real(arg.Value), arg.mem), nil
 }
 
+func minBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	return minmaxBuiltin("min", token.LSS, args, nodeargs)
+}
+
+func maxBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	return minmaxBuiltin("max", token.GTR, args, nodeargs)
+}
+
+// minmaxBuiltin returns the argument x such that x op y is true for all
+// other arguments y.
+func minmaxBuiltin(name string, op token.Token, args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments to %s: %d", name, len(args))
+	}
+
+	var r *Variable
+	for i, arg := range args {
+		arg.loadValue(loadSingleValue)
+		if arg.Unreadable != nil {
+			return nil, arg.Unreadable
+		}
+		if arg.Value == nil || (arg.Value.Kind() != constant.Int && arg.Value.Kind() != constant.Float && arg.Value.Kind() != constant.String) {
+			return nil, fmt.Errorf("invalid argument %s (type %s) for %s", exprToString(nodeargs[i]), arg.TypeString(), name)
+		}
+		if r == nil {
+			r = arg
+			continue
+		}
+		if (arg.Value.Kind() == constant.String) != (r.Value.Kind() == constant.String) {
+			return nil, fmt.Errorf("mismatched types %s and %s for %s", r.TypeString(), arg.TypeString(), name)
+		}
+		if constant.Compare(arg.Value, op, r.Value) {
+			r = arg
+		}
+	}
+	return r, nil
+}
+
+func hexBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to hex: %d", len(args))
+	}
+
+	arg := args[0]
+	arg.loadValue(loadSingleValue)
+
+	if arg.Unreadable != nil {
+		return nil, arg.Unreadable
+	}
+
+	var s string
+	switch {
+	case arg.Kind == reflect.Ptr || arg.Kind == reflect.UnsafePointer:
+		s = fmt.Sprintf("%#x", arg.Children[0].Addr)
+	case arg.Value != nil && arg.Value.Kind() == constant.Int:
+		if n, exact := constant.Uint64Val(arg.Value); exact {
+			s = fmt.Sprintf("%#x", n)
+		} else if n, exact := constant.Int64Val(arg.Value); exact {
+			s = fmt.Sprintf("%#x", n)
+		}
+	}
+	if s == "" {
+		return nil, fmt.Errorf("invalid argument %s (type %s) to hex", exprToString(nodeargs[0]), arg.TypeString())
+	}
+
+	return newConstant(constant.MakeString(s), arg.mem), nil
+}
+
+func typeofBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to runtime.typeof: %d", len(args))
+	}
+
+	arg := args[0]
+	typ := arg.TypeString()
+
+	if arg.Kind == reflect.Interface {
+		// report the dynamic type of interfaces
+		_, _, isnil := arg.readInterface()
+		if isnil {
+			typ = "nil"
+		} else {
+			arg.loadInterface(0, false, loadSingleValue)
+			if arg.Unreadable != nil {
+				return nil, arg.Unreadable
+			}
+			typ = arg.Children[0].TypeString()
+		}
+	}
+
+	return newConstant(constant.MakeString(typ), arg.mem), nil
+}
+
+// maxDerefLen is the maximum number of elements read by deref when the
+// configuration used to load its result is not known.
+const maxDerefLen = 1024 * 1024
+
+// derefBuiltin implements deref(p, n). The number of elements n must not be
+// greater than the maximum number of array values of cfg, since the
+// elements after those would not be loaded, nor than maxDerefLen.
+func derefBuiltin(args []*Variable, nodeargs []ast.Expr, cfg *LoadConfig) (*Variable, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments to deref: %d", len(args))
+	}
+
+	ptr, nv := args[0], args[1]
+	ptr.loadValue(loadSingleValue)
+	nv.loadValue(loadSingleValue)
+
+	if ptr.Unreadable != nil {
+		return nil, ptr.Unreadable
+	}
+	if nv.Unreadable != nil {
+		return nil, nv.Unreadable
+	}
+
+	if ptr.Kind != reflect.Ptr {
+		return nil, fmt.Errorf("invalid argument 1 %s (type %s) to deref", exprToString(nodeargs[0]), ptr.TypeString())
+	}
+	elemType := resolveTypedef(ptr.RealType.(*godwarf.PtrType).Type)
+	if _, isvoid := elemType.(*godwarf.VoidType); isvoid || elemType.Size() <= 0 {
+		return nil, fmt.Errorf("invalid argument 1 %s (type %s) to deref, pointer to a sized type required", exprToString(nodeargs[0]), ptr.TypeString())
+	}
+
+	if nv.Value == nil || nv.Value.Kind() != constant.Int {
+		return nil, fmt.Errorf("invalid argument 2 %s (type %s) to deref", exprToString(nodeargs[1]), nv.TypeString())
+	}
+	if constant.Sign(nv.Value) < 0 {
+		return nil, fmt.Errorf("invalid argument 2 %s to deref, must not be negative", exprToString(nodeargs[1]))
+	}
+	maxLen := int64(maxDerefLen)
+	if cfg != nil && cfg.MaxArrayValues > 0 && int64(cfg.MaxArrayValues) < maxLen {
+		// a configuration that doesn't load any element is only used to
+		// read the length of the result
+		maxLen = int64(cfg.MaxArrayValues)
+	}
+	n, exact := constant.Int64Val(nv.Value)
+	if !exact || n > maxLen {
+		return nil, fmt.Errorf("invalid argument 2 %s to deref, must not be greater than %d", exprToString(nodeargs[1]), maxLen)
+	}
+
+	return ptr.newVariable("", ptr.Children[0].Addr, fakeArrayType(uint64(n), ptr.RealType.(*godwarf.PtrType).Type), ptr.mem), nil
+}
+
+func (scope *EvalScope) sizeofBuiltin(node *ast.CallExpr) (*Variable, error) {
+	if len(node.Args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to sizeof: %d", len(node.Args))
+	}
+
+	typ, err := scope.typeOrExprType(node.Args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return newConstant(constant.MakeInt64(typ.Size()), scope.Mem), nil
+}
+
+func (scope *EvalScope) offsetofBuiltin(node *ast.CallExpr) (*Variable, error) {
+	if len(node.Args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to offsetof: %d", len(node.Args))
+	}
+
+	sel, ok := removeParen(node.Args[0]).(*ast.SelectorExpr)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument %s to offsetof, must be a selector", exprToString(node.Args[0]))
+	}
+
+	_, off, err := scope.fieldOffset(sel)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConstant(constant.MakeInt64(off), scope.Mem), nil
+}
+
+// typeOrExprType returns the type named by expr or, if expr is not a type
+// name, the type of the value of expr.
+func (scope *EvalScope) typeOrExprType(expr ast.Expr) (godwarf.Type, error) {
+	expr = removeParen(expr)
+	typ, err := scope.BinInfo.findTypeExpr(expr)
+	if err == nil {
+		return typ, nil
+	}
+	if err != reader.TypeNotFoundErr {
+		return nil, err
+	}
+	v, err := scope.evalAST(expr)
+	if err != nil {
+		return nil, err
+	}
+	if v.DwarfType == nil {
+		return nil, fmt.Errorf("%s (type %s) has no size", exprToString(expr), v.TypeString())
+	}
+	return v.DwarfType, nil
+}
+
+// fieldOffset returns the type of the field selected by sel and its offset
+// from the start of the struct it belongs to. The struct can be specified
+// either by a type name or by an expression. When a type name is used the
+// field can be nested (for example T.a.b) and the offset is computed from
+// the start of the outermost struct.
+func (scope *EvalScope) fieldOffset(sel *ast.SelectorExpr) (godwarf.Type, int64, error) {
+	var off int64
+	typ, err := scope.typeOrExprType(sel.X)
+	if err != nil {
+		x, isselector := removeParen(sel.X).(*ast.SelectorExpr)
+		if !isselector {
+			return nil, 0, err
+		}
+		var err2 error
+		typ, off, err2 = scope.fieldOffset(x)
+		if err2 != nil {
+			return nil, 0, err
+		}
+	}
+
+	rtyp := resolveTypedef(typ)
+	if ptyp, isptr := rtyp.(*godwarf.PtrType); isptr {
+		rtyp = resolveTypedef(ptyp.Type)
+	}
+	styp, isstruct := rtyp.(*godwarf.StructType)
+	if !isstruct {
+		return nil, 0, fmt.Errorf("%s (type %s) is not a struct", exprToString(sel.X), typ.String())
+	}
+	for _, field := range styp.Field {
+		if field.Name == sel.Sel.Name {
+			return field.Type, off + field.ByteOffset, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%s has no field %s", typ.String(), sel.Sel.Name)
+}
+
+// evalQueryBuiltin evaluates the collection queries filter, map, count, any
+// and all. The first argument must be a slice, array or map, the second a
+// lambda that is evaluated for each element of the collection without
+// calling any function in the target.
+// The lambda receives either the element or, if it has two parameters, the
+// index (or key) and the element. Filter and map return a fake slice that
+// can be further indexed, sliced and queried, for maps they return the
+// values.
+func (scope *EvalScope) evalQueryBuiltin(name string, node *ast.CallExpr) (*Variable, error) {
+	if name == queryMapFunc {
+		name = "map"
+	}
+	if len(node.Args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments to %s: %d", name, len(node.Args))
+	}
+
+	fn, ok := removeParen(node.Args[1]).(*ast.FuncLit)
+	if !ok {
+		return nil, fmt.Errorf("invalid argument %s to %s, must be a lambda like x => x.Field", exprToString(node.Args[1]), name)
+	}
+	params, body, err := lambdaParts(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	coll, err := scope.evalAST(node.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	if coll.Unreadable != nil {
+		return nil, coll.Unreadable
+	}
+	if coll.Kind == reflect.Ptr {
+		if _, isarrptr := coll.RealType.(*godwarf.PtrType).Type.(*godwarf.ArrayType); isarrptr {
+			coll = coll.maybeDereference()
+		}
+	}
+
+	var elemType godwarf.Type
+	switch coll.Kind {
+	case reflect.Slice, reflect.Array:
+		elemType = coll.fieldType
+	case reflect.Map:
+		elemType = coll.RealType.(*godwarf.MapType).ElemType
+	default:
+		return nil, fmt.Errorf("invalid argument %s (type %s) to %s, must be a slice, array or map", exprToString(node.Args[0]), coll.TypeString(), name)
+	}
+
+	elems := []Variable{}
+	n := int64(0) // number of matches, or elements of the result of map
+	result := name == "all"
+
+	err = scope.forEachElem(coll, func(key, elem *Variable) (bool, error) {
+		v, err := scope.evalLambda(params, body, key, elem)
+		if err != nil {
+			return false, err
+		}
+		if name == "map" {
+			if len(elems) == 0 && v.DwarfType != nil {
+				elemType = v.DwarfType
+			}
+			if v.Addr != 0 {
+				// v could have been partially loaded while evaluating the lambda
+				v = v.newVariable(v.Name, v.Addr, v.DwarfType, v.mem)
+			}
+			n++
+			if len(elems) < maxQueryResultLen {
+				elems = append(elems, *v)
+			}
+			return true, nil
+		}
+		v.loadValue(loadSingleValue)
+		if v.Unreadable != nil {
+			return false, v.Unreadable
+		}
+		if v.Kind != reflect.Bool || v.Value == nil {
+			return false, fmt.Errorf("lambda of %s must return a boolean, %s is %s", name, exprToString(body), v.TypeString())
+		}
+		match := constant.BoolVal(v.Value)
+		switch name {
+		case "filter":
+			if match {
+				n++
+				if len(elems) < maxQueryResultLen {
+					elems = append(elems, *elem)
+				}
+			}
+		case "count":
+			if match {
+				n++
+			}
+		case "any":
+			if match {
+				result = true
+				return false, nil
+			}
+		case "all":
+			if !match {
+				result = false
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "count":
+		return newConstant(constant.MakeInt64(n), scope.Mem), nil
+	case "any", "all":
+		return newConstant(constant.MakeBool(result), scope.Mem), nil
+	}
+
+	if elemType == nil {
+		if len(elems) == 0 {
+			return nil, fmt.Errorf("can not determine the type of %s", exprToString(body))
+		}
+		elemType, err = scope.constantType(&elems[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+	r := newVariable("", 0, fakeSliceType(elemType), scope.BinInfo, scope.Mem)
+	r.Len = n
+	r.Cap = r.Len
+	r.queryElems = elems
+	return r, nil
+}
+
+// forEachElem calls f for each element of the slice, array or map coll,
+// with its index (or key), until f returns false.
+func (scope *EvalScope) forEachElem(coll *Variable, f func(key, elem *Variable) (bool, error)) error {
+	if coll.Kind == reflect.Map {
+		it := coll.mapIterator()
+		if coll.Unreadable != nil {
+			return coll.Unreadable
+		}
+		if it == nil {
+			return nil
+		}
+		for it.next() {
+			ok, err := f(it.key(), it.value())
+			if err != nil || !ok {
+				return err
+			}
+		}
+		return coll.Unreadable
+	}
+	n := coll.Len
+	if coll.queryElems != nil {
+		n = int64(len(coll.queryElems))
+	}
+	for i := int64(0); i < n; i++ {
+		elem, err := coll.sliceAccess(int(i))
+		if err != nil {
+			return err
+		}
+		ok, err := f(newConstant(constant.MakeInt64(i), scope.Mem), elem)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// evalLambda evaluates body with params bound to a copy of elem or, if
+// there are two parameters, to key and elem.
+func (scope *EvalScope) evalLambda(params []string, body ast.Expr, key, elem *Variable) (*Variable, error) {
+	args := []*Variable{elem.clone()}
+	if len(params) == 2 {
+		args = []*Variable{key.clone(), elem.clone()}
+	}
+	if scope.queryVars == nil {
+		scope.queryVars = make(map[string]*Variable)
+	}
+	saved := make([]*Variable, len(params))
+	for i := range params {
+		saved[i] = scope.queryVars[params[i]]
+		scope.queryVars[params[i]] = args[i]
+	}
+	defer func() {
+		for i := len(params) - 1; i >= 0; i-- {
+			if saved[i] == nil {
+				delete(scope.queryVars, params[i])
+			} else {
+				scope.queryVars[params[i]] = saved[i]
+			}
+		}
+	}()
+	return scope.evalAST(body)
+}
+
+// lambdaParts returns the parameter names and the body of a lambda, which
+// ParseExpr represents as a function literal with untyped parameters and a
+// single expression statement.
+func lambdaParts(fn *ast.FuncLit) ([]string, ast.Expr, error) {
+	var params []string
+	for _, field := range fn.Type.Params.List {
+		if len(field.Names) > 0 {
+			for _, name := range field.Names {
+				params = append(params, name.Name)
+			}
+			continue
+		}
+		ident, ok := field.Type.(*ast.Ident)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid lambda parameter %s", exprToString(field.Type))
+		}
+		params = append(params, ident.Name)
+	}
+	if len(params) != 1 && len(params) != 2 {
+		return nil, nil, fmt.Errorf("lambdas must have one or two parameters")
+	}
+	if len(fn.Body.List) != 1 {
+		return nil, nil, fmt.Errorf("the body of a lambda must be a single expression")
+	}
+	stmt, ok := fn.Body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return nil, nil, fmt.Errorf("the body of a lambda must be a single expression")
+	}
+	return params, stmt.X, nil
+}
+
+// constantType returns a type for the constant v.
+func (scope *EvalScope) constantType(v *Variable) (godwarf.Type, error) {
+	var name string
+	switch v.Kind {
+	case reflect.Int:
+		name = "int"
+	case reflect.Float64:
+		name = "float64"
+	case reflect.Bool:
+		name = "bool"
+	case reflect.Complex128:
+		name = "complex128"
+	case reflect.String:
+		name = "string"
+	default:
+		return nil, fmt.Errorf("can not determine the type of %s", v.Kind)
+	}
+	return scope.BinInfo.findType(name)
+}
+
 // Evaluates identifier expressions
 func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 	switch node.Name {
@@ -1119,6 +2066,10 @@ func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 		return nilVariable, nil
 	}
 
+	if v := scope.queryVars[node.Name]; v != nil {
+		return v, nil
+	}
+
 	vars, err := scope.Locals()
 	if err != nil {
 		return nil, err
@@ -1236,7 +2187,7 @@ func (scope *EvalScope) evalIndex(node *ast.IndexExpr) (*Variable, error) {
 		fallthrough
 
 	case reflect.Slice, reflect.Array, reflect.String:
-		if xev.Base == 0 {
+		if xev.Base == 0 && xev.queryElems == nil {
 			return nil, fmt.Errorf("can not index \"%s\"", exprToString(node.X))
 		}
 		n, err := idxev.asInt()
@@ -1293,27 +2244,105 @@ func (scope *EvalScope) evalReslice(node *ast.SliceExpr) (*Variable, error) {
 		}
 	}
 
-	switch xev.Kind {
+	if xev.Kind == reflect.Map && node.High == nil {
+		high = -1
+	}
+	return xev.window(low, high, exprToString(node.X))
+}
+
+// window returns the elements of v, a slice, array, string or map, with
+// indices in [low, high). For maps a negative high selects all entries
+// starting at low, entries are counted in iteration order.
+func (v *Variable) window(low, high int64, name string) (*Variable, error) {
+	switch v.Kind {
 	case reflect.Slice, reflect.Array, reflect.String:
-		if xev.Base == 0 {
-			return nil, fmt.Errorf("can not slice \"%s\"", exprToString(node.X))
+		if v.Base == 0 && v.queryElems == nil {
+			return nil, fmt.Errorf("can not slice \"%s\"", name)
 		}
-		return xev.reslice(low, high)
+		return v.reslice(low, high)
 	case reflect.Map:
-		if node.High != nil {
-			return nil, fmt.Errorf("second slice argument must be empty for maps")
+		if low < 0 || (high >= 0 && high <= low) {
+			return nil, fmt.Errorf("map index out of bounds")
 		}
-		xev.mapSkip += int(low)
-		xev.mapIterator() // reads map length
-		if int64(xev.mapSkip) >= xev.Len {
+		skip := v.mapSkip
+		v.mapSkip += int(low)
+		v.mapIterator() // reads map length
+		if int64(v.mapSkip) >= v.Len {
 			return nil, fmt.Errorf("map index out of bounds")
 		}
-		return xev, nil
+		if high >= 0 {
+			end := skip + int(high)
+			if v.mapEnd == 0 || end < v.mapEnd {
+				v.mapEnd = end
+			}
+		}
+		return v, nil
 	default:
-		return nil, fmt.Errorf("can not slice \"%s\" (type %s)", exprToString(node.X), xev.TypeString())
+		return nil, fmt.Errorf("can not slice \"%s\" (type %s)", name, v.TypeString())
 	}
 }
 
+// EvalVariableRange evaluates expr, which must be a slice, array, string
+// or map, and loads count of its elements starting at start. It is
+// equivalent to evaluating expr[start:start+count] except that the range
+// is truncated to the length of the value, so that the elements of large
+// collections can be loaded one page at a time.
+// For maps the returned cursor is the position of the map iterator after
+// the last loaded entry, passing it back as cursor when loading the next
+// page resumes the iteration from there instead of iterating from the
+// first bucket again. The returned cursor is nil if there are no more
+// entries or expr is not a map.
+func (scope *EvalScope) EvalVariableRange(expr string, start, count int64, cursor *MapCursor, cfg LoadConfig) (*Variable, *MapCursor, error) {
+	if start < 0 || count <= 0 {
+		return nil, nil, fmt.Errorf("invalid range [%d:%d]", start, start+count)
+	}
+	t, err := ParseExpr(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	xev, err := scope.evalAST(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	if xev.Unreadable != nil {
+		return nil, nil, xev.Unreadable
+	}
+	if xev.Kind == reflect.Map {
+		xev.mapIterator() // reads map length
+		if xev.Unreadable != nil {
+			return nil, nil, xev.Unreadable
+		}
+		if xev.mapEnd > 0 {
+			xev.Len = int64(xev.mapEnd)
+		}
+		xev.Len -= int64(xev.mapSkip)
+	}
+	high := start + count
+	if high > xev.Len {
+		high = xev.Len
+	}
+	if start >= high {
+		return nil, nil, fmt.Errorf("index out of bounds")
+	}
+	// absolute index of the end of the entries of expr, in iteration order
+	end := int64(xev.mapSkip) + xev.Len
+	r, err := xev.window(start, high, expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.Kind == reflect.Map {
+		r.mapCursor = cursor
+	}
+	r.loadValue(cfg)
+	if r.Name == "" {
+		r.Name = expr
+	}
+	if r.Kind == reflect.Map && r.mapNext != nil && r.mapNext.Index < end {
+		return r, r.mapNext, nil
+	}
+	return r, nil, nil
+}
+
 // Evaluates a pointer dereference expression: *<subexpr>
 func (scope *EvalScope) evalPointerDeref(node *ast.StarExpr) (*Variable, error) {
 	xev, err := scope.evalAST(node.X)
@@ -1346,7 +2375,7 @@ func (scope *EvalScope) evalAddrOf(node *ast.UnaryExpr) (*Variable, error) {
 	if err != nil {
 		return nil, err
 	}
-	if xev.Addr == 0 || xev.DwarfType == nil {
+	if xev.Addr == 0 || xev.DwarfType == nil || xev.bitFieldSize > 0 {
 		return nil, fmt.Errorf("can not take address of \"%s\"", exprToString(node.X))
 	}
 
@@ -1844,9 +2873,16 @@ func sameType(t1, t2 godwarf.Type) bool {
 }
 
 func (v *Variable) sliceAccess(idx int) (*Variable, error) {
-	if idx < 0 || int64(idx) >= v.Len {
+	if idx < 0 || (int64(idx) >= v.Len && !isCArrayOfUnknownSize(v.RealType)) {
 		return nil, fmt.Errorf("index out of bounds")
 	}
+	if v.queryElems != nil {
+		if idx >= len(v.queryElems) {
+			return nil, fmt.Errorf("index %d not available, only the first %d elements of the result of the query are kept", idx, len(v.queryElems))
+		}
+		r := v.queryElems[idx]
+		return &r, nil
+	}
 	mem := v.mem
 	if v.Kind != reflect.Array {
 		mem = DereferenceMemory(mem)
@@ -1889,17 +2925,37 @@ func (v *Variable) mapAccess(idx *Variable) (*Variable, error) {
 }
 
 func (v *Variable) reslice(low int64, high int64) (*Variable, error) {
-	if low < 0 || low >= v.Len || high < 0 || high > v.Len {
+	if low < 0 || high < 0 {
+		return nil, fmt.Errorf("index out of bounds")
+	}
+	if (low >= v.Len || high > v.Len) && !isCArrayOfUnknownSize(v.RealType) {
 		return nil, fmt.Errorf("index out of bounds")
 	}
-
-	base := v.Base + uintptr(int64(low)*v.stride)
-	len := high - low
 
 	if high-low < 0 {
 		return nil, fmt.Errorf("index out of bounds")
 	}
 
+	if v.queryElems != nil {
+		r := v.newVariable("", 0, v.DwarfType, v.mem)
+		r.Cap = high - low
+		r.Len = high - low
+		// the elements of the result of a query that were not kept stay
+		// out of the new slice
+		kept := int64(len(v.queryElems))
+		if low > kept {
+			low = kept
+		}
+		if high > kept {
+			high = kept
+		}
+		r.queryElems = v.queryElems[low:high:high]
+		return r, nil
+	}
+
+	base := v.Base + uintptr(int64(low)*v.stride)
+	len := high - low
+
 	typ := v.DwarfType
 	if _, isarr := v.DwarfType.(*godwarf.ArrayType); isarr {
 		typ = fakeSliceType(v.fieldType)
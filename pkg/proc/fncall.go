@@ -24,7 +24,10 @@ import (
 // This file implements the function call injection introduced in go1.11.
 //
 // The protocol is described in $GOROOT/src/runtime/asm_amd64.s in the
-// comments for function runtime·debugCallV1.
+// comments for functions runtime·debugCallV1 and runtime·debugCallV2, the
+// latter is used by programs compiled with the register based calling
+// convention (ABIInternal), which passes arguments and return values in
+// registers, see regabi.go.
 //
 // The main entry point is EvalExpressionWithCalls which will start a goroutine to
 // evaluate the provided expression.
@@ -42,7 +45,7 @@ import (
 const (
 	debugCallFunctionNamePrefix1 = "debugCall"
 	debugCallFunctionNamePrefix2 = "runtime.debugCall"
-	debugCallFunctionName        = "runtime.debugCallV1"
+	maxDebugCallVersion          = 2
 	maxArgFrameSize              = 65535
 )
 
@@ -65,6 +68,11 @@ var (
 type functionCallState struct {
 	// savedRegs contains the saved registers
 	savedRegs Registers
+	// debugCallName is the name of the debugCall function used
+	debugCallName string
+	// protocolReg is the register used by the runtime to communicate with
+	// the debugger during the call injection protocol.
+	protocolReg x86asm.Reg
 	// err contains a saved error
 	err error
 	// expr is the expression being evaluated
@@ -138,9 +146,14 @@ func (callCtx *callContext) doReturn(ret *Variable, err error) {
 // EvalExpressionWithCalls is like EvalExpression but allows function calls in 'expr'.
 // Because this can only be done in the current goroutine, unlike
 // EvalExpression, EvalExpressionWithCalls is not a method of EvalScope.
+// On targets that can not execute code, such as core files, the called
+// functions are interpreted instead, see interp.go.
 func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig, checkEscape bool) error {
 	bi := t.BinInfo()
 	if !t.SupportsFunctionCalls() {
+		if t.interpretsFunctionCalls() {
+			return evalExpressionWithInterpretedCalls(t, g, expr, retLoadCfg)
+		}
 		return errFuncCallUnsupportedBackend
 	}
 
@@ -156,8 +169,7 @@ func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig
 		return errFuncCallInProgress
 	}
 
-	dbgcallfn := bi.LookupFunc[debugCallFunctionName]
-	if dbgcallfn == nil {
+	if dbgcallfn, _ := debugCallFunction(bi); dbgcallfn == nil {
 		return errFuncCallUnsupported
 	}
 
@@ -192,7 +204,37 @@ func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig
 	return finishEvalExpressionWithCalls(t, g, contReq, ok)
 }
 
+// evalExpressionWithInterpretedCalls is like EvalExpressionWithCalls but
+// interprets the called functions instead of executing them.
+func evalExpressionWithInterpretedCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig) error {
+	if g == nil {
+		return errNoGoroutine
+	}
+	if g.Thread == nil {
+		return errGoroutineNotRunning
+	}
+	scope, err := GoroutineScope(g.Thread)
+	if err != nil {
+		return err
+	}
+	scope.interp, err = newInterpContext(scope.BinInfo, scope.Mem, g, retLoadCfg)
+	if err != nil {
+		return err
+	}
+	ret, err := scope.EvalExpression(expr, retLoadCfg)
+	return stashCallReturnValues(g, continueRequest{ret: ret, err: err}, true)
+}
+
 func finishEvalExpressionWithCalls(t *Target, g *G, contReq continueRequest, ok bool) error {
+	err := stashCallReturnValues(g, contReq, ok)
+	close(t.fncallForG[g.ID].continueCompleted)
+	delete(t.fncallForG, g.ID)
+	return err
+}
+
+// stashCallReturnValues saves the result of a function call expression
+// as the return values of the thread of g.
+func stashCallReturnValues(g *G, contReq continueRequest, ok bool) error {
 	fncallLog("stashing return values for %d in thread=%d\n", g.ID, g.Thread.ThreadID())
 	var err error
 	if !ok {
@@ -215,9 +257,6 @@ func finishEvalExpressionWithCalls(t *Target, g *G, contReq continueRequest, ok
 	} else {
 		g.Thread.Common().returnValues = []*Variable{contReq.ret}
 	}
-
-	close(t.fncallForG[g.ID].continueCompleted)
-	delete(t.fncallForG, g.ID)
 	return err
 }
 
@@ -228,8 +267,8 @@ func finishEvalExpressionWithCalls(t *Target, g *G, contReq continueRequest, ok
 // See the comment describing the field EvalScope.callCtx for a description
 // of the preconditions that make starting the function call protocol
 // possible.
-// See runtime.debugCallV1 in $GOROOT/src/runtime/asm_amd64.s for a
-// description of the protocol.
+// See runtime.debugCallV1 and runtime.debugCallV2 in
+// $GOROOT/src/runtime/asm_amd64.s for a description of the protocol.
 func evalFunctionCall(scope *EvalScope, node *ast.CallExpr) (*Variable, error) {
 	r, err := scope.evalBuiltinCall(node)
 	if r != nil || err != nil {
@@ -237,6 +276,9 @@ func evalFunctionCall(scope *EvalScope, node *ast.CallExpr) (*Variable, error) {
 		return r, err
 	}
 	if scope.callCtx == nil {
+		if scope.interp != nil {
+			return scope.interpretFunctionCall(node)
+		}
 		return nil, errFuncCallNotAllowed
 	}
 
@@ -246,7 +288,7 @@ func evalFunctionCall(scope *EvalScope, node *ast.CallExpr) (*Variable, error) {
 		return nil, errFuncCallUnsupportedBackend
 	}
 
-	dbgcallfn := bi.LookupFunc[debugCallFunctionName]
+	dbgcallfn, dbgcallversion := debugCallFunction(bi)
 	if dbgcallfn == nil {
 		return nil, errFuncCallUnsupported
 	}
@@ -269,8 +311,13 @@ func evalFunctionCall(scope *EvalScope, node *ast.CallExpr) (*Variable, error) {
 	}
 
 	fncall := functionCallState{
-		expr:      node,
-		savedRegs: regs,
+		expr:          node,
+		savedRegs:     regs,
+		debugCallName: dbgcallfn.Name,
+		protocolReg:   x86asm.RAX,
+	}
+	if dbgcallversion >= 2 {
+		fncall.protocolReg = x86asm.R12
 	}
 
 	err = funcCallEvalFuncExpr(scope, &fncall, false)
@@ -319,23 +366,41 @@ func evalFunctionCall(scope *EvalScope, node *ast.CallExpr) (*Variable, error) {
 	if fncall.panicvar != nil {
 		return nil, fncallPanicErr{fncall.panicvar}
 	}
-	switch len(fncall.retvars) {
+	return funcCallReturnValue(scope.BinInfo, fncall.retvars), nil
+}
+
+// debugCallFunction searches for the debugCall function of the runtime,
+// returning it along with its version, the most recent version available
+// is used.
+func debugCallFunction(bi *BinaryInfo) (*Function, int) {
+	for version := maxDebugCallVersion; version >= 1; version-- {
+		if fn := bi.LookupFunc[fmt.Sprintf("%sV%d", debugCallFunctionNamePrefix2, version)]; fn != nil {
+			return fn, version
+		}
+	}
+	return nil, 0
+}
+
+// funcCallReturnValue returns the value of a function call expression
+// given the return values of the function.
+func funcCallReturnValue(bi *BinaryInfo, retvars []*Variable) *Variable {
+	switch len(retvars) {
 	case 0:
-		r := newVariable("", 0, nil, scope.BinInfo, nil)
+		r := newVariable("", 0, nil, bi, nil)
 		r.loaded = true
 		r.Unreadable = errors.New("no return values")
-		return r, nil
+		return r
 	case 1:
-		return fncall.retvars[0], nil
+		return retvars[0]
 	default:
 		// create a fake variable without address or type to return multiple values
-		r := newVariable("", 0, nil, scope.BinInfo, nil)
+		r := newVariable("", 0, nil, bi, nil)
 		r.loaded = true
-		r.Children = make([]Variable, len(fncall.retvars))
-		for i := range fncall.retvars {
-			r.Children[i] = *fncall.retvars[i]
+		r.Children = make([]Variable, len(retvars))
+		for i := range retvars {
+			r.Children[i] = *retvars[i]
 		}
-		return r, nil
+		return r
 	}
 }
 
@@ -412,7 +477,7 @@ func funcCallEvalFuncExpr(scope *EvalScope, fncall *functionCallState, allowCall
 	if fnvar.Kind != reflect.Func {
 		return fmt.Errorf("expression %q is not a function", exprToString(fncall.expr.Fun))
 	}
-	fnvar.loadValue(LoadConfig{false, 0, 0, 0, 0, 0})
+	fnvar.loadValue(LoadConfig{false, 0, 0, 0, 0, 0, false})
 	if fnvar.Unreadable != nil {
 		return fnvar.Unreadable
 	}
@@ -461,34 +526,42 @@ type funcCallArg struct {
 	typ   godwarf.Type
 	off   int64
 	isret bool
+	// pieces, if not nil, describes the registers the argument is passed
+	// in, with the register based calling convention. When pieces is set
+	// off is meaningless.
+	pieces []op.Piece
 }
 
 // funcCallEvalArgs evaluates the arguments of the function call, copying
-// the into the argument frame starting at argFrameAddr.
-func funcCallEvalArgs(scope *EvalScope, fncall *functionCallState, argFrameAddr uint64) error {
+// them into the argument frame starting at argFrameAddr, or into the
+// registers described by regs.
+func funcCallEvalArgs(scope *EvalScope, fncall *functionCallState, argFrameAddr uint64, regs op.DwarfRegisters) error {
 	if scope.g == nil {
 		// this should never happen
 		return errNoGoroutine
 	}
 
+	formalArgs := fncall.formalArgs
+	actualArgs := make([]*Variable, 0, len(formalArgs))
 	if fncall.receiver != nil {
-		err := funcCallCopyOneArg(scope, fncall, fncall.receiver, &fncall.formalArgs[0], argFrameAddr)
-		if err != nil {
-			return err
-		}
-		fncall.formalArgs = fncall.formalArgs[1:]
+		actualArgs = append(actualArgs, fncall.receiver)
 	}
 
-	for i := range fncall.formalArgs {
-		formalArg := &fncall.formalArgs[i]
+	// evaluate all the arguments before copying any of them, evaluating an
+	// argument could change the values of the registers.
+	for i := range fncall.expr.Args {
+		formalArg := &formalArgs[len(actualArgs)]
 
 		actualArg, err := scope.evalAST(fncall.expr.Args[i])
 		if err != nil {
 			return fmt.Errorf("error evaluating %q as argument %s in function %s: %v", exprToString(fncall.expr.Args[i]), formalArg.name, fncall.fn.Name, err)
 		}
 		actualArg.Name = exprToString(fncall.expr.Args[i])
+		actualArgs = append(actualArgs, actualArg)
+	}
 
-		err = funcCallCopyOneArg(scope, fncall, actualArg, formalArg, argFrameAddr)
+	for i := range formalArgs {
+		err := funcCallCopyOneArg(scope, fncall, actualArgs[i], &formalArgs[i], argFrameAddr, regs)
 		if err != nil {
 			return err
 		}
@@ -497,7 +570,7 @@ func funcCallEvalArgs(scope *EvalScope, fncall *functionCallState, argFrameAddr
 	return nil
 }
 
-func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *Variable, formalArg *funcCallArg, argFrameAddr uint64) error {
+func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *Variable, formalArg *funcCallArg, argFrameAddr uint64, regs op.DwarfRegisters) error {
 	if scope.callCtx.checkEscape {
 		//TODO(aarzilli): only apply the escapeCheck to leaking parameters.
 		if err := escapeCheck(actualArg, formalArg.name, scope.g); err != nil {
@@ -508,7 +581,10 @@ func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *
 	//TODO(aarzilli): autmoatic wrapping in interfaces for cases not handled
 	// by convertToEface.
 
-	formalArgVar := newVariable(formalArg.name, uintptr(formalArg.off+int64(argFrameAddr)), formalArg.typ, scope.BinInfo, scope.Mem)
+	formalArgVar, err := funcCallArgVariable(scope.BinInfo, scope.Mem, formalArg, argFrameAddr, regs)
+	if err != nil {
+		return err
+	}
 	if err := scope.setValue(formalArgVar, actualArg, actualArg.Name); err != nil {
 		return err
 	}
@@ -516,7 +592,27 @@ func funcCallCopyOneArg(scope *EvalScope, fncall *functionCallState, actualArg *
 	return nil
 }
 
+// funcCallArgVariable returns a variable for the formal argument (or return
+// value) arg of a function whose argument frame starts at argFrameAddr,
+// arguments passed in registers are read from, and written to, regs.
+func funcCallArgVariable(bi *BinaryInfo, mem MemoryReadWriter, arg *funcCallArg, argFrameAddr uint64, regs op.DwarfRegisters) (*Variable, error) {
+	if arg.pieces == nil {
+		return newVariable(arg.name, uintptr(arg.off+int64(argFrameAddr)), arg.typ, bi, mem), nil
+	}
+	cmem, err := newCompositeMemory(mem, regs, arg.pieces)
+	if err != nil {
+		return nil, err
+	}
+	v := newVariable(arg.name, fakeAddress, arg.typ, bi, cmem)
+	v.Flags |= VariableFakeAddress
+	return v, nil
+}
+
 func funcCallArgs(fn *Function, bi *BinaryInfo, includeRet bool) (argFrameSize int64, formalArgs []funcCallArg, err error) {
+	if bi.regabi {
+		return funcCallArgsRegABI(fn, bi, includeRet)
+	}
+
 	const CFA = 0x1000
 
 	dwarfTree, err := fn.cu.image.getDwarfTree(fn.offset)
@@ -585,6 +681,86 @@ func funcCallArgs(fn *Function, bi *BinaryInfo, includeRet bool) (argFrameSize i
 	return argFrameSize, formalArgs, nil
 }
 
+// funcCallArgsRegABI is like funcCallArgs for functions using the register
+// based calling convention.
+// Arguments and return values are assigned to registers or to the
+// argument frame by following the ABI specification. The location of the
+// arguments described by the location lists of the debug info at the entry
+// point of the function is preferred, when available.
+func funcCallArgsRegABI(fn *Function, bi *BinaryInfo, includeRet bool) (argFrameSize int64, formalArgs []funcCallArg, err error) {
+	const CFA = 0x1000
+
+	dwarfTree, err := fn.cu.image.getDwarfTree(fn.offset)
+	if err != nil {
+		return 0, nil, fmt.Errorf("DWARF read error: %v", err)
+	}
+
+	varEntries := reader.Variables(dwarfTree, fn.Entry, int(^uint(0)>>1), false, true)
+
+	var args, rets []funcCallArg
+	var located []bool // located[i] is true if the location of args[i] was found in the debug info
+	for _, entry := range varEntries {
+		if entry.Tag != dwarf.TagFormalParameter {
+			continue
+		}
+		argname, typ, err := readVarEntry(entry.Tree, fn.cu.image)
+		if err != nil {
+			return 0, nil, err
+		}
+		typ = resolveTypedef(typ)
+		isret, _ := entry.Val(dwarf.AttrVarParam).(bool)
+
+		arg := funcCallArg{name: argname, typ: typ, isret: isret}
+		if !isret {
+			ok := false
+			if locprog, _, err := bi.locationExpr(entry, dwarf.AttrLocation, fn.Entry); err == nil {
+				off, pieces, err := op.ExecuteStackProgram(op.DwarfRegisters{CFA: CFA, FrameBase: CFA}, locprog, bi.Arch.PtrSize())
+				switch {
+				case err != nil:
+				case pieces == nil:
+					arg.off, ok = off-CFA, true
+				case !piecesUseMemory(pieces):
+					arg.pieces, ok = pieces, true
+				}
+			}
+			args = append(args, arg)
+			located = append(located, ok)
+		} else {
+			rets = append(rets, arg)
+		}
+	}
+
+	// Arguments and return values appear in the debug info in declaration
+	// order, which is also the order in which they are assigned.
+	a := newRegabiAssigner(bi.Arch)
+	for i := range args {
+		pieces, off := a.assign(args[i].typ)
+		if !located[i] {
+			args[i].pieces, args[i].off = pieces, off
+		}
+	}
+	a.startResults()
+	for i := range rets {
+		rets[i].pieces, rets[i].off = a.assign(rets[i].typ)
+	}
+
+	formalArgs = args
+	if includeRet {
+		formalArgs = append(formalArgs, rets...)
+	}
+	return a.frameSize(), formalArgs, nil
+}
+
+// piecesUseMemory returns true if any of the pieces is stored in memory.
+func piecesUseMemory(pieces []op.Piece) bool {
+	for _, piece := range pieces {
+		if !piece.IsRegister && !piece.IsEmpty {
+			return true
+		}
+	}
+	return false
+}
+
 // alignAddr rounds up addr to a multiple of align. Align must be a power of 2.
 func alignAddr(addr, align int64) int64 {
 	return (addr + int64(align-1)) &^ int64(align-1)
@@ -664,7 +840,7 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState) bool {
 		return true
 	}
 
-	rax, _ := regs.Get(int(x86asm.RAX))
+	rax, _ := regs.Get(int(fncall.protocolReg))
 
 	if logflags.FnCall() {
 		loc, _ := thread.Location()
@@ -676,7 +852,7 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState) bool {
 				fnname = loc.Fn.Name
 			}
 		}
-		fncallLog("function call interrupt gid=%d thread=%d rax=%#x (PC=%#x in %s)", callScope.g.ID, thread.ThreadID(), rax, pc, fnname)
+		fncallLog("function call interrupt gid=%d thread=%d %s=%#x (PC=%#x in %s)", callScope.g.ID, thread.ThreadID(), fncall.protocolReg, rax, pc, fnname)
 	}
 
 	switch rax {
@@ -721,7 +897,10 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState) bool {
 		}
 		callOP(bi, thread, regs, fncall.fn.Entry)
 
-		err := funcCallEvalArgs(callScope, fncall, regs.SP())
+		dregs := bi.Arch.RegistersToDwarfRegisters(0, regs)
+		dregs.ChangeFunc = thread.SetReg
+
+		err := funcCallEvalArgs(callScope, fncall, regs.SP(), dregs)
 		if err != nil {
 			// rolling back the call, note: this works because we called regs.Copy() above
 			thread.SetSP(regs.SP())
@@ -744,8 +923,8 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState) bool {
 		if err := thread.SetSP(sp); err != nil {
 			fncall.err = fmt.Errorf("could not restore SP: %v", err)
 		}
-		if err := stepInstructionOut(p, thread, debugCallFunctionName, debugCallFunctionName); err != nil {
-			fncall.err = fmt.Errorf("could not step out of %s: %v", debugCallFunctionName, err)
+		if err := stepInstructionOut(p, thread, fncall.debugCallName, fncall.debugCallName); err != nil {
+			fncall.err = fmt.Errorf("could not step out of %s: %v", fncall.debugCallName, err)
 		}
 		return true
 
@@ -754,6 +933,17 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState) bool {
 		if fncall.panicvar != nil || fncall.lateCallFailure {
 			break
 		}
+		if bi.regabi {
+			// return values are in registers and in the argument frame,
+			// which starts at the current stack pointer.
+			fncall.retvars, err = regabiReturnValues(fncall.fn, bi, thread, bi.Arch.RegistersToDwarfRegisters(0, regs), regs.SP())
+			if err != nil {
+				fncall.err = fmt.Errorf("could not get return values: %v", err)
+				break
+			}
+			loadValues(fncall.retvars, callScope.callCtx.retLoadCfg)
+			break
+		}
 		retScope, err := ThreadScope(thread)
 		if err != nil {
 			fncall.err = fmt.Errorf("could not get return values: %v", err)
@@ -795,6 +985,30 @@ func funcCallStep(callScope *EvalScope, fncall *functionCallState) bool {
 	return false
 }
 
+// regabiReturnValues returns the return values of fn, a function using the
+// register based calling convention that just returned, reading them from
+// regs and from the argument frame at argFrameAddr.
+// The values of the variables are not loaded.
+func regabiReturnValues(fn *Function, bi *BinaryInfo, mem MemoryReadWriter, regs op.DwarfRegisters, argFrameAddr uint64) ([]*Variable, error) {
+	_, formalArgs, err := funcCallArgs(fn, bi, true)
+	if err != nil {
+		return nil, err
+	}
+	var retvars []*Variable
+	for i := range formalArgs {
+		if !formalArgs[i].isret {
+			continue
+		}
+		v, err := funcCallArgVariable(bi, mem, &formalArgs[i], argFrameAddr, regs)
+		if err != nil {
+			return nil, err
+		}
+		v.Flags |= VariableReturnArgument | VariableFakeAddress
+		retvars = append(retvars, v)
+	}
+	return retvars, nil
+}
+
 func readTopstackVariable(thread Thread, regs Registers, typename string, loadCfg LoadConfig) (*Variable, error) {
 	bi := thread.BinInfo()
 	scope, err := ThreadScope(thread)
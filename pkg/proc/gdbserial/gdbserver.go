@@ -77,6 +77,7 @@ import (
 
 	"golang.org/x/arch/x86/x86asm"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/linutil"
@@ -203,7 +204,7 @@ func newProcess(process *os.Process) *gdbProcess {
 }
 
 // Listen waits for a connection from the stub.
-func (p *gdbProcess) Listen(listener net.Listener, path string, pid int, debugInfoDirs []string, stopReason proc.StopReason) (*proc.Target, error) {
+func (p *gdbProcess) Listen(listener net.Listener, path string, pid int, debugInfo proc.DebugInfoConfig, stopReason proc.StopReason) (*proc.Target, error) {
 	acceptChan := make(chan net.Conn)
 
 	go func() {
@@ -217,7 +218,7 @@ func (p *gdbProcess) Listen(listener net.Listener, path string, pid int, debugIn
 		if conn == nil {
 			return nil, errors.New("could not connect")
 		}
-		return p.Connect(conn, path, pid, debugInfoDirs, stopReason)
+		return p.Connect(conn, path, pid, debugInfo, stopReason)
 	case status := <-p.waitChan:
 		listener.Close()
 		return nil, fmt.Errorf("stub exited while waiting for connection: %v", status)
@@ -225,11 +226,11 @@ func (p *gdbProcess) Listen(listener net.Listener, path string, pid int, debugIn
 }
 
 // Dial attempts to connect to the stub.
-func (p *gdbProcess) Dial(addr string, path string, pid int, debugInfoDirs []string, stopReason proc.StopReason) (*proc.Target, error) {
+func (p *gdbProcess) Dial(addr string, path string, pid int, debugInfo proc.DebugInfoConfig, stopReason proc.StopReason) (*proc.Target, error) {
 	for {
 		conn, err := net.Dial("tcp", addr)
 		if err == nil {
-			return p.Connect(conn, path, pid, debugInfoDirs, stopReason)
+			return p.Connect(conn, path, pid, debugInfo, stopReason)
 		}
 		select {
 		case status := <-p.waitChan:
@@ -246,7 +247,7 @@ func (p *gdbProcess) Dial(addr string, path string, pid int, debugInfoDirs []str
 // program and the PID of the target process, both are optional, however
 // some stubs do not provide ways to determine path and pid automatically
 // and Connect will be unable to function without knowing them.
-func (p *gdbProcess) Connect(conn net.Conn, path string, pid int, debugInfoDirs []string, stopReason proc.StopReason) (*proc.Target, error) {
+func (p *gdbProcess) Connect(conn net.Conn, path string, pid int, debugInfo proc.DebugInfoConfig, stopReason proc.StopReason) (*proc.Target, error) {
 	p.conn.conn = conn
 	p.conn.pid = pid
 	err := p.conn.handshake()
@@ -267,7 +268,7 @@ func (p *gdbProcess) Connect(conn net.Conn, path string, pid int, debugInfoDirs
 		}
 	}
 
-	tgt, err := p.initialize(path, debugInfoDirs, stopReason)
+	tgt, err := p.initialize(path, debugInfo, stopReason)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +343,7 @@ func getLdEnvVars() []string {
 // LLDBLaunch starts an instance of lldb-server and connects to it, asking
 // it to launch the specified target program with the specified arguments
 // (cmd) on the specified directory wd.
-func LLDBLaunch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tty string) (*proc.Target, error) {
+func LLDBLaunch(cmd []string, wd string, foreground bool, debugInfo proc.DebugInfoConfig, tty string) (*proc.Target, error) {
 	if runtime.GOOS == "windows" {
 		return nil, ErrUnsupportedOS
 	}
@@ -425,9 +426,9 @@ func LLDBLaunch(cmd []string, wd string, foreground bool, debugInfoDirs []string
 
 	var tgt *proc.Target
 	if listener != nil {
-		tgt, err = p.Listen(listener, cmd[0], 0, debugInfoDirs, proc.StopLaunched)
+		tgt, err = p.Listen(listener, cmd[0], 0, debugInfo, proc.StopLaunched)
 	} else {
-		tgt, err = p.Dial(port, cmd[0], 0, debugInfoDirs, proc.StopLaunched)
+		tgt, err = p.Dial(port, cmd[0], 0, debugInfo, proc.StopLaunched)
 	}
 	return tgt, err
 }
@@ -437,7 +438,7 @@ func LLDBLaunch(cmd []string, wd string, foreground bool, debugInfoDirs []string
 // Path is path to the target's executable, path only needs to be specified
 // for some stubs that do not provide an automated way of determining it
 // (for example debugserver).
-func LLDBAttach(pid int, path string, debugInfoDirs []string) (*proc.Target, error) {
+func LLDBAttach(pid int, path string, debugInfo proc.DebugInfoConfig) (*proc.Target, error) {
 	if runtime.GOOS == "windows" {
 		return nil, ErrUnsupportedOS
 	}
@@ -477,9 +478,9 @@ func LLDBAttach(pid int, path string, debugInfoDirs []string) (*proc.Target, err
 
 	var tgt *proc.Target
 	if listener != nil {
-		tgt, err = p.Listen(listener, path, pid, debugInfoDirs, proc.StopAttached)
+		tgt, err = p.Listen(listener, path, pid, debugInfo, proc.StopAttached)
 	} else {
-		tgt, err = p.Dial(port, path, pid, debugInfoDirs, proc.StopAttached)
+		tgt, err = p.Dial(port, path, pid, debugInfo, proc.StopAttached)
 	}
 	return tgt, err
 }
@@ -497,10 +498,22 @@ func (p *gdbProcess) EntryPoint() (uint64, error) {
 	return entryPoint, nil
 }
 
+// DynamicLoaderBreakpoint returns the address of the function called by the
+// dynamic loader every time it changes the list of loaded libraries.
+func (p *gdbProcess) DynamicLoaderBreakpoint() (uint64, error) {
+	if p.BinInfo().GOOS != "linux" {
+		return 0, nil
+	}
+	// If the auxiliary vector can't be read the function can still be found
+	// once the dynamic loader has initialized r_debug.
+	auxv, _ := p.conn.readAuxv()
+	return linutil.ElfDynamicLoaderBreakpoint(p, auxv)
+}
+
 // initialize uses qProcessInfo to load the inferior's PID and
 // executable path. This command is not supported by all stubs and not all
 // stubs will report both the PID and executable path.
-func (p *gdbProcess) initialize(path string, debugInfoDirs []string, stopReason proc.StopReason) (*proc.Target, error) {
+func (p *gdbProcess) initialize(path string, debugInfo proc.DebugInfoConfig, stopReason proc.StopReason) (*proc.Target, error) {
 	var err error
 	if path == "" {
 		// If we are attaching to a running process and the user didn't specify
@@ -553,7 +566,7 @@ func (p *gdbProcess) initialize(path string, debugInfoDirs []string, stopReason
 	}
 	tgt, err := proc.NewTarget(p, proc.NewTargetConfig{
 		Path:                path,
-		DebugInfoDirs:       debugInfoDirs,
+		DebugInfo:           debugInfo,
 		DisableAsyncPreempt: runtime.GOOS == "darwin",
 		StopReason:          stopReason})
 	if err != nil {
@@ -1868,6 +1881,53 @@ func (t *gdbThread) SetDX(dx uint64) error {
 	return t.p.conn.writeRegister(t.strID, reg.regnum, reg.value)
 }
 
+// SetReg will change the value of the register regNum (a DWARF register
+// number) to reg.
+func (t *gdbThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
+	regName, ok := gdbAMD64DwarfRegisterName(regNum)
+	if !ok {
+		return fmt.Errorf("changing register %d not implemented", regNum)
+	}
+	gdbreg, ok := t.regs.regs[regName]
+	value := gdbreg.value
+	if !ok && strings.HasPrefix(regName, "xmm") {
+		// the stub could report the YMM registers instead, the XMM registers
+		// are their lower halves.
+		gdbreg, ok = t.regs.regs["y"+regName[1:]]
+		if ok {
+			value = gdbreg.value[:16]
+		}
+	}
+	if !ok {
+		return fmt.Errorf("could not set register %s: not found", regName)
+	}
+	reg.FillBytes()
+	if len(reg.Bytes) > len(value) {
+		return fmt.Errorf("could not set register %s: wrong size, expected %d got %d", regName, len(value), len(reg.Bytes))
+	}
+	for i := range value {
+		value[i] = 0
+	}
+	copy(value, reg.Bytes)
+	if t.p.gcmdok {
+		return t.p.conn.writeRegisters(t.strID, t.regs.buf)
+	}
+	return t.p.conn.writeRegister(t.strID, gdbreg.regnum, gdbreg.value)
+}
+
+// gdbAMD64DwarfRegisterName returns the name used by the stub for the
+// AMD64 register with DWARF register number regNum.
+func gdbAMD64DwarfRegisterName(regNum uint64) (string, bool) {
+	names := []string{"rax", "rdx", "rcx", "rbx", "rsi", "rdi", "rbp", "rsp", "r8", "r9", "r10", "r11", "r12", "r13", "r14", "r15", "rip"}
+	switch {
+	case regNum < uint64(len(names)):
+		return names[regNum], true
+	case regNum >= 17 && regNum <= 32:
+		return fmt.Sprintf("xmm%d", regNum-17), true
+	}
+	return "", false
+}
+
 func (regs *gdbRegisters) Slice(floatingPoint bool) ([]proc.Register, error) {
 	r := make([]proc.Register, 0, len(regs.regsInfo))
 	for _, reginfo := range regs.regsInfo {
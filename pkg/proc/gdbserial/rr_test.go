@@ -30,7 +30,7 @@ func withTestRecording(name string, t testing.TB, fn func(t *proc.Target, fixtur
 		t.Skip("test skipped, rr not found")
 	}
 	t.Log("recording")
-	p, tracedir, err := gdbserial.RecordAndReplay([]string{fixture.Path}, ".", true, []string{})
+	p, tracedir, err := gdbserial.RecordAndReplay([]string{fixture.Path}, ".", true, proc.DebugInfoConfig{})
 	if err != nil {
 		t.Fatal("Launch():", err)
 	}
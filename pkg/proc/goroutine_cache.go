@@ -21,6 +21,16 @@ func (gcache *goroutineCache) init(bi *BinaryInfo) {
 		// try old name (pre Go 1.6)
 		gcache.allgentryAddr, _ = rdr.AddrFor("runtime.allg", exeimage.StaticBase, bi.Arch.PtrSize())
 	}
+
+	if exeimage.symbols != nil {
+		// no debug_info, use the symbol table
+		if addr, ok := exeimage.symbols["runtime.allglen"]; ok {
+			gcache.allglenAddr = addr + exeimage.StaticBase
+		}
+		if addr, ok := exeimage.symbols["runtime.allgs"]; ok {
+			gcache.allgentryAddr = addr + exeimage.StaticBase
+		}
+	}
 }
 
 func (gcache *goroutineCache) getRuntimeAllg(bi *BinaryInfo, mem MemoryReadWriter) (uint64, uint64, error) {
@@ -34,6 +34,37 @@ type ProcessInternal interface {
 	EraseBreakpoint(*Breakpoint) error
 }
 
+// SyscallCatcher is implemented by backends that can stop the target
+// process when one of its threads enters or exits a system call.
+type SyscallCatcher interface {
+	// SetSyscallCatchpoint selects the system calls that will stop the
+	// target process, if cp is nil no system call will stop it.
+	SetSyscallCatchpoint(cp *SyscallCatchpoint) error
+	// SyscallStop returns the system call thread is stopped at, or nil if
+	// the thread isn't stopped at a system call.
+	SyscallStop(thread Thread) *SyscallStop
+}
+
+// DynamicLoaderWatcher is implemented by backends that can find the
+// function called by the dynamic loader every time it changes the list of
+// loaded shared libraries.
+type DynamicLoaderWatcher interface {
+	// DynamicLoaderBreakpoint returns the address of the dynamic loader
+	// notification function, or 0 if the target process isn't dynamically
+	// linked.
+	DynamicLoaderBreakpoint() (uint64, error)
+}
+
+// SignalHandler is implemented by backends that support signal handling
+// policies.
+type SignalHandler interface {
+	// SetSignalPolicy sets the policy used to handle signal sig.
+	SetSignalPolicy(sig int, policy SignalPolicy) error
+	// ReceivedSignals returns the signals with either the Print or the Stop
+	// policy received since the last call to ContinueOnce.
+	ReceivedSignals() []ReceivedSignal
+}
+
 // RecordingManipulation is an interface for manipulating process recordings.
 type RecordingManipulation interface {
 	// Recorded returns true if the current process is a recording and the path
@@ -0,0 +1,1323 @@
+package proc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go/ast"
+	"math"
+	"math/bits"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// This file implements the evaluation of function calls on targets that
+// can not execute code, such as core files and recordings.
+//
+// Instead of injecting the call into the target process, the machine code
+// of the called function is emulated against the target's memory. The
+// emulated function is given a private stack, a private heap, and a copy
+// of the g struct of the current goroutine, which are the only memory it
+// is allowed to write to: writing anywhere else, making a system call or
+// panicking stops the emulation with an error.
+// Calls to runtime.mallocgc are not executed, the memory is allocated
+// from the private heap instead, which is never freed.
+// This is enough to execute simple methods that do not have side effects,
+// for example most String and Len methods.
+//
+// Only amd64 is supported.
+
+const (
+	// interpMemBase is the address of the memory private to the
+	// interpreted functions. It is a non-canonical address so that it
+	// can not overlap with the target's memory.
+	interpMemBase = 0x7fff000000000000
+	// interpMemSize is the size of the memory private to the interpreted
+	// functions, excluding their heap.
+	interpMemSize = 1024 * 1024
+	// interpHeapBase is the address of the heap of the interpreted
+	// functions, it follows their stack.
+	interpHeapBase = interpMemBase + interpMemSize
+	// interpHeapSize is the size of the heap of the interpreted functions.
+	interpHeapSize = 4 * 1024 * 1024
+	// interpStackGuard is the size of the stack below g.stackguard0.
+	interpStackGuard = 4096
+	// interpMaxSteps is the maximum number of instructions executed by an
+	// interpreted function call.
+	interpMaxSteps = 1000000
+)
+
+var (
+	errInterpSyscall       = errors.New("function makes a system call")
+	errInterpPanic         = errors.New("function panics")
+	errInterpAlloc         = errors.New("function allocates too much memory")
+	errInterpStackOverflow = errors.New("function overflows its stack")
+	errInterpTooLong       = errors.New("function executes too many instructions")
+	errInterpNotGo         = errors.New("function calls non-Go code")
+)
+
+// interpContext is the state shared by the function calls interpreted
+// while evaluating an expression.
+type interpContext struct {
+	// retLoadCfg is the load configuration used to load return values
+	retLoadCfg LoadConfig
+
+	mem *interpMemory
+	// top is the top of the free portion of the private stack, the return
+	// values of the functions called so far are stored above it.
+	top uint64
+	// tlsAddr is the address of the thread local storage slot containing
+	// the address of gaddr.
+	tlsAddr uint64
+	// gaddr is the address of the copy of the g struct of the current
+	// goroutine.
+	gaddr uint64
+	// heap is the address of the free portion of the private heap.
+	heap uint64
+}
+
+// interpMemory is the memory seen by interpreted functions: reads are
+// served by the target's memory, except for the private memory of the
+// interpreter, which is the only memory interpreted functions can write.
+type interpMemory struct {
+	mem  MemoryReadWriter
+	priv []byte
+}
+
+func (m *interpMemory) inPriv(addr uintptr, sz int) bool {
+	return uint64(addr) >= interpMemBase && uint64(addr)+uint64(sz) <= interpMemBase+uint64(len(m.priv))
+}
+
+func (m *interpMemory) ReadMemory(buf []byte, addr uintptr) (int, error) {
+	if m.inPriv(addr, len(buf)) {
+		return copy(buf, m.priv[uint64(addr)-interpMemBase:]), nil
+	}
+	return m.mem.ReadMemory(buf, addr)
+}
+
+func (m *interpMemory) WriteMemory(addr uintptr, data []byte) (int, error) {
+	if !m.inPriv(addr, len(data)) {
+		return 0, fmt.Errorf("function writes to memory at %#x", addr)
+	}
+	return copy(m.priv[uint64(addr)-interpMemBase:], data), nil
+}
+
+// newInterpContext returns a new interpContext for the goroutine g.
+func newInterpContext(bi *BinaryInfo, mem MemoryReadWriter, g *G, retLoadCfg LoadConfig) (*interpContext, error) {
+	if bi.Arch.Name != "amd64" {
+		return nil, errFuncCallUnsupportedBackend
+	}
+	if g == nil || g.variable == nil {
+		return nil, errNoGoroutine
+	}
+	ctx := &interpContext{
+		retLoadCfg: retLoadCfg,
+		mem:        &interpMemory{mem: mem, priv: make([]byte, interpMemSize+interpHeapSize)},
+		tlsAddr:    interpMemBase,
+		gaddr:      interpMemBase + 64,
+		heap:       interpHeapBase,
+	}
+
+	// The stack check in the prologue of every function compares the stack
+	// pointer with g.stackguard0, functions are given a copy of the current
+	// g whose stack bounds describe the private stack.
+	gtyp, err := bi.findType("runtime.g")
+	if err != nil {
+		return nil, err
+	}
+	gbuf := ctx.mem.priv[ctx.gaddr-interpMemBase:][:gtyp.Size()]
+	if _, err := mem.ReadMemory(gbuf, g.variable.Addr); err != nil {
+		return nil, err
+	}
+	stackLo := alignAddrUint(ctx.gaddr+uint64(gtyp.Size()), 64)
+	ctx.top = interpMemBase + interpMemSize
+	if st, ok := resolveTypedef(gtyp).(*godwarf.StructType); ok {
+		for _, field := range st.Field {
+			switch field.Name {
+			case "stackguard0", "stackguard1":
+				binary.LittleEndian.PutUint64(gbuf[field.ByteOffset:], stackLo+interpStackGuard)
+			case "stack":
+				binary.LittleEndian.PutUint64(gbuf[field.ByteOffset:], stackLo)
+				binary.LittleEndian.PutUint64(gbuf[field.ByteOffset+8:], ctx.top)
+			}
+		}
+	}
+	binary.LittleEndian.PutUint64(ctx.mem.priv[ctx.tlsAddr-interpMemBase:], ctx.gaddr)
+	return ctx, nil
+}
+
+// alloc allocates size bytes of zeroed memory from the private heap.
+func (ctx *interpContext) alloc(size uint64) (uint64, error) {
+	align := uint64(8)
+	if size >= 16 {
+		align = 16
+	}
+	addr := alignAddrUint(ctx.heap, align)
+	if size > interpHeapSize || addr+size > interpHeapBase+interpHeapSize {
+		return 0, errInterpAlloc
+	}
+	ctx.heap = addr + size
+	return addr, nil
+}
+
+func alignAddrUint(addr, align uint64) uint64 {
+	return (addr + align - 1) &^ (align - 1)
+}
+
+// interpretFunctionCall evaluates the function call node by interpreting
+// the machine code of the called function.
+func (scope *EvalScope) interpretFunctionCall(node *ast.CallExpr) (*Variable, error) {
+	bi := scope.BinInfo
+	ctx := scope.interp
+
+	fncall := functionCallState{expr: node}
+	if err := funcCallEvalFuncExpr(scope, &fncall, false); err != nil {
+		return nil, err
+	}
+	if fncall.fn == nil {
+		return nil, fmt.Errorf("could not determine the function called by %q", exprToString(node.Fun))
+	}
+	_, allArgs, err := funcCallArgs(fncall.fn, bi, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Evaluate the arguments before reserving the argument frame, so that
+	// the return values of the function calls they contain are stored
+	// above it.
+	formalArgs := fncall.formalArgs
+	actualArgs := make([]*Variable, 0, len(formalArgs))
+	if fncall.receiver != nil {
+		actualArgs = append(actualArgs, fncall.receiver)
+	}
+	for _, arg := range node.Args {
+		actualArg, err := scope.evalAST(arg)
+		if err != nil {
+			return nil, fmt.Errorf("error evaluating %q as argument in function %s: %v", exprToString(arg), fncall.fn.Name, err)
+		}
+		actualArg.Name = exprToString(arg)
+		actualArgs = append(actualArgs, actualArg)
+	}
+
+	ptrSize := uint64(bi.Arch.PtrSize())
+	argFrameAddr := alignAddrUint(ctx.top-uint64(fncall.argFrameSize)-ptrSize, 16) - 16
+	sp := argFrameAddr - ptrSize
+	if sp < ctx.gaddr+interpStackGuard*2 {
+		return nil, errInterpStackOverflow
+	}
+	ctx.top = sp
+
+	it := &interpreter{bi: bi, ctx: ctx, mem: ctx.mem, insts: make(map[uint64]x86asm.Inst)}
+
+	for i := range formalArgs {
+		formalArgVar, err := funcCallArgVariable(bi, ctx.mem, &formalArgs[i], argFrameAddr, it.dwarfRegisters())
+		if err != nil {
+			return nil, err
+		}
+		if err := scope.setValue(formalArgVar, actualArgs[i], actualArgs[i].Name); err != nil {
+			return nil, err
+		}
+	}
+
+	it.regs[x86asm.RSP-x86asm.RAX] = sp
+	it.regs[x86asm.RDX-x86asm.RAX] = fncall.closureAddr
+	it.regs[x86asm.R14-x86asm.RAX] = ctx.gaddr
+	if err := it.store(sp, 8, interpMemBase); err != nil {
+		return nil, err
+	}
+	if err := it.run(fncall.fn.Entry); err != nil {
+		return nil, fmt.Errorf("can not evaluate call to %s: %v", fncall.fn.Name, err)
+	}
+
+	if bi.regabi {
+		retvars, err := regabiReturnValues(fncall.fn, bi, ctx.mem, it.dwarfRegisters(), argFrameAddr)
+		if err != nil {
+			return nil, err
+		}
+		loadValues(retvars, ctx.retLoadCfg)
+		return funcCallReturnValue(bi, retvars), nil
+	}
+
+	var retvars []*Variable
+	for _, arg := range allArgs {
+		if !arg.isret {
+			continue
+		}
+		v := newVariable(arg.name, uintptr(argFrameAddr+uint64(arg.off)), arg.typ, bi, ctx.mem)
+		v.Flags |= VariableReturnArgument | VariableFakeAddress
+		v.loadValue(ctx.retLoadCfg)
+		retvars = append(retvars, v)
+	}
+	return funcCallReturnValue(bi, retvars), nil
+}
+
+// interpreter emulates the execution of amd64 machine code.
+type interpreter struct {
+	bi    *BinaryInfo
+	ctx   *interpContext
+	mem   *interpMemory
+	insts map[uint64]x86asm.Inst
+
+	pc   uint64
+	cur  uint64 // address of the instruction being executed
+	regs [16]uint64
+	xmm  [16][2]uint64
+
+	cf, zf, sf, of, pf bool
+}
+
+// interpDwarfRegs maps the general purpose registers of the interpreter,
+// in x86asm order, to DWARF register numbers.
+var interpDwarfRegs = [16]uint64{0, 2, 1, 3, 7, 6, 4, 5, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// dwarfRegisters returns the registers of the interpreter as DWARF
+// registers, changing them through their ChangeFunc changes the registers
+// of the interpreter.
+func (it *interpreter) dwarfRegisters() op.DwarfRegisters {
+	dregs := make([]*op.DwarfRegister, amd64DwarfIPRegNum+1+uint64(len(it.xmm)))
+	for i, regNum := range interpDwarfRegs {
+		dregs[regNum] = op.DwarfRegisterFromUint64(it.regs[i])
+	}
+	dregs[amd64DwarfIPRegNum] = op.DwarfRegisterFromUint64(it.pc)
+	for i := range it.xmm {
+		buf := make([]byte, 16)
+		binary.LittleEndian.PutUint64(buf, it.xmm[i][0])
+		binary.LittleEndian.PutUint64(buf[8:], it.xmm[i][1])
+		dregs[amd64DwarfIPRegNum+1+uint64(i)] = op.DwarfRegisterFromBytes(buf)
+	}
+	r := op.NewDwarfRegisters(0, dregs, binary.LittleEndian, amd64DwarfIPRegNum, amd64DwarfSPRegNum, amd64DwarfBPRegNum, 0)
+	r.ChangeFunc = it.setDwarfRegister
+	return *r
+}
+
+func (it *interpreter) setDwarfRegister(regNum uint64, reg *op.DwarfRegister) error {
+	for i := range interpDwarfRegs {
+		if interpDwarfRegs[i] == regNum {
+			it.regs[i] = reg.Uint64Val
+			return nil
+		}
+	}
+	if i := int(regNum) - int(amd64DwarfIPRegNum) - 1; i >= 0 && i < len(it.xmm) {
+		buf := make([]byte, 16)
+		reg.FillBytes()
+		copy(buf, reg.Bytes)
+		it.xmm[i][0] = binary.LittleEndian.Uint64(buf)
+		it.xmm[i][1] = binary.LittleEndian.Uint64(buf[8:])
+		return nil
+	}
+	return fmt.Errorf("can not change register %d", regNum)
+}
+
+// run executes the function starting at entry until it returns.
+func (it *interpreter) run(entry uint64) error {
+	it.pc = entry
+	for steps := 0; it.pc != interpMemBase; steps++ {
+		if steps > interpMaxSteps {
+			return errInterpTooLong
+		}
+		inst, err := it.decode(it.pc)
+		if err != nil {
+			return err
+		}
+		it.cur = it.pc
+		if err := it.step(inst); err != nil {
+			if fn := it.bi.PCToFunc(it.cur); fn != nil {
+				return fmt.Errorf("%v (in %s)", err, fn.Name)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (it *interpreter) decode(pc uint64) (x86asm.Inst, error) {
+	if inst, ok := it.insts[pc]; ok {
+		return inst, nil
+	}
+	buf := make([]byte, 16)
+	if _, err := it.mem.ReadMemory(buf, uintptr(pc)); err != nil {
+		return x86asm.Inst{}, fmt.Errorf("could not read instruction at %#x: %v", pc, err)
+	}
+	inst, err := x86asm.Decode(buf, 64)
+	if err != nil {
+		return x86asm.Inst{}, fmt.Errorf("could not decode instruction at %#x: %v", pc, err)
+	}
+	it.insts[pc] = inst
+	return inst, nil
+}
+
+func (it *interpreter) unsupported(inst x86asm.Inst) error {
+	return fmt.Errorf("unsupported instruction %s at %#x", inst.String(), it.cur)
+}
+
+func (it *interpreter) hasPrefix(inst x86asm.Inst, prefix x86asm.Prefix) bool {
+	for _, p := range inst.Prefix {
+		if p == 0 {
+			break
+		}
+		if p&0xff == prefix && p&(x86asm.PrefixIgnored|x86asm.PrefixInvalid) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// gpReg returns the index, size and position of the general purpose
+// register r.
+func gpReg(r x86asm.Reg) (idx, size int, high, ok bool) {
+	switch {
+	case r >= x86asm.AL && r <= x86asm.BL:
+		return int(r - x86asm.AL), 1, false, true
+	case r >= x86asm.AH && r <= x86asm.BH:
+		return int(r - x86asm.AH), 1, true, true
+	case r >= x86asm.SPB && r <= x86asm.R15B:
+		return int(r-x86asm.SPB) + 4, 1, false, true
+	case r >= x86asm.AX && r <= x86asm.R15W:
+		return int(r - x86asm.AX), 2, false, true
+	case r >= x86asm.EAX && r <= x86asm.R15L:
+		return int(r - x86asm.EAX), 4, false, true
+	case r >= x86asm.RAX && r <= x86asm.R15:
+		return int(r - x86asm.RAX), 8, false, true
+	}
+	return 0, 0, false, false
+}
+
+func isXMM(arg x86asm.Arg) bool {
+	r, ok := arg.(x86asm.Reg)
+	return ok && r >= x86asm.X0 && r <= x86asm.X15
+}
+
+func sizeMask(size int) uint64 {
+	if size >= 8 {
+		return ^uint64(0)
+	}
+	return 1<<(uint(size)*8) - 1
+}
+
+func signExtend(v uint64, size int) uint64 {
+	switch size {
+	case 1:
+		return uint64(int64(int8(v)))
+	case 2:
+		return uint64(int64(int16(v)))
+	case 4:
+		return uint64(int64(int32(v)))
+	}
+	return v
+}
+
+// argSize returns the size of the argument arg of inst.
+func argSize(inst x86asm.Inst, arg x86asm.Arg) int {
+	switch arg := arg.(type) {
+	case x86asm.Reg:
+		if _, size, _, ok := gpReg(arg); ok {
+			return size
+		}
+		if isXMM(arg) {
+			return 16
+		}
+	case x86asm.Mem:
+		if inst.MemBytes != 0 {
+			return inst.MemBytes
+		}
+	}
+	return inst.DataSize / 8
+}
+
+// opSize returns the size of the operation performed by inst.
+func opSize(inst x86asm.Inst) int {
+	return argSize(inst, inst.Args[0])
+}
+
+func (it *interpreter) addr(m x86asm.Mem, next uint64) (uint64, error) {
+	var a uint64
+	switch {
+	case m.Segment == x86asm.FS:
+		// thread local storage, FS points just above the slot containing g
+		a = it.ctx.tlsAddr + 8
+	case m.Segment != 0 && m.Segment != x86asm.DS && m.Segment != x86asm.SS && m.Segment != x86asm.ES && m.Segment != x86asm.CS:
+		return 0, fmt.Errorf("unsupported segment %s", m.Segment)
+	}
+	switch {
+	case m.Base == x86asm.RIP:
+		a += next
+	case m.Base != 0:
+		idx, _, _, ok := gpReg(m.Base)
+		if !ok {
+			return 0, fmt.Errorf("unsupported base register %s", m.Base)
+		}
+		a += it.regs[idx]
+	}
+	if m.Index != 0 {
+		idx, _, _, ok := gpReg(m.Index)
+		if !ok {
+			return 0, fmt.Errorf("unsupported index register %s", m.Index)
+		}
+		a += it.regs[idx] * uint64(m.Scale)
+	}
+	return a + uint64(m.Disp), nil
+}
+
+func (it *interpreter) load(addr uint64, size int) (uint64, error) {
+	buf := make([]byte, 8)
+	if _, err := it.mem.ReadMemory(buf[:size], uintptr(addr)); err != nil {
+		return 0, fmt.Errorf("function reads invalid memory at %#x", addr)
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+func (it *interpreter) store(addr uint64, size int, val uint64) error {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, val)
+	_, err := it.mem.WriteMemory(uintptr(addr), buf[:size])
+	return err
+}
+
+func (it *interpreter) getReg(r x86asm.Reg) (uint64, int, error) {
+	idx, size, high, ok := gpReg(r)
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported register %s", r)
+	}
+	v := it.regs[idx]
+	if high {
+		v >>= 8
+	}
+	return v & sizeMask(size), size, nil
+}
+
+func (it *interpreter) setReg(r x86asm.Reg, val uint64) error {
+	idx, size, high, ok := gpReg(r)
+	if !ok {
+		return fmt.Errorf("unsupported register %s", r)
+	}
+	switch {
+	case high:
+		it.regs[idx] = it.regs[idx]&^0xff00 | (val&0xff)<<8
+	case size >= 4:
+		// 32bit operations zero the upper half of the register
+		it.regs[idx] = val & sizeMask(size)
+	default:
+		it.regs[idx] = it.regs[idx]&^sizeMask(size) | val&sizeMask(size)
+	}
+	return nil
+}
+
+// read returns the value of the argument arg of inst, next is the address
+// of the following instruction.
+func (it *interpreter) read(inst x86asm.Inst, arg x86asm.Arg, size int, next uint64) (uint64, error) {
+	switch arg := arg.(type) {
+	case x86asm.Reg:
+		if isXMM(arg) {
+			return it.xmm[arg-x86asm.X0][0] & sizeMask(size), nil
+		}
+		v, _, err := it.getReg(arg)
+		return v, err
+	case x86asm.Mem:
+		a, err := it.addr(arg, next)
+		if err != nil {
+			return 0, err
+		}
+		return it.load(a, size)
+	case x86asm.Imm:
+		return uint64(arg) & sizeMask(size), nil
+	case x86asm.Rel:
+		return next + uint64(int64(arg)), nil
+	}
+	return 0, it.unsupported(inst)
+}
+
+func (it *interpreter) write(inst x86asm.Inst, arg x86asm.Arg, size int, next, val uint64) error {
+	switch arg := arg.(type) {
+	case x86asm.Reg:
+		if isXMM(arg) {
+			it.xmm[arg-x86asm.X0] = [2]uint64{val & sizeMask(size), 0}
+			return nil
+		}
+		return it.setReg(arg, val)
+	case x86asm.Mem:
+		a, err := it.addr(arg, next)
+		if err != nil {
+			return err
+		}
+		return it.store(a, size, val)
+	}
+	return it.unsupported(inst)
+}
+
+func (it *interpreter) read128(inst x86asm.Inst, arg x86asm.Arg, next uint64) ([2]uint64, error) {
+	if isXMM(arg) {
+		return it.xmm[arg.(x86asm.Reg)-x86asm.X0], nil
+	}
+	m, ok := arg.(x86asm.Mem)
+	if !ok {
+		return [2]uint64{}, it.unsupported(inst)
+	}
+	a, err := it.addr(m, next)
+	if err != nil {
+		return [2]uint64{}, err
+	}
+	lo, err := it.load(a, 8)
+	if err != nil {
+		return [2]uint64{}, err
+	}
+	hi, err := it.load(a+8, 8)
+	return [2]uint64{lo, hi}, err
+}
+
+func (it *interpreter) write128(inst x86asm.Inst, arg x86asm.Arg, next uint64, val [2]uint64) error {
+	if isXMM(arg) {
+		it.xmm[arg.(x86asm.Reg)-x86asm.X0] = val
+		return nil
+	}
+	m, ok := arg.(x86asm.Mem)
+	if !ok {
+		return it.unsupported(inst)
+	}
+	a, err := it.addr(m, next)
+	if err != nil {
+		return err
+	}
+	if err := it.store(a, 8, val[0]); err != nil {
+		return err
+	}
+	return it.store(a+8, 8, val[1])
+}
+
+func (it *interpreter) push(val uint64) error {
+	sp := &it.regs[x86asm.RSP-x86asm.RAX]
+	*sp -= 8
+	return it.store(*sp, 8, val)
+}
+
+func (it *interpreter) pop() (uint64, error) {
+	sp := &it.regs[x86asm.RSP-x86asm.RAX]
+	v, err := it.load(*sp, 8)
+	*sp += 8
+	return v, err
+}
+
+func (it *interpreter) setResultFlags(r uint64, size int) {
+	r &= sizeMask(size)
+	it.zf = r == 0
+	it.sf = r>>(uint(size)*8-1)&1 != 0
+	it.pf = bits.OnesCount8(uint8(r))%2 == 0
+}
+
+func signBit(v uint64, size int) bool {
+	return v>>(uint(size)*8-1)&1 != 0
+}
+
+// add returns a+b+carry setting the flags like the ADD and ADC
+// instructions.
+func (it *interpreter) add(a, b, carry uint64, size int) uint64 {
+	mask := sizeMask(size)
+	a, b = a&mask, b&mask
+	r, c := bits.Add64(a, b, carry)
+	if size < 8 {
+		c = (r >> (uint(size) * 8)) & 1
+	}
+	r &= mask
+	it.cf = c != 0
+	it.of = signBit(a, size) == signBit(b, size) && signBit(r, size) != signBit(a, size)
+	it.setResultFlags(r, size)
+	return r
+}
+
+// sub returns a-b-borrow setting the flags like the SUB, SBB and CMP
+// instructions.
+func (it *interpreter) sub(a, b, borrow uint64, size int) uint64 {
+	mask := sizeMask(size)
+	a, b = a&mask, b&mask
+	r, c := bits.Sub64(a, b, borrow)
+	r &= mask
+	it.cf = c != 0
+	it.of = signBit(a, size) != signBit(b, size) && signBit(r, size) != signBit(a, size)
+	it.setResultFlags(r, size)
+	return r
+}
+
+func (it *interpreter) logic(r uint64, size int) uint64 {
+	it.cf, it.of = false, false
+	it.setResultFlags(r, size)
+	return r & sizeMask(size)
+}
+
+// cond evaluates the condition code cc of a Jcc, SETcc or CMOVcc
+// instruction.
+func (it *interpreter) cond(cc string) (bool, bool) {
+	switch cc {
+	case "A":
+		return !it.cf && !it.zf, true
+	case "AE":
+		return !it.cf, true
+	case "B":
+		return it.cf, true
+	case "BE":
+		return it.cf || it.zf, true
+	case "E":
+		return it.zf, true
+	case "NE":
+		return !it.zf, true
+	case "G":
+		return !it.zf && it.sf == it.of, true
+	case "GE":
+		return it.sf == it.of, true
+	case "L":
+		return it.sf != it.of, true
+	case "LE":
+		return it.zf || it.sf != it.of, true
+	case "S":
+		return it.sf, true
+	case "NS":
+		return !it.sf, true
+	case "O":
+		return it.of, true
+	case "NO":
+		return !it.of, true
+	case "P":
+		return it.pf, true
+	case "NP":
+		return !it.pf, true
+	}
+	return false, false
+}
+
+// call emulates a call to the function at addr, returning to ret.
+func (it *interpreter) call(addr, ret uint64) error {
+	fn := it.bi.PCToFunc(addr)
+	if fn == nil {
+		return errInterpNotGo
+	}
+	switch {
+	case strings.HasPrefix(fn.Name, "runtime.morestack"):
+		return errInterpStackOverflow
+	case fn.Name == "runtime.gopanic" || fn.Name == "runtime.throw" || strings.HasPrefix(fn.Name, "runtime.panic") || strings.HasPrefix(fn.Name, "runtime.goPanic"):
+		return errInterpPanic
+	case fn.Name == "runtime.mallocgc":
+		return it.mallocgc(ret)
+	case strings.HasPrefix(fn.Name, "runtime.cgocall") || strings.HasPrefix(fn.Name, "runtime.asmcgocall") || strings.HasPrefix(fn.Name, "runtime.systemstack"):
+		return errInterpNotGo
+	}
+	if err := it.push(ret); err != nil {
+		return err
+	}
+	it.pc = addr
+	return nil
+}
+
+// mallocgc emulates a call to runtime.mallocgc, returning to ret, by
+// allocating the requested memory from the private heap.
+func (it *interpreter) mallocgc(ret uint64) error {
+	// func mallocgc(size uintptr, typ *_type, needzero bool) unsafe.Pointer
+	sp := it.regs[x86asm.RSP-x86asm.RAX]
+	size := it.regs[x86asm.RAX-x86asm.RAX]
+	if !it.bi.regabi {
+		var err error
+		size, err = it.load(sp, 8)
+		if err != nil {
+			return err
+		}
+	}
+	addr, err := it.ctx.alloc(size)
+	if err != nil {
+		return err
+	}
+	if it.bi.regabi {
+		it.regs[x86asm.RAX-x86asm.RAX] = addr
+	} else if err := it.store(sp+24, 8, addr); err != nil {
+		return err
+	}
+	it.pc = ret
+	return nil
+}
+
+// step executes inst.
+func (it *interpreter) step(inst x86asm.Inst) error {
+	next := it.pc + uint64(inst.Len)
+	args := inst.Args[:]
+	for i := range args {
+		if args[i] == nil {
+			args = args[:i]
+			break
+		}
+	}
+
+	if it.hasPrefix(inst, x86asm.PrefixLOCK) {
+		return it.unsupported(inst)
+	}
+
+	op := inst.Op.String()
+	switch {
+	case strings.HasPrefix(op, "J") && inst.Op != x86asm.JMP && inst.Op != x86asm.JCXZ && inst.Op != x86asm.JECXZ && inst.Op != x86asm.JRCXZ:
+		taken, ok := it.cond(op[1:])
+		if !ok {
+			return it.unsupported(inst)
+		}
+		it.pc = next
+		if taken {
+			it.pc = next + uint64(int64(args[0].(x86asm.Rel)))
+		}
+		return nil
+	case strings.HasPrefix(op, "SET"):
+		v, ok := it.cond(op[3:])
+		if !ok {
+			return it.unsupported(inst)
+		}
+		var b uint64
+		if v {
+			b = 1
+		}
+		it.pc = next
+		return it.write(inst, args[0], 1, next, b)
+	case strings.HasPrefix(op, "CMOV"):
+		v, ok := it.cond(op[4:])
+		if !ok {
+			return it.unsupported(inst)
+		}
+		size := opSize(inst)
+		it.pc = next
+		src, err := it.read(inst, args[1], size, next)
+		if err != nil {
+			return err
+		}
+		if !v {
+			// the destination register is zero extended even if the
+			// condition is false
+			src, _ = it.read(inst, args[0], size, next)
+		}
+		return it.write(inst, args[0], size, next, src)
+	}
+
+	size := opSize(inst)
+	binop := func(f func(a, b uint64) uint64, writeback bool) error {
+		a, err := it.read(inst, args[0], size, next)
+		if err != nil {
+			return err
+		}
+		b, err := it.read(inst, args[1], argSize(inst, args[1]), next)
+		if err != nil {
+			return err
+		}
+		if _, isImm := args[1].(x86asm.Imm); isImm || argSize(inst, args[1]) < size {
+			b = signExtend(b, argSize(inst, args[1]))
+		}
+		r := f(a, b)
+		if !writeback {
+			return nil
+		}
+		return it.write(inst, args[0], size, next, r)
+	}
+
+	it.pc = next
+
+	switch inst.Op {
+	case x86asm.NOP, x86asm.PAUSE, x86asm.PREFETCHT0, x86asm.PREFETCHT1, x86asm.PREFETCHT2, x86asm.PREFETCHNTA:
+		return nil
+	case x86asm.SYSCALL, x86asm.SYSENTER:
+		return errInterpSyscall
+	case x86asm.INT:
+		if imm, _ := args[0].(x86asm.Imm); imm == 3 {
+			// breakpoint
+			return errInterpPanic
+		}
+		return errInterpSyscall
+	case x86asm.UD1, x86asm.UD2, x86asm.HLT:
+		return errInterpPanic
+
+	case x86asm.MOV:
+		v, err := it.read(inst, args[1], size, next)
+		if err != nil {
+			return err
+		}
+		return it.write(inst, args[0], size, next, v)
+	case x86asm.MOVZX, x86asm.MOVSX, x86asm.MOVSXD:
+		srcSize := argSize(inst, args[1])
+		v, err := it.read(inst, args[1], srcSize, next)
+		if err != nil {
+			return err
+		}
+		if inst.Op != x86asm.MOVZX {
+			v = signExtend(v, srcSize)
+		}
+		return it.write(inst, args[0], size, next, v)
+	case x86asm.LEA:
+		m, ok := args[1].(x86asm.Mem)
+		if !ok {
+			return it.unsupported(inst)
+		}
+		a, err := it.addr(m, next)
+		if err != nil {
+			return err
+		}
+		return it.write(inst, args[0], size, next, a)
+	case x86asm.XCHG:
+		a, err := it.read(inst, args[0], size, next)
+		if err != nil {
+			return err
+		}
+		b, err := it.read(inst, args[1], size, next)
+		if err != nil {
+			return err
+		}
+		if err := it.write(inst, args[0], size, next, b); err != nil {
+			return err
+		}
+		return it.write(inst, args[1], size, next, a)
+
+	case x86asm.ADD:
+		return binop(func(a, b uint64) uint64 { return it.add(a, b, 0, size) }, true)
+	case x86asm.ADC:
+		c := uint64(0)
+		if it.cf {
+			c = 1
+		}
+		return binop(func(a, b uint64) uint64 { return it.add(a, b, c, size) }, true)
+	case x86asm.SUB:
+		return binop(func(a, b uint64) uint64 { return it.sub(a, b, 0, size) }, true)
+	case x86asm.SBB:
+		c := uint64(0)
+		if it.cf {
+			c = 1
+		}
+		return binop(func(a, b uint64) uint64 { return it.sub(a, b, c, size) }, true)
+	case x86asm.CMP:
+		return binop(func(a, b uint64) uint64 { return it.sub(a, b, 0, size) }, false)
+	case x86asm.AND:
+		return binop(func(a, b uint64) uint64 { return it.logic(a&b, size) }, true)
+	case x86asm.OR:
+		return binop(func(a, b uint64) uint64 { return it.logic(a|b, size) }, true)
+	case x86asm.XOR:
+		return binop(func(a, b uint64) uint64 { return it.logic(a^b, size) }, true)
+	case x86asm.TEST:
+		return binop(func(a, b uint64) uint64 { return it.logic(a&b, size) }, false)
+	case x86asm.INC, x86asm.DEC, x86asm.NEG, x86asm.NOT:
+		a, err := it.read(inst, args[0], size, next)
+		if err != nil {
+			return err
+		}
+		var r uint64
+		switch inst.Op {
+		case x86asm.INC, x86asm.DEC:
+			cf := it.cf // INC and DEC do not change CF
+			if inst.Op == x86asm.INC {
+				r = it.add(a, 1, 0, size)
+			} else {
+				r = it.sub(a, 1, 0, size)
+			}
+			it.cf = cf
+		case x86asm.NEG:
+			r = it.sub(0, a, 0, size)
+		case x86asm.NOT:
+			r = ^a
+		}
+		return it.write(inst, args[0], size, next, r)
+
+	case x86asm.SHL, x86asm.SHR, x86asm.SAR, x86asm.ROL, x86asm.ROR:
+		a, err := it.read(inst, args[0], size, next)
+		if err != nil {
+			return err
+		}
+		n := uint64(1)
+		if len(args) > 1 {
+			if n, err = it.read(inst, args[1], 1, next); err != nil {
+				return err
+			}
+		}
+		if size == 8 {
+			n &= 63
+		} else {
+			n &= 31
+		}
+		if n == 0 {
+			return nil
+		}
+		nbits := uint64(size) * 8
+		var r uint64
+		switch inst.Op {
+		case x86asm.SHL:
+			it.cf = n <= nbits && (a>>(nbits-n))&1 != 0
+			r = a << n
+		case x86asm.SHR:
+			it.cf = (a>>(n-1))&1 != 0
+			r = a >> n
+		case x86asm.SAR:
+			sa := int64(signExtend(a, size))
+			it.cf = (sa>>(n-1))&1 != 0
+			r = uint64(sa >> n)
+		case x86asm.ROL, x86asm.ROR:
+			n %= nbits
+			if inst.Op == x86asm.ROR {
+				n = (nbits - n) % nbits
+			}
+			r = (a<<n | a>>((nbits-n)%nbits)) & sizeMask(size)
+			it.cf = r&1 != 0
+			return it.write(inst, args[0], size, next, r)
+		}
+		it.of = false
+		it.setResultFlags(r, size)
+		return it.write(inst, args[0], size, next, r)
+
+	case x86asm.BT:
+		a, err := it.read(inst, args[0], size, next)
+		if err != nil {
+			return err
+		}
+		n, err := it.read(inst, args[1], size, next)
+		if err != nil {
+			return err
+		}
+		it.cf = (a>>(n%(uint64(size)*8)))&1 != 0
+		return nil
+	case x86asm.BSF, x86asm.BSR, x86asm.TZCNT, x86asm.LZCNT, x86asm.POPCNT:
+		src, err := it.read(inst, args[1], size, next)
+		if err != nil {
+			return err
+		}
+		nbits := size * 8
+		var r int
+		switch inst.Op {
+		case x86asm.BSF:
+			it.zf = src == 0
+			if src == 0 {
+				return nil
+			}
+			r = bits.TrailingZeros64(src)
+		case x86asm.BSR:
+			it.zf = src == 0
+			if src == 0 {
+				return nil
+			}
+			r = 63 - bits.LeadingZeros64(src)
+		case x86asm.TZCNT:
+			it.cf = src == 0
+			r = bits.TrailingZeros64(src)
+			if r > nbits {
+				r = nbits
+			}
+			it.zf = r == 0
+		case x86asm.LZCNT:
+			it.cf = src == 0
+			r = bits.LeadingZeros64(src) - (64 - nbits)
+			it.zf = r == 0
+		case x86asm.POPCNT:
+			r = bits.OnesCount64(src)
+			it.zf = src == 0
+			it.cf, it.of, it.sf, it.pf = false, false, false, false
+		}
+		return it.write(inst, args[0], size, next, uint64(r))
+
+	case x86asm.IMUL:
+		switch len(args) {
+		case 1:
+			return it.mulDiv(inst, next, size)
+		case 2, 3:
+			a, err := it.read(inst, args[len(args)-2], size, next)
+			if err != nil {
+				return err
+			}
+			b, err := it.read(inst, args[len(args)-1], argSize(inst, args[len(args)-1]), next)
+			if err != nil {
+				return err
+			}
+			sa, sb := int64(signExtend(a, size)), int64(signExtend(b, argSize(inst, args[len(args)-1])))
+			r := sa * sb
+			it.cf = (sa != 0 && r/sa != sb) || int64(signExtend(uint64(r), size)) != r
+			it.of = it.cf
+			return it.write(inst, args[0], size, next, uint64(r))
+		}
+		return it.unsupported(inst)
+	case x86asm.MUL, x86asm.DIV, x86asm.IDIV:
+		return it.mulDiv(inst, next, size)
+	case x86asm.CQO, x86asm.CDQ, x86asm.CWD:
+		ax, dx := &it.regs[0], &it.regs[2]
+		sz := map[x86asm.Op]int{x86asm.CQO: 8, x86asm.CDQ: 4, x86asm.CWD: 2}[inst.Op]
+		hi := uint64(0)
+		if signBit(*ax, sz) {
+			hi = sizeMask(sz)
+		}
+		if sz == 2 {
+			*dx = *dx&^0xffff | hi
+		} else {
+			*dx = hi
+		}
+		return nil
+	case x86asm.CDQE, x86asm.CWDE, x86asm.CBW:
+		ax := &it.regs[0]
+		switch inst.Op {
+		case x86asm.CDQE:
+			*ax = signExtend(*ax, 4)
+		case x86asm.CWDE:
+			*ax = signExtend(*ax, 2) & sizeMask(4)
+		case x86asm.CBW:
+			*ax = *ax&^0xffff | signExtend(*ax, 1)&0xffff
+		}
+		return nil
+
+	case x86asm.PUSH:
+		v, err := it.read(inst, args[0], argSize(inst, args[0]), next)
+		if err != nil {
+			return err
+		}
+		if _, isImm := args[0].(x86asm.Imm); isImm {
+			v = signExtend(v, argSize(inst, args[0]))
+		}
+		return it.push(v)
+	case x86asm.POP:
+		v, err := it.pop()
+		if err != nil {
+			return err
+		}
+		return it.write(inst, args[0], 8, next, v)
+	case x86asm.JMP:
+		dst, err := it.read(inst, args[0], 8, next)
+		if err != nil {
+			return err
+		}
+		it.pc = dst
+		return nil
+	case x86asm.JCXZ, x86asm.JECXZ, x86asm.JRCXZ:
+		if it.regs[1]&sizeMask(map[x86asm.Op]int{x86asm.JCXZ: 2, x86asm.JECXZ: 4, x86asm.JRCXZ: 8}[inst.Op]) == 0 {
+			it.pc = next + uint64(int64(args[0].(x86asm.Rel)))
+		}
+		return nil
+	case x86asm.CALL:
+		dst, err := it.read(inst, args[0], 8, next)
+		if err != nil {
+			return err
+		}
+		return it.call(dst, next)
+	case x86asm.RET:
+		ret, err := it.pop()
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 {
+			it.regs[x86asm.RSP-x86asm.RAX] += uint64(args[0].(x86asm.Imm))
+		}
+		it.pc = ret
+		return nil
+
+	case x86asm.STOSB, x86asm.STOSW, x86asm.STOSD, x86asm.STOSQ, x86asm.MOVSB, x86asm.MOVSW, x86asm.MOVSD, x86asm.MOVSQ:
+		return it.stringOp(inst, next)
+
+	case x86asm.MOVUPS, x86asm.MOVAPS, x86asm.MOVUPD, x86asm.MOVAPD, x86asm.MOVDQU, x86asm.MOVDQA, x86asm.LDDQU:
+		v, err := it.read128(inst, args[1], next)
+		if err != nil {
+			return err
+		}
+		return it.write128(inst, args[0], next, v)
+	case x86asm.XORPS, x86asm.XORPD, x86asm.PXOR:
+		a, err := it.read128(inst, args[0], next)
+		if err != nil {
+			return err
+		}
+		b, err := it.read128(inst, args[1], next)
+		if err != nil {
+			return err
+		}
+		return it.write128(inst, args[0], next, [2]uint64{a[0] ^ b[0], a[1] ^ b[1]})
+	case x86asm.MOVQ, x86asm.MOVD, x86asm.MOVSD_XMM, x86asm.MOVSS:
+		sz := 8
+		if inst.Op == x86asm.MOVD || inst.Op == x86asm.MOVSS {
+			sz = 4
+		}
+		v, err := it.read(inst, args[1], sz, next)
+		if err != nil {
+			return err
+		}
+		if isXMM(args[0]) && isXMM(args[1]) && (inst.Op == x86asm.MOVSD_XMM || inst.Op == x86asm.MOVSS) {
+			// register to register moves preserve the upper bits
+			dst := &it.xmm[args[0].(x86asm.Reg)-x86asm.X0]
+			dst[0] = dst[0]&^sizeMask(sz) | v
+			return nil
+		}
+		return it.write(inst, args[0], sz, next, v)
+	case x86asm.ADDSD, x86asm.SUBSD, x86asm.MULSD, x86asm.DIVSD, x86asm.UCOMISD, x86asm.COMISD:
+		a, err := it.read(inst, args[0], 8, next)
+		if err != nil {
+			return err
+		}
+		b, err := it.read(inst, args[1], 8, next)
+		if err != nil {
+			return err
+		}
+		fa, fb := math.Float64frombits(a), math.Float64frombits(b)
+		var r float64
+		switch inst.Op {
+		case x86asm.ADDSD:
+			r = fa + fb
+		case x86asm.SUBSD:
+			r = fa - fb
+		case x86asm.MULSD:
+			r = fa * fb
+		case x86asm.DIVSD:
+			r = fa / fb
+		default:
+			unordered := math.IsNaN(fa) || math.IsNaN(fb)
+			it.zf = unordered || fa == fb
+			it.pf = unordered
+			it.cf = unordered || fa < fb
+			it.of, it.sf = false, false
+			return nil
+		}
+		dst := &it.xmm[args[0].(x86asm.Reg)-x86asm.X0]
+		dst[0] = math.Float64bits(r)
+		return nil
+	case x86asm.CVTSI2SD:
+		v, err := it.read(inst, args[1], argSize(inst, args[1]), next)
+		if err != nil {
+			return err
+		}
+		dst := &it.xmm[args[0].(x86asm.Reg)-x86asm.X0]
+		dst[0] = math.Float64bits(float64(int64(signExtend(v, argSize(inst, args[1])))))
+		return nil
+	case x86asm.CVTTSD2SI:
+		v, err := it.read(inst, args[1], 8, next)
+		if err != nil {
+			return err
+		}
+		return it.write(inst, args[0], size, next, uint64(int64(math.Float64frombits(v))))
+	}
+
+	return it.unsupported(inst)
+}
+
+// mulDiv executes the single operand forms of MUL, IMUL, DIV and IDIV,
+// which operate on RDX:RAX.
+func (it *interpreter) mulDiv(inst x86asm.Inst, next uint64, size int) error {
+	src, err := it.read(inst, inst.Args[0], size, next)
+	if err != nil {
+		return err
+	}
+	if size == 1 {
+		return it.unsupported(inst)
+	}
+	mask := sizeMask(size)
+	ax, dx := it.regs[0]&mask, it.regs[2]&mask
+	setResult := func(hi, lo uint64) {
+		if size == 2 {
+			it.regs[0] = it.regs[0]&^mask | lo&mask
+			it.regs[2] = it.regs[2]&^mask | hi&mask
+		} else {
+			it.regs[0], it.regs[2] = lo&mask, hi&mask
+		}
+	}
+	nbits := uint(size) * 8
+
+	switch inst.Op {
+	case x86asm.MUL:
+		var hi, lo uint64
+		if size == 8 {
+			hi, lo = bits.Mul64(ax, src)
+		} else {
+			r := ax * src
+			hi, lo = r>>nbits, r
+		}
+		setResult(hi, lo)
+		it.cf = hi&mask != 0
+		it.of = it.cf
+	case x86asm.IMUL:
+		sa, ss := int64(signExtend(ax, size)), int64(signExtend(src, size))
+		if size == 8 {
+			hi, lo := bits.Mul64(uint64(sa), uint64(ss))
+			// adjust the unsigned product for the sign of the operands
+			if sa < 0 {
+				hi -= uint64(ss)
+			}
+			if ss < 0 {
+				hi -= uint64(sa)
+			}
+			setResult(hi, lo)
+			it.cf = !(hi == 0 && int64(lo) >= 0 || hi == ^uint64(0) && int64(lo) < 0)
+		} else {
+			r := sa * ss
+			setResult(uint64(r>>nbits), uint64(r))
+			it.cf = int64(signExtend(uint64(r), size)) != r
+		}
+		it.of = it.cf
+	case x86asm.DIV:
+		if src == 0 {
+			return errInterpPanic
+		}
+		var q, r uint64
+		if size == 8 {
+			if dx >= src {
+				return errInterpPanic
+			}
+			q, r = bits.Div64(dx, ax, src)
+		} else {
+			n := dx<<nbits | ax
+			q, r = n/src, n%src
+			if q > mask {
+				return errInterpPanic
+			}
+		}
+		setResult(r, q)
+	case x86asm.IDIV:
+		ss := int64(signExtend(src, size))
+		if ss == 0 {
+			return errInterpPanic
+		}
+		var n int64
+		if size == 8 {
+			// only dividends that fit in 64 bits are supported
+			if dx != 0 && dx != ^uint64(0) || (dx == 0) == (int64(ax) < 0) {
+				return it.unsupported(inst)
+			}
+			n = int64(ax)
+		} else {
+			n = int64(signExtend(dx<<nbits|ax, size*2))
+		}
+		if ss == -1 && n == math.MinInt64 {
+			return errInterpPanic
+		}
+		q, r := n/ss, n%ss
+		if int64(signExtend(uint64(q), size)) != q {
+			return errInterpPanic
+		}
+		setResult(uint64(r), uint64(q))
+	}
+	return nil
+}
+
+// stringOp executes the STOS and MOVS instructions, with or without a REP
+// prefix.
+func (it *interpreter) stringOp(inst x86asm.Inst, next uint64) error {
+	var size int
+	switch inst.Op {
+	case x86asm.STOSB, x86asm.MOVSB:
+		size = 1
+	case x86asm.STOSW, x86asm.MOVSW:
+		size = 2
+	case x86asm.STOSD, x86asm.MOVSD:
+		size = 4
+	default:
+		size = 8
+	}
+	rep := it.hasPrefix(inst, x86asm.PrefixREP)
+	cx, si, di := &it.regs[1], &it.regs[6], &it.regs[7]
+	for !rep || *cx != 0 {
+		var v uint64
+		var err error
+		if inst.Op == x86asm.STOSB || inst.Op == x86asm.STOSW || inst.Op == x86asm.STOSD || inst.Op == x86asm.STOSQ {
+			v = it.regs[0] & sizeMask(size)
+		} else {
+			if v, err = it.load(*si, size); err != nil {
+				return err
+			}
+			*si += uint64(size)
+		}
+		if err := it.store(*di, size, v); err != nil {
+			return err
+		}
+		*di += uint64(size)
+		if !rep {
+			break
+		}
+		*cx--
+	}
+	return nil
+}
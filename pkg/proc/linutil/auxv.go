@@ -7,6 +7,7 @@ import (
 
 const (
 	_AT_NULL  = 0
+	_AT_BASE  = 7
 	_AT_ENTRY = 9
 )
 
@@ -18,10 +19,22 @@ const (
 // System V Application Binary Interface, Intel386 Architecture Processor
 // Supplement (fourth edition), section 3-28.
 func EntryPointFromAuxv(auxv []byte, ptrSize int) uint64 {
+	return auxvValue(auxv, ptrSize, _AT_ENTRY)
+}
+
+// InterpreterBaseFromAuxv searches the elf auxiliary vector for the address
+// where the program interpreter (the dynamic loader) was mapped.
+func InterpreterBaseFromAuxv(auxv []byte, ptrSize int) uint64 {
+	return auxvValue(auxv, ptrSize, _AT_BASE)
+}
+
+// auxvValue returns the value of the entry of the elf auxiliary vector with
+// the specified tag, or 0 if there is no such entry.
+func auxvValue(auxv []byte, ptrSize int, tag uint64) uint64 {
 	rd := bytes.NewBuffer(auxv)
 
 	for {
-		tag, err := readUintRaw(rd, binary.LittleEndian, ptrSize)
+		tag2, err := readUintRaw(rd, binary.LittleEndian, ptrSize)
 		if err != nil {
 			return 0
 		}
@@ -30,10 +43,10 @@ func EntryPointFromAuxv(auxv []byte, ptrSize int) uint64 {
 			return 0
 		}
 
-		switch tag {
+		switch tag2 {
 		case _AT_NULL:
 			return 0
-		case _AT_ENTRY:
+		case tag:
 			return val
 		}
 	}
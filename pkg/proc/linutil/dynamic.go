@@ -2,10 +2,12 @@ package linutil
 
 import (
 	"bytes"
+	"debug/elf"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 
 	"github.com/go-delve/delve/pkg/proc"
 )
@@ -22,6 +24,11 @@ const (
 	_DT_DEBUG = 21 // DT_DEBUG as defined by SysV ABI specification
 )
 
+// dynamicLoaderNotificationFunction is the function called by the dynamic
+// loader before and after it changes the list of loaded libraries, its
+// address is stored in the r_brk field of r_debug.
+const dynamicLoaderNotificationFunction = "_dl_debug_state"
+
 // readUintRaw reads an integer of ptrSize bytes, with the specified byte order, from reader.
 func readUintRaw(reader io.Reader, order binary.ByteOrder, ptrSize int) (uint64, error) {
 	switch ptrSize {
@@ -183,3 +190,79 @@ func ElfUpdateSharedObjects(p proc.Process) error {
 
 	return nil
 }
+
+// ElfDynamicLoaderBreakpoint returns the address of the function called by
+// the dynamic loader every time it changes the list of loaded libraries
+// (r_debug.r_brk), or 0 if the target process isn't dynamically linked.
+// Until the dynamic loader initializes r_debug, for example when the process
+// was just launched, the function is looked up in the symbol table of the
+// program interpreter, which is mapped at the base address specified in the
+// auxiliary vector auxv.
+func ElfDynamicLoaderBreakpoint(p proc.Process, auxv []byte) (uint64, error) {
+	bi := p.BinInfo()
+	if bi.ElfDynamicSection.Addr == 0 {
+		// no dynamic section, therefore nothing to do here
+		return 0, nil
+	}
+	debugAddr, err := dynamicSearchDebug(p)
+	if err != nil {
+		return 0, err
+	}
+	if debugAddr != 0 {
+		// Offset of the r_brk field of the r_debug struct, see
+		// /usr/include/link.h.
+		debugBrkOffset := uint64(2 * bi.Arch.PtrSize())
+		r_brk, err := readPtr(p, debugAddr+debugBrkOffset)
+		if err != nil {
+			return 0, err
+		}
+		if r_brk != 0 {
+			return r_brk, nil
+		}
+	}
+
+	base := InterpreterBaseFromAuxv(auxv, bi.Arch.PtrSize())
+	if base == 0 {
+		return 0, nil
+	}
+	interp, err := elfInterpreter(bi.Images[0].Path)
+	if err != nil || interp == "" {
+		return 0, err
+	}
+	f, err := elf.Open(interp)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	syms, _ := f.DynamicSymbols()
+	if symtab, err := f.Symbols(); err == nil {
+		syms = append(syms, symtab...)
+	}
+	for _, sym := range syms {
+		if sym.Name == dynamicLoaderNotificationFunction {
+			return base + sym.Value, nil
+		}
+	}
+	return 0, fmt.Errorf("could not find %s in %s", dynamicLoaderNotificationFunction, interp)
+}
+
+// elfInterpreter returns the path of the program interpreter of the
+// executable at path, or the empty string if it doesn't have one.
+func elfInterpreter(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		buf, err := ioutil.ReadAll(prog.Open())
+		if err != nil {
+			return "", err
+		}
+		return string(bytes.TrimRight(buf, "\x00")), nil
+	}
+	return "", nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"golang.org/x/arch/x86/x86asm"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -311,6 +312,82 @@ func (r *AMD64Registers) Copy() (proc.Registers, error) {
 	return &rr, nil
 }
 
+// SetReg changes the value of one of the registers to the value of reg,
+// regNum is a DWARF register number. The floating point registers are
+// loaded, if they weren't already, so that the full register set can be
+// written back to the thread.
+func (r *AMD64Registers) SetReg(regNum uint64, reg *op.DwarfRegister) error {
+	if r.loadFpRegs != nil {
+		err := r.loadFpRegs(r)
+		r.loadFpRegs = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	var p *uint64
+	switch regNum {
+	case 0:
+		p = &r.Regs.Rax
+	case 1:
+		p = &r.Regs.Rdx
+	case 2:
+		p = &r.Regs.Rcx
+	case 3:
+		p = &r.Regs.Rbx
+	case 4:
+		p = &r.Regs.Rsi
+	case 5:
+		p = &r.Regs.Rdi
+	case 6:
+		p = &r.Regs.Rbp
+	case 7:
+		p = &r.Regs.Rsp
+	case 8:
+		p = &r.Regs.R8
+	case 9:
+		p = &r.Regs.R9
+	case 10:
+		p = &r.Regs.R10
+	case 11:
+		p = &r.Regs.R11
+	case 12:
+		p = &r.Regs.R12
+	case 13:
+		p = &r.Regs.R13
+	case 14:
+		p = &r.Regs.R14
+	case 15:
+		p = &r.Regs.R15
+	case 16:
+		p = &r.Regs.Rip
+	}
+	if p != nil {
+		*p = reg.Uint64Val
+		return nil
+	}
+
+	if regNum < 17 || regNum > 32 || r.Fpregset == nil {
+		return fmt.Errorf("changing register %d not implemented", regNum)
+	}
+
+	// XMM registers
+	i := int(regNum - 17)
+	reg.FillBytes()
+	xmm := r.Fpregset.XmmSpace[i*16 : (i+1)*16]
+	for j := range xmm {
+		xmm[j] = 0
+	}
+	copy(xmm, reg.Bytes)
+	if r.Fpregset.Xsave != nil {
+		// the legacy region of the XSAVE area has the same layout as
+		// AMD64PtraceFpRegs, the XMM registers start at byte 160.
+		const xmmOffset = 160
+		copy(r.Fpregset.Xsave[xmmOffset+i*16:xmmOffset+(i+1)*16], xmm)
+	}
+	return nil
+}
+
 // AMD64PtraceFpRegs tracks user_fpregs_struct in /usr/include/x86_64-linux-gnu/sys/user.h
 type AMD64PtraceFpRegs struct {
 	Cwd      uint16
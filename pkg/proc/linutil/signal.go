@@ -0,0 +1,74 @@
+package linutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// signalNames maps the numbers of linux signals to their names, signal
+// numbers are the same on every architecture supported by delve.
+var signalNames = map[int]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	5:  "SIGTRAP",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	10: "SIGUSR1",
+	11: "SIGSEGV",
+	12: "SIGUSR2",
+	13: "SIGPIPE",
+	14: "SIGALRM",
+	15: "SIGTERM",
+	16: "SIGSTKFLT",
+	17: "SIGCHLD",
+	18: "SIGCONT",
+	19: "SIGSTOP",
+	20: "SIGTSTP",
+	21: "SIGTTIN",
+	22: "SIGTTOU",
+	23: "SIGURG",
+	24: "SIGXCPU",
+	25: "SIGXFSZ",
+	26: "SIGVTALRM",
+	27: "SIGPROF",
+	28: "SIGWINCH",
+	29: "SIGIO",
+	30: "SIGPWR",
+	31: "SIGSYS",
+}
+
+// SignalName returns the name of signal sig, or the number itself if sig
+// is not a known signal.
+func SignalName(sig int) string {
+	if name, ok := signalNames[sig]; ok {
+		return name
+	}
+	return strconv.Itoa(sig)
+}
+
+// SignalNumber returns the number of the signal called name. The SIG
+// prefix of name is optional and it is matched case insensitively, name
+// can also be a signal number.
+func SignalNumber(name string) (int, error) {
+	if sig, err := strconv.Atoi(name); err == nil {
+		if sig <= 0 || sig > 64 {
+			return 0, fmt.Errorf("invalid signal number %d", sig)
+		}
+		return sig, nil
+	}
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	for sig, name2 := range signalNames {
+		if name2 == name {
+			return sig, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown signal %q", name)
+}
@@ -0,0 +1,97 @@
+package linutil
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SyscallRegisters is implemented by the register sets of this package to
+// describe the system call a thread is stopped at.
+type SyscallRegisters interface {
+	// SyscallArgs returns the system call number and its arguments, the
+	// values are only meaningful at syscall entry.
+	SyscallArgs() (num uint64, args [6]uint64)
+	// SyscallRet returns the return value of the system call, the value is
+	// only meaningful at syscall exit.
+	SyscallRet() int64
+	// SyscallEntry returns true if the registers were read at a
+	// syscall-enter-stop rather than at a syscall-exit-stop.
+	SyscallEntry() bool
+}
+
+// enosys is the value of the return register at syscall-enter-stop on
+// amd64 and 386, the kernel sets it to -ENOSYS before reporting the stop.
+const enosys = 38
+
+// SyscallName returns the name of system call num on goarch, or the
+// number itself if the name is not known.
+func SyscallName(goarch string, num uint64) string {
+	if name, ok := syscallNames[goarch][num]; ok {
+		return name
+	}
+	return strconv.FormatUint(num, 10)
+}
+
+// SyscallNumber returns the number of the system call called name on
+// goarch. Name can also be a system call number.
+func SyscallNumber(goarch string, name string) (uint64, error) {
+	if num, err := strconv.ParseUint(name, 0, 64); err == nil {
+		return num, nil
+	}
+	for num, name2 := range syscallNames[goarch] {
+		if name2 == name {
+			return num, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown system call %q", name)
+}
+
+// SyscallArgs returns the system call number (from Orig_rax) and its
+// arguments (from Rdi, Rsi, Rdx, R10, R8 and R9).
+func (r *AMD64Registers) SyscallArgs() (num uint64, args [6]uint64) {
+	return r.Regs.Orig_rax, [6]uint64{r.Regs.Rdi, r.Regs.Rsi, r.Regs.Rdx, r.Regs.R10, r.Regs.R8, r.Regs.R9}
+}
+
+// SyscallRet returns the value of Rax.
+func (r *AMD64Registers) SyscallRet() int64 {
+	return int64(r.Regs.Rax)
+}
+
+// SyscallEntry returns true if Rax is -ENOSYS.
+func (r *AMD64Registers) SyscallEntry() bool {
+	return int64(r.Regs.Rax) == -enosys
+}
+
+// SyscallArgs returns the system call number (from X8) and its arguments
+// (from X0 to X5).
+func (r *ARM64Registers) SyscallArgs() (num uint64, args [6]uint64) {
+	var x = &r.Regs.Regs
+	return x[8], [6]uint64{x[0], x[1], x[2], x[3], x[4], x[5]}
+}
+
+// SyscallRet returns the value of X0.
+func (r *ARM64Registers) SyscallRet() int64 {
+	return int64(r.Regs.Regs[0])
+}
+
+// SyscallEntry returns true if X7 is zero, the kernel sets X7 to 0 at
+// syscall-enter-stop and to 1 at syscall-exit-stop.
+func (r *ARM64Registers) SyscallEntry() bool {
+	return r.Regs.Regs[7] == 0
+}
+
+// SyscallArgs returns the system call number (from Orig_eax) and its
+// arguments (from Ebx, Ecx, Edx, Esi, Edi and Ebp).
+func (r *I386Registers) SyscallArgs() (num uint64, args [6]uint64) {
+	return uint64(uint32(r.Regs.Orig_eax)), [6]uint64{uint64(uint32(r.Regs.Ebx)), uint64(uint32(r.Regs.Ecx)), uint64(uint32(r.Regs.Edx)), uint64(uint32(r.Regs.Esi)), uint64(uint32(r.Regs.Edi)), uint64(uint32(r.Regs.Ebp))}
+}
+
+// SyscallRet returns the value of Eax.
+func (r *I386Registers) SyscallRet() int64 {
+	return int64(r.Regs.Eax)
+}
+
+// SyscallEntry returns true if Eax is -ENOSYS.
+func (r *I386Registers) SyscallEntry() bool {
+	return r.Regs.Eax == -enosys
+}
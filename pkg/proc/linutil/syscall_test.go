@@ -0,0 +1,50 @@
+package linutil
+
+import "testing"
+
+func TestSyscallNames(t *testing.T) {
+	for _, tc := range []struct {
+		goarch string
+		name   string
+		num    uint64
+	}{
+		{"amd64", "write", 1},
+		{"amd64", "exit_group", 231},
+		{"arm64", "write", 64},
+		{"386", "write", 4},
+	} {
+		if name := SyscallName(tc.goarch, tc.num); name != tc.name {
+			t.Errorf("%s: SyscallName(%d) = %q, expected %q", tc.goarch, tc.num, name, tc.name)
+		}
+		num, err := SyscallNumber(tc.goarch, tc.name)
+		if err != nil || num != tc.num {
+			t.Errorf("%s: SyscallNumber(%q) = %d, %v, expected %d", tc.goarch, tc.name, num, err, tc.num)
+		}
+	}
+	if num, err := SyscallNumber("amd64", "0x3c"); err != nil || num != 60 {
+		t.Errorf("SyscallNumber(\"0x3c\") = %d, %v", num, err)
+	}
+	if _, err := SyscallNumber("amd64", "nosuchsyscall"); err == nil {
+		t.Errorf("expected error for unknown system call")
+	}
+}
+
+func TestSyscallEntry(t *testing.T) {
+	const negENOSYS = 1<<64 - 38
+	for _, tc := range []struct {
+		name  string
+		regs  SyscallRegisters
+		entry bool
+	}{
+		{"amd64 entry", &AMD64Registers{Regs: &AMD64PtraceRegs{Rax: negENOSYS, Orig_rax: 1}}, true},
+		{"amd64 exit", &AMD64Registers{Regs: &AMD64PtraceRegs{Rax: 5, Orig_rax: 1}}, false},
+		{"arm64 entry", &ARM64Registers{Regs: &ARM64PtraceRegs{Regs: [31]uint64{7: 0, 8: 64}}}, true},
+		{"arm64 exit", &ARM64Registers{Regs: &ARM64PtraceRegs{Regs: [31]uint64{7: 1, 8: 64}}}, false},
+		{"386 entry", &I386Registers{Regs: &I386PtraceRegs{Eax: -38, Orig_eax: 4}}, true},
+		{"386 exit", &I386Registers{Regs: &I386PtraceRegs{Eax: 5, Orig_eax: 4}}, false},
+	} {
+		if entry := tc.regs.SyscallEntry(); entry != tc.entry {
+			t.Errorf("%s: SyscallEntry() = %v, expected %v", tc.name, entry, tc.entry)
+		}
+	}
+}
@@ -97,7 +97,8 @@ type compositeMemory struct {
 func newCompositeMemory(mem MemoryReadWriter, regs op.DwarfRegisters, pieces []op.Piece) (*compositeMemory, error) {
 	cmem := &compositeMemory{realmem: mem, regs: regs, pieces: pieces, data: []byte{}}
 	for _, piece := range pieces {
-		if piece.IsRegister {
+		switch {
+		case piece.IsRegister:
 			reg := regs.Bytes(piece.RegNum)
 			sz := piece.Size
 			if sz == 0 && len(pieces) == 1 {
@@ -110,7 +111,9 @@ func newCompositeMemory(mem MemoryReadWriter, regs op.DwarfRegisters, pieces []o
 				return nil, fmt.Errorf("could not read %d bytes from register %d (size: %d)", sz, piece.RegNum, len(reg))
 			}
 			cmem.data = append(cmem.data, reg[:sz]...)
-		} else {
+		case piece.IsEmpty:
+			cmem.data = append(cmem.data, make([]byte, piece.Size)...)
+		default:
 			buf := make([]byte, piece.Size)
 			mem.ReadMemory(buf, uintptr(piece.Addr))
 			cmem.data = append(cmem.data, buf...)
@@ -128,9 +131,49 @@ func (mem *compositeMemory) ReadMemory(data []byte, addr uintptr) (int, error) {
 	return len(data), nil
 }
 
+// WriteMemory writes data at addr, the pieces that overlap the written
+// range are written back to the registers (using the ChangeFunc of the
+// registers) and memory they are stored in.
 func (mem *compositeMemory) WriteMemory(addr uintptr, data []byte) (int, error) {
-	//TODO(aarzilli): implement
-	return 0, errors.New("can't write composite memory")
+	addr -= fakeAddress
+	if addr >= uintptr(len(mem.data)) || addr+uintptr(len(data)) > uintptr(len(mem.data)) {
+		return 0, errors.New("write out of bounds")
+	}
+	copy(mem.data[addr:], data)
+
+	end := addr + uintptr(len(data))
+	cur := uintptr(0)
+	for _, piece := range mem.pieces {
+		sz := uintptr(piece.Size)
+		if sz == 0 && len(mem.pieces) == 1 {
+			sz = uintptr(len(mem.data))
+		}
+		if cur < end && cur+sz > addr {
+			buf := mem.data[cur : cur+sz]
+			switch {
+			case piece.IsRegister:
+				if mem.regs.ChangeFunc == nil {
+					return 0, errors.New("can't write composite memory: registers can not be changed")
+				}
+				newReg := op.DwarfRegisterFromBytes(append([]byte(nil), buf...))
+				if oldReg := mem.regs.Reg(piece.RegNum); oldReg != nil {
+					newReg = oldReg.Overwrite(newReg)
+				}
+				if err := mem.regs.ChangeFunc(piece.RegNum, newReg); err != nil {
+					return 0, err
+				}
+				mem.regs.AddReg(piece.RegNum, newReg)
+			case piece.IsEmpty:
+				// nothing to write
+			default:
+				if _, err := mem.realmem.WriteMemory(uintptr(piece.Addr), buf); err != nil {
+					return 0, err
+				}
+			}
+		}
+		cur += sz
+	}
+	return len(data), nil
 }
 
 // DereferenceMemory returns a MemoryReadWriter that can read and write the
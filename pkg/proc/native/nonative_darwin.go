@@ -6,18 +6,19 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
 var ErrNativeBackendDisabled = errors.New("native backend disabled during compilation")
 
 // Launch returns ErrNativeBackendDisabled.
-func Launch(_ []string, _ string, _ bool, _ []string, _ string) (*proc.Target, error) {
+func Launch(_ []string, _ string, _ bool, _ proc.DebugInfoConfig, _ string) (*proc.Target, error) {
 	return nil, ErrNativeBackendDisabled
 }
 
 // Attach returns ErrNativeBackendDisabled.
-func Attach(_ int, _ []string) (*proc.Target, error) {
+func Attach(_ int, _ proc.DebugInfoConfig) (*proc.Target, error) {
 	return nil, ErrNativeBackendDisabled
 }
 
@@ -101,6 +102,11 @@ func (t *nativeThread) SetDX(dx uint64) error {
 	panic(ErrNativeBackendDisabled)
 }
 
+// SetReg changes the value of the specified register.
+func (t *nativeThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
+	panic(ErrNativeBackendDisabled)
+}
+
 // ReadMemory reads len(buf) bytes at addr into buf.
 func (t *nativeThread) ReadMemory(buf []byte, addr uintptr) (int, error) {
 	panic(ErrNativeBackendDisabled)
@@ -237,6 +237,8 @@ func (dbp *nativeProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
 
 	for _, th := range dbp.threads {
 		th.CurrentBreakpoint.Clear()
+		th.syscallStop = nil
+		th.stopSignal = 0
 	}
 
 	if dbp.resumeChan != nil {
@@ -251,6 +253,12 @@ func (dbp *nativeProcess) ContinueOnce() (proc.Thread, proc.StopReason, error) {
 	if err := dbp.stop(trapthread); err != nil {
 		return nil, proc.StopUnknown, err
 	}
+	if trapthread.syscallStop != nil {
+		return trapthread, proc.StopSyscall, nil
+	}
+	if trapthread.stopSignal != 0 {
+		return trapthread, proc.StopSignal, nil
+	}
 	return trapthread, proc.StopUnknown, err
 }
 
@@ -271,7 +279,7 @@ func (dbp *nativeProcess) FindBreakpoint(pc uint64, adjustPC bool) (*proc.Breakp
 
 // initialize will ensure that all relevant information is loaded
 // so the process is ready to be debugged.
-func (dbp *nativeProcess) initialize(path string, debugInfoDirs []string) (*proc.Target, error) {
+func (dbp *nativeProcess) initialize(path string, debugInfo proc.DebugInfoConfig) (*proc.Target, error) {
 	if err := initialize(dbp); err != nil {
 		return nil, err
 	}
@@ -284,7 +292,7 @@ func (dbp *nativeProcess) initialize(path string, debugInfoDirs []string) (*proc
 	}
 	return proc.NewTarget(dbp, proc.NewTargetConfig{
 		Path:                path,
-		DebugInfoDirs:       debugInfoDirs,
+		DebugInfo:           debugInfo,
 		DisableAsyncPreempt: runtime.GOOS == "windows" || runtime.GOOS == "freebsd",
 		StopReason:          stopReason})
 }
@@ -37,7 +37,7 @@ type osProcessDetails struct {
 // custom fork/exec process in order to take advantage of
 // PT_SIGEXC on Darwin which will turn Unix signals into
 // Mach exceptions.
-func Launch(cmd []string, wd string, foreground bool, _ []string, _ string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, foreground bool, _ proc.DebugInfoConfig, _ string) (*proc.Target, error) {
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
@@ -120,7 +120,7 @@ func Launch(cmd []string, wd string, foreground bool, _ []string, _ string) (*pr
 	dbp.os.initialized = true
 	dbp.currentThread = trapthread
 
-	tgt, err := dbp.initialize(argv0Go, []string{})
+	tgt, err := dbp.initialize(argv0Go, proc.DebugInfoConfig{})
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +129,7 @@ func Launch(cmd []string, wd string, foreground bool, _ []string, _ string) (*pr
 }
 
 // Attach to an existing process with the given PID.
-func Attach(pid int, _ []string) (*proc.Target, error) {
+func Attach(pid int, _ proc.DebugInfoConfig) (*proc.Target, error) {
 	dbp := newProcess(pid)
 
 	kret := C.acquire_mach_task(C.int(pid),
@@ -152,7 +152,7 @@ func Attach(pid int, _ []string) (*proc.Target, error) {
 		return nil, err
 	}
 
-	tgt, err := dbp.initialize("", []string{})
+	tgt, err := dbp.initialize("", proc.DebugInfoConfig{})
 	if err != nil {
 		dbp.Detach(false)
 		return nil, err
@@ -42,14 +42,29 @@ const (
 // process details.
 type osProcessDetails struct {
 	comm string
+
+	// syscallCatchpoint selects the system calls that stop the process, if
+	// it is not nil threads are resumed with PTRACE_SYSCALL.
+	syscallCatchpoint *proc.SyscallCatchpoint
+
+	// signalPolicies are the signal handling policies, signals without a
+	// policy use proc.DefaultSignalPolicy.
+	signalPolicies map[int]proc.SignalPolicy
+	// receivedSignals are the signals with the Print or Stop policy
+	// received since the process was last resumed.
+	receivedSignals []proc.ReceivedSignal
 }
 
+// syscallTrap is the signal reported for syscall stops when the
+// PTRACE_O_TRACESYSGOOD option is set.
+const syscallTrap = sys.SIGTRAP | 0x80
+
 // Launch creates and begins debugging a new process. First entry in
 // `cmd` is the program to run, and then rest are the arguments
 // to be supplied to that process. `wd` is working directory of the program.
 // If the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tty string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, foreground bool, debugInfo proc.DebugInfoConfig, tty string) (*proc.Target, error) {
 	var (
 		process *exec.Cmd
 		err     error
@@ -101,7 +116,7 @@ func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tt
 	if err != nil {
 		return nil, fmt.Errorf("waiting for target execve failed: %s", err)
 	}
-	tgt, err := dbp.initialize(cmd[0], debugInfoDirs)
+	tgt, err := dbp.initialize(cmd[0], debugInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +126,7 @@ func Launch(cmd []string, wd string, foreground bool, debugInfoDirs []string, tt
 // Attach to an existing process with the given PID. Once attached, if
 // the DWARF information cannot be found in the binary, Delve will look
 // for external debug files in the directories passed in.
-func Attach(pid int, debugInfoDirs []string) (*proc.Target, error) {
+func Attach(pid int, debugInfo proc.DebugInfoConfig) (*proc.Target, error) {
 	dbp := newProcess(pid)
 
 	var err error
@@ -129,7 +144,7 @@ func Attach(pid int, debugInfoDirs []string) (*proc.Target, error) {
 		return nil, err
 	}
 
-	tgt, err := dbp.initialize(execPath, debugInfoDirs)
+	tgt, err := dbp.initialize(execPath, debugInfo)
 	if err != nil {
 		_ = dbp.Detach(false)
 		return nil, err
@@ -219,12 +234,13 @@ func (dbp *nativeProcess) addThread(tid int, attach bool) (*nativeThread, error)
 		}
 	}
 
-	dbp.execPtraceFunc(func() { err = syscall.PtraceSetOptions(tid, syscall.PTRACE_O_TRACECLONE) })
+	const ptraceOptions = syscall.PTRACE_O_TRACECLONE | sys.PTRACE_O_TRACESYSGOOD
+	dbp.execPtraceFunc(func() { err = syscall.PtraceSetOptions(tid, ptraceOptions) })
 	if err == syscall.ESRCH {
 		if _, _, err = dbp.waitFast(tid); err != nil {
 			return nil, fmt.Errorf("error while waiting after adding thread: %d %s", tid, err)
 		}
-		dbp.execPtraceFunc(func() { err = syscall.PtraceSetOptions(tid, syscall.PTRACE_O_TRACECLONE) })
+		dbp.execPtraceFunc(func() { err = syscall.PtraceSetOptions(tid, ptraceOptions) })
 		if err == syscall.ESRCH {
 			return nil, err
 		}
@@ -349,6 +365,20 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 			// Sometimes we get an unknown thread, ignore it?
 			continue
 		}
+		if status.StopSignal() == syscallTrap {
+			stopped, err := th.handleSyscallStop(halt)
+			if err != nil {
+				if err == sys.ESRCH {
+					dbp.postExit()
+					return nil, proc.ErrProcessExited{Pid: dbp.pid}
+				}
+				return nil, err
+			}
+			if stopped {
+				return th, nil
+			}
+			continue
+		}
 		if (halt && status.StopSignal() == sys.SIGSTOP) || (status.StopSignal() == sys.SIGTRAP) {
 			th.os.running = false
 			if status.StopSignal() == sys.SIGTRAP {
@@ -357,17 +387,29 @@ func (dbp *nativeProcess) trapWaitInternal(pid int, options trapWaitOptions) (*n
 			return th, nil
 		}
 
-		// TODO(dp) alert user about unexpected signals here.
+		sig := int(status.StopSignal())
+		policy := dbp.signalPolicy(sig)
+		if policy.Print || policy.Stop {
+			dbp.os.receivedSignals = append(dbp.os.receivedSignals, proc.ReceivedSignal{ThreadID: th.ID, Signal: sig})
+		}
+		if !policy.Pass {
+			sig = 0
+		}
 		if halt && !th.os.running {
 			// We are trying to stop the process, queue this signal to be delivered
 			// to the thread when we resume.
 			// Do not do this for threads that were running because we sent them a
 			// STOP signal and we need to observe it so we don't mistakenly deliver
 			// it later.
-			th.os.delayedSignal = int(status.StopSignal())
+			th.os.delayedSignal = sig
 			th.os.running = false
 			return th, nil
-		} else if err := th.resumeWithSig(int(status.StopSignal())); err != nil {
+		} else if policy.Stop && !halt {
+			th.os.delayedSignal = sig
+			th.os.running = false
+			th.stopSignal = int(status.StopSignal())
+			return th, nil
+		} else if err := th.resumeWithSig(sig); err != nil {
 			if err == sys.ESRCH {
 				dbp.postExit()
 				return nil, proc.ErrProcessExited{Pid: dbp.pid}
@@ -450,6 +492,7 @@ func (dbp *nativeProcess) exitGuard(err error) error {
 }
 
 func (dbp *nativeProcess) resume() error {
+	dbp.os.receivedSignals = nil
 	// all threads stopped over a breakpoint are made to step over it
 	for _, thread := range dbp.threads {
 		if thread.CurrentBreakpoint.Breakpoint != nil {
@@ -477,7 +520,7 @@ func (dbp *nativeProcess) stop(trapthread *nativeThread) (err error) {
 	for _, th := range dbp.threads {
 		th.os.setbp = false
 	}
-	trapthread.os.setbp = true
+	trapthread.os.setbp = trapthread.syscallStop == nil && trapthread.stopSignal == 0
 
 	// check if any other thread simultaneously received a SIGTRAP
 	for {
@@ -564,6 +607,61 @@ func (dbp *nativeProcess) EntryPoint() (uint64, error) {
 	return linutil.EntryPointFromAuxv(auxvbuf, dbp.bi.Arch.PtrSize()), nil
 }
 
+// DynamicLoaderBreakpoint returns the address of the function called by the
+// dynamic loader every time it changes the list of loaded libraries.
+func (dbp *nativeProcess) DynamicLoaderBreakpoint() (uint64, error) {
+	auxvbuf, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/auxv", dbp.pid))
+	if err != nil {
+		return 0, fmt.Errorf("could not read auxiliary vector: %v", err)
+	}
+	return linutil.ElfDynamicLoaderBreakpoint(dbp, auxvbuf)
+}
+
+// SetSyscallCatchpoint selects the system calls that will stop the target
+// process. Takes effect the next time the process is resumed.
+func (dbp *nativeProcess) SetSyscallCatchpoint(cp *proc.SyscallCatchpoint) error {
+	if dbp.exited {
+		return &proc.ErrProcessExited{Pid: dbp.Pid()}
+	}
+	dbp.os.syscallCatchpoint = cp
+	return nil
+}
+
+// SyscallStop returns the system call thread is stopped at, or nil.
+func (dbp *nativeProcess) SyscallStop(thread proc.Thread) *proc.SyscallStop {
+	th, ok := thread.(*nativeThread)
+	if !ok {
+		return nil
+	}
+	return th.syscallStop
+}
+
+// SetSignalPolicy sets the policy used to handle signal sig.
+func (dbp *nativeProcess) SetSignalPolicy(sig int, policy proc.SignalPolicy) error {
+	switch sys.Signal(sig) {
+	case sys.SIGTRAP, sys.SIGKILL:
+		return fmt.Errorf("can not change the handling policy of %s", sys.SignalName(sys.Signal(sig)))
+	}
+	if dbp.os.signalPolicies == nil {
+		dbp.os.signalPolicies = make(map[int]proc.SignalPolicy)
+	}
+	dbp.os.signalPolicies[sig] = policy
+	return nil
+}
+
+// ReceivedSignals returns the signals with either the Print or the Stop
+// policy received since the process was last resumed.
+func (dbp *nativeProcess) ReceivedSignals() []proc.ReceivedSignal {
+	return dbp.os.receivedSignals
+}
+
+func (dbp *nativeProcess) signalPolicy(sig int) proc.SignalPolicy {
+	if policy, ok := dbp.os.signalPolicies[sig]; ok {
+		return policy
+	}
+	return proc.DefaultSignalPolicy
+}
+
 func killProcess(pid int) error {
 	return sys.Kill(pid, sys.SIGINT)
 }
@@ -20,7 +20,7 @@ type osProcessDetails struct {
 }
 
 // Launch creates and begins debugging a new process.
-func Launch(cmd []string, wd string, foreground bool, _ []string, _ string) (*proc.Target, error) {
+func Launch(cmd []string, wd string, foreground bool, _ proc.DebugInfoConfig, _ string) (*proc.Target, error) {
 	argv0Go, err := filepath.Abs(cmd[0])
 	if err != nil {
 		return nil, err
@@ -49,7 +49,7 @@ func Launch(cmd []string, wd string, foreground bool, _ []string, _ string) (*pr
 	dbp.pid = p.Pid
 	dbp.childProcess = true
 
-	tgt, err := dbp.initialize(argv0Go, []string{})
+	tgt, err := dbp.initialize(argv0Go, proc.DebugInfoConfig{})
 	if err != nil {
 		dbp.Detach(true)
 		return nil, err
@@ -125,7 +125,7 @@ func findExePath(pid int) (string, error) {
 }
 
 // Attach to an existing process with the given PID.
-func Attach(pid int, _ []string) (*proc.Target, error) {
+func Attach(pid int, _ proc.DebugInfoConfig) (*proc.Target, error) {
 	dbp := newProcess(pid)
 	var err error
 	dbp.execPtraceFunc(func() {
@@ -139,7 +139,7 @@ func Attach(pid int, _ []string) (*proc.Target, error) {
 	if err != nil {
 		return nil, err
 	}
-	tgt, err := dbp.initialize(exepath, []string{})
+	tgt, err := dbp.initialize(exepath, proc.DebugInfoConfig{})
 	if err != nil {
 		dbp.Detach(true)
 		return nil, err
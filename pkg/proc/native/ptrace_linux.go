@@ -2,6 +2,7 @@ package native
 
 import (
 	"syscall"
+	"unsafe"
 
 	sys "golang.org/x/sys/unix"
 )
@@ -24,3 +25,37 @@ func ptraceDetach(tid, sig int) error {
 func ptraceCont(tid, sig int) error {
 	return sys.PtraceCont(tid, sig)
 }
+
+// ptraceSyscall executes ptrace PTRACE_SYSCALL
+func ptraceSyscall(tid, sig int) error {
+	return sys.PtraceSyscall(tid, sig)
+}
+
+const (
+	_PTRACE_GET_SYSCALL_INFO = 0x420e
+
+	_PTRACE_SYSCALL_INFO_ENTRY = 1
+	_PTRACE_SYSCALL_INFO_EXIT  = 2
+)
+
+// ptraceSyscallInfo is struct ptrace_syscall_info, the union describing
+// the system call is not decoded.
+type ptraceSyscallInfo struct {
+	Op                 uint8
+	_                  [3]uint8
+	Arch               uint32
+	InstructionPointer uint64
+	StackPointer       uint64
+	Data               [8]uint64
+}
+
+// ptraceGetSyscallInfo executes ptrace PTRACE_GET_SYSCALL_INFO, available
+// since Linux 5.3.
+func ptraceGetSyscallInfo(tid int) (*ptraceSyscallInfo, error) {
+	var info ptraceSyscallInfo
+	_, _, err := sys.Syscall6(sys.SYS_PTRACE, _PTRACE_GET_SYSCALL_INFO, uintptr(tid), unsafe.Sizeof(info), uintptr(unsafe.Pointer(&info)), 0, 0)
+	if err != syscall.Errno(0) {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -11,6 +11,7 @@ import (
 
 	"golang.org/x/arch/x86/x86asm"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -128,6 +129,10 @@ func (thread *nativeThread) SetDX(dx uint64) error {
 	return errors.New("not implemented")
 }
 
+func (thread *nativeThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
+	return errors.New("not implemented")
+}
+
 func (r *Regs) Get(n int) (uint64, error) {
 	reg := x86asm.Reg(n)
 	const (
@@ -5,6 +5,7 @@ import (
 
 	sys "golang.org/x/sys/unix"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/linutil"
 )
@@ -46,6 +47,19 @@ func (thread *nativeThread) SetDX(dx uint64) (err error) {
 	return
 }
 
+// SetReg changes the value of the specified register.
+func (thread *nativeThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
+	ir, err := registers(thread)
+	if err != nil {
+		return err
+	}
+	r := ir.(*linutil.AMD64Registers)
+	if err := r.SetReg(regNum, reg); err != nil {
+		return err
+	}
+	return thread.restoreRegisters(r)
+}
+
 func registers(thread *nativeThread) (proc.Registers, error) {
 	var (
 		regs linutil.AMD64PtraceRegs
@@ -8,6 +8,7 @@ import (
 
 	sys "golang.org/x/sys/unix"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/linutil"
 )
@@ -83,6 +84,10 @@ func (thread *nativeThread) SetDX(dx uint64) (err error) {
 	return fmt.Errorf("not supported")
 }
 
+func (thread *nativeThread) SetReg(regNum uint64, reg *op.DwarfRegister) error {
+	return fmt.Errorf("not supported")
+}
+
 func registers(thread *nativeThread) (proc.Registers, error) {
 	var (
 		regs linutil.Mips64PtraceRegs
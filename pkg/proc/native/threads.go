@@ -20,6 +20,13 @@ type nativeThread struct {
 	singleStepping bool
 	os             *osSpecificDetails
 	common         proc.CommonThread
+
+	// syscallStop describes the system call the thread is stopped at, if it
+	// was stopped by a syscall catchpoint.
+	syscallStop *proc.SyscallStop
+	// stopSignal is the signal that stopped the thread, if it was stopped by
+	// a signal with the Stop policy.
+	stopSignal int
 }
 
 // Continue the execution of this thread.
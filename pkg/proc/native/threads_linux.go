@@ -2,10 +2,12 @@ package native
 
 import (
 	"fmt"
+	"runtime"
 
 	sys "golang.org/x/sys/unix"
 
 	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/linutil"
 )
 
 type waitStatus sys.WaitStatus
@@ -17,6 +19,11 @@ type osSpecificDetails struct {
 	registers     sys.PtraceRegs
 	running       bool
 	setbp         bool
+
+	// syscallEntry describes the system call entered at the last
+	// syscall-enter-stop, its arguments are not guaranteed to be preserved
+	// in registers until syscall-exit-stop.
+	syscallEntry *proc.SyscallStop
 }
 
 func (t *nativeThread) stop() (err error) {
@@ -43,11 +50,83 @@ func (t *nativeThread) resume() error {
 
 func (t *nativeThread) resumeWithSig(sig int) (err error) {
 	t.os.running = true
+	if t.dbp.os.syscallCatchpoint != nil {
+		t.dbp.execPtraceFunc(func() { err = ptraceSyscall(t.ID, sig) })
+		return
+	}
+	// resuming with anything other than PTRACE_SYSCALL suppresses
+	// syscall-exit-stop
+	t.os.syscallEntry = nil
 	t.dbp.execPtraceFunc(func() { err = ptraceCont(t.ID, sig) })
 	return
 }
 
+// handleSyscallStop is called when the thread stops at the entry or exit
+// of a system call, it returns true if the thread must stay stopped
+// because the system call is selected by the syscall catchpoint.
+// If halt is true the process is being stopped and the thread is resumed
+// so that it can receive the SIGSTOP signal we sent it.
+func (t *nativeThread) handleSyscallStop(halt bool) (bool, error) {
+	if cp := t.dbp.os.syscallCatchpoint; cp != nil && !halt {
+		stop, err := t.readSyscallStop()
+		if err != nil {
+			return false, err
+		}
+		if cp.Match(stop.Num) {
+			t.os.running = false
+			stop.ThreadID = t.ID
+			t.syscallStop = stop
+			return true, nil
+		}
+	}
+	return false, t.resumeWithSig(0)
+}
+
+// readSyscallStop describes the system call the thread is stopped at using
+// its registers.
+// Syscall-enter-stop and syscall-exit-stop are told apart with
+// PTRACE_GET_SYSCALL_INFO or, on kernels that do not support it, with the
+// registers: counting stops is not reliable because a stop can be
+// suppressed (for example by a signal or by resuming the thread with
+// PTRACE_CONT while the syscall catchpoint is being set).
+func (t *nativeThread) readSyscallStop() (*proc.SyscallStop, error) {
+	regs, err := registers(t)
+	if err != nil {
+		return nil, err
+	}
+	sregs, ok := regs.(linutil.SyscallRegisters)
+	if !ok {
+		return nil, fmt.Errorf("syscall catchpoints not supported on %s", runtime.GOARCH)
+	}
+	exit := !sregs.SyscallEntry()
+	var info *ptraceSyscallInfo
+	t.dbp.execPtraceFunc(func() { info, err = ptraceGetSyscallInfo(t.ID) })
+	if err == nil {
+		switch info.Op {
+		case _PTRACE_SYSCALL_INFO_ENTRY:
+			exit = false
+		case _PTRACE_SYSCALL_INFO_EXIT:
+			exit = true
+		}
+	}
+	if !exit {
+		num, args := sregs.SyscallArgs()
+		t.os.syscallEntry = &proc.SyscallStop{Num: num, Name: linutil.SyscallName(runtime.GOARCH, num), Args: args}
+		return t.os.syscallEntry, nil
+	}
+	stop := &proc.SyscallStop{Exit: true, Ret: sregs.SyscallRet()}
+	if entry := t.os.syscallEntry; entry != nil {
+		stop.Num, stop.Name, stop.Args = entry.Num, entry.Name, entry.Args
+		t.os.syscallEntry = nil
+	} else {
+		stop.Num, stop.Args = sregs.SyscallArgs()
+		stop.Name = linutil.SyscallName(runtime.GOARCH, stop.Num)
+	}
+	return stop, nil
+}
+
 func (t *nativeThread) singleStep() (err error) {
+	t.os.syscallEntry = nil
 	for {
 		t.dbp.execPtraceFunc(func() { err = sys.PtraceSingleStep(t.ID) })
 		if err != nil {
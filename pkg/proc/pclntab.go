@@ -0,0 +1,834 @@
+package proc
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/frame"
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/line"
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// This file implements a fallback for executables that do not contain
+// DWARF debug information (for example because they were linked with
+// -ldflags=-w): functions, line tables and the frame sizes needed for
+// stack unwinding are recovered from runtime.pclntab, the table used by
+// the Go runtime itself to print stack traces.
+// See $GOROOT/src/debug/gosym/pclntab.go and $GOROOT/src/runtime/symtab.go.
+
+type pclntabVersion int
+
+const (
+	pclntabVerUnknown pclntabVersion = iota
+	pclntabVer12
+	pclntabVer116
+	pclntabVer118
+	pclntabVer120
+)
+
+const (
+	pclntabMagic12  = 0xfffffffb
+	pclntabMagic116 = 0xfffffffa
+	pclntabMagic118 = 0xfffffff0
+	pclntabMagic120 = 0xfffffff1
+)
+
+var errPclntabFormat = errors.New("unsupported or corrupt pclntab")
+
+// pclntab is a parsed runtime.pclntab.
+type pclntab struct {
+	data      []byte
+	version   pclntabVersion
+	quantum   uint32
+	ptrSize   int
+	textStart uint64
+
+	nfunc       int
+	functab     []byte
+	funcdata    []byte
+	funcnametab []byte
+	cutab       []byte
+	filetab     []byte
+	pctab       []byte
+}
+
+// pclnFunc describes a function listed in pclntab.
+type pclnFunc struct {
+	name               string
+	entry, end         uint64
+	pcsp, pcfile, pcln uint32
+	cuOffset           uint32
+}
+
+// parsePclntab parses the contents of runtime.pclntab. The textStart
+// argument is the address of runtime.text and is used for pclntab
+// versions that store function entry points as offsets from it.
+func parsePclntab(data []byte, textStart uint64) (*pclntab, error) {
+	if len(data) < 16 || data[4] != 0 || data[5] != 0 {
+		return nil, errPclntabFormat
+	}
+	t := &pclntab{data: data, quantum: uint32(data[6]), ptrSize: int(data[7]), textStart: textStart}
+	if t.ptrSize != 4 && t.ptrSize != 8 {
+		return nil, errPclntabFormat
+	}
+	switch binary.LittleEndian.Uint32(data) {
+	case pclntabMagic12:
+		t.version = pclntabVer12
+	case pclntabMagic116:
+		t.version = pclntabVer116
+	case pclntabMagic118:
+		t.version = pclntabVer118
+	case pclntabMagic120:
+		t.version = pclntabVer120
+	default:
+		return nil, errPclntabFormat
+	}
+
+	word := func(i int) uint64 {
+		off := 8 + i*t.ptrSize
+		if off+t.ptrSize > len(data) {
+			return 0
+		}
+		return t.uintptr(data[off:])
+	}
+	section := func(i int) []byte {
+		off := word(i)
+		if off >= uint64(len(data)) {
+			return nil
+		}
+		return data[off:]
+	}
+
+	t.nfunc = int(word(0))
+	switch t.version {
+	case pclntabVer118, pclntabVer120:
+		if ts := word(2); ts != 0 {
+			t.textStart = ts
+		}
+		t.funcnametab = section(3)
+		t.cutab = section(4)
+		t.filetab = section(5)
+		t.pctab = section(6)
+		t.funcdata = section(7)
+		t.functab = t.funcdata
+	case pclntabVer116:
+		t.funcnametab = section(2)
+		t.cutab = section(3)
+		t.filetab = section(4)
+		t.pctab = section(5)
+		t.funcdata = section(6)
+		t.functab = t.funcdata
+	case pclntabVer12:
+		t.funcnametab = data
+		t.pctab = data
+		t.funcdata = data
+		t.functab = data[8+t.ptrSize:]
+		functabsize := (t.nfunc*2 + 1) * t.ptrSize
+		if functabsize+4 > len(t.functab) {
+			return nil, errPclntabFormat
+		}
+		fileoff := binary.LittleEndian.Uint32(t.functab[functabsize:])
+		if uint64(fileoff) >= uint64(len(data)) {
+			return nil, errPclntabFormat
+		}
+		t.filetab = data[fileoff:]
+	}
+	if t.functab == nil || t.funcdata == nil || t.funcnametab == nil || t.pctab == nil || t.filetab == nil {
+		return nil, errPclntabFormat
+	}
+	if (t.nfunc*2+1)*t.functabFieldSize() > len(t.functab) {
+		return nil, errPclntabFormat
+	}
+	return t, nil
+}
+
+func (t *pclntab) uintptr(b []byte) uint64 {
+	if t.ptrSize == 4 {
+		return uint64(binary.LittleEndian.Uint32(b))
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// functabFieldSize returns the size of a field of the functab.
+func (t *pclntab) functabFieldSize() int {
+	if t.version >= pclntabVer118 {
+		return 4
+	}
+	return t.ptrSize
+}
+
+// functabPC returns the entry point of the i-th function of the functab,
+// as an absolute address.
+func (t *pclntab) functabPC(i int) uint64 {
+	sz := t.functabFieldSize()
+	b := t.functab[2*i*sz:]
+	if t.version >= pclntabVer118 {
+		return t.textStart + uint64(binary.LittleEndian.Uint32(b))
+	}
+	return t.uintptr(b)
+}
+
+// functabFuncoff returns the offset of the _func struct of the i-th
+// function of the functab.
+func (t *pclntab) functabFuncoff(i int) uint64 {
+	sz := t.functabFieldSize()
+	b := t.functab[(2*i+1)*sz:]
+	if t.version >= pclntabVer118 {
+		return uint64(binary.LittleEndian.Uint32(b))
+	}
+	return t.uintptr(b)
+}
+
+// funcField returns the n-th 32bit field of the _func struct at funcoff,
+// counting from nameOff (n == 1).
+func (t *pclntab) funcField(funcoff uint64, n int) uint32 {
+	sz := t.ptrSize
+	if t.version >= pclntabVer118 {
+		sz = 4
+	}
+	off := funcoff + uint64(sz) + uint64(n-1)*4
+	if off+4 > uint64(len(t.funcdata)) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(t.funcdata[off:])
+}
+
+// funcs returns the list of functions contained in pclntab, sorted by
+// entry point.
+func (t *pclntab) funcs() []pclnFunc {
+	const (
+		fieldNameOff  = 1
+		fieldPcsp     = 4
+		fieldPcfile   = 5
+		fieldPcln     = 6
+		fieldCuOffset = 8
+	)
+	r := make([]pclnFunc, 0, t.nfunc)
+	for i := 0; i < t.nfunc; i++ {
+		funcoff := t.functabFuncoff(i)
+		fn := pclnFunc{
+			name:   t.funcName(t.funcField(funcoff, fieldNameOff)),
+			entry:  t.functabPC(i),
+			end:    t.functabPC(i + 1),
+			pcsp:   t.funcField(funcoff, fieldPcsp),
+			pcfile: t.funcField(funcoff, fieldPcfile),
+			pcln:   t.funcField(funcoff, fieldPcln),
+		}
+		if t.version >= pclntabVer116 {
+			fn.cuOffset = t.funcField(funcoff, fieldCuOffset)
+		}
+		// The entry point of the next function includes alignment padding,
+		// the line table covers the function exactly.
+		if pcln := t.pcvalues(fn.pcln, fn.entry); len(pcln) > 0 && pcln[len(pcln)-1].end < fn.end {
+			fn.end = pcln[len(pcln)-1].end
+		}
+		if fn.name == "" || fn.end <= fn.entry {
+			continue
+		}
+		r = append(r, fn)
+	}
+	return r
+}
+
+func (t *pclntab) funcName(off uint32) string {
+	return cstring(t.funcnametab, uint64(off))
+}
+
+// fileName returns the name of file number fileno of the compile unit at
+// cuOffset.
+func (t *pclntab) fileName(cuOffset uint32, fileno int32) string {
+	if fileno < 0 {
+		return ""
+	}
+	if t.version == pclntabVer12 {
+		off := 4 * uint64(fileno)
+		if off+4 > uint64(len(t.filetab)) {
+			return ""
+		}
+		return cstring(t.data, uint64(binary.LittleEndian.Uint32(t.filetab[off:])))
+	}
+	off := 4 * (uint64(cuOffset) + uint64(fileno))
+	if off+4 > uint64(len(t.cutab)) {
+		return ""
+	}
+	fnoff := binary.LittleEndian.Uint32(t.cutab[off:])
+	if fnoff == ^uint32(0) {
+		return ""
+	}
+	return cstring(t.filetab, uint64(fnoff))
+}
+
+func cstring(b []byte, off uint64) string {
+	if off >= uint64(len(b)) {
+		return ""
+	}
+	b = b[off:]
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// pcvalue is a single entry of a decoded pc-value table, val is the value
+// for all PC addresses in [pc, end).
+type pcvalue struct {
+	pc, end uint64
+	val     int32
+}
+
+// pcvalues decodes the pc-value table at off of the function starting at
+// entry.
+// See runtime.pcvalue and runtime.step in $GOROOT/src/runtime/symtab.go.
+func (t *pclntab) pcvalues(off uint32, entry uint64) []pcvalue {
+	if off == 0 || uint64(off) >= uint64(len(t.pctab)) {
+		return nil
+	}
+	buf := bytes.NewBuffer(t.pctab[off:])
+	var r []pcvalue
+	pc, val := entry, int32(-1)
+	for first := true; ; first = false {
+		uvdelta, _ := util.DecodeULEB128(buf)
+		if uvdelta == 0 && !first {
+			break
+		}
+		vdelta := int32(uvdelta >> 1)
+		if uvdelta&1 != 0 {
+			vdelta = ^vdelta
+		}
+		pcdelta, _ := util.DecodeULEB128(buf)
+		if pcdelta == 0 {
+			break
+		}
+		val += vdelta
+		r = append(r, pcvalue{pc: pc, end: pc + pcdelta*uint64(t.quantum), val: val})
+		pc += pcdelta * uint64(t.quantum)
+	}
+	return r
+}
+
+// loadDebugInfoMapsPclntab loads functions, compile units, line tables and
+// frame descriptions of image from its pclntab. It is used instead of
+// loadDebugInfoMaps for images that do not have any debug_info.
+func (bi *BinaryInfo) loadDebugInfoMapsPclntab(image *Image, t *pclntab) {
+	if bi.types == nil {
+		bi.types = make(map[string]dwarfRef)
+	}
+	if bi.consts == nil {
+		bi.consts = make(map[dwarfRef]*constantType)
+	}
+	if bi.PackageMap == nil {
+		bi.PackageMap = make(map[string][]string)
+	}
+	if bi.inlinedCallLines == nil {
+		bi.inlinedCallLines = make(map[fileLine][]uint64)
+	}
+
+	image.runtimeTypeToDIE = make(map[uint64]runtimeTypeDIE)
+
+	if image.index == 0 && t.version >= pclntabVer118 && len(bi.Arch.argumentRegs) > 0 {
+		// The register based calling convention was enabled by default at the
+		// same time as the 1.18 pclntab format was introduced, for all the
+		// architectures we support it on.
+		bi.regabi = true
+	}
+
+	fns := t.funcs()
+
+	// Group functions by compile unit, pclntab versions before 1.16 do not
+	// have compile units, all functions will end up in the same one.
+	cuFns := make(map[uint32][]pclnFunc)
+	cuOffsets := []uint32{}
+	for _, fn := range fns {
+		if _, ok := cuFns[fn.cuOffset]; !ok {
+			cuOffsets = append(cuOffsets, fn.cuOffset)
+		}
+		cuFns[fn.cuOffset] = append(cuFns[fn.cuOffset], fn)
+	}
+
+	for i, cuOffset := range cuOffsets {
+		fns := cuFns[cuOffset]
+		cu := &compileUnit{image: image, isgo: true, optimized: true, offset: dwarf.Offset(i)}
+		cu.name = packageName(fns[0].name)
+		cu.lowPC = fns[0].entry + image.StaticBase
+		for _, fn := range fns {
+			cu.ranges = append(cu.ranges, [2]uint64{fn.entry + image.StaticBase, fn.end + image.StaticBase})
+			bi.Functions = append(bi.Functions, Function{Name: fn.name, Entry: fn.entry + image.StaticBase, End: fn.end + image.StaticBase, cu: cu})
+		}
+		cu.lineInfo = line.Parse("", bytes.NewBuffer(t.lineProgram(cuOffset, fns)), nil, nil, image.StaticBase, bi.GOOS == "windows", bi.Arch.PtrSize())
+		if slash := strings.LastIndex(cu.name, "/"); slash >= 0 && slash+1 < len(cu.name) {
+			bi.PackageMap[cu.name[slash+1:]] = append(bi.PackageMap[cu.name[slash+1:]], cu.name)
+		}
+		image.compileUnits = append(image.compileUnits, cu)
+	}
+
+	sort.Sort(compileUnitsByOffset(image.compileUnits))
+	sort.Sort(functionsDebugInfoByEntry(bi.Functions))
+
+	bi.LookupFunc = make(map[string]*Function)
+	for i := range bi.Functions {
+		bi.LookupFunc[bi.Functions[i].Name] = &bi.Functions[i]
+	}
+
+	bi.Sources = []string{}
+	for _, cu := range image.compileUnits {
+		for _, fileEntry := range cu.lineInfo.FileNames {
+			bi.Sources = append(bi.Sources, fileEntry.Path)
+		}
+	}
+	sort.Strings(bi.Sources)
+	bi.Sources = uniq(bi.Sources)
+
+	bi.frameEntries = bi.frameEntries.Append(frame.Parse(t.debugFrame(bi.Arch, fns), binary.LittleEndian, image.StaticBase, bi.Arch.PtrSize()))
+}
+
+// lineProgram synthesizes a DWARF version 2 line number program describing
+// the pcfile and pcln tables of fns, which must all belong to the compile
+// unit at cuOffset.
+func (t *pclntab) lineProgram(cuOffset uint32, fns []pclnFunc) []byte {
+	files := []string{}
+	fileIdx := map[int32]uint64{}
+
+	prog := new(bytes.Buffer)
+	for _, fn := range fns {
+		pcfile := t.pcvalues(fn.pcfile, fn.entry)
+		pcln := t.pcvalues(fn.pcln, fn.entry)
+		if len(pcfile) == 0 || len(pcln) == 0 {
+			continue
+		}
+
+		prog.WriteByte(0)
+		util.EncodeULEB128(prog, uint64(1+t.ptrSize))
+		prog.WriteByte(line.DW_LINE_set_address)
+		util.WriteUint(prog, binary.LittleEndian, t.ptrSize, fn.entry)
+
+		curfile, curline, curpc := uint64(1), int64(1), fn.entry
+		for i, j := 0, 0; i < len(pcfile) && j < len(pcln); {
+			pc := pcln[j].pc
+			if pcfile[i].pc > pc {
+				pc = pcfile[i].pc
+			}
+
+			idx, ok := fileIdx[pcfile[i].val]
+			if !ok {
+				files = append(files, t.fileName(cuOffset, pcfile[i].val))
+				idx = uint64(len(files))
+				fileIdx[pcfile[i].val] = idx
+			}
+			if idx != curfile {
+				prog.WriteByte(line.DW_LNS_set_file)
+				util.EncodeULEB128(prog, idx)
+				curfile = idx
+			}
+			if ln := int64(pcln[j].val); ln != curline {
+				prog.WriteByte(line.DW_LNS_advance_line)
+				util.EncodeSLEB128(prog, ln-curline)
+				curline = ln
+			}
+			if pc != curpc {
+				prog.WriteByte(line.DW_LNS_advance_pc)
+				util.EncodeULEB128(prog, pc-curpc)
+				curpc = pc
+			}
+			prog.WriteByte(line.DW_LNS_copy)
+
+			// advance whichever table changes value first
+			switch {
+			case pcfile[i].end < pcln[j].end:
+				i++
+			case pcfile[i].end > pcln[j].end:
+				j++
+			default:
+				i++
+				j++
+			}
+		}
+
+		if fn.end != curpc {
+			prog.WriteByte(line.DW_LNS_advance_pc)
+			util.EncodeULEB128(prog, fn.end-curpc)
+		}
+		prog.WriteByte(0)
+		util.EncodeULEB128(prog, 1)
+		prog.WriteByte(line.DW_LINE_end_sequence)
+	}
+
+	hdr := new(bytes.Buffer)
+	hdr.WriteByte(1)                             // minimum_instruction_length
+	hdr.WriteByte(1)                             // default_is_stmt
+	hdr.WriteByte(0)                             // line_base, special opcodes are never used
+	hdr.WriteByte(1)                             // line_range
+	hdr.WriteByte(10)                            // opcode_base
+	hdr.Write([]byte{0, 1, 1, 1, 1, 0, 0, 0, 1}) // standard_opcode_lengths
+	hdr.WriteByte(0)                             // include_directories
+	for _, file := range files {
+		hdr.WriteString(file)
+		hdr.WriteByte(0)
+		hdr.Write([]byte{0, 0, 0}) // directory index, modification time, length
+	}
+	hdr.WriteByte(0)
+
+	out := new(bytes.Buffer)
+	binary.Write(out, binary.LittleEndian, uint32(2+4+hdr.Len()+prog.Len())) // unit_length
+	binary.Write(out, binary.LittleEndian, uint16(2))                        // version
+	binary.Write(out, binary.LittleEndian, uint32(hdr.Len()))                // header_length
+	out.Write(hdr.Bytes())
+	out.Write(prog.Bytes())
+	return out.Bytes()
+}
+
+// debugFrame synthesizes a debug_frame section describing the stack frames
+// of fns, using their pcsp tables. The result is the same that the Go
+// linker would emit, see writeframes in $GOROOT/src/cmd/link/internal/ld/dwarf.go.
+func (t *pclntab) debugFrame(arch *Arch, fns []pclnFunc) []byte {
+	const dataAlignmentFactor = -4
+
+	regs := arch.addrAndStackRegsToDwarfRegisters(0, 0, 0, 0, 0)
+	haslr := regs.LRRegNum != 0
+	ptrSize := arch.PtrSize()
+
+	out := new(bytes.Buffer)
+
+	cie := new(bytes.Buffer)
+	binary.Write(cie, binary.LittleEndian, ^uint32(0)) // CIE id
+	cie.WriteByte(3)                                   // version
+	cie.WriteByte(0)                                   // augmentation
+	util.EncodeULEB128(cie, 1)                         // code_alignment_factor
+	util.EncodeSLEB128(cie, dataAlignmentFactor)
+	if haslr {
+		util.EncodeULEB128(cie, regs.LRRegNum)
+		cie.WriteByte(frame.DW_CFA_def_cfa)
+		util.EncodeULEB128(cie, regs.SPRegNum)
+		util.EncodeULEB128(cie, 0)
+		cie.WriteByte(frame.DW_CFA_same_value)
+		util.EncodeULEB128(cie, regs.LRRegNum)
+		cie.WriteByte(frame.DW_CFA_val_offset)
+		util.EncodeULEB128(cie, regs.SPRegNum)
+		util.EncodeULEB128(cie, 0)
+	} else {
+		util.EncodeULEB128(cie, regs.PCRegNum)
+		cie.WriteByte(frame.DW_CFA_def_cfa)
+		util.EncodeULEB128(cie, regs.SPRegNum)
+		util.EncodeULEB128(cie, uint64(ptrSize))
+		cie.WriteByte(frame.DW_CFA_offset_extended)
+		util.EncodeULEB128(cie, regs.PCRegNum)
+		util.EncodeULEB128(cie, uint64(-ptrSize/dataAlignmentFactor))
+	}
+	for cie.Len()%ptrSize != 0 {
+		cie.WriteByte(frame.DW_CFA_nop)
+	}
+	binary.Write(out, binary.LittleEndian, uint32(cie.Len()))
+	out.Write(cie.Bytes())
+
+	insts := new(bytes.Buffer)
+	for _, fn := range fns {
+		insts.Reset()
+		for _, pcsp := range t.pcvalues(fn.pcsp, fn.entry) {
+			end := pcsp.end
+			if end == fn.end {
+				// DWARF expects us to stop just before the end of the function
+				end--
+				if end < pcsp.pc {
+					continue
+				}
+			}
+			spdelta := int64(pcsp.val)
+			if !haslr {
+				// Return address has been pushed onto stack.
+				spdelta += int64(ptrSize)
+			} else if pcsp.val > 0 {
+				// The return address is preserved at (CFA-frame_size) after a
+				// stack frame has been allocated.
+				insts.WriteByte(frame.DW_CFA_offset_extended_sf)
+				util.EncodeULEB128(insts, regs.LRRegNum)
+				util.EncodeSLEB128(insts, -spdelta/dataAlignmentFactor)
+			} else {
+				insts.WriteByte(frame.DW_CFA_same_value)
+				util.EncodeULEB128(insts, regs.LRRegNum)
+			}
+
+			insts.WriteByte(frame.DW_CFA_def_cfa_offset_sf)
+			util.EncodeSLEB128(insts, spdelta/dataAlignmentFactor)
+			insts.WriteByte(frame.DW_CFA_advance_loc4)
+			binary.Write(insts, binary.LittleEndian, uint32(end-pcsp.pc))
+		}
+		for insts.Len()%ptrSize != 0 {
+			insts.WriteByte(frame.DW_CFA_nop)
+		}
+
+		binary.Write(out, binary.LittleEndian, uint32(4+2*ptrSize+insts.Len()))
+		binary.Write(out, binary.LittleEndian, uint32(0)) // CIE pointer
+		util.WriteUint(out, binary.LittleEndian, ptrSize, fn.entry)
+		util.WriteUint(out, binary.LittleEndian, ptrSize, fn.end-fn.entry)
+		out.Write(insts.Bytes())
+	}
+
+	return out.Bytes()
+}
+
+// emptyDwarf returns a dwarf.Data object containing a single, empty,
+// compile unit. It is used for images that do not have debug_info so that
+// all code that expects image.dwarf to be present keeps working.
+func emptyDwarf(ptrSize int) *dwarf.Data {
+	abbrev := []byte{
+		1,                             // abbreviation code
+		byte(dwarf.TagCompileUnit), 0, // tag, no children
+		0, 0, // end of attributes
+		0, // end of abbreviations
+	}
+	info := new(bytes.Buffer)
+	binary.Write(info, binary.LittleEndian, uint32(2+4+1+1)) // unit_length
+	binary.Write(info, binary.LittleEndian, uint16(4))       // version
+	binary.Write(info, binary.LittleEndian, uint32(0))       // debug_abbrev_offset
+	info.WriteByte(byte(ptrSize))                            // address_size
+	info.WriteByte(1)                                        // abbreviation code
+	dw, err := dwarf.New(abbrev, nil, nil, info.Bytes(), nil, nil, nil, nil)
+	if err != nil {
+		panic(fmt.Errorf("could not create empty DWARF data: %v", err))
+	}
+	return dw
+}
+
+// Flags and masks used by runtime type descriptors.
+// See $GOROOT/src/internal/abi/type.go.
+const (
+	rtypeKindMask         = (1 << 5) - 1
+	rtypeTflagExtraStar   = 1 << 1
+	rtypeNameflagEmbedded = 1 << 3
+)
+
+// rtypeReader converts runtime type descriptors into godwarf types. It is
+// used to recover the layout of runtime.g on binaries without debug_info
+// and only supports the type descriptor layout used by Go 1.20 and later
+// (the first version using the 1.20 pclntab format).
+type rtypeReader struct {
+	read    func(addr uint64, size int) []byte
+	ptrSize int
+	types   uint64 // address of runtime.types, base for name offsets
+	cache   map[uint64]godwarf.Type
+}
+
+func (r *rtypeReader) uintptr(addr uint64) uint64 {
+	b := r.read(addr, r.ptrSize)
+	if b == nil {
+		return 0
+	}
+	if r.ptrSize == 4 {
+		return uint64(binary.LittleEndian.Uint32(b))
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// name decodes the abi.Name at addr, returning its string and flags.
+func (r *rtypeReader) name(addr uint64) (string, byte) {
+	b := r.read(addr, 1+binary.MaxVarintLen16)
+	if b == nil {
+		return "", 0
+	}
+	n, sz := binary.Uvarint(b[1:])
+	if sz <= 0 {
+		return "", 0
+	}
+	return string(r.read(addr+1+uint64(sz), int(n))), b[0]
+}
+
+// findNamed returns the address of the type descriptor of the type called
+// name, of the specified kind, in the types area data.
+func (r *rtypeReader) findNamed(data []byte, name string, kind reflect.Kind) uint64 {
+	// Type names are stored with a leading '*' and the tflagExtraStar flag
+	// set so that they can be shared with the name of the pointer type.
+	pat := binary.AppendUvarint(nil, uint64(len(name)+1))
+	pat = append(pat, '*')
+	pat = append(pat, name...)
+	hdrSize := 4*r.ptrSize + 16
+	for start := 0; ; {
+		i := bytes.Index(data[start:], pat)
+		if i < 0 {
+			return 0
+		}
+		nameOff := int32(start + i - 1)
+		start += i + 1
+		if nameOff < 0 {
+			continue
+		}
+		for off := 0; off+hdrSize <= len(data); off += r.ptrSize {
+			hdr := data[off:]
+			if int32(binary.LittleEndian.Uint32(hdr[4*r.ptrSize+8:])) == nameOff && reflect.Kind(hdr[2*r.ptrSize+7]&rtypeKindMask) == kind && hdr[2*r.ptrSize+4]&rtypeTflagExtraStar != 0 {
+				return r.types + uint64(off)
+			}
+		}
+	}
+}
+
+// typeAt converts the type descriptor at addr.
+func (r *rtypeReader) typeAt(addr uint64) (godwarf.Type, error) {
+	if typ, ok := r.cache[addr]; ok {
+		return typ, nil
+	}
+	hdrSize := 4*r.ptrSize + 16
+	hdr := r.read(addr, hdrSize)
+	if hdr == nil {
+		return nil, fmt.Errorf("could not read type descriptor at %#x", addr)
+	}
+	ps := uint64(r.ptrSize)
+	size := int64(r.uintptr(addr))
+	tflag := hdr[2*ps+4]
+	kind := reflect.Kind(hdr[2*ps+7] & rtypeKindMask)
+	name, _ := r.name(r.types + uint64(int32(binary.LittleEndian.Uint32(hdr[4*ps+8:]))))
+	if tflag&rtypeTflagExtraStar != 0 && name != "" {
+		name = name[1:]
+	}
+	common := godwarf.CommonType{ByteSize: size, Name: name, ReflectKind: kind}
+	basic := godwarf.BasicType{CommonType: common, BitSize: size * 8}
+	extra := addr + uint64(hdrSize) // kind specific fields
+
+	var typ godwarf.Type
+	switch kind {
+	case reflect.Bool:
+		typ = &godwarf.BoolType{BasicType: basic}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		typ = &godwarf.IntType{BasicType: basic}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		typ = &godwarf.UintType{BasicType: basic}
+	case reflect.Float32, reflect.Float64:
+		typ = &godwarf.FloatType{BasicType: basic}
+	case reflect.Complex64, reflect.Complex128:
+		typ = &godwarf.ComplexType{BasicType: basic}
+	case reflect.Func:
+		typ = &godwarf.FuncType{CommonType: common}
+	case reflect.UnsafePointer:
+		typ = &godwarf.PtrType{CommonType: common, Type: &godwarf.VoidType{}}
+	case reflect.String:
+		typ = &godwarf.StringType{StructType: godwarf.StructType{CommonType: common, StructName: name, Kind: "struct", Field: []*godwarf.StructField{
+			{Name: "str", Type: r.ptrTo(r.basicType(reflect.Uint8, 1, "uint8")), ByteOffset: 0, ByteSize: int64(ps)},
+			{Name: "len", Type: r.basicType(reflect.Int, int64(ps), "int"), ByteOffset: int64(ps), ByteSize: int64(ps)},
+		}}}
+	case reflect.Ptr:
+		t := &godwarf.PtrType{CommonType: common}
+		r.cache[addr] = t
+		elem, err := r.typeAt(r.uintptr(extra))
+		if err != nil {
+			return nil, err
+		}
+		t.Type = elem
+		typ = t
+	case reflect.Array:
+		t := &godwarf.ArrayType{CommonType: common, Count: int64(r.uintptr(extra + 2*ps))}
+		r.cache[addr] = t
+		elem, err := r.typeAt(r.uintptr(extra))
+		if err != nil {
+			return nil, err
+		}
+		t.Type = elem
+		t.StrideBitSize = elem.Size() * 8
+		typ = t
+	case reflect.Slice:
+		t := &godwarf.SliceType{StructType: godwarf.StructType{CommonType: common, StructName: name, Kind: "struct"}}
+		r.cache[addr] = t
+		elem, err := r.typeAt(r.uintptr(extra))
+		if err != nil {
+			return nil, err
+		}
+		t.ElemType = elem
+		intType := r.basicType(reflect.Int, int64(ps), "int")
+		t.Field = []*godwarf.StructField{
+			{Name: "array", Type: r.ptrTo(elem), ByteOffset: 0, ByteSize: int64(ps)},
+			{Name: "len", Type: intType, ByteOffset: int64(ps), ByteSize: int64(ps)},
+			{Name: "cap", Type: intType, ByteOffset: 2 * int64(ps), ByteSize: int64(ps)},
+		}
+		typ = t
+	case reflect.Struct:
+		t := &godwarf.StructType{CommonType: common, StructName: name, Kind: "struct"}
+		r.cache[addr] = t
+		fields, nfields := r.uintptr(extra+ps), r.uintptr(extra+2*ps)
+		for i := uint64(0); i < nfields; i++ {
+			field := fields + i*3*ps
+			fname, fflags := r.name(r.uintptr(field))
+			ftyp, err := r.typeAt(r.uintptr(field + ps))
+			if err != nil {
+				return nil, err
+			}
+			t.Field = append(t.Field, &godwarf.StructField{
+				Name:       fname,
+				Type:       ftyp,
+				ByteOffset: int64(r.uintptr(field + 2*ps)),
+				ByteSize:   ftyp.Size(),
+				Embedded:   fflags&rtypeNameflagEmbedded != 0,
+			})
+		}
+		typ = t
+	default:
+		// Maps, channels and interfaces are not needed to read goroutines.
+		typ = &godwarf.UnspecifiedType{BasicType: basic}
+	}
+	r.cache[addr] = typ
+	return typ, nil
+}
+
+func (r *rtypeReader) basicType(kind reflect.Kind, size int64, name string) godwarf.Type {
+	basic := godwarf.BasicType{CommonType: godwarf.CommonType{ByteSize: size, Name: name, ReflectKind: kind}, BitSize: size * 8}
+	if kind == reflect.Int {
+		return &godwarf.IntType{BasicType: basic}
+	}
+	return &godwarf.UintType{BasicType: basic}
+}
+
+func (r *rtypeReader) ptrTo(typ godwarf.Type) godwarf.Type {
+	return &godwarf.PtrType{CommonType: godwarf.CommonType{ByteSize: int64(r.ptrSize), Name: "*" + typ.Common().Name, ReflectKind: reflect.Ptr}, Type: typ}
+}
+
+// loadRuntimeTypesPclntab recovers the layout of runtime.g, and of all the
+// types it references, from the runtime type descriptors of image, so that
+// goroutines can be listed on binaries without debug_info. The read
+// function is used to read the contents of the executable file at the
+// specified (link time) address.
+func (bi *BinaryInfo) loadRuntimeTypesPclntab(image *Image, t *pclntab, read func(addr uint64, size int) []byte) error {
+	if t.version < pclntabVer120 {
+		return errors.New("unsupported type descriptor format")
+	}
+	types, etypes := image.symbols["runtime.types"], image.symbols["runtime.etypes"]
+	if types == 0 || etypes <= types {
+		return errors.New("could not find runtime.types")
+	}
+	data := read(types, int(etypes-types))
+	if data == nil {
+		return errors.New("could not read runtime.types")
+	}
+
+	r := &rtypeReader{read: read, ptrSize: bi.Arch.PtrSize(), types: types, cache: make(map[uint64]godwarf.Type)}
+	addr := r.findNamed(data, "runtime.g", reflect.Struct)
+	if addr == 0 {
+		return errors.New("could not find runtime.g")
+	}
+	if _, err := r.typeAt(addr); err != nil {
+		return err
+	}
+
+	// Register all named types that were loaded, they are not found in
+	// debug_info so they are assigned offsets past the end of it.
+	addrs := make([]uint64, 0, len(r.cache))
+	for addr := range r.cache {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	off := dwarf.Offset(1 << 31)
+	for _, addr := range addrs {
+		typ := r.cache[addr]
+		typ.Common().Index = image.index
+		typ.Common().Offset = off
+		image.typeCache[off] = typ
+		if name := typ.Common().Name; name != "" {
+			if _, exists := bi.types[name]; !exists {
+				bi.types[name] = dwarfRef{image.index, off}
+			}
+		}
+		off++
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package proc
+
+import (
+	"fmt"
+	"go/constant"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxPrettyPrintDepth is the maximum nesting level of variables produced
+// by pretty-printers that are pretty-printed themselves.
+const maxPrettyPrintDepth = 3
+
+// PrettyPrinter describes how variables whose type name matches Type are
+// displayed, Type must match the whole type name.
+// The expressions used by a pretty-printer are evaluated with the variable
+// being printed bound to x, they can not call functions.
+type PrettyPrinter struct {
+	Type *regexp.Regexp
+	// Value is the display string of the variable, each expression
+	// enclosed in curly braces is replaced by its value.
+	Value string
+	// Children are the synthetic children of the variable.
+	Children []PrettyPrinterChild
+}
+
+// PrettyPrinterChild is a synthetic child of the variables displayed by a
+// PrettyPrinter.
+type PrettyPrinterChild struct {
+	Name string
+	Expr string
+}
+
+// PrettyValue is the representation of a variable produced by a
+// PrettyPrinter.
+type PrettyValue struct {
+	// Printer is the pretty-printer that produced this value, nil for
+	// values produced by a builtin formatter.
+	Printer  *PrettyPrinter
+	Value    string
+	Children []*Variable
+}
+
+// ApplyPrettyPrinters sets the Pretty field of the variables in vars, and
+// of their loaded children, whose type matches one of printers. The first
+// matching pretty-printer is used, variables that don't match any of
+// printers are formatted by the builtin formatter for their type, if any.
+// Nothing is done if cfg.DisableFormatters is set.
+func ApplyPrettyPrinters(vars []*Variable, printers []*PrettyPrinter, cfg LoadConfig) {
+	if cfg.DisableFormatters {
+		return
+	}
+	for _, v := range vars {
+		v.applyPrettyPrinters(printers, cfg, 0)
+	}
+}
+
+func (v *Variable) applyPrettyPrinters(printers []*PrettyPrinter, cfg LoadConfig, depth int) {
+	if v == nil || depth > maxPrettyPrintDepth {
+		return
+	}
+	for i := range v.Children {
+		v.Children[i].applyPrettyPrinters(printers, cfg, depth)
+	}
+	if v.Unreadable != nil || v.Pretty != nil || v.DwarfType == nil {
+		return
+	}
+	typ := v.TypeString()
+	for _, pp := range printers {
+		if pp.Type.MatchString(typ) {
+			v.Pretty = pp.apply(v, printers, cfg, depth)
+			v.Flags |= VariableFormatted
+			return
+		}
+	}
+	v.applyBuiltinFormatter(cfg)
+	if v.Pretty != nil {
+		for _, child := range v.Pretty.Children {
+			child.applyPrettyPrinters(printers, cfg, depth+1)
+		}
+	}
+}
+
+func (pp *PrettyPrinter) apply(v *Variable, printers []*PrettyPrinter, cfg LoadConfig, depth int) *PrettyValue {
+	r := &PrettyValue{Printer: pp}
+
+	var buf strings.Builder
+	rest := pp.Value
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			break
+		}
+		end := prettyExprEnd(rest[start+1:])
+		if end < 0 {
+			break
+		}
+		end += start + 1
+		buf.WriteString(rest[:start])
+		val, err := v.evalPrettyExpr(rest[start+1:end], cfg)
+		if err != nil {
+			fmt.Fprintf(&buf, "<%v>", err)
+		} else {
+			val.applyPrettyPrinters(printers, cfg, depth+1)
+			buf.WriteString(val.prettyString())
+		}
+		rest = rest[end+1:]
+	}
+	buf.WriteString(rest)
+	r.Value = buf.String()
+
+	for _, child := range pp.Children {
+		val, err := v.evalPrettyExpr(child.Expr, cfg)
+		if err != nil {
+			val = &Variable{Unreadable: err}
+		}
+		val.Name = child.Name
+		val.applyPrettyPrinters(printers, cfg, depth+1)
+		r.Children = append(r.Children, val)
+	}
+
+	return r
+}
+
+// prettyExprEnd returns the index of the curly brace that closes the
+// expression at the start of s, or -1 if there is none. Braces of
+// composite literals and braces inside string and character literals are
+// part of the expression.
+func prettyExprEnd(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '"', '\'', '`':
+			q := s[i]
+			for i++; i < len(s) && s[i] != q; i++ {
+				if s[i] == '\\' && q != '`' {
+					i++
+				}
+			}
+			if i >= len(s) {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// evalPrettyExpr evaluates expr with v bound to x.
+func (v *Variable) evalPrettyExpr(expr string, cfg LoadConfig) (*Variable, error) {
+	t, err := ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	scope := &EvalScope{Mem: v.mem, BinInfo: v.bi, queryVars: map[string]*Variable{"x": v.clone()}}
+	r, err := scope.evalAST(t)
+	if err != nil {
+		return nil, err
+	}
+	r.loadValue(cfg)
+	return r, nil
+}
+
+// prettyString returns the representation of v used to replace the
+// expressions in the Value of a PrettyPrinter.
+func (v *Variable) prettyString() string {
+	if v.Pretty != nil {
+		return v.Pretty.Value
+	}
+	if v.Unreadable != nil {
+		return fmt.Sprintf("<%v>", v.Unreadable)
+	}
+	switch {
+	case (v.Kind == reflect.Ptr || v.Kind == reflect.UnsafePointer) && len(v.Children) > 0:
+		return fmt.Sprintf("%#x", v.Children[0].Addr)
+	case v.Value == nil:
+		return v.TypeString()
+	case v.Kind == reflect.String:
+		return constant.StringVal(v.Value)
+	case v.Kind == reflect.Float32 || v.Kind == reflect.Float64:
+		f, _ := constant.Float64Val(v.Value)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	default:
+		return v.Value.String()
+	}
+}
@@ -1,8 +1,14 @@
 package proc
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 	"unsafe"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"golang.org/x/arch/x86/x86asm"
 )
 
 func ptrSizeByRuntimeArch() int {
@@ -24,3 +30,118 @@ func TestIssue554(t *testing.T) {
 		t.Fatalf("should be false")
 	}
 }
+
+func TestTimeUnix(t *testing.T) {
+	// time.Time values with and without a monotonic clock reading
+	for _, tm := range []time.Time{time.Now(), time.Date(2021, time.March, 4, 5, 6, 7, 8, time.UTC)} {
+		wall := *(*uint64)(unsafe.Pointer(&tm))
+		ext := *(*int64)(unsafe.Pointer(uintptr(unsafe.Pointer(&tm)) + 8))
+		sec, nsec := timeUnix(wall, ext)
+		if sec != tm.Unix() || nsec != int64(tm.Nanosecond()) {
+			t.Errorf("timeUnix(%#x, %d) = %d, %d, expected %d, %d", wall, ext, sec, nsec, tm.Unix(), tm.Nanosecond())
+		}
+	}
+}
+
+func TestInterpreter(t *testing.T) {
+	const (
+		codeAddr = interpMemBase + 0x1000
+		sp       = interpMemBase + 0x8000
+	)
+	run := func(code []byte, args ...uint64) (*interpreter, error) {
+		mem := &interpMemory{priv: make([]byte, 0x10000)}
+		copy(mem.priv[codeAddr-interpMemBase:], code)
+		ctx := &interpContext{mem: mem, tlsAddr: interpMemBase}
+		it := &interpreter{bi: NewBinaryInfo("linux", "amd64"), ctx: ctx, mem: mem, insts: make(map[uint64]x86asm.Inst)}
+		it.regs[x86asm.RSP-x86asm.RAX] = sp
+		it.store(sp, 8, interpMemBase)
+		for i, arg := range args {
+			it.store(sp+8*uint64(i+1), 8, arg)
+		}
+		return it, it.run(codeAddr)
+	}
+
+	// (a + b) * 3
+	it, err := run([]byte{
+		0x48, 0x8b, 0x44, 0x24, 0x08, // mov rax, [rsp+8]
+		0x48, 0x03, 0x44, 0x24, 0x10, // add rax, [rsp+16]
+		0x48, 0x6b, 0xc0, 0x03, // imul rax, rax, 3
+		0x48, 0x89, 0x44, 0x24, 0x18, // mov [rsp+24], rax
+		0xc3, // ret
+	}, 2, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := it.load(sp+24, 8); r != 21 {
+		t.Errorf("expected 21 got %d", r)
+	}
+
+	// sum of the numbers from 1 to n
+	it, err = run([]byte{
+		0x31, 0xc0, // xor eax, eax
+		0x48, 0x8b, 0x4c, 0x24, 0x08, // mov rcx, [rsp+8]
+		0x48, 0x01, 0xc8, // add rax, rcx
+		0x48, 0xff, 0xc9, // dec rcx
+		0x75, 0xf8, // jnz -8
+		0x48, 0x89, 0x44, 0x24, 0x10, // mov [rsp+16], rax
+		0xc3, // ret
+	}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := it.load(sp+16, 8); r != 5050 {
+		t.Errorf("expected 5050 got %d", r)
+	}
+
+	// writes outside of the stack are refused
+	_, err = run([]byte{
+		0x48, 0x89, 0x04, 0x25, 0x00, 0x10, 0x00, 0x00, // mov [0x1000], rax
+		0xc3, // ret
+	})
+	if err == nil || !strings.Contains(err.Error(), "writes to memory") {
+		t.Errorf("expected write error, got %v", err)
+	}
+
+	// system calls are refused
+	_, err = run([]byte{
+		0x0f, 0x05, // syscall
+		0xc3, // ret
+	})
+	if err != errInterpSyscall {
+		t.Errorf("expected syscall error, got %v", err)
+	}
+}
+
+func TestSliceBytesBogusLen(t *testing.T) {
+	// the length of a []byte read from an uninitialized or corrupted
+	// strings.Builder or bytes.Buffer must not be trusted
+	mem := &memCache{true, 0x1000, []byte("hello, world"), nil}
+	typ := &godwarf.SliceType{ElemType: &godwarf.UintType{BasicType: godwarf.BasicType{CommonType: godwarf.CommonType{ByteSize: 1, Name: "uint8"}}}}
+	newSlice := func(n int64) *Variable {
+		return &Variable{Kind: reflect.Slice, RealType: typ, Base: 0x1000, Len: n, Cap: n, mem: mem}
+	}
+
+	buf, n, err := newSlice(1<<62).sliceBytes(0, 5)
+	if err != nil {
+		t.Fatalf("sliceBytes: %v", err)
+	}
+	if string(buf) != "hello" || n != 1<<62 {
+		t.Errorf("got %q %d, expected %q %d", buf, n, "hello", int64(1<<62))
+	}
+
+	for _, tc := range []struct{ len, off int64 }{{-1, 0}, {-1 << 62, 0}, {5, 6}, {5, -1}} {
+		if _, _, err := newSlice(tc.len).sliceBytes(tc.off, 5); err == nil {
+			t.Errorf("sliceBytes(%d) with Len %d: expected error", tc.off, tc.len)
+		}
+	}
+}
+
+func TestFormatElementsLimit(t *testing.T) {
+	// builtin formatters never load more than maxFormatElements elements,
+	// even if the load configuration allows it
+	for _, tc := range []struct{ max, tgt int }{{64, 64}, {-1, maxFormatElements}, {1 << 30, maxFormatElements}} {
+		if n := formatElementsLimit(LoadConfig{MaxArrayValues: tc.max}); n != tc.tgt {
+			t.Errorf("MaxArrayValues %d: got %d expected %d", tc.max, n, tc.tgt)
+		}
+	}
+}
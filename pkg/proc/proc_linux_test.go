@@ -1,11 +1,18 @@
 package proc_test
 
 import (
+	"debug/elf"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/proc/debuginfod"
 	"github.com/go-delve/delve/pkg/proc/native"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 )
@@ -14,13 +21,59 @@ func TestLoadingExternalDebugInfo(t *testing.T) {
 	fixture := protest.BuildFixture("locationsprog", 0)
 	defer os.Remove(fixture.Path)
 	stripAndCopyDebugInfo(fixture, t)
-	p, err := native.Launch(append([]string{fixture.Path}, ""), "", false, []string{filepath.Dir(fixture.Path)}, "")
+	p, err := native.Launch(append([]string{fixture.Path}, ""), "", false, proc.DebugInfoConfig{Dirs: []string{filepath.Dir(fixture.Path)}}, "")
 	if err != nil {
 		t.Fatal(err)
 	}
 	p.Detach(true)
 }
 
+func TestLoadingDebugInfoFromDebuginfod(t *testing.T) {
+	// Work on a copy of the fixture, stripAndCopyDebugInfo modifies it.
+	buf, err := ioutil.ReadFile(protest.BuildFixture("testnextprog", 0).Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "debuginfod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fixture := protest.Fixture{Path: filepath.Join(dir, "testnextprog")}
+	if err := ioutil.WriteFile(fixture.Path, buf, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if exe, err := elf.Open(fixture.Path); err != nil || exe.Section(".note.gnu.build-id") == nil {
+		t.Skip("executable has no build-id note")
+	}
+	stripAndCopyDebugInfo(fixture, t)
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		http.ServeFile(w, r, fixture.Path+".debug")
+	}))
+	defer srv.Close()
+
+	srcs := []proc.DebugInfoSource{debuginfod.New([]string{srv.URL}, filepath.Join(dir, "cache"))}
+
+	for i := 0; i < 2; i++ {
+		bi := proc.NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+		if err := bi.LoadBinaryInfo(fixture.Path, 0, proc.DebugInfoConfig{Sources: srcs}); err != nil {
+			t.Fatal(err)
+		}
+		if bi.LookupFunc["main.main"] == nil || bi.Producer() == "" {
+			t.Fatal("debug info not loaded")
+		}
+		bi.Close()
+	}
+
+	// the second load should have used the cache
+	if len(requests) != 1 {
+		t.Fatalf("wrong requests: %q", requests)
+	}
+}
+
 func stripAndCopyDebugInfo(f protest.Fixture, t *testing.T) {
 	name := filepath.Base(f.Path)
 	// Copy the debug information to an external file.
@@ -14,6 +14,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -25,11 +26,12 @@ import (
 	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/gdbserial"
+	"github.com/go-delve/delve/pkg/proc/linutil"
 	"github.com/go-delve/delve/pkg/proc/native"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 )
 
-var normalLoadConfig = proc.LoadConfig{true, 1, 64, 64, -1, 0}
+var normalLoadConfig = proc.LoadConfig{true, 1, 64, 64, -1, 0, false}
 var testBackend, buildMode string
 
 func init() {
@@ -67,13 +69,13 @@ func withTestProcessArgs(name string, t testing.TB, wd string, args []string, bu
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, []string{}, "")
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, proc.DebugInfoConfig{}, "")
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, []string{}, "")
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, proc.DebugInfoConfig{}, "")
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
-		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, []string{})
+		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, proc.DebugInfoConfig{})
 		t.Logf("replaying %q", tracedir)
 	default:
 		t.Fatal("unknown backend")
@@ -350,7 +352,7 @@ type nextTest struct {
 func countBreakpoints(p *proc.Target) int {
 	bpcount := 0
 	for _, bp := range p.Breakpoints().M {
-		if bp.LogicalID >= 0 {
+		if bp.LogicalID >= 0 && bp.Kind != proc.ImageLoadBreakpoint {
 			bpcount++
 		}
 	}
@@ -1881,6 +1883,163 @@ func TestPanicBreakpoint(t *testing.T) {
 	})
 }
 
+func TestRecoveredPanicBreakpoint(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("recoveredpanic", t, func(p *proc.Target, fixture protest.Fixture) {
+		addrs, err := proc.FindPanicLocation(p)
+		assertNoError(err, t, "FindPanicLocation()")
+		bp, err := p.SetBreakpoint(addrs[0], proc.UserBreakpoint, nil)
+		assertNoError(err, t, "SetBreakpoint()")
+
+		for _, tc := range []struct {
+			filter string
+			tgt    string
+		}{
+			{"", "*errors.errorString"},
+			{"myError", "*main.myError"},
+		} {
+			bp.PanicFilter = regexp.MustCompile(tc.filter)
+			assertNoError(p.Continue(), t, "Continue()")
+			if bpstate := p.CurrentThread().Breakpoint(); bpstate.Breakpoint != bp {
+				t.Fatalf("not on panic breakpoint: %v", bpstate)
+			}
+			e := evalVariable(p, t, "e")
+			if len(e.Children) != 1 || e.Children[0].TypeString() != tc.tgt {
+				t.Fatalf("filter %q: wrong panic argument %#v (expected type %q)", tc.filter, e, tc.tgt)
+			}
+		}
+
+		bp.PanicFilter = regexp.MustCompile("nomatch")
+		err = p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit: %v", err)
+		}
+	})
+}
+
+func TestSyscallCatchpoint(t *testing.T) {
+	if runtime.GOOS != "linux" || testBackend != "native" {
+		t.Skip("syscall catchpoints are only supported on linux's native backend")
+	}
+	withTestProcess("syscalltest", t, func(p *proc.Target, fixture protest.Fixture) {
+		num, err := linutil.SyscallNumber(runtime.GOARCH, "write")
+		assertNoError(err, t, "SyscallNumber()")
+		assertNoError(p.SetSyscallCatchpoint(&proc.SyscallCatchpoint{Syscalls: []uint64{num}}), t, "SetSyscallCatchpoint()")
+
+		nextWrite := func() *proc.SyscallStop {
+			for {
+				assertNoError(p.Continue(), t, "Continue()")
+				if p.StopReason != proc.StopSyscall {
+					t.Fatalf("wrong stop reason %v", p.StopReason)
+				}
+				sc := p.CurrentSyscall()
+				if sc == nil || sc.Name != "write" {
+					t.Fatalf("wrong syscall %#v", sc)
+				}
+				if _, ok := p.FindThread(sc.ThreadID); !ok {
+					t.Fatalf("syscall stop reported for unknown thread %d", sc.ThreadID)
+				}
+				// skip writes made by the runtime
+				if sc.Args[0] == 1 {
+					return sc
+				}
+			}
+		}
+
+		if sc := nextWrite(); sc.Exit || sc.Args[2] != 6 {
+			t.Fatalf("expected entry of write(1, ..., 6) got %#v", sc)
+		}
+		if sc := nextWrite(); !sc.Exit || sc.Ret != 6 {
+			t.Fatalf("expected exit of write returning 6 got %#v", sc)
+		}
+
+		assertNoError(p.SetSyscallCatchpoint(nil), t, "SetSyscallCatchpoint(nil)")
+		err = p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit: %v", err)
+		}
+	})
+}
+
+func TestSignalPolicy(t *testing.T) {
+	if runtime.GOOS != "linux" || testBackend != "native" {
+		t.Skip("signal handling policies are only supported on linux's native backend")
+	}
+	withTestProcess("signalprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		sigusr1, err := linutil.SignalNumber("SIGUSR1")
+		assertNoError(err, t, "SignalNumber()")
+		assertNoError(p.SetSignalPolicy(sigusr1, proc.SignalPolicy{Stop: true, Print: true, Pass: false}), t, "SetSignalPolicy()")
+
+		assertNoError(p.Continue(), t, "Continue()")
+		if p.StopReason != proc.StopSignal {
+			t.Fatalf("wrong stop reason %v", p.StopReason)
+		}
+		if sigs := p.ReceivedSignals(); len(sigs) != 1 || sigs[0].Signal != sigusr1 {
+			t.Fatalf("wrong received signals %v", sigs)
+		}
+
+		// SIGUSR1 is not passed to the target, which would otherwise be
+		// terminated by it.
+		err = p.Continue()
+		if pexit, exited := err.(proc.ErrProcessExited); !exited || pexit.Status != 0 {
+			t.Fatalf("expected process to exit normally: %v", err)
+		}
+	})
+}
+
+func TestUntilAdvance(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("testnextprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		findLine := func(lineno int) []uint64 {
+			addrs, err := proc.FindFileLocation(p, fixture.Source, lineno)
+			assertNoError(err, t, fmt.Sprintf("FindFileLocation(%d)", lineno))
+			return addrs
+		}
+
+		bp := setFunctionBreakpoint(p, t, "main.testnext")
+		assertNoError(p.Continue(), t, "Continue()")
+		_, err := p.ClearBreakpoint(bp.Addr)
+		assertNoError(err, t, "ClearBreakpoint()")
+
+		assertNoError(p.Until(findLine(34)), t, "Until(34)")
+		assertLineNumber(p, t, 34, "after until 34")
+
+		// line 24 is not executed again, until stops when testnext returns
+		assertNoError(p.Until(findLine(24)), t, "Until(24)")
+		assertLineNumber(p, t, 39, "after until 24")
+
+		if err := p.Until(findLine(47)); err == nil {
+			t.Fatal("until to a location outside of the current function should fail")
+		}
+
+		assertNoError(p.Advance(findLine(42)), t, "Advance(42)")
+		assertLineNumber(p, t, 42, "after advance 42")
+	})
+}
+
+func TestStepInto(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("stepintotest", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFileBreakpoint(p, t, fixture.Source, 22)
+		assertNoError(p.Continue(), t, "Continue()")
+
+		targets, err := proc.StepInTargets(p)
+		assertNoError(err, t, "StepInTargets()")
+		names := make([]string, len(targets))
+		for i := range targets {
+			names[i] = targets[i].Fn.Name
+		}
+		t.Logf("step in targets: %v", names)
+		if len(targets) != 3 || names[0] != "main.double" || names[1] != "main.square" || names[2] != "main.sum" {
+			t.Fatalf("wrong step in targets %v", names)
+		}
+
+		// stepping into square must skip the call to double
+		assertNoError(p.StepInto(targets[1].CallPC), t, "StepInto(square)")
+		assertLineNumber(p, t, 12, "after step into square")
+	})
+}
+
 func TestCmdLineArgs(t *testing.T) {
 	expectSuccess := func(p *proc.Target, fixture protest.Fixture) {
 		err := p.Continue()
@@ -2078,9 +2237,9 @@ func TestUnsupportedArch(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Launch([]string{outfile}, ".", false, []string{}, "")
+		p, err = native.Launch([]string{outfile}, ".", false, proc.DebugInfoConfig{}, "")
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", false, []string{}, "")
+		p, err = gdbserial.LLDBLaunch([]string{outfile}, ".", false, proc.DebugInfoConfig{}, "")
 	default:
 		t.Skip("test not valid for this backend")
 	}
@@ -2653,7 +2812,7 @@ func BenchmarkTrace(b *testing.B) {
 			assertNoError(p.Continue(), b, "Continue()")
 			s, err := proc.GoroutineScope(p.CurrentThread())
 			assertNoError(err, b, "Scope()")
-			_, err = s.FunctionArguments(proc.LoadConfig{false, 0, 64, 0, 3, 0})
+			_, err = s.FunctionArguments(proc.LoadConfig{false, 0, 64, 0, 3, 0, false})
 			assertNoError(err, b, "FunctionArguments()")
 		}
 		b.StopTimer()
@@ -2833,13 +2992,13 @@ func TestAttachDetach(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Attach(cmd.Process.Pid, []string{})
+		p, err = native.Attach(cmd.Process.Pid, proc.DebugInfoConfig{})
 	case "lldb":
 		path := ""
 		if runtime.GOOS == "darwin" {
 			path = fixture.Path
 		}
-		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, []string{})
+		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, proc.DebugInfoConfig{})
 	default:
 		err = fmt.Errorf("unknown backend %q", testBackend)
 	}
@@ -3134,13 +3293,13 @@ func TestAttachStripped(t *testing.T) {
 
 	switch testBackend {
 	case "native":
-		p, err = native.Attach(cmd.Process.Pid, []string{})
+		p, err = native.Attach(cmd.Process.Pid, proc.DebugInfoConfig{})
 	case "lldb":
 		path := ""
 		if runtime.GOOS == "darwin" {
 			path = fixture.Path
 		}
-		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, []string{})
+		p, err = gdbserial.LLDBAttach(cmd.Process.Pid, path, proc.DebugInfoConfig{})
 	default:
 		t.Fatalf("unknown backend %q", testBackend)
 	}
@@ -4435,6 +4594,142 @@ func TestPluginStepping(t *testing.T) {
 		{contNext, "plugintest2.go:42"}})
 }
 
+func TestPluginOpenBreakpoint(t *testing.T) {
+	// Checks that plugins are reported to the new images callback as soon as
+	// they are opened, so that breakpoints can be set on them before they are
+	// called, without the target process being stopped in between.
+	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
+
+	withTestProcessArgs("plugintest2", t, ".", []string{pluginFixtures[0].Path, pluginFixtures[1].Path}, protest.AllNonOptimized, func(p *proc.Target, fixture protest.Fixture) {
+		loaded := map[string]bool{}
+		p.SetNewImagesCallback(func(images []*proc.Image) {
+			for _, image := range images {
+				loaded[image.Path] = true
+				if image.Path == pluginFixtures[0].Path {
+					setFunctionBreakpoint(p, t, "github.com/go-delve/delve/_fixtures/plugin1.HelloFn")
+				}
+			}
+		})
+		assertNoError(p.Continue(), t, "Continue")
+		if p.StopReason != proc.StopBreakpoint {
+			t.Fatalf("wrong stop reason %v", p.StopReason)
+		}
+		loc, err := p.CurrentThread().Location()
+		assertNoError(err, t, "Location")
+		if loc.Fn == nil || loc.Fn.Name != "github.com/go-delve/delve/_fixtures/plugin1.HelloFn" {
+			t.Fatalf("wrong location %v", loc)
+		}
+		for _, fixture := range pluginFixtures {
+			if !loaded[fixture.Path] {
+				t.Errorf("plugin %s not reported", fixture.Path)
+			}
+		}
+		if p.Breakpoints().HasInternalBreakpoints() {
+			t.Errorf("plugin open breakpoint reported as internal breakpoint")
+		}
+		found := false
+		for _, pkg := range p.BinInfo().ListPackagesBuildInfo(false) {
+			if pkg.ImportPath == "github.com/go-delve/delve/_fixtures/plugin1" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("plugin package not listed by ListPackagesBuildInfo")
+		}
+	})
+}
+
+func TestDynamicLoaderBreakpoint(t *testing.T) {
+	// Checks that shared libraries opened with dlopen are reported to the new
+	// images callback when the dynamic loader notification function is called.
+	if runtime.GOOS != "linux" {
+		t.Skip("dynamic loader breakpoints are only supported on linux")
+	}
+	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
+
+	withTestProcessArgs("plugintest2", t, ".", []string{pluginFixtures[0].Path, pluginFixtures[1].Path}, protest.AllNonOptimized, func(p *proc.Target, fixture protest.Fixture) {
+		var dlbp *proc.Breakpoint
+		for _, bp := range p.Breakpoints().M {
+			if bp.Kind == proc.ImageLoadBreakpoint && bp.FunctionName != "plugin.lastmoduleinit" {
+				dlbp = bp
+			}
+		}
+		if dlbp == nil {
+			t.Fatal("no breakpoint on the dynamic loader notification function")
+		}
+
+		atDynamicLoader := map[string]bool{}
+		p.SetNewImagesCallback(func(images []*proc.Image) {
+			stopped := false
+			for _, th := range p.ThreadList() {
+				if th.Breakpoint().Breakpoint == dlbp {
+					stopped = true
+				}
+			}
+			for _, image := range images {
+				atDynamicLoader[image.Path] = stopped
+			}
+		})
+		err := p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit: %v", err)
+		}
+		for _, fixture := range pluginFixtures {
+			if !atDynamicLoader[fixture.Path] {
+				t.Errorf("plugin %s not reported by the dynamic loader breakpoint", fixture.Path)
+			}
+		}
+	})
+}
+
+func TestImageLoadBreakpointID(t *testing.T) {
+	// Image load breakpoints do not consume logical IDs, a user breakpoint
+	// set at the same address gets its own ID.
+	if runtime.GOOS != "linux" {
+		t.Skip("plugins are only supported on linux")
+	}
+	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
+
+	withTestProcessArgs("plugintest2", t, ".", []string{pluginFixtures[0].Path, pluginFixtures[1].Path}, protest.AllNonOptimized, func(p *proc.Target, fixture protest.Fixture) {
+		var imageLoadBp *proc.Breakpoint
+		for _, bp := range p.Breakpoints().M {
+			if bp.Kind == proc.ImageLoadBreakpoint {
+				if bp.LogicalID != 0 {
+					t.Errorf("image load breakpoint at %#x has logical ID %d", bp.Addr, bp.LogicalID)
+				}
+				imageLoadBp = bp
+			}
+		}
+		if imageLoadBp == nil {
+			t.Fatal("no image load breakpoint")
+		}
+
+		bp := setFunctionBreakpoint(p, t, "main.main")
+		if bp.LogicalID != 1 {
+			t.Errorf("wrong ID for first user breakpoint %d", bp.LogicalID)
+		}
+		pc := findFunctionLocation(p, t, "main.must")
+		nextbp, err := p.SetBreakpoint(pc, proc.NextBreakpoint, nil)
+		assertNoError(err, t, "SetBreakpoint(NextBreakpoint)")
+		if nextbp.LogicalID != 1 {
+			t.Errorf("wrong ID for first internal breakpoint %d", nextbp.LogicalID)
+		}
+
+		for _, id := range []int{2, 3} {
+			bp, err := p.SetBreakpoint(imageLoadBp.Addr, proc.UserBreakpoint, nil)
+			assertNoError(err, t, "SetBreakpoint(UserBreakpoint)")
+			if bp != imageLoadBp || bp.LogicalID != id {
+				t.Errorf("wrong ID for user breakpoint on image load breakpoint %d, expected %d", bp.LogicalID, id)
+			}
+			_, err = p.ClearBreakpoint(imageLoadBp.Addr)
+			assertNoError(err, t, "ClearBreakpoint()")
+			if p.Breakpoints().M[imageLoadBp.Addr] != imageLoadBp || imageLoadBp.Kind != proc.ImageLoadBreakpoint || imageLoadBp.LogicalID != 0 {
+				t.Errorf("image load breakpoint not restored after clearing user breakpoint: %v", imageLoadBp)
+			}
+		}
+	})
+}
+
 func TestIssue1601(t *testing.T) {
 	protest.MustHaveCgo(t)
 	//Tests that recursive types involving C qualifiers and typedefs are parsed correctly
@@ -4476,6 +4771,99 @@ func TestCgoStacktrace2(t *testing.T) {
 	})
 }
 
+func TestCgoSignalStacktrace(t *testing.T) {
+	// Stacktraces of a signal handler should continue through the signal
+	// frame into the interrupted C code, which is only described by
+	// .eh_frame and does not use frame pointers.
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("linux/amd64 and linux/arm64 only")
+	}
+	protest.MustHaveCgo(t)
+	withTestProcess("cgosigstack", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "C.sighandler")
+		assertNoError(p.Continue(), t, "Continue()")
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 100)
+		assertNoError(err, t, "Stacktrace()")
+		logStacktrace(t, p.BinInfo(), frames)
+		if stacktraceCheck(t, []string{"C.sighandler", "C.raiser", "C.testfn"}, frames) == nil {
+			t.Fatal("stack trace does not go through the signal frame")
+		}
+	})
+}
+
+func TestFramePointerFallback(t *testing.T) {
+	// The frame descriptor entry of main.asmfn is wrong, the stacktrace
+	// should recover by following the frame pointer.
+	if runtime.GOARCH != "amd64" {
+		t.Skip("amd64 only")
+	}
+	withTestProcess("badframe/", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.gofn")
+		assertNoError(p.Continue(), t, "Continue()")
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 20)
+		assertNoError(err, t, "Stacktrace()")
+		logStacktrace(t, p.BinInfo(), frames)
+		m := stacktraceCheck(t, []string{"main.gofn", "main.asmfn", "main.main"}, frames)
+		if m == nil {
+			t.Fatal("stack trace does not reach main.main")
+		}
+		if m[2] != m[1]+1 {
+			t.Errorf("unexpected frames between main.asmfn and main.main")
+		}
+		for i, fnname := range []string{"main.gofn", "main.asmfn", "main.main"} {
+			if frames[m[i]].UnwoundByFramePointer != (fnname == "main.main") {
+				t.Errorf("wrong UnwoundByFramePointer for frame %s: %v", fnname, frames[m[i]].UnwoundByFramePointer)
+			}
+		}
+	})
+}
+
+func TestFramePointerFallbackCgo(t *testing.T) {
+	// The frame descriptor entries of the C functions and of
+	// runtime.asmcgocall are correct, the frame pointer fallback must not
+	// replace them and the stack switches done by runtime.asmcgocall and
+	// runtime.systemstack must still be followed.
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skip("frame pointer fallback only enabled on amd64 and arm64")
+	}
+	protest.MustHaveCgo(t)
+	withTestProcess("cgostacktest/", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "C.helloworld_pt2")
+		assertNoError(p.Continue(), t, "first continue") // runtime.Breakpoint in main.main
+		assertNoError(p.Continue(), t, "second continue")
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 100)
+		assertNoError(err, t, "Stacktrace()")
+		logStacktrace(t, p.BinInfo(), frames)
+		m := stacktraceCheck(t, []string{"!C.helloworld_pt2", "!C.helloworld", "!runtime.asmcgocall", "main.main"}, frames)
+		if m == nil {
+			t.Fatal("see previous loglines")
+		}
+		for i := 0; i <= m[2]; i++ {
+			if frames[i].UnwoundByFramePointer {
+				t.Errorf("frame %d (%#x) unwound by frame pointer", i, frames[i].Current.PC)
+			}
+		}
+	})
+	withTestProcess("panic", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "runtime.startpanic_m")
+		assertNoError(p.Continue(), t, "first continue")
+		assertNoError(p.Continue(), t, "second continue")
+		g, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG")
+		frames, err := g.Stacktrace(100, 0)
+		assertNoError(err, t, "stacktrace")
+		logStacktrace(t, p.BinInfo(), frames)
+		if stacktraceCheck(t, []string{"!runtime.startpanic_m", "runtime.gopanic", "main.main"}, frames) == nil {
+			t.Fatal("see previous loglines")
+		}
+		for i := range frames {
+			if frames[i].UnwoundByFramePointer {
+				t.Errorf("frame %d (%#x) unwound by frame pointer", i, frames[i].Current.PC)
+			}
+		}
+	})
+}
+
 func TestIssue1656(t *testing.T) {
 	if runtime.GOARCH != "amd64" {
 		t.Skip("amd64 only")
@@ -4792,3 +5180,120 @@ func TestStepIntoWrapperForEmbeddedPointer(t *testing.T) {
 
 	}
 }
+
+func TestPclntabBinaryInfo(t *testing.T) {
+	// Functions and line tables of executables built without DWARF should be
+	// loaded from pclntab and match the ones in debug_info.
+	if runtime.GOOS != "linux" {
+		t.Skip("only supported on linux")
+	}
+	if buildMode == "pie" {
+		t.Skip("not enabled with buildmode=PIE")
+	}
+	fixture := protest.BuildFixture("testnextprog", 0)
+	fixtureNoDWARF := protest.BuildFixture("testnextprog", protest.LinkNoDWARF)
+
+	bi := proc.NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	assertNoError(bi.LoadBinaryInfo(fixture.Path, 0, proc.DebugInfoConfig{}), t, "LoadBinaryInfo(DWARF)")
+	bi2 := proc.NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+	assertNoError(bi2.LoadBinaryInfo(fixtureNoDWARF.Path, 0, proc.DebugInfoConfig{}), t, "LoadBinaryInfo(pclntab)")
+
+	found := false
+	for _, src := range bi2.Sources {
+		if src == fixture.Source {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("%s not found in sources", fixture.Source)
+	}
+
+	n := 0
+	for _, fn := range bi.Functions {
+		if !strings.HasPrefix(fn.Name, "main.") {
+			continue
+		}
+		n++
+		fn2 := bi2.LookupFunc[fn.Name]
+		if fn2 == nil {
+			t.Errorf("function %s not found", fn.Name)
+			continue
+		}
+		if fn2.Entry != fn.Entry || fn2.End != fn.End {
+			t.Errorf("function %s: range mismatch %#x-%#x %#x-%#x", fn.Name, fn.Entry, fn.End, fn2.Entry, fn2.End)
+			continue
+		}
+		for pc := fn.Entry; pc < fn.End; pc++ {
+			file, line, _ := bi.PCToLine(pc)
+			file2, line2, fn3 := bi2.PCToLine(pc)
+			if file != file2 || line != line2 || fn3 != fn2 {
+				t.Errorf("%#x: mismatch %s:%d %s:%d", pc, file, line, file2, line2)
+				break
+			}
+		}
+	}
+	if n == 0 {
+		t.Fatal("no functions found")
+	}
+
+	pcs, err := bi.LineToPC(fixture.Source, 20)
+	assertNoError(err, t, "LineToPC(DWARF)")
+	pcs2, err := bi2.LineToPC(fixture.Source, 20)
+	assertNoError(err, t, "LineToPC(pclntab)")
+	if pcs[0] != pcs2[0] {
+		t.Errorf("LineToPC mismatch %#x %#x", pcs, pcs2)
+	}
+}
+
+func TestStacktraceNoDWARF(t *testing.T) {
+	// Breakpoints, stack traces and goroutines should work on executables
+	// built without DWARF.
+	if runtime.GOOS != "linux" {
+		t.Skip("only supported on linux")
+	}
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 20) {
+		t.Skip("reading runtime.g without DWARF requires go1.20 or later")
+	}
+	if buildMode == "pie" {
+		t.Skip("not enabled with buildmode=PIE")
+	}
+	stacks := [][]loc{
+		{{4, "main.stacktraceme"}, {8, "main.func1"}, {16, "main.main"}},
+		{{4, "main.stacktraceme"}, {8, "main.func1"}, {12, "main.func2"}, {17, "main.main"}},
+	}
+	withTestProcessArgs("stacktraceprog", t, ".", []string{}, protest.LinkNoDWARF, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "main.stacktraceme")
+
+		for i := range stacks {
+			assertNoError(p.Continue(), t, "Continue()")
+			locations, err := p.SelectedGoroutine().Stacktrace(40, 0)
+			assertNoError(err, t, "Stacktrace()")
+
+			t.Logf("Stacktrace %d:\n", i)
+			for i := range locations {
+				t.Logf("\t%s:%d\n", locations[i].Call.File, locations[i].Call.Line)
+			}
+
+			if len(locations) != len(stacks[i])+2 {
+				t.Fatalf("Wrong stack trace size %d %d\n", len(locations), len(stacks[i])+2)
+			}
+			for j := range stacks[i] {
+				if !stacks[i][j].match(locations[j]) {
+					t.Fatalf("Wrong stack trace pos %d\n", j)
+				}
+			}
+		}
+
+		gs, _, err := proc.GoroutinesInfo(p, 0, 0)
+		assertNoError(err, t, "GoroutinesInfo")
+		found := false
+		for _, g := range gs {
+			if g.ID == p.SelectedGoroutine().ID {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("selected goroutine %d not found in %d goroutines", p.SelectedGoroutine().ID, len(gs))
+		}
+	})
+}
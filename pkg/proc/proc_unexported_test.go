@@ -1,7 +1,18 @@
 package proc
 
 import (
+	"debug/dwarf"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
 	"testing"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	protest "github.com/go-delve/delve/pkg/proc/test"
 )
 
 func TestAlignAddr(t *testing.T) {
@@ -73,3 +84,231 @@ func TestAlignAddr(t *testing.T) {
 		c(example.align, example.in+0x10000, example.tgt+0x10000)
 	}
 }
+
+func TestCCastRewrite(t *testing.T) {
+	for _, tc := range []struct{ in, out string }{
+		{"(struct foo*)p", `(*"C.struct_foo")(p)`},
+		{"(struct foo *)p.next[2].x + 1", `(*"C.struct_foo")(p.next[2].x) + 1`},
+		{"((union bar**)&x)", `((**"C.union_bar")(&x))`},
+		{"(unsigned long)x", `("C.unsigned long")(x)`},
+		{"(const char*)s", `(*"C.char")(s)`},
+		{"(void*)p", `(unsafe.Pointer)(p)`},
+		{"(foo_t)x", `("C.foo_t")(x)`},
+		{"(int)(x)", "(int)(x)"},
+		{"(x)*y", "(x)*y"},
+		{"f(x)", "f(x)"},
+	} {
+		out, _, err := rewriteQueryExpr(tc.in)
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if out != tc.out {
+			t.Errorf("%q: got %q expected %q", tc.in, out, tc.out)
+		}
+	}
+}
+
+func TestPrettyExprEnd(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		end int
+	}{
+		{"x.A} rest", 3},
+		{`x.m["}"]} rest`, 8},
+		{"x.r == '}'} rest", 10},
+		{"len(T{1, 2}.f)} rest", 14},
+		{"x.A", -1},
+		{`x.m["}]`, -1},
+	} {
+		if end := prettyExprEnd(tc.in); end != tc.end {
+			t.Errorf("%q: got %d expected %d", tc.in, end, tc.end)
+		}
+	}
+}
+
+func TestSubstituteTypes(t *testing.T) {
+	typ := func(off int, name string, kind reflect.Kind) godwarf.CommonType {
+		return godwarf.CommonType{Name: name, ByteSize: 8, ReflectKind: kind, Offset: dwarf.Offset(off)}
+	}
+	shape := &godwarf.IntType{BasicType: godwarf.BasicType{CommonType: typ(1, "go.shape.int", reflect.Int)}}
+	param := &godwarf.ParametricType{TypedefType: godwarf.TypedefType{CommonType: typ(2, ".param0", reflect.Int), Type: shape}}
+	inttyp := &godwarf.IntType{BasicType: godwarf.BasicType{CommonType: typ(3, "int", reflect.Int)}}
+	strtyp := &godwarf.StringType{StructType: godwarf.StructType{CommonType: typ(4, "string", reflect.String)}}
+	subst := func(t godwarf.Type) godwarf.Type {
+		if t == param {
+			return inttyp
+		}
+		return t
+	}
+
+	for _, tc := range []struct {
+		in  godwarf.Type
+		out string
+	}{
+		{param, "int"},
+		{shape, "go.shape.int"},
+		{&godwarf.PtrType{CommonType: typ(5, "*.param0", reflect.Ptr), Type: param}, "*int"},
+		{&godwarf.PtrType{CommonType: typ(6, "**.param0", reflect.Ptr), Type: &godwarf.PtrType{CommonType: typ(7, "*.param0", reflect.Ptr), Type: param}}, "**int"},
+		{&godwarf.ArrayType{CommonType: typ(8, "[2].param0", reflect.Array), Type: param, Count: 2}, "[2]int"},
+		{&godwarf.SliceType{StructType: godwarf.StructType{CommonType: typ(9, "[].param0", reflect.Slice), Field: []*godwarf.StructField{{Name: sliceArrayFieldName, Type: &godwarf.PtrType{CommonType: typ(10, "*.param0", reflect.Ptr), Type: param}}}}, ElemType: param}, "[]int"},
+		{&godwarf.MapType{TypedefType: godwarf.TypedefType{CommonType: typ(11, "map[string].param0", reflect.Map)}, KeyType: strtyp, ElemType: param}, "map[string]int"},
+		{&godwarf.ChanType{TypedefType: godwarf.TypedefType{CommonType: typ(12, "chan .param0", reflect.Chan)}, ElemType: param}, "chan int"},
+	} {
+		out := substituteTypes(tc.in, subst)
+		if out.String() != tc.out {
+			t.Errorf("%s: got %q expected %q", tc.in, out, tc.out)
+		}
+		if st, ok := out.(*godwarf.SliceType); ok {
+			if elem := st.Field[0].Type.(*godwarf.PtrType).Type; elem != inttyp {
+				t.Errorf("%s: wrong type of the array field %s", tc.in, elem)
+			}
+		}
+	}
+	if in := (&godwarf.PtrType{CommonType: typ(13, "*string", reflect.Ptr), Type: strtyp}); substituteTypes(in, subst) != in {
+		t.Errorf("type without parameters was copied")
+	}
+}
+
+func TestRegabiAssign(t *testing.T) {
+	basic := func(sz int64, name string) godwarf.BasicType {
+		return godwarf.BasicType{CommonType: godwarf.CommonType{ByteSize: sz, Name: name}}
+	}
+	intType := &godwarf.IntType{BasicType: basic(8, "int")}
+	int8Type := &godwarf.IntType{BasicType: basic(1, "int8")}
+	float64Type := &godwarf.FloatType{BasicType: basic(8, "float64")}
+	ptrType := &godwarf.PtrType{CommonType: godwarf.CommonType{ByteSize: 8, Name: "*uint8", Offset: 1}, Type: &godwarf.UintType{BasicType: basic(1, "uint8")}}
+	stringType := &godwarf.StringType{StructType: godwarf.StructType{
+		CommonType: godwarf.CommonType{ByteSize: 16, Name: "string", Offset: 2},
+		Field: []*godwarf.StructField{
+			{Name: "str", Type: ptrType, ByteOffset: 0},
+			{Name: "len", Type: intType, ByteOffset: 8},
+		},
+	}}
+	arrayType := &godwarf.ArrayType{CommonType: godwarf.CommonType{ByteSize: 16, Name: "[2]int", Offset: 3}, Type: intType, Count: 2}
+	structType := &godwarf.StructType{
+		CommonType: godwarf.CommonType{ByteSize: 16, Name: "main.T", Offset: 4},
+		Field: []*godwarf.StructField{
+			{Name: "x", Type: int8Type, ByteOffset: 0},
+			{Name: "y", Type: intType, ByteOffset: 8},
+		},
+	}
+
+	reg := func(regNum uint64, sz int) op.Piece {
+		return op.Piece{Size: sz, RegNum: regNum, IsRegister: true}
+	}
+	empty := func(sz int) op.Piece {
+		return op.Piece{Size: sz, IsEmpty: true}
+	}
+
+	type assignment struct {
+		pieces []op.Piece
+		off    int64
+	}
+
+	a := newRegabiAssigner(AMD64Arch("linux"))
+	var got []assignment
+	for _, typ := range []godwarf.Type{intType, stringType, float64Type, arrayType, structType} {
+		pieces, off := a.assign(typ)
+		got = append(got, assignment{pieces, off})
+	}
+	a.startResults()
+	for _, typ := range []godwarf.Type{intType, stringType} {
+		pieces, off := a.assign(typ)
+		got = append(got, assignment{pieces, off})
+	}
+
+	want := []assignment{
+		{[]op.Piece{reg(0, 8)}, 0},                      // int in RAX
+		{[]op.Piece{reg(3, 8), reg(2, 8)}, 0},           // string in RBX, RCX
+		{[]op.Piece{reg(17, 8)}, 0},                     // float64 in X0
+		{nil, 0},                                        // arrays with more than one element go on the stack
+		{[]op.Piece{reg(5, 1), empty(7), reg(4, 8)}, 0}, // struct in RDI, RSI
+		{[]op.Piece{reg(0, 8)}, 0},                      // results start again from RAX
+		{[]op.Piece{reg(3, 8), reg(2, 8)}, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assignment mismatch\ngot:  %v\nwant: %v", got, want)
+	}
+	if sz := a.frameSize(); sz != 16+9*8+15*8 {
+		t.Errorf("frame size mismatch: got %d", sz)
+	}
+
+	// when registers run out the whole value goes on the stack
+	a = newRegabiAssigner(AMD64Arch("linux"))
+	for i := 0; i < 8; i++ {
+		a.assign(intType)
+	}
+	if pieces, off := a.assign(stringType); pieces != nil || off != 0 {
+		t.Errorf("string assigned to %v %d, expected stack offset 0", pieces, off)
+	}
+	if pieces, _ := a.assign(intType); !reflect.DeepEqual(pieces, []op.Piece{reg(11, 8)}) {
+		t.Errorf("int assigned to %v, expected R11", pieces)
+	}
+	if pieces, off := a.assign(intType); pieces != nil || off != 16 {
+		t.Errorf("int assigned to %v %d, expected stack offset 16", pieces, off)
+	}
+}
+
+func TestDebugInfoIndexCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fixture := protest.BuildFixture("testvariables2", 0)
+
+	load := func() *BinaryInfo {
+		t.Helper()
+		bi := NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+		if err := bi.LoadBinaryInfo(fixture.Path, 0, DebugInfoConfig{IndexCacheDir: dir}); err != nil {
+			t.Fatal(err)
+		}
+		return bi
+	}
+
+	bi1 := load()
+	if bi1.Images[0].buildID == "" {
+		t.Skip("executable has no build ID")
+	}
+	if _, err := os.Stat(bi1.indexCachePath(bi1.Images[0])); err != nil {
+		t.Fatalf("index was not saved: %v", err)
+	}
+	bi2 := load()
+
+	funcs := func(bi *BinaryInfo) []string {
+		r := make([]string, len(bi.Functions))
+		for i, fn := range bi.Functions {
+			r[i] = fmt.Sprintf("%s %#x %#x %#x %s", fn.Name, fn.Entry, fn.End, fn.offset, fn.cu.name)
+		}
+		return r
+	}
+	typeNames := func(bi *BinaryInfo) []string {
+		r, err := bi.Types()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(r)
+		return r
+	}
+
+	if !reflect.DeepEqual(funcs(bi1), funcs(bi2)) {
+		t.Errorf("functions mismatch")
+	}
+	if !reflect.DeepEqual(bi1.Sources, bi2.Sources) {
+		t.Errorf("sources mismatch")
+	}
+	if !reflect.DeepEqual(bi1.PackageMap, bi2.PackageMap) {
+		t.Errorf("package map mismatch")
+	}
+	if !reflect.DeepEqual(bi1.inlinedCallLines, bi2.inlinedCallLines) {
+		t.Errorf("inlined call lines mismatch")
+	}
+	if !reflect.DeepEqual(typeNames(bi1), typeNames(bi2)) {
+		t.Errorf("types mismatch")
+	}
+	if len(bi1.consts) != len(bi2.consts) || len(bi1.packageVars) != len(bi2.packageVars) {
+		t.Errorf("constants or package variables mismatch")
+	}
+}
@@ -0,0 +1,158 @@
+package proc
+
+import (
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/op"
+)
+
+// regabiAssigner assigns the arguments and return values of a function
+// either to registers or to the argument frame, following the register
+// based calling convention (ABIInternal) described in
+// $GOROOT/src/cmd/compile/abi-internal.md.
+//
+// The receiver and the arguments must be assigned first, in declaration
+// order, followed by a call to startResults and then by the return values,
+// also in declaration order.
+type regabiAssigner struct {
+	arch *Arch
+
+	intRegs   int   // number of integer registers assigned
+	floatRegs int   // number of floating point registers assigned
+	stackOff  int64 // size of the stack assigned part of the argument frame
+
+	// pieces and off describe the value being assigned: the pieces it is
+	// split into and the offset of the end of the last piece.
+	pieces []op.Piece
+	off    int64
+}
+
+func newRegabiAssigner(arch *Arch) *regabiAssigner {
+	return &regabiAssigner{arch: arch}
+}
+
+// assign assigns a value of type typ. If the value is assigned to registers
+// the pieces describing its location are returned, otherwise pieces is nil
+// and off is the offset of the value in the argument frame.
+func (a *regabiAssigner) assign(typ godwarf.Type) (pieces []op.Piece, off int64) {
+	intRegs, floatRegs := a.intRegs, a.floatRegs
+	a.pieces, a.off = nil, 0
+	if a.regAssign(typ, 0) {
+		if typ.Size() == 0 {
+			return nil, a.stackOff
+		}
+		a.pad(typ.Size())
+		return a.pieces, 0
+	}
+
+	// stack assignment
+	a.intRegs, a.floatRegs = intRegs, floatRegs
+	a.stackOff = alignAddr(a.stackOff, typ.Align())
+	off = a.stackOff
+	a.stackOff += typ.Size()
+	return nil, off
+}
+
+// startResults must be called after all the arguments have been assigned
+// and before assigning the first return value.
+func (a *regabiAssigner) startResults() {
+	a.stackOff = alignAddr(a.stackOff, int64(a.arch.PtrSize()))
+	a.intRegs, a.floatRegs = 0, 0
+}
+
+// frameSize returns the size of the argument frame, including the spill
+// area for the arguments passed in registers.
+// Because the exact size of the spill area depends on the alignment of the
+// arguments assigned to registers it is computed conservatively, assuming
+// that all registers are used.
+func (a *regabiAssigner) frameSize() int64 {
+	return alignAddr(a.stackOff, int64(a.arch.PtrSize())) + int64(a.arch.maxRegArgBytes)
+}
+
+// regAssign tries to assign a value of type typ, stored at offset base of
+// the value being assigned, to registers. Returns false if there aren't
+// enough registers or if the value can not be assigned to registers.
+func (a *regabiAssigner) regAssign(typ godwarf.Type, base int64) bool {
+	typ = resolveTypedef(typ)
+	if typ.Size() == 0 {
+		return true
+	}
+	ptrSize := int64(a.arch.PtrSize())
+	switch t := typ.(type) {
+	case *godwarf.BoolType, *godwarf.IntType, *godwarf.UintType, *godwarf.CharType, *godwarf.UcharType, *godwarf.PtrType, *godwarf.ChanType, *godwarf.MapType, *godwarf.FuncType:
+		// integer values larger than a register, such as int64 on 32bit
+		// architectures, are split across multiple registers.
+		for off := int64(0); off < t.Size(); off += ptrSize {
+			sz := t.Size() - off
+			if sz > ptrSize {
+				sz = ptrSize
+			}
+			if !a.intReg(base+off, sz) {
+				return false
+			}
+		}
+		return true
+	case *godwarf.FloatType:
+		return a.floatReg(base, t.Size())
+	case *godwarf.ComplexType:
+		return a.floatReg(base, t.Size()/2) && a.floatReg(base+t.Size()/2, t.Size()/2)
+	case *godwarf.InterfaceType:
+		return a.intReg(base, ptrSize) && a.intReg(base+ptrSize, ptrSize)
+	case *godwarf.StringType:
+		return a.regAssignStruct(&t.StructType, base)
+	case *godwarf.SliceType:
+		return a.regAssignStruct(&t.StructType, base)
+	case *godwarf.StructType:
+		return a.regAssignStruct(t, base)
+	case *godwarf.ArrayType:
+		switch t.Count {
+		case 0:
+			return true
+		case 1:
+			return a.regAssign(t.Type, base)
+		}
+		return false
+	}
+	return false
+}
+
+func (a *regabiAssigner) regAssignStruct(t *godwarf.StructType, base int64) bool {
+	for _, field := range t.Field {
+		if !a.regAssign(field.Type, base+field.ByteOffset) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *regabiAssigner) intReg(off, sz int64) bool {
+	if a.intRegs >= len(a.arch.argumentRegs) {
+		return false
+	}
+	a.addRegPiece(a.arch.argumentRegs[a.intRegs], off, sz)
+	a.intRegs++
+	return true
+}
+
+func (a *regabiAssigner) floatReg(off, sz int64) bool {
+	if a.floatRegs >= len(a.arch.floatArgumentRegs) {
+		return false
+	}
+	a.addRegPiece(a.arch.floatArgumentRegs[a.floatRegs], off, sz)
+	a.floatRegs++
+	return true
+}
+
+func (a *regabiAssigner) addRegPiece(regNum uint64, off, sz int64) {
+	a.pad(off)
+	a.pieces = append(a.pieces, op.Piece{Size: int(sz), RegNum: regNum, IsRegister: true})
+	a.off = off + sz
+}
+
+// pad adds an empty piece to fill the space between the last piece and
+// off.
+func (a *regabiAssigner) pad(off int64) {
+	if off > a.off {
+		a.pieces = append(a.pieces, op.Piece{Size: int(off - a.off), IsEmpty: true})
+		a.off = off
+	}
+}
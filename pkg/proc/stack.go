@@ -1,10 +1,12 @@
 package proc
 
 import (
+	"bytes"
 	"debug/dwarf"
 	"errors"
 	"fmt"
 	"go/constant"
+	"strings"
 
 	"github.com/go-delve/delve/pkg/dwarf/frame"
 	"github.com/go-delve/delve/pkg/dwarf/op"
@@ -48,6 +50,10 @@ type Stackframe struct {
 	Inlined bool
 	// Bottom is true if this is the bottom of the stack
 	Bottom bool
+	// UnwoundByFramePointer is true if this frame was found by following the
+	// frame pointer of the previous frame, because its frame descriptor
+	// entry was missing or wrong.
+	UnwoundByFramePointer bool
 
 	// lastpc is a memory address guaranteed to belong to the last instruction
 	// executed in this stack frame.
@@ -195,6 +201,12 @@ type stackIterator struct {
 	g0_sched_sp        uint64 // value of g0.sched.sp (see comments around its use)
 	g0_sched_sp_loaded bool   // g0_sched_sp was loaded from g0
 
+	sigframe    bool // the current frame is a signal return trampoline
+	interrupted bool // the current frame was interrupted by a signal, pc is not a return address
+
+	usedFramePointer bool // the current frame was unwound by following the frame pointer
+	fpunwound        bool // the current frame was found by following the frame pointer
+
 	opts StacktraceOptions
 }
 
@@ -238,6 +250,8 @@ func (it *stackIterator) Next() bool {
 
 	callFrameRegs, ret, retaddr := it.advanceRegs()
 	it.frame = it.newStackframe(ret, retaddr)
+	it.interrupted = false
+	it.fpunwound = false
 
 	if it.stkbar != nil && it.frame.Ret == it.stackBarrierPC && it.frame.addrret == it.stkbar[0].ptr {
 		// Skip stack barrier frames
@@ -257,6 +271,8 @@ func (it *stackIterator) Next() bool {
 	}
 
 	it.top = false
+	it.interrupted = it.sigframe
+	it.fpunwound = it.usedFramePointer
 	it.pc = it.frame.Ret
 	it.regs = callFrameRegs
 	return true
@@ -307,9 +323,9 @@ func (it *stackIterator) newStackframe(ret, retaddr uint64) Stackframe {
 	} else {
 		it.regs.FrameBase = it.frameBase(fn)
 	}
-	r := Stackframe{Current: Location{PC: it.pc, File: f, Line: l, Fn: fn}, Regs: it.regs, Ret: ret, addrret: retaddr, stackHi: it.stackhi, SystemStack: it.systemstack, lastpc: it.pc}
+	r := Stackframe{Current: Location{PC: it.pc, File: f, Line: l, Fn: fn}, Regs: it.regs, Ret: ret, addrret: retaddr, stackHi: it.stackhi, SystemStack: it.systemstack, UnwoundByFramePointer: it.fpunwound, lastpc: it.pc}
 	r.Call = r.Current
-	if !it.top && r.Current.Fn != nil && it.pc != r.Current.Fn.Entry {
+	if !it.top && !it.interrupted && r.Current.Fn != nil && it.pc != r.Current.Fn.Entry {
 		// if the return address is the entry point of the function that
 		// contains it then this is some kind of fake return frame (for example
 		// runtime.sigreturn) that didn't actually call the current frame,
@@ -377,7 +393,8 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 		if !okname || !okfileidx || !okline {
 			break
 		}
-		if fileidx-1 < 0 || fileidx-1 >= int64(len(frame.Current.Fn.cu.lineInfo.FileNames)) {
+		fileEntry := frame.Current.Fn.cu.lineInfo.FileAt(uint64(fileidx))
+		if fileidx < 0 || fileEntry == nil {
 			break
 		}
 
@@ -398,9 +415,11 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 			SystemStack: frame.SystemStack,
 			Inlined:     true,
 			lastpc:      frame.lastpc,
+
+			UnwoundByFramePointer: frame.UnwoundByFramePointer,
 		})
 
-		frame.Call.File = frame.Current.Fn.cu.lineInfo.FileNames[fileidx-1].Path
+		frame.Call.File = fileEntry.Path
 		frame.Call.Line = int(line)
 	}
 
@@ -411,18 +430,71 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 // descriptor entry for the current stack frame.
 // it.regs.CallFrameCFA is updated.
 func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uint64, retaddr uint64) {
+	it.sigframe = false
+	it.usedFramePointer = false
+	if callFrameRegs, ret, retaddr, ok := it.sigframeRegs(); ok {
+		it.sigframe = true
+		return callFrameRegs, ret, retaddr
+	}
+
+	fpfallback := it.bi.Arch.Name == "amd64" || it.bi.Arch.Name == "arm64"
+
 	fde, err := it.bi.frameEntries.FDEForPC(it.pc)
 	var framectx *frame.FrameContext
-	if _, nofde := err.(*frame.ErrNoFDEForPC); nofde {
+	_, nofde := err.(*frame.ErrNoFDEForPC)
+	if nofde {
 		framectx = it.bi.Arch.fixFrameUnwindContext(nil, it.pc, it.bi)
+		it.usedFramePointer = fpfallback
 	} else {
 		framectx = it.bi.Arch.fixFrameUnwindContext(fde.EstablishFrame(it.pc), it.pc, it.bi)
 	}
 
-	cfareg, err := it.executeFrameRegRule(0, framectx.CFA, 0)
+	cfa := it.regs.CFA
+	callFrameRegs, ret, retaddr, err = it.executeFrameContext(framectx)
+	fpbroken := false
+
+	if fpfallback && !nofde && !it.validCallFrame(callFrameRegs, ret, err) {
+		// The frame descriptor entry is wrong, for example because this is an
+		// assembly function that manipulates the stack pointer, fall back to
+		// following the frame pointer chain.
+		fdeCFA := it.regs.CFA
+		it.regs.CFA = cfa
+		fpRegs, fpRet, fpRetaddr, fpErr := it.executeFrameContext(it.bi.Arch.fixFrameUnwindContext(nil, it.pc, it.bi))
+		if fpRet != 0 && it.validCallFrame(fpRegs, fpRet, fpErr) {
+			callFrameRegs, ret, retaddr, err = fpRegs, fpRet, fpRetaddr, fpErr
+			it.usedFramePointer = true
+		} else {
+			it.regs.CFA = fdeCFA
+		}
+	} else if it.usedFramePointer && !it.validCallFrame(callFrameRegs, ret, err) {
+		// The frame pointer chain was broken, stop here rather than returning
+		// garbage frames.
+		if err == nil {
+			err = fmt.Errorf("could not unwind frame at %#x using the frame pointer", it.pc)
+		}
+		ret = 0
+		fpbroken = true
+	}
+
+	if err != nil {
+		it.err = err
+	}
+
+	if it.bi.Arch.Name == "arm64" && !fpbroken {
+		if ret == 0 && it.regs.Reg(it.regs.LRRegNum) != nil {
+			ret = it.regs.Reg(it.regs.LRRegNum).Uint64Val
+		}
+	}
+
+	return callFrameRegs, ret, retaddr
+}
+
+// executeFrameContext calculates the registers of the calling frame using
+// it.regs and the rules in framectx. it.regs.CFA is updated.
+func (it *stackIterator) executeFrameContext(framectx *frame.FrameContext) (callFrameRegs op.DwarfRegisters, ret uint64, retaddr uint64, err error) {
+	cfareg, _ := it.executeFrameRegRule(0, framectx.CFA, 0)
 	if cfareg == nil {
-		it.err = fmt.Errorf("CFA becomes undefined at PC %#x", it.pc)
-		return op.DwarfRegisters{}, 0, 0
+		return op.DwarfRegisters{}, 0, 0, fmt.Errorf("CFA becomes undefined at PC %#x", it.pc)
 	}
 	it.regs.CFA = int64(cfareg.Uint64Val)
 
@@ -440,14 +512,14 @@ func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uin
 	callFrameRegs.AddReg(callFrameRegs.SPRegNum, cfareg)
 
 	for i, regRule := range framectx.Regs {
-		reg, err := it.executeFrameRegRule(i, regRule, it.regs.CFA)
+		reg, rerr := it.executeFrameRegRule(i, regRule, it.regs.CFA)
 		callFrameRegs.AddReg(i, reg)
 		if i == framectx.RetAddrReg {
 			if reg == nil {
-				if err == nil {
-					err = fmt.Errorf("Undefined return address at %#x", it.pc)
+				if rerr == nil {
+					rerr = fmt.Errorf("Undefined return address at %#x", it.pc)
 				}
-				it.err = err
+				err = rerr
 			} else {
 				ret = reg.Uint64Val
 			}
@@ -455,13 +527,102 @@ func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uin
 		}
 	}
 
-	if it.bi.Arch.Name == "arm64" {
-		if ret == 0 && it.regs.Reg(it.regs.LRRegNum) != nil {
-			ret = it.regs.Reg(it.regs.LRRegNum).Uint64Val
+	return callFrameRegs, ret, retaddr, err
+}
+
+// validCallFrame returns true if the calling frame computed by
+// executeFrameContext looks plausible: the stack pointer must increase and
+// the return address must belong to a known text segment.
+// On arm64 the stack pointer of the topmost frame (or of a frame
+// interrupted by a signal) can be the same as the one of its caller,
+// because leaf functions do not always allocate a frame and keep the
+// return address in the link register.
+func (it *stackIterator) validCallFrame(callFrameRegs op.DwarfRegisters, ret uint64, err error) bool {
+	if err != nil {
+		return false
+	}
+	if callFrameRegs.Reg(callFrameRegs.SPRegNum) == nil {
+		return false
+	}
+	if sp := callFrameRegs.SP(); sp < it.regs.SP() || (sp == it.regs.SP() && !(it.bi.Arch.Name == "arm64" && (it.top || it.interrupted))) {
+		return false
+	}
+	if ret == 0 {
+		return true
+	}
+	return it.bi.pcInText(ret) || it.isSigreturnTrampoline(ret)
+}
+
+// sigframeLayout describes the frame that the linux kernel pushes on the
+// stack before calling a signal handler (struct rt_sigframe). The signal
+// handler returns into a trampoline that calls rt_sigreturn, the context
+// interrupted by the signal is saved inside the signal frame.
+type sigframeLayout struct {
+	// trampolines are the possible instruction sequences of the signal
+	// return trampoline.
+	trampolines [][]byte
+	// sigcontextOff is the offset of the saved registers (struct
+	// sigcontext) from the stack pointer of the trampoline.
+	sigcontextOff uint64
+	// regs maps DWARF register numbers to their offset inside struct
+	// sigcontext.
+	regs map[uint64]uint64
+}
+
+func linuxSigframeIf(goos string, layout *sigframeLayout) *sigframeLayout {
+	if goos != "linux" {
+		return nil
+	}
+	return layout
+}
+
+// sigframeRegs checks if it.pc is a signal return trampoline, either
+// the one installed by the Go runtime or the one installed by the C
+// library (__restore_rt), and if it is reads the registers of the
+// interrupted context from the signal frame.
+func (it *stackIterator) sigframeRegs() (callFrameRegs op.DwarfRegisters, ret uint64, retaddr uint64, ok bool) {
+	layout := it.bi.Arch.linuxSigframe
+	if layout == nil || it.regs.Reg(it.regs.SPRegNum) == nil {
+		return op.DwarfRegisters{}, 0, 0, false
+	}
+	if fn := it.bi.PCToFunc(it.pc); fn != nil && fn.cu.isgo && !strings.HasPrefix(fn.Name, "runtime.sigreturn") {
+		return op.DwarfRegisters{}, 0, 0, false
+	}
+
+	if !it.isSigreturnTrampoline(it.pc) {
+		return op.DwarfRegisters{}, 0, 0, false
+	}
+
+	sigcontext := it.regs.SP() + layout.sigcontextOff
+	callimage := it.bi.PCToImage(it.pc)
+	callFrameRegs = op.DwarfRegisters{StaticBase: callimage.StaticBase, ByteOrder: it.regs.ByteOrder, PCRegNum: it.regs.PCRegNum, SPRegNum: it.regs.SPRegNum, BPRegNum: it.regs.BPRegNum, LRRegNum: it.regs.LRRegNum}
+	for regnum, off := range layout.regs {
+		reg, err := it.readRegisterAt(regnum, sigcontext+off)
+		if err != nil {
+			return op.DwarfRegisters{}, 0, 0, false
 		}
+		callFrameRegs.AddReg(regnum, reg)
 	}
 
-	return callFrameRegs, ret, retaddr
+	it.regs.CFA = int64(it.regs.SP())
+	retaddr = sigcontext + layout.regs[it.regs.PCRegNum]
+	return callFrameRegs, callFrameRegs.Uint64Val(it.regs.PCRegNum), retaddr, true
+}
+
+// isSigreturnTrampoline returns true if pc is the start of a signal
+// return trampoline.
+func (it *stackIterator) isSigreturnTrampoline(pc uint64) bool {
+	layout := it.bi.Arch.linuxSigframe
+	if layout == nil {
+		return false
+	}
+	for _, code := range layout.trampolines {
+		buf := make([]byte, len(code))
+		if _, err := it.mem.ReadMemory(buf, uintptr(pc)); err == nil && bytes.Equal(buf, code) {
+			return true
+		}
+	}
+	return false
 }
 
 func (it *stackIterator) executeFrameRegRule(regnum uint64, rule frame.DWRule, cfa int64) (*op.DwarfRegister, error) {
@@ -599,7 +760,7 @@ func (g *G) readDefers(frames []Stackframe) {
 }
 
 func (d *Defer) load() {
-	d.variable.loadValue(LoadConfig{false, 1, 0, 0, -1, 0})
+	d.variable.loadValue(LoadConfig{false, 1, 0, 0, -1, 0, false})
 	if d.variable.Unreadable != nil {
 		d.Unreadable = d.variable.Unreadable
 		return
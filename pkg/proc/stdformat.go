@@ -0,0 +1,506 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"math/big"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// maxFormatBytes is the maximum number of bytes read from the target's
+// memory by a builtin formatter.
+const maxFormatBytes = 64 * 1024
+
+// maxFormatElements is the maximum number of elements of a container
+// loaded by a builtin formatter, regardless of the load configuration.
+const maxFormatElements = 1024
+
+// builtinFormatters are the formatters used to display variables of
+// commonly used standard library types, keyed by the name of their DWARF
+// type. They decode the value directly from the target's memory, without
+// calling functions.
+// A formatter returns nil if it does not recognize the layout of the
+// variable, in which case the variable is displayed normally.
+var builtinFormatters = map[string]func(v *Variable, cfg LoadConfig) (*PrettyValue, error){
+	"time.Time":           formatTime,
+	"time.Duration":       formatDuration,
+	"math/big.Int":        formatBigInt,
+	"net.IP":              formatIP,
+	"sync.Mutex":          formatMutex,
+	"strings.Builder":     formatStringsBuilder,
+	"bytes.Buffer":        formatBytesBuffer,
+	"reflect.Value":       formatReflectValue,
+	"sync.Map":            formatSyncMap,
+	"container/list.List": formatList,
+}
+
+var errUnknownLayout = errors.New("unknown layout")
+
+// formatElementsLimit returns the maximum number of elements of a
+// container loaded by a builtin formatter using cfg.
+func formatElementsLimit(cfg LoadConfig) int {
+	if cfg.MaxArrayValues < 0 || cfg.MaxArrayValues > maxFormatElements {
+		return maxFormatElements
+	}
+	return cfg.MaxArrayValues
+}
+
+// applyBuiltinFormatter sets v.Pretty using the builtin formatter for the
+// type of v, if there is one.
+func (v *Variable) applyBuiltinFormatter(cfg LoadConfig) {
+	f := builtinFormatters[v.TypeString()]
+	if f == nil {
+		return
+	}
+	pretty, err := f(v, cfg)
+	if err != nil || pretty == nil {
+		return
+	}
+	v.Pretty = pretty
+	v.Flags |= VariableFormatted
+}
+
+// loadMember returns the member name of v, loaded using cfg.
+func (v *Variable) loadMember(name string, cfg LoadConfig) (*Variable, error) {
+	f, err := v.structMember(name)
+	if err != nil {
+		return nil, errUnknownLayout
+	}
+	f.loadValue(cfg)
+	if f.Unreadable != nil {
+		return nil, f.Unreadable
+	}
+	return f, nil
+}
+
+func (v *Variable) loadInt(name string) (int64, error) {
+	f, err := v.loadMember(name, loadSingleValue)
+	if err != nil {
+		return 0, err
+	}
+	if f.Value == nil || f.Value.Kind() != constant.Int {
+		return 0, errUnknownLayout
+	}
+	n, _ := constant.Int64Val(f.Value)
+	return n, nil
+}
+
+func (v *Variable) loadUint(name string) (uint64, error) {
+	f, err := v.loadMember(name, loadSingleValue)
+	if err != nil {
+		return 0, err
+	}
+	if f.Value == nil || f.Value.Kind() != constant.Int {
+		return 0, errUnknownLayout
+	}
+	n, _ := constant.Uint64Val(f.Value)
+	return n, nil
+}
+
+// sliceBytes reads at most max bytes of the []byte slice s, starting at
+// offset off. Returns the bytes read and the number of bytes available.
+func (s *Variable) sliceBytes(off, max int64) ([]byte, int64, error) {
+	if s.Kind != reflect.Slice {
+		return nil, 0, errUnknownLayout
+	}
+	if elem := resolveTypedef(s.RealType.(*godwarf.SliceType).ElemType); elem.Size() != 1 {
+		return nil, 0, errUnknownLayout
+	}
+	if s.Len < 0 || off < 0 || off > s.Len {
+		return nil, 0, errUnknownLayout
+	}
+	// s.Len is read from the target and could be garbage, never allocate
+	// more than max bytes.
+	n := s.Len - off
+	sz := n
+	if sz > max {
+		sz = max
+	}
+	if sz < 0 {
+		sz = 0
+	}
+	buf := make([]byte, sz)
+	if len(buf) > 0 {
+		if _, err := s.mem.ReadMemory(buf, uintptr(s.Base+uintptr(off))); err != nil {
+			return nil, 0, err
+		}
+	}
+	return buf, n, nil
+}
+
+// quoteBytes returns buf as a quoted string, followed by the number of
+// omitted bytes if buf contains less than n bytes.
+func quoteBytes(buf []byte, n int64) string {
+	s := strconv.Quote(string(buf))
+	if int64(len(buf)) < n {
+		s += fmt.Sprintf("...+%d more", n-int64(len(buf)))
+	}
+	return s
+}
+
+const (
+	timeSecondsPerDay    = 24 * 60 * 60
+	timeHasMonotonic     = 1 << 63
+	timeNsecMask         = 1<<30 - 1
+	timeNsecShift        = 30
+	timeWallToInternal   = (1884*365 + 1884/4 - 1884/100 + 1884/400) * timeSecondsPerDay
+	timeUnixToInternal   = (1969*365 + 1969/4 - 1969/100 + 1969/400) * timeSecondsPerDay
+	timeFormatWithZoneID = "2006-01-02 15:04:05.999999999 -0700 MST"
+)
+
+// timeUnix converts the wall and ext fields of a time.Time to the number
+// of seconds and nanoseconds elapsed since the Unix epoch.
+// See the documentation of time.Time in $GOROOT/src/time/time.go.
+func timeUnix(wall uint64, ext int64) (sec, nsec int64) {
+	nsec = int64(wall & timeNsecMask)
+	if wall&timeHasMonotonic != 0 {
+		sec = timeWallToInternal + int64(wall<<1>>(timeNsecShift+1))
+	} else {
+		sec = ext
+	}
+	return sec - timeUnixToInternal, nsec
+}
+
+func formatTime(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	var sec, nsec int64
+	wall, err := v.loadUint("wall")
+	if err == nil {
+		ext, err := v.loadInt("ext")
+		if err != nil {
+			return nil, err
+		}
+		sec, nsec = timeUnix(wall, ext)
+	} else {
+		// before Go 1.9
+		sec, err = v.loadInt("sec")
+		if err != nil {
+			return nil, err
+		}
+		nsec, err = v.loadInt("nsec")
+		if err != nil {
+			return nil, err
+		}
+		sec -= timeUnixToInternal
+	}
+
+	loc := time.UTC
+	locv, err := v.loadMember("loc", loadSingleValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(locv.Children) > 0 && locv.Children[0].Addr != 0 {
+		loc = timeLocation(locv, sec)
+	}
+
+	return &PrettyValue{Value: time.Unix(sec, nsec).In(loc).Format(timeFormatWithZoneID)}, nil
+}
+
+// timeLocation returns the zone in effect at sec for the *time.Location
+// locv, using the zone cached by the Location. Locations without a cached
+// zone are looked up by name on the local machine.
+func timeLocation(locv *Variable, sec int64) *time.Location {
+	name := ""
+	if namev, err := locv.loadMember("name", loadFullValue); err == nil && namev.Value != nil {
+		name = constant.StringVal(namev.Value)
+	}
+	start, err1 := locv.loadInt("cacheStart")
+	end, err2 := locv.loadInt("cacheEnd")
+	zonev, err3 := locv.loadMember("cacheZone", loadSingleValue)
+	if err1 == nil && err2 == nil && err3 == nil && start <= sec && sec < end && len(zonev.Children) > 0 && zonev.Children[0].Addr != 0 {
+		zname, err1 := zonev.loadMember("name", loadFullValue)
+		offset, err2 := zonev.loadInt("offset")
+		if err1 == nil && err2 == nil && zname.Value != nil {
+			return time.FixedZone(constant.StringVal(zname.Value), int(offset))
+		}
+	}
+	if name == "" || name == "UTC" {
+		return time.UTC
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.FixedZone(name, 0)
+}
+
+func formatDuration(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	if v.Value == nil || v.Value.Kind() != constant.Int {
+		return nil, errUnknownLayout
+	}
+	n, _ := constant.Int64Val(v.Value)
+	return &PrettyValue{Value: time.Duration(n).String()}, nil
+}
+
+func formatBigInt(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	negv, err := v.loadMember("neg", loadSingleValue)
+	if err != nil {
+		return nil, err
+	}
+	absv, err := v.loadMember("abs", LoadConfig{})
+	if err != nil || absv.Kind != reflect.Slice {
+		return nil, errUnknownLayout
+	}
+	wordSize := resolveTypedef(absv.RealType.(*godwarf.SliceType).ElemType).Size()
+	if wordSize != 4 && wordSize != 8 {
+		return nil, errUnknownLayout
+	}
+	if absv.Len*wordSize > maxFormatBytes {
+		return &PrettyValue{Value: fmt.Sprintf("<%d bits integer>", absv.Len*wordSize*8)}, nil
+	}
+	buf := make([]byte, absv.Len*wordSize)
+	if len(buf) > 0 {
+		if _, err := absv.mem.ReadMemory(buf, absv.Base); err != nil {
+			return nil, err
+		}
+	}
+	// abs is little endian, SetBytes wants big endian
+	be := make([]byte, len(buf))
+	for i := range buf {
+		be[len(buf)-1-i] = buf[i]
+	}
+	n := new(big.Int).SetBytes(be)
+	if negv.Value != nil && constant.BoolVal(negv.Value) {
+		n.Neg(n)
+	}
+	return &PrettyValue{Value: n.String()}, nil
+}
+
+func formatIP(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	buf, n, err := v.sliceBytes(0, net.IPv6len)
+	if err != nil {
+		return nil, err
+	}
+	if n != net.IPv4len && n != net.IPv6len {
+		return nil, errUnknownLayout
+	}
+	return &PrettyValue{Value: net.IP(buf).String()}, nil
+}
+
+const (
+	mutexLocked      = 1
+	mutexWoken       = 2
+	mutexStarving    = 4
+	mutexWaiterShift = 3
+)
+
+func formatMutex(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	state, err := v.loadInt("state")
+	if err != nil {
+		return nil, err
+	}
+	s := []string{"unlocked"}
+	if state&mutexLocked != 0 {
+		s[0] = "locked"
+	}
+	if waiters := state >> mutexWaiterShift; waiters > 0 {
+		s = append(s, fmt.Sprintf("%d waiters", waiters))
+	}
+	if state&mutexWoken != 0 {
+		s = append(s, "woken")
+	}
+	if state&mutexStarving != 0 {
+		s = append(s, "starving")
+	}
+	return &PrettyValue{Value: strings.Join(s, ", ")}, nil
+}
+
+func formatStringsBuilder(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	bufv, err := v.loadMember("buf", LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	buf, n, err := bufv.sliceBytes(0, int64(cfg.MaxStringLen))
+	if err != nil {
+		return nil, err
+	}
+	return &PrettyValue{Value: quoteBytes(buf, n)}, nil
+}
+
+func formatBytesBuffer(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	bufv, err := v.loadMember("buf", LoadConfig{})
+	if err != nil {
+		return nil, err
+	}
+	off, err := v.loadInt("off")
+	if err != nil {
+		return nil, err
+	}
+	buf, n, err := bufv.sliceBytes(off, int64(cfg.MaxStringLen))
+	if err != nil {
+		return nil, err
+	}
+	return &PrettyValue{Value: quoteBytes(buf, n)}, nil
+}
+
+// flagIndir is set in the flag field of a reflect.Value when ptr points
+// to the value, rather than being the value itself.
+const reflectFlagIndir = 1 << 7
+
+func formatReflectValue(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	typv, err := v.loadMember("typ", loadSingleValue)
+	if err != nil {
+		typv, err = v.loadMember("typ_", loadSingleValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(typv.Children) == 0 || typv.Children[0].Addr == 0 {
+		return &PrettyValue{Value: "<invalid reflect.Value>"}, nil
+	}
+	ptrv, err := v.loadMember("ptr", loadSingleValue)
+	if err != nil {
+		return nil, err
+	}
+	flag, err := v.loadUint("flag")
+	if err != nil {
+		return nil, err
+	}
+	ptr, err := readUintRaw(ptrv.mem, ptrv.Addr, int64(v.bi.Arch.PtrSize()))
+	if err != nil {
+		return nil, err
+	}
+	typ, _, err := runtimeTypeToDIE(typv, uintptr(ptr))
+	if err != nil {
+		return nil, err
+	}
+
+	var val *Variable
+	if flag&reflectFlagIndir != 0 {
+		val = v.newVariable("value", uintptr(ptr), typ, DereferenceMemory(v.mem))
+	} else {
+		// pointer shaped values are stored in the ptr field itself
+		val = v.newVariable("value", ptrv.Addr, typ, v.mem)
+	}
+	val.loadValue(cfg)
+	return &PrettyValue{Value: typ.String(), Children: []*Variable{val}}, nil
+}
+
+func formatSyncMap(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	cfg.MaxArrayValues = formatElementsLimit(cfg)
+	// Entries of the dirty map are a superset of the entries of the read
+	// map, when the dirty map is nil the read map contains all entries.
+	m, err := v.loadMember("dirty", cfg)
+	if err != nil {
+		return nil, err
+	}
+	if m.Kind != reflect.Map {
+		return nil, errUnknownLayout
+	}
+	if m.Base == 0 {
+		readv, err := v.structMember("read")
+		if err != nil {
+			return nil, errUnknownLayout
+		}
+		if readv.Kind == reflect.Struct {
+			// Go 1.20 and later: atomic.Pointer[readOnly], earlier: atomic.Value
+			if readv, err = readv.structMember("v"); err != nil {
+				return nil, errUnknownLayout
+			}
+		}
+		var ro *Variable
+		switch readv.Kind {
+		case reflect.Interface:
+			readv.loadValue(loadSingleValue)
+			if readv.Unreadable != nil {
+				return nil, readv.Unreadable
+			}
+			if len(readv.Children) == 0 {
+				return &PrettyValue{Value: "len: 0"}, nil
+			}
+			ro = &readv.Children[0]
+		case reflect.UnsafePointer:
+			p, err := readUintRaw(readv.mem, readv.Addr, int64(v.bi.Arch.PtrSize()))
+			if err != nil {
+				return nil, err
+			}
+			if p == 0 {
+				return &PrettyValue{Value: "len: 0"}, nil
+			}
+			typ, err := v.bi.findType("sync.readOnly")
+			if err != nil {
+				return nil, err
+			}
+			ro = v.newVariable("", uintptr(p), typ, DereferenceMemory(v.mem))
+		default:
+			return nil, errUnknownLayout
+		}
+		if m, err = ro.loadMember("m", cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	ifaceType, err := v.bi.findType("interface {}")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PrettyValue{}
+	for i := 0; i+1 < len(m.Children); i += 2 {
+		key, entry := &m.Children[i], &m.Children[i+1]
+		pv, err := entry.structMember("p")
+		if err != nil {
+			return nil, errUnknownLayout
+		}
+		if pv.Kind == reflect.Struct {
+			// Go 1.19 and later: atomic.Pointer[any]
+			if pv, err = pv.structMember("v"); err != nil {
+				return nil, errUnknownLayout
+			}
+		}
+		p, err := readUintRaw(pv.mem, pv.Addr, int64(v.bi.Arch.PtrSize()))
+		if err != nil {
+			return nil, err
+		}
+		if p == 0 {
+			// deleted entry
+			continue
+		}
+		val := v.newVariable(key.prettyString(), uintptr(p), ifaceType, DereferenceMemory(v.mem))
+		val.loadValue(cfg)
+		r.Children = append(r.Children, val)
+	}
+	r.Value = fmt.Sprintf("len: %d", len(r.Children))
+	return r, nil
+}
+
+func formatList(v *Variable, cfg LoadConfig) (*PrettyValue, error) {
+	n, err := v.loadInt("len")
+	if err != nil {
+		return nil, err
+	}
+	r := &PrettyValue{Value: fmt.Sprintf("len: %d", n)}
+	rootv, err := v.structMember("root")
+	if err != nil {
+		return nil, errUnknownLayout
+	}
+	e, err := rootv.structMember("next")
+	if err != nil {
+		return nil, errUnknownLayout
+	}
+	limit := formatElementsLimit(cfg)
+	for i := 0; int64(i) < n && i < limit; i++ {
+		e.loadValue(loadSingleValue)
+		if e.Unreadable != nil {
+			return nil, e.Unreadable
+		}
+		if len(e.Children) == 0 || e.Children[0].Addr == 0 || e.Children[0].Addr == rootv.Addr {
+			break
+		}
+		val, err := e.loadMember("Value", cfg)
+		if err != nil {
+			return nil, err
+		}
+		val.Name = fmt.Sprintf("[%d]", i)
+		r.Children = append(r.Children, val)
+		if e, err = e.structMember("next"); err != nil {
+			return nil, errUnknownLayout
+		}
+	}
+	return r, nil
+}
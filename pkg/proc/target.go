@@ -21,6 +21,14 @@ var (
 
 	// ErrProcessDetached indicates that we detached from the target process.
 	ErrProcessDetached = errors.New("detached from the process")
+
+	// ErrSyscallCatchpointsNotSupported is returned when syscall catchpoints
+	// are requested on a backend that does not support them.
+	ErrSyscallCatchpointsNotSupported = errors.New("syscall catchpoints not supported by this backend")
+
+	// ErrSignalPolicyNotSupported is returned when a signal handling policy
+	// is set on a backend that does not support them.
+	ErrSignalPolicyNotSupported = errors.New("signal handling policies not supported by this backend")
 )
 
 // Target represents the process being debugged.
@@ -48,6 +56,28 @@ type Target struct {
 	// have read and parsed from the targets memory.
 	// This must be cleared whenever the target is resumed.
 	gcache goroutineCache
+
+	// syscallCatchpoint is the currently active syscall catchpoint, nil if
+	// there isn't one.
+	syscallCatchpoint *SyscallCatchpoint
+	// syscallStop is the system call that stopped the target process during
+	// the last call to Continue, if StopReason is StopSyscall.
+	syscallStop *SyscallStop
+
+	// signalPolicies are the signal handling policies set with
+	// SetSignalPolicy, signals without a policy use DefaultSignalPolicy.
+	signalPolicies map[int]SignalPolicy
+	// receivedSignals is the list of signals received by the target process
+	// during the last call to Continue that had either the Print or the
+	// Stop policy.
+	receivedSignals []ReceivedSignal
+
+	// newImagesCallback is called by Continue with the images that were
+	// loaded by the target process since the last time it was called.
+	newImagesCallback func([]*Image)
+	// numImages is the number of images already reported to
+	// newImagesCallback.
+	numImages int
 }
 
 // ErrProcessExited indicates that the process has exited and contains both
@@ -76,14 +106,67 @@ const (
 	StopManual                         // A manual stop was requested
 	StopNextFinished                   // The next/step/stepout command terminated
 	StopCallReturned                   // An injected call completed
+	StopSyscall                        // A thread entered or exited a system call selected by a syscall catchpoint
+	StopSignal                         // A thread received a signal with the Stop policy
 )
 
+// SyscallCatchpoint describes the system calls that should stop the
+// target process when a thread enters or exits them.
+type SyscallCatchpoint struct {
+	// Syscalls is the list of system call numbers to catch, if it is empty
+	// every system call is caught.
+	Syscalls []uint64
+}
+
+// Match returns true if the system call num is selected by cp.
+func (cp *SyscallCatchpoint) Match(num uint64) bool {
+	if len(cp.Syscalls) == 0 {
+		return true
+	}
+	for _, num2 := range cp.Syscalls {
+		if num == num2 {
+			return true
+		}
+	}
+	return false
+}
+
+// SyscallStop describes the system call a thread is stopped at because of
+// a syscall catchpoint.
+type SyscallStop struct {
+	ThreadID int       // thread stopped at the system call
+	Num      uint64    // system call number
+	Name     string    // system call name
+	Args     [6]uint64 // arguments of the system call
+	Exit     bool      // true if the thread is exiting the system call
+	Ret      int64     // return value of the system call, only valid if Exit is true
+}
+
+// SignalPolicy describes how a signal received by the target process is
+// handled by the debugger.
+type SignalPolicy struct {
+	Stop  bool // stop the target process when the signal is received
+	Print bool // report the signal, see Target.ReceivedSignals
+	Pass  bool // deliver the signal to the target process
+}
+
+// DefaultSignalPolicy is the policy used for signals that do not have one:
+// they are delivered to the target process silently.
+var DefaultSignalPolicy = SignalPolicy{Pass: true}
+
+// ReceivedSignal describes a signal received by a thread of the target
+// process.
+type ReceivedSignal struct {
+	ThreadID int
+	Signal   int
+}
+
 // NewTargetConfig contains the configuration for a new Target object,
 type NewTargetConfig struct {
-	Path                string     // path of the main executable
-	DebugInfoDirs       []string   // Directories to search for split debug info
-	DisableAsyncPreempt bool       // Go 1.14 asynchronous preemption should be disabled
-	StopReason          StopReason // Initial stop reason
+	Path                string          // path of the main executable
+	DebugInfo           DebugInfoConfig // Where debug info is looked up and cached
+	DisableAsyncPreempt bool            // Go 1.14 asynchronous preemption should be disabled
+	StopReason          StopReason      // Initial stop reason
 }
 
 // DisableAsyncPreemptEnv returns a process environment (like os.Environ)
@@ -107,7 +190,7 @@ func NewTarget(p Process, cfg NewTargetConfig) (*Target, error) {
 		return nil, err
 	}
 
-	err = p.BinInfo().LoadBinaryInfo(cfg.Path, entryPoint, cfg.DebugInfoDirs)
+	err = p.BinInfo().LoadBinaryInfo(cfg.Path, entryPoint, cfg.DebugInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +212,9 @@ func NewTarget(p Process, cfg NewTargetConfig) (*Target, error) {
 
 	t.createUnrecoveredPanicBreakpoint()
 	t.createFatalThrowBreakpoint()
+	t.createPluginOpenBreakpoint()
+	t.createDynamicLoaderBreakpoint()
+	t.numImages = len(p.BinInfo().Images)
 
 	t.gcache.init(p.BinInfo())
 
@@ -150,6 +236,16 @@ func (t *Target) SupportsFunctionCalls() bool {
 	return t.Process.BinInfo().Arch.Name == "amd64"
 }
 
+// interpretsFunctionCalls returns true if function calls are evaluated by
+// interpreting the called functions, because the target can not execute
+// them.
+func (t *Target) interpretsFunctionCalls() bool {
+	if ok, _ := t.Process.Recorded(); !ok {
+		return false
+	}
+	return t.Process.BinInfo().Arch.Name == "amd64"
+}
+
 // ClearAllGCache clears the internal Goroutine cache.
 // This should be called anytime the target process executes instructions.
 func (t *Target) ClearAllGCache() {
@@ -221,6 +317,7 @@ func (t *Target) Detach(kill bool) error {
 		}
 		for _, bp := range t.Breakpoints().M {
 			if bp != nil {
+				bp.Kind &^= ImageLoadBreakpoint
 				_, err := t.ClearBreakpoint(bp.Addr)
 				if err != nil {
 					return err
@@ -263,6 +360,74 @@ func setAsyncPreemptOff(p *Target, v int64) {
 	logger.Warnf("could not set asyncpreemptoff %v", err)
 }
 
+// SetSyscallCatchpoint sets the syscall catchpoint of the target process,
+// replacing the current one. If cp is nil the syscall catchpoint is removed.
+func (t *Target) SetSyscallCatchpoint(cp *SyscallCatchpoint) error {
+	if valid, err := t.Valid(); !valid {
+		return err
+	}
+	sc, ok := t.proc.(SyscallCatcher)
+	if !ok {
+		return ErrSyscallCatchpointsNotSupported
+	}
+	if err := sc.SetSyscallCatchpoint(cp); err != nil {
+		return err
+	}
+	t.syscallCatchpoint = cp
+	return nil
+}
+
+// SyscallCatchpoint returns the syscall catchpoint of the target process, or
+// nil if there isn't one.
+func (t *Target) SyscallCatchpoint() *SyscallCatchpoint {
+	return t.syscallCatchpoint
+}
+
+// CurrentSyscall returns the system call that stopped the target process,
+// or nil if the target process isn't stopped because of a syscall
+// catchpoint. The thread stopped at the system call is not necessarily the
+// current thread.
+func (t *Target) CurrentSyscall() *SyscallStop {
+	if t.StopReason != StopSyscall {
+		return nil
+	}
+	return t.syscallStop
+}
+
+// SetSignalPolicy sets the policy used to handle signal sig when it is
+// received by the target process.
+func (t *Target) SetSignalPolicy(sig int, policy SignalPolicy) error {
+	if valid, err := t.Valid(); !valid {
+		return err
+	}
+	sh, ok := t.proc.(SignalHandler)
+	if !ok {
+		return ErrSignalPolicyNotSupported
+	}
+	if err := sh.SetSignalPolicy(sig, policy); err != nil {
+		return err
+	}
+	if t.signalPolicies == nil {
+		t.signalPolicies = make(map[int]SignalPolicy)
+	}
+	t.signalPolicies[sig] = policy
+	return nil
+}
+
+// SignalPolicy returns the policy used to handle signal sig.
+func (t *Target) SignalPolicy(sig int) SignalPolicy {
+	if policy, ok := t.signalPolicies[sig]; ok {
+		return policy
+	}
+	return DefaultSignalPolicy
+}
+
+// ReceivedSignals returns the signals with either the Print or the Stop
+// policy received by the target process during the last call to Continue.
+func (t *Target) ReceivedSignals() []ReceivedSignal {
+	return t.receivedSignals
+}
+
 // createUnrecoveredPanicBreakpoint creates the unrecoverable-panic breakpoint.
 func (t *Target) createUnrecoveredPanicBreakpoint() {
 	panicpcs, err := FindFunctionLocation(t.Process, "runtime.startpanic", 0)
@@ -270,7 +435,7 @@ func (t *Target) createUnrecoveredPanicBreakpoint() {
 		panicpcs, err = FindFunctionLocation(t.Process, "runtime.fatalpanic", 0)
 	}
 	if err == nil {
-		bp, err := t.setBreakpointWithID(unrecoveredPanicID, panicpcs[0])
+		bp, err := t.SetBreakpointWithID(unrecoveredPanicID, panicpcs[0])
 		if err == nil {
 			bp.Name = UnrecoveredPanic
 			bp.Variables = []string{"runtime.curg._panic.arg"}
@@ -282,9 +447,62 @@ func (t *Target) createUnrecoveredPanicBreakpoint() {
 func (t *Target) createFatalThrowBreakpoint() {
 	fatalpcs, err := FindFunctionLocation(t.Process, "runtime.fatalthrow", 0)
 	if err == nil {
-		bp, err := t.setBreakpointWithID(fatalThrowID, fatalpcs[0])
+		bp, err := t.SetBreakpointWithID(fatalThrowID, fatalpcs[0])
 		if err == nil {
 			bp.Name = FatalThrow
 		}
 	}
 }
+
+// createPluginOpenBreakpoint creates a breakpoint on the function called by
+// plugin.Open after the dynamic linker has loaded a plugin and its module
+// data has been added to the list of active modules, so that the plugin can
+// be loaded before any of its code is executed.
+func (t *Target) createPluginOpenBreakpoint() {
+	pcs, err := FindFunctionLocation(t.Process, "plugin.lastmoduleinit", 0)
+	if err == nil {
+		t.SetBreakpoint(pcs[0], ImageLoadBreakpoint, nil)
+	}
+}
+
+// createDynamicLoaderBreakpoint creates a breakpoint on the function called
+// by the dynamic loader every time it changes the list of loaded shared
+// libraries, so that shared libraries opened with dlopen are loaded as soon
+// as they are mapped.
+func (t *Target) createDynamicLoaderBreakpoint() {
+	dlw, ok := t.proc.(DynamicLoaderWatcher)
+	if !ok {
+		return
+	}
+	addr, err := dlw.DynamicLoaderBreakpoint()
+	if err != nil {
+		t.BinInfo().logger.Warnf("could not find dynamic loader notification function: %v", err)
+		return
+	}
+	if addr == 0 {
+		return
+	}
+	t.SetBreakpoint(addr, ImageLoadBreakpoint, nil)
+}
+
+// SetNewImagesCallback sets a function that will be called, while the target
+// process is stopped, every time Continue finds that new images (shared
+// libraries or plugins) were loaded.
+// Plugins are reported before any of their code is executed.
+func (t *Target) SetNewImagesCallback(cb func([]*Image)) {
+	t.newImagesCallback = cb
+}
+
+// checkNewImages calls newImagesCallback with the images added to
+// BinaryInfo since the last time it was called.
+func (t *Target) checkNewImages() {
+	images := t.BinInfo().Images
+	if len(images) <= t.numImages {
+		return
+	}
+	newImages := images[t.numImages:]
+	t.numImages = len(images)
+	if t.newImagesCallback != nil {
+		t.newImagesCallback(newImages)
+	}
+}
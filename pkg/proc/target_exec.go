@@ -51,6 +51,7 @@ func (dbp *Target) Continue() error {
 	for _, thread := range dbp.ThreadList() {
 		thread.Common().returnValues = nil
 	}
+	dbp.receivedSignals = nil
 	dbp.CheckAndClearManualStopRequest()
 	defer func() {
 		// Make sure we clear internal breakpoints if we simultaneously receive a
@@ -67,14 +68,22 @@ func (dbp *Target) Continue() error {
 			return nil
 		}
 		dbp.ClearAllGCache()
+		dbp.syscallStop = nil
 		trapthread, stopReason, err := dbp.proc.ContinueOnce()
 		dbp.StopReason = stopReason
+		if sc, ok := dbp.proc.(SyscallCatcher); ok && stopReason == StopSyscall {
+			dbp.syscallStop = sc.SyscallStop(trapthread)
+		}
+		if sh, ok := dbp.proc.(SignalHandler); ok {
+			dbp.receivedSignals = append(dbp.receivedSignals, sh.ReceivedSignals()...)
+		}
 		if err != nil {
 			return err
 		}
 		if dbp.StopReason == StopLaunched {
 			dbp.ClearInternalBreakpoints()
 		}
+		dbp.checkNewImages()
 
 		threads := dbp.ThreadList()
 
@@ -94,6 +103,10 @@ func (dbp *Target) Continue() error {
 		curthread := dbp.CurrentThread()
 		curbp := curthread.Breakpoint()
 
+		if (dbp.StopReason == StopSyscall || dbp.StopReason == StopSignal) && !curbp.Active {
+			return conditionErrors(threads)
+		}
+
 		switch {
 		case curbp.Breakpoint == nil:
 			// runtime.Breakpoint, manual stop or debugCallV1-related stop
@@ -287,6 +300,130 @@ func (dbp *Target) Step() (err error) {
 	return dbp.Continue()
 }
 
+// StepInTarget is a function call, made by the current line of the
+// selected goroutine, that can be stepped into.
+type StepInTarget struct {
+	CallPC uint64    // address of the call instruction
+	Fn     *Function // function called
+}
+
+// StepInTargets returns the function calls that the selected goroutine
+// can still make before leaving the current line. Calls whose destination
+// can not be determined without executing them, and calls to private
+// runtime functions, are not returned.
+func StepInTargets(dbp *Target) ([]StepInTarget, error) {
+	if _, err := dbp.Valid(); err != nil {
+		return nil, err
+	}
+	selg := dbp.SelectedGoroutine()
+	curthread := dbp.CurrentThread()
+	topframe, _, err := topframe(selg, curthread)
+	if err != nil {
+		return nil, err
+	}
+	if topframe.Current.Fn == nil {
+		return nil, &ErrNoSourceForPC{topframe.Current.PC}
+	}
+
+	var thread MemoryReadWriter = curthread
+	var regs Registers
+	if selg != nil && selg.Thread != nil {
+		thread = selg.Thread
+		regs, err = selg.Thread.Registers()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	text, err := disassemble(thread, regs, dbp.Breakpoints(), dbp.BinInfo(), topframe.Current.Fn.Entry, topframe.Current.Fn.End, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var r []StepInTarget
+	for _, instr := range text {
+		if instr.Loc.PC < topframe.Current.PC || instr.Loc.File != topframe.Current.File || instr.Loc.Line != topframe.Current.Line {
+			continue
+		}
+		if !instr.IsCall() || instr.DestLoc == nil || instr.DestLoc.Fn == nil {
+			continue
+		}
+		if instr.DestLoc.Fn.privateRuntime() || dbp.BinInfo().Arch.inhibitStepInto(dbp.BinInfo(), instr.DestLoc.PC) {
+			continue
+		}
+		fn, _ := skipAutogeneratedWrappersIn(dbp, instr.DestLoc.Fn, instr.DestLoc.PC)
+		if fn == nil {
+			fn = instr.DestLoc.Fn
+		}
+		r = append(r, StepInTarget{CallPC: instr.Loc.PC, Fn: fn})
+	}
+	return r, nil
+}
+
+// StepInto continues until the selected goroutine enters the function
+// called by the call instruction at callPC, which must be one of the step
+// in targets returned by StepInTargets.
+// If the current function returns without executing the call instruction
+// execution stops on the return address.
+func (dbp *Target) StepInto(callPC uint64) error {
+	if dbp.GetDirection() == Backward {
+		return errors.New("can not step into a specific call backward")
+	}
+	targets, err := StepInTargets(dbp)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, tgt := range targets {
+		if tgt.CallPC == callPC {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("no call instruction at %#x on the current line", callPC)
+	}
+
+	curthread := dbp.CurrentThread()
+	regs, err := curthread.Registers()
+	if err != nil {
+		return err
+	}
+	if regs.PC() != callPC {
+		if err := dbp.Until([]uint64{callPC}); err != nil {
+			return err
+		}
+		if dbp.StopReason != StopNextFinished {
+			// stopped by something else, for example a user breakpoint
+			return nil
+		}
+		curthread = dbp.CurrentThread()
+		regs, err = curthread.Registers()
+		if err != nil {
+			return err
+		}
+		if regs.PC() != callPC {
+			// the current function returned
+			return nil
+		}
+	}
+
+	// We are stopped on the call instruction, its destination can be
+	// resolved using the registers of the current thread.
+	text, err := disassembleCurrentInstruction(dbp, curthread)
+	if err != nil {
+		return err
+	}
+	if err := setStepIntoBreakpoint(dbp, text, sameGoroutineCondition(dbp.SelectedGoroutine())); err != nil {
+		dbp.ClearInternalBreakpoints()
+		return err
+	}
+	if !dbp.Breakpoints().HasInternalBreakpoints() {
+		return nil
+	}
+	return dbp.Continue()
+}
+
 // sameGoroutineCondition returns an expression that evaluates to true when
 // the current goroutine is g.
 func sameGoroutineCondition(g *G) ast.Expr {
@@ -375,6 +512,119 @@ func (dbp *Target) StepOut() error {
 	return dbp.Continue()
 }
 
+// Until continues execution until the selected goroutine reaches one of
+// the addresses in pcs while in the current frame, or until the current
+// frame returns. All addresses in pcs must belong to the current function.
+func (dbp *Target) Until(pcs []uint64) error {
+	backward := dbp.GetDirection() == Backward
+	if _, err := dbp.Valid(); err != nil {
+		return err
+	}
+	if dbp.Breakpoints().HasInternalBreakpoints() {
+		return fmt.Errorf("next while nexting")
+	}
+	if len(pcs) == 0 {
+		return errors.New("no location specified")
+	}
+
+	selg := dbp.SelectedGoroutine()
+	curthread := dbp.CurrentThread()
+
+	topframe, retframe, err := topframe(selg, curthread)
+	if err != nil {
+		return err
+	}
+	fn := topframe.Current.Fn
+	if fn == nil {
+		return &ErrNoSourceForPC{topframe.Current.PC}
+	}
+	for _, pc := range pcs {
+		if pc < fn.Entry || pc >= fn.End {
+			return fmt.Errorf("location %#x is not in the current function %s", pc, fn.Name)
+		}
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			dbp.ClearInternalBreakpoints()
+		}
+	}()
+
+	sameGCond := sameGoroutineCondition(selg)
+	sameFrameCond := astutil.And(sameGCond, frameoffCondition(&topframe))
+	for _, pc := range pcs {
+		if _, err := allowDuplicateBreakpoint(dbp.SetBreakpoint(pc, NextBreakpoint, sameFrameCond)); err != nil {
+			return err
+		}
+	}
+
+	// Inlined calls do not have a return address, only the locations in the
+	// current function will stop them.
+	if !topframe.Inlined {
+		if backward {
+			if retframe.Current.Fn != nil {
+				if err := stepOutReverse(dbp, topframe, retframe, sameGCond); err != nil {
+					return err
+				}
+			}
+		} else if topframe.Ret != 0 {
+			topframe, retframe := skipAutogeneratedWrappersOut(selg, curthread, &topframe, &retframe)
+			retFrameCond := astutil.And(sameGCond, frameoffCondition(retframe))
+			bp, err := allowDuplicateBreakpoint(dbp.SetBreakpoint(topframe.Ret, NextBreakpoint, retFrameCond))
+			if err != nil {
+				return err
+			}
+			if bp != nil {
+				configureReturnBreakpoint(dbp.BinInfo(), bp, topframe, retFrameCond)
+			}
+		}
+	}
+
+	if bp := curthread.Breakpoint(); bp.Breakpoint == nil {
+		curthread.SetCurrentBreakpoint(false)
+	}
+
+	success = true
+	return dbp.Continue()
+}
+
+// Advance continues execution until the selected goroutine reaches one of
+// the addresses in pcs.
+func (dbp *Target) Advance(pcs []uint64) error {
+	if _, err := dbp.Valid(); err != nil {
+		return err
+	}
+	if dbp.Breakpoints().HasInternalBreakpoints() {
+		return fmt.Errorf("next while nexting")
+	}
+	if len(pcs) == 0 {
+		return errors.New("no location specified")
+	}
+
+	success := false
+	defer func() {
+		if !success {
+			dbp.ClearInternalBreakpoints()
+		}
+	}()
+
+	sameGCond := sameGoroutineCondition(dbp.SelectedGoroutine())
+	for _, pc := range pcs {
+		if _, err := allowDuplicateBreakpoint(dbp.SetBreakpoint(pc, NextBreakpoint, sameGCond)); err != nil {
+			return err
+		}
+	}
+
+	curthread := dbp.CurrentThread()
+	if bp := curthread.Breakpoint(); bp.Breakpoint == nil {
+		curthread.SetCurrentBreakpoint(false)
+	}
+
+	success = true
+	return dbp.Continue()
+}
+
 // StepInstruction will continue the current thread for exactly
 // one instruction. This method affects only the thread
 // associated with the selected goroutine. All other
@@ -77,6 +77,8 @@ const (
 	BuildModePIE
 	BuildModePlugin
 	AllNonOptimized
+	// LinkNoDWARF enables '-ldflags="-w"'.
+	LinkNoDWARF
 )
 
 // BuildFixture will compile the fixture 'name' using the provided build flags.
@@ -116,6 +118,9 @@ func BuildFixture(name string, flags BuildFlags) Fixture {
 	if flags&LinkStrip != 0 {
 		buildFlags = append(buildFlags, "-ldflags=-s")
 	}
+	if flags&LinkNoDWARF != 0 {
+		buildFlags = append(buildFlags, "-ldflags=-w")
+	}
 	gcflagsv := []string{}
 	if flags&EnableInlining == 0 {
 		gcflagsv = append(gcflagsv, "-l")
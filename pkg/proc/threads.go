@@ -2,6 +2,8 @@ package proc
 
 import (
 	"errors"
+
+	"github.com/go-delve/delve/pkg/dwarf/op"
 )
 
 // Thread represents a thread.
@@ -35,6 +37,9 @@ type Thread interface {
 	SetPC(uint64) error
 	SetSP(uint64) error
 	SetDX(uint64) error
+	// SetReg changes the value of the register regNum (a DWARF register
+	// number) to reg.
+	SetReg(regNum uint64, reg *op.DwarfRegister) error
 }
 
 // Location represents the location of a thread.
@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
-	"github.com/go-delve/delve/pkg/dwarf/reader"
 )
 
 // The kind field in runtime._type is a reflect.Kind value plus
@@ -76,24 +75,105 @@ func (v packageVarsByAddr) Len() int               { return len(v) }
 func (v packageVarsByAddr) Less(i int, j int) bool { return v[i].addr < v[j].addr }
 func (v packageVarsByAddr) Swap(i int, j int)      { v[i], v[j] = v[j], v[i] }
 
-type loadDebugInfoMapsContext struct {
-	ardr                *reader.Reader
-	abstractOriginTable map[dwarf.Offset]int
-	knownPackageVars    map[string]struct{}
+// resolveParametricType returns the concrete type of the type parameter
+// t, reading its runtime._type from the dictionary at dictAddr. If the
+// concrete type can not be determined the shape type of the
+// instantiation is returned along with an error.
+func resolveParametricType(bi *BinaryInfo, mem MemoryReadWriter, t *godwarf.ParametricType, dictAddr uint64) (godwarf.Type, error) {
+	if dictAddr == 0 {
+		return t.TypedefType.Type, errors.New("parametric type without a dictionary")
+	}
+	rtypeAddr, err := readUintRaw(mem, uintptr(dictAddr+uint64(t.DictIndex*int64(bi.Arch.PtrSize()))), int64(bi.Arch.PtrSize()))
+	if err != nil {
+		return t.TypedefType.Type, err
+	}
+	rtyp, err := bi.findType("runtime._type")
+	if err != nil {
+		// since Go 1.21 runtime._type is an alias of internal/abi.Type,
+		// only the address is needed to look up the DIE.
+		rtyp, err = bi.findType("internal/abi.Type")
+		if err != nil {
+			return t.TypedefType.Type, err
+		}
+	}
+	typ, _, err := runtimeTypeToDIE(newVariable("", uintptr(rtypeAddr), rtyp, bi, mem), 0)
+	if err != nil {
+		return t.TypedefType.Type, err
+	}
+	return typ, nil
 }
 
-func newLoadDebugInfoMapsContext(bi *BinaryInfo, image *Image) *loadDebugInfoMapsContext {
-	ctxt := &loadDebugInfoMapsContext{}
-
-	ctxt.ardr = image.DwarfReader()
-	ctxt.abstractOriginTable = make(map[dwarf.Offset]int)
-
-	ctxt.knownPackageVars = map[string]struct{}{}
-	for _, v := range bi.packageVars {
-		ctxt.knownPackageVars[v.name] = struct{}{}
+// substituteTypes returns t with every type for which subst returns a
+// different type replaced, looking inside pointer, array, slice, map and
+// channel types. The types that contain a replaced type are copied, t
+// itself is returned if nothing is replaced.
+func substituteTypes(t godwarf.Type, subst func(godwarf.Type) godwarf.Type) godwarf.Type {
+	if r := subst(t); r != t {
+		return r
+	}
+	switch t := t.(type) {
+	case *godwarf.PtrType:
+		elem := substituteTypes(t.Type, subst)
+		if elem == t.Type {
+			return t
+		}
+		r := *t
+		r.Type = elem
+		r.Name = "*" + elem.String()
+		return &r
+	case *godwarf.ArrayType:
+		elem := substituteTypes(t.Type, subst)
+		if elem == t.Type {
+			return t
+		}
+		r := *t
+		r.Type = elem
+		r.Name = fmt.Sprintf("[%d]%s", t.Count, elem.String())
+		return &r
+	case *godwarf.SliceType:
+		elem := substituteTypes(t.ElemType, subst)
+		if elem == t.ElemType {
+			return t
+		}
+		r := *t
+		r.ElemType = elem
+		r.Name = "[]" + elem.String()
+		// the elements are read through the type of the array field
+		r.Field = make([]*godwarf.StructField, len(t.Field))
+		for i, f := range t.Field {
+			r.Field[i] = f
+			if ptr, ok := f.Type.(*godwarf.PtrType); ok && f.Name == sliceArrayFieldName {
+				nptr := *ptr
+				nptr.Type = elem
+				nptr.Name = "*" + elem.String()
+				nf := *f
+				nf.Type = &nptr
+				r.Field[i] = &nf
+			}
+		}
+		return &r
+	case *godwarf.MapType:
+		key := substituteTypes(t.KeyType, subst)
+		elem := substituteTypes(t.ElemType, subst)
+		if key == t.KeyType && elem == t.ElemType {
+			return t
+		}
+		r := *t
+		r.KeyType = key
+		r.ElemType = elem
+		r.Name = "map[" + key.String() + "]" + elem.String()
+		return &r
+	case *godwarf.ChanType:
+		elem := substituteTypes(t.ElemType, subst)
+		if elem == t.ElemType {
+			return t
+		}
+		r := *t
+		r.ElemType = elem
+		r.Name = "chan " + elem.String()
+		return &r
 	}
-
-	return ctxt
+	return t
 }
 
 // runtimeTypeToDIE returns the DIE corresponding to the runtime._type.
@@ -112,6 +192,7 @@ func runtimeTypeToDIE(_type *Variable, dataAddr uintptr) (typ godwarf.Type, kind
 
 	// go 1.11 implementation: use extended attribute in debug_info
 
+	bi.loadPendingTypes()
 	mds, err := loadModuleData(bi, _type.mem)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error loading module data: %v", err)
@@ -399,7 +480,7 @@ func nameOfInterfaceRuntimeType(mds []moduleData, _type *Variable, kind, tflag i
 	buf.WriteString("interface {")
 
 	methods, _ := _type.structMember(interfacetypeFieldMhdr)
-	methods.loadArrayValues(0, LoadConfig{false, 1, 0, 4096, -1, 0})
+	methods.loadArrayValues(0, LoadConfig{false, 1, 0, 4096, -1, 0, false})
 	if methods.Unreadable != nil {
 		return "", nil
 	}
@@ -460,7 +541,7 @@ func nameOfStructRuntimeType(mds []moduleData, _type *Variable, kind, tflag int6
 	buf.WriteString("struct {")
 
 	fields, _ := _type.structMember("fields")
-	fields.loadArrayValues(0, LoadConfig{false, 2, 0, 4096, -1, 0})
+	fields.loadArrayValues(0, LoadConfig{false, 2, 0, 4096, -1, 0, false})
 	if fields.Unreadable != nil {
 		return "", fields.Unreadable
 	}
@@ -75,6 +75,9 @@ const (
 	// the variable is the return value of a function call and allocated on a
 	// frame that no longer exists)
 	VariableFakeAddress
+	// VariableFormatted means the Pretty field of this variable was set by
+	// a pretty-printer or by a builtin formatter
+	VariableFormatted
 )
 
 // Variable represents a variable. It contains the address, name,
@@ -112,6 +115,29 @@ type Variable struct {
 
 	// number of elements to skip when loading a map
 	mapSkip int
+	// index of the map element where loading stops, 0 if all elements
+	// after mapSkip should be loaded
+	mapEnd int
+	// mapCursor, if not nil, is the position of the map iterator where
+	// loading should resume, used instead of skipping mapSkip elements if
+	// mapCursor.Index == mapSkip.
+	mapCursor *MapCursor
+	// mapNext is the position of the map iterator after the last loaded
+	// element, nil if all elements were loaded.
+	mapNext *MapCursor
+
+	// Pretty is the representation of this variable produced by a
+	// PrettyPrinter, see ApplyPrettyPrinters.
+	Pretty *PrettyValue
+
+	// queryElems are the elements of a fake slice created by a collection
+	// query (see evalQueryBuiltin), nil for all other variables.
+	queryElems []Variable
+
+	// bitFieldOffset is the offset in bits of a bit field from Addr,
+	// bitFieldSize is its size (0 for variables that aren't bit fields).
+	bitFieldOffset int64
+	bitFieldSize   int64
 
 	Children []Variable
 
@@ -163,11 +189,15 @@ type LoadConfig struct {
 	// sparse map is in scope, but evaluating a single variable will still work
 	// correctly, even if the variable in question is a very sparse map.
 	MaxMapBuckets int
+
+	// DisableFormatters disables pretty-printers and builtin formatters,
+	// variables are displayed using their internal representation.
+	DisableFormatters bool
 }
 
-var loadSingleValue = LoadConfig{false, 0, 64, 0, 0, 0}
-var loadFullValue = LoadConfig{true, 1, 64, 64, -1, 0}
-var loadFullValueLongerStrings = LoadConfig{true, 1, 1024 * 1024, 64, -1, 0}
+var loadSingleValue = LoadConfig{false, 0, 64, 0, 0, 0, false}
+var loadFullValue = LoadConfig{true, 1, 64, 64, -1, 0, false}
+var loadFullValueLongerStrings = LoadConfig{true, 1, 1024 * 1024, 64, -1, 0, false}
 
 // G status, from: src/runtime/runtime2.go
 const (
@@ -613,6 +643,9 @@ func newVariable(name string, addr uintptr, dwarfType godwarf.Type, bi *BinaryIn
 		if _, isvoid := t.Type.(*godwarf.VoidType); isvoid {
 			v.Kind = reflect.UnsafePointer
 		}
+		if isCFuncPtr(t) {
+			v.Kind = reflect.Func
+		}
 	case *godwarf.ChanType:
 		v.Kind = reflect.Chan
 		if v.Addr != 0 {
@@ -645,7 +678,10 @@ func newVariable(name string, addr uintptr, dwarfType godwarf.Type, bi *BinaryIn
 		v.stride = 0
 
 		if t.Count > 0 {
-			v.stride = t.ByteSize / t.Count
+			v.stride = t.Size() / t.Count
+		} else if isCArrayOfUnknownSize(t) {
+			v.Len = 0
+			v.stride = t.Type.Size()
 		}
 	case *godwarf.ComplexType:
 		switch t.ByteSize {
@@ -658,6 +694,12 @@ func newVariable(name string, addr uintptr, dwarfType godwarf.Type, bi *BinaryIn
 		v.Kind = reflect.Int
 	case *godwarf.UintType:
 		v.Kind = reflect.Uint
+	case *godwarf.CharType:
+		v.Kind = reflect.Int8
+	case *godwarf.UcharType:
+		v.Kind = reflect.Uint8
+	case *godwarf.EnumType:
+		v.Kind = reflect.Int
 	case *godwarf.FloatType:
 		switch t.ByteSize {
 		case 4:
@@ -680,11 +722,42 @@ func newVariable(name string, addr uintptr, dwarfType godwarf.Type, bi *BinaryIn
 	return v
 }
 
+// isCFuncPtr returns true if t is a C function pointer, unlike Go function
+// values they point directly to the entry point of the function.
+func isCFuncPtr(t *godwarf.PtrType) bool {
+	ft, isfunc := resolveTypedef(t.Type).(*godwarf.FuncType)
+	return isfunc && ft.ReflectKind == reflect.Invalid
+}
+
+// isCString returns true if t is a pointer to C characters, which is
+// loaded as a NUL terminated string.
+func isCString(t godwarf.Type) bool {
+	ptyp, isptr := t.(*godwarf.PtrType)
+	if !isptr {
+		return false
+	}
+	switch resolveTypedef(ptyp.Type).(type) {
+	case *godwarf.CharType, *godwarf.UcharType:
+		return true
+	}
+	return false
+}
+
+// isCArrayOfUnknownSize returns true if t is a C array declared without a
+// size (for example a flexible array member), like in C these can be
+// indexed and sliced past their length.
+func isCArrayOfUnknownSize(t godwarf.Type) bool {
+	at, isarr := t.(*godwarf.ArrayType)
+	return isarr && at.Count <= 0 && at.ReflectKind == reflect.Invalid
+}
+
 func resolveTypedef(typ godwarf.Type) godwarf.Type {
 	for {
 		switch tt := typ.(type) {
 		case *godwarf.TypedefType:
 			typ = tt.Type
+		case *godwarf.ParametricType:
+			typ = tt.Type
 		case *godwarf.QualType:
 			typ = tt.Type
 		default:
@@ -754,6 +827,12 @@ func (v *Variable) toField(field *godwarf.StructField) (*Variable, error) {
 			name = fmt.Sprintf("%s.%s", v.Name, field.Name)
 		}
 	}
+	if field.BitSize > 0 {
+		r := v.newVariable(name, uintptr(int64(v.Addr)+field.DataBitOffset/8), field.Type, v.mem)
+		r.bitFieldOffset = field.DataBitOffset % 8
+		r.bitFieldSize = field.BitSize
+		return r, nil
+	}
 	return v.newVariable(name, uintptr(int64(v.Addr)+field.ByteOffset), field.Type, v.mem), nil
 }
 
@@ -990,7 +1069,7 @@ func (g *G) stkbar() ([]savedLR, error) {
 	if g.stkbarVar == nil { // stack barriers were removed in Go 1.9
 		return nil, nil
 	}
-	g.stkbarVar.loadValue(LoadConfig{false, 1, 0, int(g.stkbarVar.Len), 3, 0})
+	g.stkbarVar.loadValue(LoadConfig{false, 1, 0, int(g.stkbarVar.Len), 3, 0, false})
 	if g.stkbarVar.Unreadable != nil {
 		return nil, fmt.Errorf("unreadable stkbar: %v", g.stkbarVar.Unreadable)
 	}
@@ -1164,7 +1243,7 @@ func (v *Variable) loadValue(cfg LoadConfig) {
 }
 
 func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
-	if v.Unreadable != nil || v.loaded || (v.Addr == 0 && v.Base == 0) {
+	if v.Unreadable != nil || v.loaded || (v.Addr == 0 && v.Base == 0 && v.queryElems == nil) {
 		return
 	}
 
@@ -1184,6 +1263,11 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 		} else {
 			v.Children[0].OnlyAddr = true
 		}
+		if isCString(v.RealType) && v.Children[0].Addr != 0 && cfg.MaxStringLen > 0 {
+			var val string
+			val, v.Unreadable = readCStringValue(DereferenceMemory(v.mem), v.Children[0].Addr, cfg)
+			v.Value = constant.MakeString(val)
+		}
 
 	case reflect.Chan:
 		sv := v.clone()
@@ -1236,14 +1320,32 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 		v.readComplex(v.RealType.(*godwarf.ComplexType).ByteSize)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		var val int64
-		val, v.Unreadable = readIntRaw(v.mem, v.Addr, v.RealType.(*godwarf.IntType).ByteSize)
+		if v.bitFieldSize > 0 {
+			var n uint64
+			n, v.Unreadable = v.readBitField(true)
+			val = int64(n)
+		} else {
+			val, v.Unreadable = readIntRaw(v.mem, v.Addr, v.RealType.Size())
+		}
 		v.Value = constant.MakeInt64(val)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		var val uint64
-		val, v.Unreadable = readUintRaw(v.mem, v.Addr, v.RealType.(*godwarf.UintType).ByteSize)
+		if v.bitFieldSize > 0 {
+			val, v.Unreadable = v.readBitField(false)
+		} else {
+			val, v.Unreadable = readUintRaw(v.mem, v.Addr, v.RealType.Size())
+		}
 		v.Value = constant.MakeUint64(val)
 
 	case reflect.Bool:
+		if v.bitFieldSize > 0 {
+			var n uint64
+			n, v.Unreadable = v.readBitField(false)
+			if v.Unreadable == nil {
+				v.Value = constant.MakeBool(n != 0)
+			}
+			break
+		}
 		val := make([]byte, 1)
 		_, err := v.mem.ReadMemory(val, v.Addr)
 		v.Unreadable = err
@@ -1298,6 +1400,49 @@ func convertToEface(srcv, dstv *Variable) error {
 	return dstv.writeEmptyInterface(typeAddr, srcv)
 }
 
+// readBitField reads the value of a bit field, if signed is true the value
+// is sign extended.
+func (v *Variable) readBitField(signed bool) (uint64, error) {
+	buf := make([]byte, (v.bitFieldOffset+v.bitFieldSize+7)/8)
+	if _, err := v.mem.ReadMemory(buf, v.Addr); err != nil {
+		return 0, err
+	}
+	var tmp [9]byte
+	copy(tmp[:], buf)
+	n := binary.LittleEndian.Uint64(tmp[:8])>>uint(v.bitFieldOffset) | uint64(tmp[8])<<uint(64-v.bitFieldOffset)
+	mask := uint64(1)<<uint(v.bitFieldSize) - 1
+	n &= mask
+	if signed && n&(1<<uint(v.bitFieldSize-1)) != 0 {
+		n |= ^mask
+	}
+	return n, nil
+}
+
+// readCStringValue reads a NUL terminated string starting at addr, at most
+// cfg.MaxStringLen bytes are read.
+func readCStringValue(mem MemoryReadWriter, addr uintptr, cfg LoadConfig) (string, error) {
+	// Memory is read in aligned chunks so that a read never crosses into a
+	// page that follows the end of the string.
+	const chunkSize = 64
+	var out []byte
+	for len(out) < cfg.MaxStringLen {
+		cur := addr + uintptr(len(out))
+		n := chunkSize - int(cur%chunkSize)
+		if rem := cfg.MaxStringLen - len(out); n > rem {
+			n = rem
+		}
+		buf := make([]byte, n)
+		if _, err := mem.ReadMemory(buf, cur); err != nil {
+			return string(out), err
+		}
+		if i := bytes.IndexByte(buf, 0); i >= 0 {
+			return string(append(out, buf[:i]...)), nil
+		}
+		out = append(out, buf...)
+	}
+	return string(out), nil
+}
+
 func readStringInfo(mem MemoryReadWriter, arch *Arch, addr uintptr) (uintptr, int64, error) {
 	// string data structure is always two ptrs in size. Addr, followed by len
 	// http://research.swtch.com/godata
@@ -1464,6 +1609,18 @@ func (v *Variable) loadArrayValues(recurseLevel int, cfg LoadConfig) {
 		count = int64(cfg.MaxArrayValues)
 	}
 
+	if v.queryElems != nil {
+		if count > int64(len(v.queryElems)) {
+			count = int64(len(v.queryElems))
+		}
+		for i := int64(0); i < count; i++ {
+			fieldvar := v.queryElems[i]
+			fieldvar.loadValueInternal(recurseLevel+1, cfg)
+			v.Children = append(v.Children, fieldvar)
+		}
+		return
+	}
+
 	if v.stride < maxArrayStridePrefetch {
 		v.mem = cacheMemory(v.mem, v.Base, int(v.stride*count))
 	}
@@ -1687,23 +1844,34 @@ func (v *Variable) writeCopy(srcv *Variable) error {
 }
 
 func (v *Variable) readFunctionPtr() {
-	// dereference pointer to find function pc
-	v.closureAddr = v.funcvalAddr()
-	if v.Unreadable != nil {
-		return
+	var val uint64
+	if _, iscfnptr := v.RealType.(*godwarf.PtrType); iscfnptr {
+		// C function pointers contain the entry point of the function
+		val = v.funcvalAddr()
+		if v.Unreadable != nil {
+			return
+		}
+	} else {
+		// dereference pointer to find function pc
+		v.closureAddr = v.funcvalAddr()
+		if v.Unreadable != nil {
+			return
+		}
+		if v.closureAddr != 0 {
+			var err error
+			val, err = readUintRaw(v.mem, uintptr(v.closureAddr), int64(v.bi.Arch.PtrSize()))
+			if err != nil {
+				v.Unreadable = err
+				return
+			}
+		}
 	}
-	if v.closureAddr == 0 {
+	if val == 0 {
 		v.Base = 0
 		v.Value = constant.MakeString("")
 		return
 	}
 
-	val, err := readUintRaw(v.mem, uintptr(v.closureAddr), int64(v.bi.Arch.PtrSize()))
-	if err != nil {
-		v.Unreadable = err
-		return
-	}
-
 	v.Base = uintptr(val)
 	fn := v.bi.PCToFunc(uint64(v.Base))
 	if fn == nil {
@@ -1729,18 +1897,29 @@ func (v *Variable) loadMap(recurseLevel int, cfg LoadConfig) {
 	if it == nil {
 		return
 	}
-	it.maxNumBuckets = uint64(cfg.MaxMapBuckets)
 
 	if v.Len == 0 || int64(v.mapSkip) >= v.Len || cfg.MaxArrayValues == 0 {
 		return
 	}
 
-	for skip := 0; skip < v.mapSkip; skip++ {
+	skip := 0
+	if v.mapCursor != nil && v.mapCursor.Index == int64(v.mapSkip) && it.seek(v.mapCursor) {
+		skip = v.mapSkip
+	}
+	if v.Unreadable != nil {
+		return
+	}
+	for ; skip < v.mapSkip; skip++ {
 		if ok := it.next(); !ok {
 			v.Unreadable = fmt.Errorf("map index out of bounds")
 			return
 		}
 	}
+	// the limit on the number of buckets only applies to the buckets
+	// scanned for the loaded elements
+	if cfg.MaxMapBuckets > 0 {
+		it.maxNumBuckets = it.bidx + uint64(cfg.MaxMapBuckets)
+	}
 
 	count := 0
 	errcount := 0
@@ -1765,9 +1944,28 @@ func (v *Variable) loadMap(recurseLevel int, cfg LoadConfig) {
 		if count >= cfg.MaxArrayValues || int64(count) >= v.Len {
 			break
 		}
+		if v.mapEnd > 0 && v.mapSkip+count >= v.mapEnd {
+			break
+		}
+	}
+	if v.Unreadable == nil && int64(v.mapSkip+count) < v.Len {
+		v.mapNext = it.cursor(int64(v.mapSkip + count))
 	}
 }
 
+// MapCursor is a position in the iteration order of a map, returned by
+// EvalVariableRange so that loading the entries of a map one page at a
+// time can resume from where the previous page ended instead of iterating
+// again from the first bucket. Its fields are only meaningful to the map
+// iterator.
+type MapCursor struct {
+	Index   int64  // index, in iteration order, of the entry at the cursor
+	Buckets uint64 // address of the buckets array of the map
+	Bucket  uint64 // index in the buckets array of the next bucket
+	Cur     uint64 // address of the bucket being iterated, 0 if none
+	Slot    int64  // index of the next slot of the bucket at Cur
+}
+
 type mapIterator struct {
 	v          *Variable
 	numbuckets uint64
@@ -1784,6 +1982,11 @@ type mapIterator struct {
 
 	maxNumBuckets uint64 // maximum number of buckets to scan
 
+	// keyType and elemType are the key and element types of the map, which
+	// differ from the types of the buckets when the map was declared
+	// using type parameters.
+	keyType, elemType godwarf.Type
+
 	idx int64
 
 	hashTophashEmptyOne uint64 // Go 1.12 and later has two sentinel tophash values for an empty cell, this is the second one (the first one hashTophashEmptyZero, the same as Go 1.11 and earlier)
@@ -1793,7 +1996,8 @@ type mapIterator struct {
 // Code derived from go/src/runtime/hashmap.go
 func (v *Variable) mapIterator() *mapIterator {
 	sv := v.clone()
-	sv.RealType = resolveTypedef(&(sv.RealType.(*godwarf.MapType).TypedefType))
+	mt := sv.RealType.(*godwarf.MapType)
+	sv.RealType = resolveTypedef(&(mt.TypedefType))
 	sv = sv.maybeDereference()
 	v.Base = sv.Addr
 
@@ -1803,7 +2007,7 @@ func (v *Variable) mapIterator() *mapIterator {
 		return nil
 	}
 
-	it := &mapIterator{v: v, bidx: 0, b: nil, idx: 0}
+	it := &mapIterator{v: v, bidx: 0, b: nil, idx: 0, keyType: mt.KeyType, elemType: mt.ElemType}
 
 	if sv.Addr == 0 {
 		it.numbuckets = 0
@@ -1853,6 +2057,37 @@ var errMapBucketContentsNotArray = errors.New("malformed map type: keys, values
 var errMapBucketContentsInconsistentLen = errors.New("malformed map type: inconsistent array length in bucket")
 var errMapBucketsNotStruct = errors.New("malformed map type: buckets, oldbuckets or overflow field not a struct")
 
+// cursor returns the position of the iterator, index is the index of
+// the next entry in iteration order.
+func (it *mapIterator) cursor(index int64) *MapCursor {
+	c := &MapCursor{Index: index, Buckets: uint64(it.buckets.Addr), Bucket: it.bidx, Slot: it.idx}
+	if it.b != nil {
+		c.Cur = uint64(it.b.Addr)
+	}
+	return c
+}
+
+// seek moves the iterator to the position c, returned by cursor. Returns
+// false if c does not belong to this map.
+func (it *mapIterator) seek(c *MapCursor) bool {
+	if it.buckets == nil || c.Buckets != uint64(it.buckets.Addr) || c.Bucket > it.numbuckets {
+		return false
+	}
+	it.bidx = c.Bucket
+	it.b = nil
+	it.overflow = nil
+	if c.Cur != 0 {
+		b := it.buckets.clone()
+		b.Addr = uintptr(c.Cur)
+		it.b = b
+		if !it.loadBucket() {
+			return false
+		}
+		it.idx = c.Slot
+	}
+	return true
+}
+
 func (it *mapIterator) nextBucket() bool {
 	if it.overflow != nil && it.overflow.Addr > 0 {
 		it.b = it.overflow
@@ -1908,6 +2143,11 @@ func (it *mapIterator) nextBucket() bool {
 		return false
 	}
 
+	return it.loadBucket()
+}
+
+// loadBucket reads the fields of the bucket it.b.
+func (it *mapIterator) loadBucket() bool {
 	it.b.mem = cacheMemory(it.b.mem, it.b.Addr, int(it.b.RealType.Size()))
 
 	it.tophashes = nil
@@ -1993,12 +2233,22 @@ func (it *mapIterator) next() bool {
 
 func (it *mapIterator) key() *Variable {
 	k, _ := it.keys.sliceAccess(int(it.idx - 1))
-	return k
+	return it.retype(k, it.keyType)
 }
 
 func (it *mapIterator) value() *Variable {
 	v, _ := it.values.sliceAccess(int(it.idx - 1))
-	return v
+	return it.retype(v, it.elemType)
+}
+
+// retype returns v with type typ if the type of v, read from the buckets
+// of the map, is a different type with the same size (e.g. the shape type
+// of a type parameter).
+func (it *mapIterator) retype(v *Variable, typ godwarf.Type) *Variable {
+	if v == nil || typ == nil || v.DwarfType == typ || v.DwarfType.Size() != typ.Size() || v.DwarfType.String() == typ.String() {
+		return v
+	}
+	return v.newVariable(v.Name, v.Addr, typ, v.mem)
 }
 
 func (it *mapIterator) mapEvacuated(b *Variable) bool {
@@ -2119,6 +2369,16 @@ func (v *Variable) ConstDescr() string {
 	if v.bi == nil || (v.Flags&VariableConstant != 0) {
 		return ""
 	}
+	if et, isenum := v.RealType.(*godwarf.EnumType); isenum {
+		n, _ := constant.Int64Val(v.Value)
+		for _, val := range et.Val {
+			if val.Val == n {
+				return val.Name
+			}
+		}
+		return ""
+	}
+	v.bi.loadPendingTypes()
 	ctyp := v.bi.consts.Get(v.DwarfType)
 	if ctyp == nil {
 		return ""
@@ -8,6 +8,7 @@ import (
 
 	"golang.org/x/arch/x86/x86asm"
 
+	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/proc"
 )
 
@@ -416,6 +417,66 @@ type CONTEXT struct {
 	LastExceptionFromRip uint64
 }
 
+// SetReg changes the value of one of the registers to the value of reg,
+// regNum is a DWARF register number.
+func (ctx *CONTEXT) SetReg(regNum uint64, reg *op.DwarfRegister) error {
+	var p *uint64
+	switch regNum {
+	case 0:
+		p = &ctx.Rax
+	case 1:
+		p = &ctx.Rdx
+	case 2:
+		p = &ctx.Rcx
+	case 3:
+		p = &ctx.Rbx
+	case 4:
+		p = &ctx.Rsi
+	case 5:
+		p = &ctx.Rdi
+	case 6:
+		p = &ctx.Rbp
+	case 7:
+		p = &ctx.Rsp
+	case 8:
+		p = &ctx.R8
+	case 9:
+		p = &ctx.R9
+	case 10:
+		p = &ctx.R10
+	case 11:
+		p = &ctx.R11
+	case 12:
+		p = &ctx.R12
+	case 13:
+		p = &ctx.R13
+	case 14:
+		p = &ctx.R14
+	case 15:
+		p = &ctx.R15
+	case 16:
+		p = &ctx.Rip
+	}
+	if p != nil {
+		*p = reg.Uint64Val
+		return nil
+	}
+
+	if regNum < 17 || regNum > 32 {
+		return fmt.Errorf("changing register %d not implemented", regNum)
+	}
+
+	// XMM registers
+	i := int(regNum - 17)
+	reg.FillBytes()
+	xmm := ctx.FltSave.XmmRegisters[i*16 : (i+1)*16]
+	for j := range xmm {
+		xmm[j] = 0
+	}
+	copy(xmm, reg.Bytes)
+	return nil
+}
+
 // NewCONTEXT allocates Windows CONTEXT structure aligned to 16 bytes.
 func NewCONTEXT() *CONTEXT {
 	var c *CONTEXT
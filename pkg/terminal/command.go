@@ -23,6 +23,7 @@ import (
 
 	"github.com/cosiner/argv"
 	"github.com/go-delve/delve/pkg/locspec"
+	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/rpc2"
@@ -117,16 +118,25 @@ Type "help" followed by the name of a command for more information about it.`},
 		{aliases: []string{"break", "b"}, group: breakCmds, cmdFn: breakpoint, helpMsg: `Sets a breakpoint.
 
 	break [name] <linespec>
+	break [name] -pending <linespec>
+	break [name] -panic [<regex>]
 
 See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec.
 
+The second form creates a pending breakpoint if linespec can not be found in the code currently loaded by the program, the breakpoint will be set as soon as a shared library or plugin containing linespec is loaded. Only linespecs that do not depend on the current position (i.e. functions, files and regular expressions) can be used.
+
+The third form sets a breakpoint that is triggered every time the program panics, whether the panic is later recovered or not. If regex is specified the breakpoint will only be triggered when the dynamic type of the panic argument, or its error message, matches it.
+
 See also: "help on", "help cond" and "help clear"`},
 		{aliases: []string{"trace", "t"}, group: breakCmds, cmdFn: tracepoint, helpMsg: `Set tracepoint.
 
 	trace [name] <linespec>
+	trace [name] -pending <linespec>
 
 A tracepoint is a breakpoint that does not stop the execution of the program, instead when the tracepoint is hit a notification is displayed. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec.
 
+The second form creates a pending tracepoint, see "help break".
+
 See also: "help on", "help cond" and "help clear"`},
 		{aliases: []string{"restart", "r"}, group: runCmds, cmdFn: restart, helpMsg: `Restart process.
 
@@ -144,7 +154,12 @@ If newargv is omitted the process is restarted (or re-recorded) with the same ar
 If -noargs is specified instead, the argument vector is cleared.
 `},
 		{aliases: []string{"continue", "c"}, group: runCmds, cmdFn: c.cont, allowedPrefixes: revPrefix, helpMsg: "Run until breakpoint or program termination."},
-		{aliases: []string{"step", "s"}, group: runCmds, cmdFn: c.step, allowedPrefixes: revPrefix, helpMsg: "Single step through program."},
+		{aliases: []string{"step", "s"}, group: runCmds, cmdFn: c.step, allowedPrefixes: revPrefix, helpMsg: `Single step through program.
+
+	step [-targets | [-target] <function name>]
+
+If a function name is specified execution continues until the function, called by the current line, is entered. This is useful when the current line makes more than one function call, for example f(g(x), h(y)). The package of the function can be omitted.
+With -targets the functions called by the current line are listed.`},
 		{aliases: []string{"step-instruction", "si"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.stepInstruction, helpMsg: "Single step a single cpu instruction."},
 		{aliases: []string{"next", "n"}, group: runCmds, cmdFn: c.next, allowedPrefixes: revPrefix, helpMsg: `Step over to next source line.
 
@@ -153,6 +168,18 @@ If -noargs is specified instead, the argument vector is cleared.
 Optional [count] argument allows you to skip multiple lines.
 `},
 		{aliases: []string{"stepout", "so"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.stepout, helpMsg: "Step out of the current function."},
+		{aliases: []string{"until", "u"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.until, helpMsg: `Continue until a location in the current function is reached.
+
+	until <linespec>
+
+Execution stops when the current goroutine reaches the location in the current frame (recursive calls are skipped) or when the current function returns.
+See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec.`},
+		{aliases: []string{"advance"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.advance, helpMsg: `Continue until the current goroutine reaches a location.
+
+	advance <linespec>
+
+Works like setting a temporary breakpoint, that only the current goroutine can hit, and continuing.
+See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec.`},
 		{aliases: []string{"call"}, group: runCmds, cmdFn: c.call, helpMsg: `Resumes process, injecting a function call (EXPERIMENTAL!!!)
 	
 	call [-unsafe] <function call expression>
@@ -168,6 +195,13 @@ Current limitations:
   point.
 - calling a function will resume execution of all goroutines.
 - only supported on linux's native backend.
+
+On core files and recordings the called function is not executed, its
+machine code is interpreted instead. Only functions that do not write to
+memory outside of their stack and the memory they allocate, make system
+calls or panic, for example most String and Len methods, can be called
+this way.
+Only supported on amd64.
 `},
 		{aliases: []string{"threads"}, group: goroutineCmds, cmdFn: threads, helpMsg: "Print out info for every traced thread."},
 		{aliases: []string{"thread", "tr"}, group: goroutineCmds, cmdFn: thread, helpMsg: `Switch to the specified thread.
@@ -205,9 +239,32 @@ Called without arguments it will show information about the current goroutine.
 Called with a single argument it will switch to the specified goroutine.
 Called with more arguments it will execute a command on the specified goroutine.`},
 		{aliases: []string{"breakpoints", "bp"}, group: breakCmds, cmdFn: breakpoints, helpMsg: "Print out info for active breakpoints."},
+		{aliases: []string{"catch"}, group: breakCmds, cmdFn: catch, helpMsg: `Stops the program when it enters or exits a system call.
+
+	catch syscall [<name or number> ...]
+	catch -clear
+
+If no system calls are specified the program will stop at every system call. Setting a new syscall catchpoint replaces the previous one, use catch -clear to remove it.
+
+Only supported on linux's native backend.`},
+		{aliases: []string{"handle"}, group: breakCmds, cmdFn: handle, helpMsg: `Changes how signals received by the program are handled.
+
+	handle [<signal> [stop|nostop] [print|noprint] [pass|nopass]]
+
+	stop	the program is stopped when it receives the signal, implies print
+	print	a message is printed when the program receives the signal
+	pass	the signal is delivered to the program
+
+Signals can be specified by name (SIGSEGV or segv) or number. Called with only a signal it shows how the signal is handled, called without arguments it lists all signals that are not handled in the default way (nostop noprint pass). Default policies can also be changed using the "signals" section of the configuration file.
+
+Only supported on linux's native backend.`},
 		{aliases: []string{"print", "p"}, group: dataCmds, allowedPrefixes: onPrefix | deferredPrefix, cmdFn: printVar, helpMsg: `Evaluate an expression.
 
-	[goroutine <n>] [frame <m>] print <expression>
+	[goroutine <n>] [frame <m>] print [-raw] [%format] <expression>
+
+If a format is specified integer, floating point and string values, including those contained in arrays, slices, maps and structs, are formatted with it. The format is a verb of Go's fmt package with optional flags, width and precision, for example %x, %o, %b, %c, %q or %08.3f.
+
+Values of some standard library types (time.Time, time.Duration, big.Int, net.IP, sync.Mutex, strings.Builder, bytes.Buffer, reflect.Value, sync.Map and list.List) and of types matching a pretty-printer are displayed in a readable form, use -raw to display their internal representation instead.
 
 See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.`},
 		{aliases: []string{"whatis"}, group: dataCmds, cmdFn: whatisCommand, helpMsg: `Prints type of an expression.
@@ -285,6 +342,8 @@ For example:
 			normal	- attempts to automatically switch between cgo frames and go frames
 			simple	- disables automatic switch between cgo and go
 			fromg	- starts from the registers stored in the runtime.g struct
+
+Frames marked "(unwound by frame pointer)" were found by following the frame pointer chain because the previous frame had no valid unwind information, they may be incorrect.
 `},
 		{aliases: []string{"frame"},
 			group: stackCmds,
@@ -395,12 +454,21 @@ For example:
 
 		{aliases: []string{"display"}, group: dataCmds, cmdFn: display, helpMsg: `Print value of an expression every time the program stops.
 
-	display -a <expression>
+	display -a [%format] <expression>
 	display -d <number>
 
-The '-a' option adds an expression to the list of expression printed every time the program stops. The '-d' option removes the specified expression from the list.
+The '-a' option adds an expression to the list of expression printed every time the program stops, formatted using format if specified (see the print command). The '-d' option removes the specified expression from the list.
 
 If display is called without arguments it will print the value of all expression in the list.`},
+
+		{aliases: []string{"diff"}, group: dataCmds, cmdFn: diffCommand, helpMsg: `Compares two values structurally.
+
+	diff <expression1> <expression2>
+	diff -since-last-stop <expression>
+
+Prints the differences between the values of two expressions: struct fields that changed, slice and array elements that differ, by index, and map keys that were added, removed or whose value changed. Parts of the values beyond the loading limits are not compared.
+
+With -since-last-stop the current value of the expression is compared with its value at the previous stop. The first time an expression is used with -since-last-stop its value is only recorded, from then on its value is recorded every time the program stops.`},
 	}
 
 	addrecorded := client == nil
@@ -1140,10 +1208,30 @@ func (c *Commands) step(t *Term, ctx callContext, args string) error {
 		return err
 	}
 	c.frame = 0
+	args = strings.TrimSpace(args)
+	if args == "-targets" {
+		tgts, err := t.client.ListStepInTargets()
+		if err != nil {
+			return err
+		}
+		for _, tgt := range tgts {
+			fmt.Printf("%s\t(call at %#x)\n", tgt.Function.Name(), tgt.CallPC)
+		}
+		return nil
+	}
 	stepfn := t.client.Step
 	if ctx.Prefix == revPrefix {
 		stepfn = t.client.ReverseStep
 	}
+	args = strings.TrimSpace(strings.TrimPrefix(args, "-target "))
+	if args != "" {
+		if ctx.Prefix == revPrefix {
+			return errors.New("can not step into a specific function backward")
+		}
+		stepfn = func() (*api.DebuggerState, error) {
+			return t.client.StepInto(args)
+		}
+	}
 	state, err := exitedToError(stepfn())
 	if err != nil {
 		printcontextNoState(t)
@@ -1251,6 +1339,41 @@ func (c *Commands) stepout(t *Term, ctx callContext, args string) error {
 	return continueUntilCompleteNext(t, state, "stepout", true)
 }
 
+func (c *Commands) until(t *Term, ctx callContext, args string) error {
+	untilfn := t.client.Until
+	if ctx.Prefix == revPrefix {
+		untilfn = t.client.ReverseUntil
+	}
+	return c.continueToLocation(t, ctx, args, "until", untilfn)
+}
+
+func (c *Commands) advance(t *Term, ctx callContext, args string) error {
+	advancefn := t.client.Advance
+	if ctx.Prefix == revPrefix {
+		advancefn = t.client.ReverseAdvance
+	}
+	return c.continueToLocation(t, ctx, args, "advance", advancefn)
+}
+
+func (c *Commands) continueToLocation(t *Term, ctx callContext, args, op string, fn func(string) (*api.DebuggerState, error)) error {
+	if err := scopePrefixSwitch(t, ctx); err != nil {
+		return err
+	}
+	if c.frame != 0 {
+		return notOnFrameZeroErr
+	}
+	if args == "" {
+		return fmt.Errorf("not enough arguments to %s", op)
+	}
+	state, err := exitedToError(fn(args))
+	if err != nil {
+		printcontextNoState(t)
+		return err
+	}
+	printcontext(t, state)
+	return continueUntilCompleteNext(t, state, op, true)
+}
+
 func (c *Commands) call(t *Term, ctx callContext, args string) error {
 	if err := scopePrefixSwitch(t, ctx); err != nil {
 		return err
@@ -1350,6 +1473,9 @@ func breakpoints(t *Term, ctx callContext, args string) error {
 		if bp.Cond != "" {
 			attrs = append(attrs, fmt.Sprintf("\tcond %s", bp.Cond))
 		}
+		if bp.Panic {
+			attrs = append(attrs, strings.TrimRight(fmt.Sprintf("\tpanic %s", bp.PanicFilter), " "))
+		}
 		if bp.Stacktrace > 0 {
 			attrs = append(attrs, fmt.Sprintf("\tstack %d", bp.Stacktrace))
 		}
@@ -1400,8 +1526,16 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) err
 	}
 
 	requestedBp.Tracepoint = tracepoint
+	pending := false
+	if strings.HasPrefix(spec, "-pending ") {
+		pending = true
+		spec = strings.TrimSpace(spec[len("-pending "):])
+	}
 	locs, err := t.client.FindLocation(ctx.Scope, spec, true)
 	if err != nil {
+		if pending {
+			return setPendingBreakpoint(t, requestedBp, spec)
+		}
 		if requestedBp.Name == "" {
 			return err
 		}
@@ -1465,9 +1599,44 @@ func setBreakpoint(t *Term, ctx callContext, tracepoint bool, argstr string) err
 }
 
 func breakpoint(t *Term, ctx callContext, args string) error {
+	name, rest := "", args
+	if v := split2PartsBySpace(args); len(v) == 2 && v[0] != "-panic" && api.ValidBreakpointName(v[0]) == nil {
+		name, rest = v[0], v[1]
+	}
+	if rest == "-panic" || strings.HasPrefix(rest, "-panic ") {
+		return setPanicBreakpoint(t, name, strings.TrimSpace(rest[len("-panic"):]))
+	}
 	return setBreakpoint(t, ctx, false, args)
 }
 
+func setPendingBreakpoint(t *Term, requestedBp *api.Breakpoint, spec string) error {
+	requestedBp.LocExpr = spec
+	requestedBp.Pending = true
+	if requestedBp.Tracepoint {
+		requestedBp.LoadArgs = &ShortLoadConfig
+	}
+	bp, err := t.client.CreateBreakpoint(requestedBp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s set at %s\n", formatBreakpointName(bp, true), formatBreakpointLocation(bp))
+	return nil
+}
+
+func setPanicBreakpoint(t *Term, name, filter string) error {
+	bp, err := t.client.CreateBreakpoint(&api.Breakpoint{
+		Name:        name,
+		Panic:       true,
+		PanicFilter: filter,
+		Variables:   []string{"e"},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s set at %s\n", formatBreakpointName(bp, true), formatBreakpointLocation(bp))
+	return nil
+}
+
 func tracepoint(t *Term, ctx callContext, args string) error {
 	return setBreakpoint(t, ctx, true, args)
 }
@@ -1571,19 +1740,137 @@ func printVar(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
 	}
+	raw := false
+	if v := split2PartsBySpace(args); len(v) == 2 && v[0] == "-raw" {
+		raw = true
+		args = v[1]
+	}
+	fmtstr, args, err := parseFormatArg(args)
+	if err != nil {
+		return err
+	}
 	if ctx.Prefix == onPrefix {
+		if raw || fmtstr != "" {
+			return fmt.Errorf("-raw and formats can not be used with on")
+		}
 		ctx.Breakpoint.Variables = append(ctx.Breakpoint.Variables, args)
 		return nil
 	}
-	val, err := t.client.EvalVariable(ctx.Scope, args, t.loadConfig())
+	cfg := t.loadConfig()
+	cfg.DisableFormatters = raw
+	val, err := t.client.EvalVariable(ctx.Scope, args, cfg)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(val.MultilineString(""))
+	if !raw {
+		t.starlarkEnv.ApplyPrettyPrinters(val)
+	}
+	fmt.Println(val.MultilineStringFormatted("", fmtstr))
+	return nil
+}
+
+// parseFormatArg splits the optional format, for example %x, at the start
+// of args from the expression that follows it.
+func parseFormatArg(args string) (fmtstr, expr string, err error) {
+	if !strings.HasPrefix(args, "%") {
+		return "", args, nil
+	}
+	v := split2PartsBySpace(args)
+	if len(v) != 2 {
+		return "", "", fmt.Errorf("not enough arguments")
+	}
+	if err := api.ValidFormatVerb(v[0]); err != nil {
+		return "", "", err
+	}
+	return v[0], v[1], nil
+}
+
+// diffLoadConfig is used to load the values compared by the diff command.
+var diffLoadConfig = api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 8, MaxStringLen: 1024, MaxArrayValues: 1024, MaxStructFields: -1}
+
+const sinceLastStopOption = "-since-last-stop"
+
+func diffCommand(t *Term, ctx callContext, args string) error {
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, sinceLastStopOption+" ") || args == sinceLastStopOption {
+		expr := strings.TrimSpace(args[len(sinceLastStopOption):])
+		if expr == "" {
+			return fmt.Errorf("not enough arguments")
+		}
+		diffs, recorded, err := t.client.DiffVariableSinceLastStop(ctx.Scope, expr, diffLoadConfig)
+		if err != nil {
+			return err
+		}
+		if recorded {
+			fmt.Printf("Recorded the value of %s, it can be compared after the next stop\n", expr)
+			return nil
+		}
+		printVariableDiffs(diffs)
+		return nil
+	}
+
+	expr1, expr2, err := splitDiffArgs(args)
+	if err != nil {
+		return err
+	}
+	diffs, err := t.client.DiffVariables(ctx.Scope, expr1, expr2, diffLoadConfig)
+	if err != nil {
+		return err
+	}
+	printVariableDiffs(diffs)
 	return nil
 }
 
+// splitDiffArgs splits the arguments of the diff command into two
+// expressions. Expressions can contain spaces, the arguments are split at
+// the first space that leaves a valid expression on both sides.
+func splitDiffArgs(args string) (string, string, error) {
+	for i := range args {
+		if args[i] != ' ' {
+			continue
+		}
+		expr1, expr2 := strings.TrimSpace(args[:i]), strings.TrimSpace(args[i+1:])
+		if expr1 == "" || expr2 == "" {
+			continue
+		}
+		// the expressions are parsed like the debugger does, they can use
+		// the syntax of collection queries
+		if _, err := proc.ParseExpr(expr1); err != nil {
+			continue
+		}
+		if _, err := proc.ParseExpr(expr2); err != nil {
+			continue
+		}
+		return expr1, expr2, nil
+	}
+	if v := split2PartsBySpace(args); len(v) == 2 && v[1] != "" {
+		return v[0], v[1], nil
+	}
+	return "", "", fmt.Errorf("not enough arguments")
+}
+
+func printVariableDiffs(diffs []api.VariableDiff) {
+	if len(diffs) == 0 {
+		fmt.Println("No differences")
+		return
+	}
+	for _, d := range diffs {
+		path := d.Path
+		if path == "" {
+			path = "value"
+		}
+		switch d.Kind {
+		case api.DiffAdded:
+			fmt.Printf("%s: added %s\n", path, d.New.SinglelineString())
+		case api.DiffRemoved:
+			fmt.Printf("%s: removed %s\n", path, d.Old.SinglelineString())
+		default:
+			fmt.Printf("%s: %s -> %s\n", path, d.Old.SinglelineString(), d.New.SinglelineString())
+		}
+	}
+}
+
 func whatisCommand(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
@@ -1624,7 +1911,7 @@ func setVar(t *Term, ctx callContext, args string) error {
 	return t.client.SetVariable(ctx.Scope, lexpr, rexpr)
 }
 
-func printFilteredVariables(varType string, vars []api.Variable, filter string, cfg api.LoadConfig) error {
+func printFilteredVariables(t *Term, varType string, vars []api.Variable, filter string, cfg api.LoadConfig) error {
 	reg, err := regexp.Compile(filter)
 	if err != nil {
 		return err
@@ -1633,6 +1920,7 @@ func printFilteredVariables(varType string, vars []api.Variable, filter string,
 	for _, v := range vars {
 		if reg == nil || reg.Match([]byte(v.Name)) {
 			match = true
+			t.starlarkEnv.ApplyPrettyPrinters(&v)
 			name := v.Name
 			if v.Flags&api.VariableShadowed != 0 {
 				name = "(" + name + ")"
@@ -1697,7 +1985,7 @@ func args(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("args", vars, filter, cfg)
+	return printFilteredVariables(t, "args", vars, filter, cfg)
 }
 
 func locals(t *Term, ctx callContext, args string) error {
@@ -1713,7 +2001,7 @@ func locals(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("locals", locals, filter, cfg)
+	return printFilteredVariables(t, "locals", locals, filter, cfg)
 }
 
 func vars(t *Term, ctx callContext, args string) error {
@@ -1722,7 +2010,7 @@ func vars(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("vars", vars, filter, cfg)
+	return printFilteredVariables(t, "vars", vars, filter, cfg)
 }
 
 func regs(t *Term, ctx callContext, args string) error {
@@ -2057,7 +2345,11 @@ func printStack(out io.Writer, stack []api.Stackframe, ind string, offsets bool)
 			fmt.Fprintf(out, "%serror: %s\n", s, stack[i].Err)
 			continue
 		}
-		fmt.Fprintf(out, fmtstr, ind, i, stack[i].PC, stack[i].Function.Name())
+		fnname := stack[i].Function.Name()
+		if stack[i].UnwoundByFramePointer {
+			fnname += " (unwound by frame pointer)"
+		}
+		fmt.Fprintf(out, fmtstr, ind, i, stack[i].PC, fnname)
 		fmt.Fprintf(out, "%sat %s:%d\n", s, shortenFilePath(stack[i].File), stack[i].Line)
 
 		if offsets {
@@ -2094,6 +2386,10 @@ func printStack(out io.Writer, stack []api.Stackframe, ind string, offsets bool)
 }
 
 func printcontext(t *Term, state *api.DebuggerState) {
+	for _, bp := range state.ResolvedBreakpoints {
+		fmt.Printf("%s resolved at %s\n", formatBreakpointName(bp, true), formatBreakpointLocation(bp))
+	}
+
 	for i := range state.Threads {
 		if (state.CurrentThread != nil) && (state.Threads[i].ID == state.CurrentThread.ID) {
 			continue
@@ -2108,6 +2404,14 @@ func printcontext(t *Term, state *api.DebuggerState) {
 		return
 	}
 
+	for _, sig := range state.Signals {
+		fmt.Printf("Thread %d received signal %s\n", sig.ThreadID, sig.Name)
+	}
+
+	if state.Syscall != nil {
+		printSyscall(state.Syscall)
+	}
+
 	var th *api.Thread
 	if state.SelectedGoroutine == nil {
 		th = state.CurrentThread
@@ -2155,6 +2459,22 @@ func printReturnValues(th *api.Thread) {
 	fmt.Println()
 }
 
+func printSyscall(sc *api.Syscall) {
+	name := sc.Name
+	if name == "" {
+		name = fmt.Sprintf("syscall(%d)", sc.Num)
+	}
+	args := make([]string, len(sc.Args))
+	for i := range sc.Args {
+		args[i] = fmt.Sprintf("%#x", sc.Args[i])
+	}
+	if sc.Exit {
+		fmt.Printf("> [syscall exit] thread %d: %s(%s) = %d\n", sc.ThreadID, name, strings.Join(args, ", "), sc.Ret)
+	} else {
+		fmt.Printf("> [syscall entry] thread %d: %s(%s)\n", sc.ThreadID, name, strings.Join(args, ", "))
+	}
+}
+
 func printcontextThread(t *Term, th *api.Thread) {
 	fn := th.Function
 
@@ -2437,6 +2757,59 @@ func (c *Commands) rewind(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+func catch(t *Term, ctx callContext, args string) error {
+	v := strings.Fields(args)
+	if len(v) == 0 {
+		return errors.New("not enough arguments to catch")
+	}
+	switch v[0] {
+	case "-clear":
+		if len(v) != 1 {
+			return errors.New("too many arguments to catch -clear")
+		}
+		return t.client.ClearSyscallCatchpoint()
+	case "syscall":
+		return t.client.SetSyscallCatchpoint(v[1:])
+	default:
+		return fmt.Errorf("unknown catchpoint type %q", v[0])
+	}
+}
+
+func handle(t *Term, ctx callContext, args string) error {
+	v := strings.Fields(args)
+	var signals []string
+	if len(v) > 0 {
+		signals = v[:1]
+	}
+	policies, err := t.client.ListSignalPolicies(signals)
+	if err != nil {
+		return err
+	}
+	if len(v) > 1 {
+		policy, err := api.ParseSignalPolicy(policies[0], v[1:])
+		if err != nil {
+			return err
+		}
+		if err := t.client.SetSignalPolicy(policy); err != nil {
+			return err
+		}
+		policies[0] = policy
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 4, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "Signal\tStop\tPrint\tPass")
+	yesno := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	for _, policy := range policies {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", policy.Signal, yesno(policy.Stop), yesno(policy.Print), yesno(policy.Pass))
+	}
+	return w.Flush()
+}
+
 func checkpoint(t *Term, ctx callContext, args string) error {
 	if args == "" {
 		state, err := t.client.GetState()
@@ -2502,7 +2875,11 @@ func display(t *Term, ctx callContext, args string) error {
 		if args == "" {
 			return fmt.Errorf("not enough arguments")
 		}
-		t.addDisplay(args)
+		fmtstr, args, err := parseFormatArg(args)
+		if err != nil {
+			return err
+		}
+		t.addDisplay(args, fmtstr)
 		t.printDisplay(len(t.displays) - 1)
 
 	case strings.HasPrefix(args, delOption):
@@ -2535,6 +2912,9 @@ func formatBreakpointName(bp *api.Breakpoint, upcase bool) string {
 }
 
 func formatBreakpointLocation(bp *api.Breakpoint) string {
+	if bp.Pending {
+		return fmt.Sprintf("%s (pending)", bp.LocExpr)
+	}
 	var out bytes.Buffer
 	if len(bp.Addrs) > 0 {
 		for i, addr := range bp.Addrs {
@@ -1075,3 +1075,116 @@ func TestExamineMemoryCmd(t *testing.T) {
 		}
 	})
 }
+
+func TestDiffCmd(t *testing.T) {
+	withTestTerminal("largecollections", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		out := term.MustExec("diff s[:3] s[1:4]")
+		for _, tgt := range []string{"[0]: 0 -> 1\n", "[1]: 1 -> 2\n", "[2]: 2 -> 3\n"} {
+			if !strings.Contains(out, tgt) {
+				t.Errorf("output of diff does not contain %q:\n%s", tgt, out)
+			}
+		}
+		out = term.MustExec("diff m[0] m[0]")
+		if !strings.Contains(out, "No differences") {
+			t.Errorf("wrong output of diff between equal values:\n%s", out)
+		}
+	})
+
+	withTestTerminal("increment", t, func(term *FakeTerminal) {
+		term.MustExec("break increment.go:10")
+		term.MustExec("continue")
+		out := term.MustExec("diff -since-last-stop y")
+		if !strings.Contains(out, "Recorded the value of y") {
+			t.Errorf("wrong output of the first diff -since-last-stop:\n%s", out)
+		}
+		term.MustExec("continue")
+		for i := 0; i < 2; i++ {
+			out = term.MustExec("diff -since-last-stop y")
+			if !strings.Contains(out, "value: 3 -> 1") {
+				t.Errorf("wrong output of diff -since-last-stop:\n%s", out)
+			}
+		}
+		// the value is compared with the one at the previous stop, not with
+		// the one at the last stop where it was compared
+		term.MustExec("next")
+		term.MustExec("step")
+		term.MustExec("next")
+		out = term.MustExec("diff -since-last-stop y")
+		if !strings.Contains(out, "No differences") {
+			t.Errorf("wrong output of diff -since-last-stop after next:\n%s", out)
+		}
+	})
+}
+
+func TestSplitDiffArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args, expr1, expr2 string
+	}{
+		{"a b", "a", "b"},
+		{"a + 1 b[2]", "a + 1", "b[2]"},
+		{"filter(s, x => x > 0) s", "filter(s, x => x > 0)", "s"},
+		{"s[?].A == 1 map(s, x => x.A)", "s[?].A == 1", "map(s, x => x.A)"},
+	} {
+		expr1, expr2, err := splitDiffArgs(tc.args)
+		if err != nil || expr1 != tc.expr1 || expr2 != tc.expr2 {
+			t.Errorf("%q: got %q %q %v expected %q %q", tc.args, expr1, expr2, err, tc.expr1, tc.expr2)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	if !goversion.VersionAfterOrEqual(runtime.Version(), 1, 18) {
+		t.Skip("generics not supported")
+	}
+	withTestTerminal("testgenerics", t, func(term *FakeTerminal) {
+		term.MustExec("break main.MakePair")
+		for _, tc := range []struct {
+			k, ktyp, v, vtyp string
+		}{
+			{"1", "int", `"a"`, "string"},
+			{`"x"`, "string", "2.5", "float64"},
+		} {
+			term.MustExec("continue")
+			if out := strings.TrimSpace(term.MustExec("print k")); out != tc.k {
+				t.Errorf("print k: got %q expected %q", out, tc.k)
+			}
+			if out := strings.TrimSpace(term.MustExec("whatis k")); out != tc.ktyp {
+				t.Errorf("whatis k: got %q expected %q", out, tc.ktyp)
+			}
+			if out := strings.TrimSpace(term.MustExec("print v")); out != tc.v {
+				t.Errorf("print v: got %q expected %q", out, tc.v)
+			}
+			if out := strings.TrimSpace(term.MustExec("whatis v")); out != tc.vtyp {
+				t.Errorf("whatis v: got %q expected %q", out, tc.vtyp)
+			}
+		}
+	})
+
+	withTestTerminal("testgenerics", t, func(term *FakeTerminal) {
+		term.MustExec("break main.MakePair[string, float64]")
+		term.MustExec("continue")
+		if out := strings.TrimSpace(term.MustExec("print k")); out != `"x"` {
+			t.Errorf("print k: got %q expected %q", out, `"x"`)
+		}
+	})
+
+	withTestTerminal("testgenerics", t, func(term *FakeTerminal) {
+		term.MustExec("break testgenerics.go:24")
+		term.MustExec("continue")
+		for _, tc := range []struct {
+			expr, out string
+		}{
+			{"whatis x", "int"}, // escaped variable
+			{"print x", "3"},
+			{"whatis s", "[]*int"},
+			{"whatis s[0]", "*int"},
+			{"print *s[0]", "3"},
+			{"whatis &x", "*int"},
+		} {
+			if out := strings.TrimSpace(term.MustExec(tc.expr)); out != tc.out {
+				t.Errorf("%s: got %q expected %q", tc.expr, out, tc.out)
+			}
+		}
+	})
+}
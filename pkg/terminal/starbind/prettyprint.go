@@ -0,0 +1,149 @@
+package starbind
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"go.starlark.net/starlark"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// maxPrettyPrintDepth is the maximum nesting level of the children
+// produced by pretty-printers that are pretty-printed themselves.
+const maxPrettyPrintDepth = 3
+
+// prettyPrinter is a pretty-printer defined as a starlark function.
+type prettyPrinter struct {
+	pattern string
+	typ     *regexp.Regexp
+	fn      starlark.Callable
+}
+
+// prettyPrinterBuiltin implements the pretty_printer builtin, which
+// registers fn as the pretty-printer of the types matching typ.
+func (env *Env) prettyPrinterBuiltin(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var typ string
+	var fn starlark.Callable
+	if err := starlark.UnpackArgs(prettyPrinterBuiltinName, args, kwargs, "Type", &typ, "Fn?", &fn); err != nil {
+		return starlark.None, decorateError(thread, err)
+	}
+	for i := range env.prettyPrinters {
+		if env.prettyPrinters[i].pattern == typ {
+			env.prettyPrinters = append(env.prettyPrinters[:i], env.prettyPrinters[i+1:]...)
+			break
+		}
+	}
+	if fn == nil {
+		return starlark.None, nil
+	}
+	re, err := regexp.Compile("^(?:" + typ + ")$")
+	if err != nil {
+		return starlark.None, decorateError(thread, fmt.Errorf("invalid type pattern %q: %v", typ, err))
+	}
+	env.prettyPrinters = append(env.prettyPrinters, prettyPrinter{typ, re, fn})
+	return starlark.None, nil
+}
+
+// ApplyPrettyPrinters sets the Pretty field of v, and of its children,
+// using the pretty-printers defined by starlark scripts. Pretty-printers
+// defined by scripts take precedence over the ones registered with the
+// server.
+func (env *Env) ApplyPrettyPrinters(v *api.Variable) {
+	if env == nil || len(env.prettyPrinters) == 0 {
+		return
+	}
+	env.applyPrettyPrinters(v, 0)
+}
+
+func (env *Env) applyPrettyPrinters(v *api.Variable, depth int) {
+	if depth > maxPrettyPrintDepth {
+		return
+	}
+	for i := range v.Children {
+		env.applyPrettyPrinters(&v.Children[i], depth)
+	}
+	if v.Unreadable != "" {
+		return
+	}
+	for _, pp := range env.prettyPrinters {
+		if pp.typ.MatchString(v.Type) {
+			v.Pretty = env.callPrettyPrinter(pp, v)
+			v.Flags |= api.VariableFormatted
+			for i := range v.Pretty.Children {
+				env.applyPrettyPrinters(&v.Pretty.Children[i], depth+1)
+			}
+			return
+		}
+	}
+}
+
+// callPrettyPrinter calls the function of pp with v as argument. The
+// function returns either the display string of v or a tuple of the
+// display string and a dictionary of synthetic children.
+func (env *Env) callPrettyPrinter(pp prettyPrinter, v *api.Variable) *api.PrettyValue {
+	r := &api.PrettyValue{Printer: pp.pattern}
+	x, err := env.variableValueToStarlarkValue(v, true)
+	if err != nil {
+		r.Value = fmt.Sprintf("<%v>", err)
+		return r
+	}
+	thread := env.newThread()
+	out, err := starlark.Call(thread, pp.fn, starlark.Tuple{x}, nil)
+	if err != nil {
+		r.Value = fmt.Sprintf("<%v>", err)
+		return r
+	}
+	if t, ok := out.(starlark.Tuple); ok && len(t) == 2 {
+		out = t[0]
+		children, ok := t[1].(starlark.IterableMapping)
+		if !ok {
+			r.Value = fmt.Sprintf("<pretty-printer returned %s instead of a dictionary of children>", t[1].Type())
+			return r
+		}
+		for _, item := range children.Items() {
+			child := starlarkValueToVariable(item[1])
+			if name, ok := item[0].(starlark.String); ok {
+				child.Name = string(name)
+			} else {
+				child.Name = item[0].String()
+			}
+			r.Children = append(r.Children, child)
+		}
+	}
+	if s, ok := out.(starlark.String); ok {
+		r.Value = string(s)
+	} else {
+		r.Value = out.String()
+	}
+	return r
+}
+
+// starlarkValueToVariable converts a value returned by a pretty-printer
+// into a variable. Values read from the target process are returned
+// unchanged, other values are converted to a variable of the
+// corresponding Go type.
+func starlarkValueToVariable(x starlark.Value) api.Variable {
+	switch x := x.(type) {
+	case structVariableAsStarlarkValue:
+		return *x.v
+	case sliceVariableAsStarlarkValue:
+		return *x.v
+	case ptrVariableAsStarlarkValue:
+		return *x.v
+	case mapVariableAsStarlarkValue:
+		return *x.v
+	case starlark.String:
+		return api.Variable{Type: "string", Kind: reflect.String, Value: string(x), Len: int64(len(x))}
+	case starlark.Int:
+		return api.Variable{Type: "int", Kind: reflect.Int, Value: x.String()}
+	case starlark.Float:
+		return api.Variable{Type: "float64", Kind: reflect.Float64, Value: strconv.FormatFloat(float64(x), 'g', -1, 64)}
+	case starlark.Bool:
+		return api.Variable{Type: "bool", Kind: reflect.Bool, Value: strconv.FormatBool(bool(x))}
+	default:
+		return api.Variable{Type: x.Type(), Value: x.String()}
+	}
+}
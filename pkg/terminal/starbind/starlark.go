@@ -26,6 +26,7 @@ const (
 	dlvContextName               = "dlv_context"
 	curScopeBuiltinName          = "cur_scope"
 	defaultLoadConfigBuiltinName = "default_load_config"
+	prettyPrinterBuiltinName     = "pretty_printer"
 )
 
 func init() {
@@ -55,6 +56,8 @@ type Env struct {
 	cancelfn  context.CancelFunc
 
 	ctx Context
+
+	prettyPrinters []prettyPrinter
 }
 
 // New creates a new starlark binding environment.
@@ -107,6 +110,7 @@ func New(ctx Context) *Env {
 		err := ioutil.WriteFile(string(path), []byte(args[1].String()), 0640)
 		return starlark.None, decorateError(thread, err)
 	})
+	env.env[prettyPrinterBuiltinName] = starlark.NewBuiltin(prettyPrinterBuiltinName, env.prettyPrinterBuiltin)
 	env.env[curScopeBuiltinName] = starlark.NewBuiltin(curScopeBuiltinName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		return env.interfaceToStarlarkValue(env.ctx.Scope()), nil
 	})
@@ -162,3 +162,32 @@ func TestStarlarkVariable(t *testing.T) {
 		}
 	})
 }
+
+func TestStarlarkPrettyPrinter(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		term.MustExecStarlark(`
+def Astruct(x):
+	return ("A=%d B=%d" % (x.A, x.B), { "sum": x.A + x.B })
+
+def main():
+	pretty_printer("main\\.astruct", Astruct)
+`)
+		if out := strings.TrimSpace(term.MustExec("print as1")); out != "main.astruct A=1 B=1 {sum: 2}" {
+			t.Errorf("wrong output of print as1: %q", out)
+		}
+		if out := strings.TrimSpace(term.MustExec("print -raw as1")); out != "main.astruct {A: 1, B: 1}" {
+			t.Errorf("wrong output of print -raw as1: %q", out)
+		}
+		term.MustExecStarlark(`pretty_printer("main\\.astruct")`)
+		if out := strings.TrimSpace(term.MustExec("print as1")); out != "main.astruct {A: 1, B: 1}" {
+			t.Errorf("wrong output of print as1 after removing the pretty-printer: %q", out)
+		}
+
+		// the type pattern must match the whole type name
+		term.MustExecStarlark(`pretty_printer("main\\.astr", Astruct)`)
+		if out := strings.TrimSpace(term.MustExec("print as1")); out != "main.astruct {A: 1, B: 1}" {
+			t.Errorf("wrong output of print as1 with a partial type pattern: %q", out)
+		}
+	})
+}
@@ -54,7 +54,7 @@ type Term struct {
 	dumb     bool
 	stdout   io.Writer
 	InitFile string
-	displays []string
+	displays []displayEntry
 
 	historyFile *os.File
 
@@ -442,13 +442,19 @@ func (t *Term) loadConfig() api.LoadConfig {
 	return r
 }
 
+// displayEntry is an expression printed every time the program stops.
+type displayEntry struct {
+	expr   string
+	fmtstr string // format of the values, empty for the default format
+}
+
 func (t *Term) removeDisplay(n int) error {
 	if n < 0 || n >= len(t.displays) {
 		return fmt.Errorf("%d is out of range", n)
 	}
-	t.displays[n] = ""
+	t.displays[n] = displayEntry{}
 	for i := len(t.displays) - 1; i >= 0; i-- {
-		if t.displays[i] != "" {
+		if t.displays[i].expr != "" {
 			t.displays = t.displays[:i+1]
 			return nil
 		}
@@ -457,12 +463,12 @@ func (t *Term) removeDisplay(n int) error {
 	return nil
 }
 
-func (t *Term) addDisplay(expr string) {
-	t.displays = append(t.displays, expr)
+func (t *Term) addDisplay(expr, fmtstr string) {
+	t.displays = append(t.displays, displayEntry{expr: expr, fmtstr: fmtstr})
 }
 
 func (t *Term) printDisplay(i int) {
-	expr := t.displays[i]
+	expr, fmtstr := t.displays[i].expr, t.displays[i].fmtstr
 	val, err := t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, ShortLoadConfig)
 	if err != nil {
 		if isErrProcessExited(err) {
@@ -471,12 +477,13 @@ func (t *Term) printDisplay(i int) {
 		fmt.Printf("%d: %s = error %v\n", i, expr, err)
 		return
 	}
-	fmt.Printf("%d: %s = %s\n", i, val.Name, val.SinglelineString())
+	t.starlarkEnv.ApplyPrettyPrinters(val)
+	fmt.Printf("%d: %s = %s\n", i, val.Name, val.SinglelineStringFormatted(fmtstr))
 }
 
 func (t *Term) printDisplays() {
 	for i := range t.displays {
-		if t.displays[i] != "" {
+		if t.displays[i].expr != "" {
 			t.printDisplay(i)
 		}
 	}
@@ -7,6 +7,7 @@ import (
 	"go/printer"
 	"go/token"
 	"reflect"
+	"regexp"
 	"strconv"
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
@@ -35,6 +36,11 @@ func ConvertBreakpoint(bp *proc.Breakpoint) *Breakpoint {
 		Addrs:         []uint64{bp.Addr},
 	}
 
+	if bp.PanicFilter != nil {
+		b.Panic = true
+		b.PanicFilter = bp.PanicFilter.String()
+	}
+
 	b.HitCount = map[string]uint64{}
 	for idx := range bp.HitCount {
 		b.HitCount[strconv.Itoa(idx)] = bp.HitCount[idx]
@@ -69,6 +75,42 @@ func ConvertBreakpoints(bps []*proc.Breakpoint) []*Breakpoint {
 	return r
 }
 
+// ConvertSyscall converts a proc.SyscallStop into an api.Syscall.
+func ConvertSyscall(sc *proc.SyscallStop) *Syscall {
+	if sc == nil {
+		return nil
+	}
+	return &Syscall{ThreadID: sc.ThreadID, Num: sc.Num, Name: sc.Name, Args: sc.Args, Exit: sc.Exit, Ret: sc.Ret}
+}
+
+// ConvertStopReason converts a proc.StopReason into an api.StopReason.
+func ConvertStopReason(sr proc.StopReason) StopReason {
+	switch sr {
+	case proc.StopLaunched:
+		return StopLaunched
+	case proc.StopAttached:
+		return StopAttached
+	case proc.StopExited:
+		return StopExited
+	case proc.StopBreakpoint:
+		return StopBreakpoint
+	case proc.StopHardcodedBreakpoint:
+		return StopHardcodedBreakpoint
+	case proc.StopManual:
+		return StopManual
+	case proc.StopNextFinished:
+		return StopNextFinished
+	case proc.StopCallReturned:
+		return StopCallReturned
+	case proc.StopSyscall:
+		return StopSyscall
+	case proc.StopSignal:
+		return StopSignal
+	default:
+		return StopUnknown
+	}
+}
+
 // ConvertThread converts a proc.Thread into an
 // api thread.
 func ConvertThread(th proc.Thread) *Thread {
@@ -165,7 +207,8 @@ func ConvertVar(v *proc.Variable) *Variable {
 			r.Value = convertFloatValue(v, 32)
 		case reflect.Float64:
 			r.Value = convertFloatValue(v, 64)
-		case reflect.String, reflect.Func:
+		case reflect.String, reflect.Func, reflect.Ptr:
+			// pointers only have a value when they point to C strings
 			r.Value = constant.StringVal(v.Value)
 		default:
 			if cd := v.ConstDescr(); cd != "" {
@@ -227,9 +270,35 @@ func ConvertVar(v *proc.Variable) *Variable {
 		}
 	}
 
+	if v.Pretty != nil {
+		r.Pretty = &PrettyValue{
+			Value:    v.Pretty.Value,
+			Children: make([]Variable, len(v.Pretty.Children)),
+		}
+		if v.Pretty.Printer != nil {
+			r.Pretty.Printer = v.Pretty.Printer.Type.String()
+		}
+		for i := range v.Pretty.Children {
+			r.Pretty.Children[i] = *ConvertVar(v.Pretty.Children[i])
+		}
+	}
+
 	return &r
 }
 
+// ConvertPrettyPrinter converts from api.PrettyPrinter to proc.PrettyPrinter.
+func ConvertPrettyPrinter(pp PrettyPrinter) (*proc.PrettyPrinter, error) {
+	re, err := regexp.Compile("^(?:" + pp.Type + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid type pattern %q: %v", pp.Type, err)
+	}
+	r := &proc.PrettyPrinter{Type: re, Value: pp.Value}
+	for _, child := range pp.Children {
+		r.Children = append(r.Children, proc.PrettyPrinterChild{Name: child.Name, Expr: child.Expr})
+	}
+	return r, nil
+}
+
 // ConvertFunction converts from gosym.Func to
 // api.Function.
 func ConvertFunction(fn *proc.Function) *Function {
@@ -310,9 +379,20 @@ func LoadConfigToProc(cfg *LoadConfig) *proc.LoadConfig {
 		MaxArrayValues:     cfg.MaxArrayValues,
 		MaxStructFields:    cfg.MaxStructFields,
 		MaxMapBuckets:      0, // MaxMapBuckets is set internally by pkg/proc, read its documentation for an explanation.
+		DisableFormatters:  cfg.DisableFormatters,
 	}
 }
 
+// ConvertMapCursor converts a proc.MapCursor to api.MapCursor.
+func ConvertMapCursor(c *proc.MapCursor) *MapCursor {
+	return (*MapCursor)(c)
+}
+
+// MapCursorToProc converts an api.MapCursor to proc.MapCursor.
+func MapCursorToProc(c *MapCursor) *proc.MapCursor {
+	return (*proc.MapCursor)(c)
+}
+
 // LoadConfigFromProc converts a proc.LoadConfig to api.LoadConfig.
 func LoadConfigFromProc(cfg *proc.LoadConfig) *LoadConfig {
 	if cfg == nil {
@@ -324,6 +404,7 @@ func LoadConfigFromProc(cfg *proc.LoadConfig) *LoadConfig {
 		MaxStringLen:       cfg.MaxStringLen,
 		MaxArrayValues:     cfg.MaxArrayValues,
 		MaxStructFields:    cfg.MaxStructFields,
+		DisableFormatters:  cfg.DisableFormatters,
 	}
 }
 
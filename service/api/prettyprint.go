@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -20,23 +21,46 @@ const (
 // SinglelineString returns a representation of v on a single line.
 func (v *Variable) SinglelineString() string {
 	var buf bytes.Buffer
-	v.writeTo(&buf, true, false, true, "")
+	v.writeTo(&buf, true, false, true, "", "")
 	return buf.String()
 }
 
 // MultilineString returns a representation of v on multiple lines.
 func (v *Variable) MultilineString(indent string) string {
 	var buf bytes.Buffer
-	v.writeTo(&buf, true, true, true, indent)
+	v.writeTo(&buf, true, true, true, indent, "")
 	return buf.String()
 }
 
-func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, indent string) {
+// SinglelineStringFormatted returns a representation of v on a single
+// line, with its integer, floating point and string values, and those of
+// its children, formatted using the fmt verb fmtstr.
+func (v *Variable) SinglelineStringFormatted(fmtstr string) string {
+	var buf bytes.Buffer
+	v.writeTo(&buf, true, false, true, "", fmtstr)
+	return buf.String()
+}
+
+// MultilineStringFormatted returns a representation of v on multiple
+// lines, with its integer, floating point and string values, and those of
+// its children, formatted using the fmt verb fmtstr.
+func (v *Variable) MultilineStringFormatted(indent, fmtstr string) string {
+	var buf bytes.Buffer
+	v.writeTo(&buf, true, true, true, indent, fmtstr)
+	return buf.String()
+}
+
+func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, indent, fmtstr string) {
 	if v.Unreadable != "" {
 		fmt.Fprintf(buf, "(unreadable %s)", v.Unreadable)
 		return
 	}
 
+	if v.Pretty != nil {
+		v.writePrettyTo(buf, newlines, includeType, indent, fmtstr)
+		return
+	}
+
 	if !top && v.Addr == 0 && v.Value == "" {
 		if includeType && v.Type != "void" {
 			fmt.Fprintf(buf, "%s nil", v.Type)
@@ -48,12 +72,15 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 
 	switch v.Kind {
 	case reflect.Slice:
-		v.writeSliceTo(buf, newlines, includeType, indent)
+		v.writeSliceTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Array:
-		v.writeArrayTo(buf, newlines, includeType, indent)
+		v.writeArrayTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Ptr:
 		if v.Type == "" || len(v.Children) == 0 {
 			fmt.Fprint(buf, "nil")
+		} else if v.Value != "" {
+			// C string
+			fmt.Fprintf(buf, "%#x %q", v.Children[0].Addr, v.Value)
 		} else if v.Children[0].OnlyAddr && v.Children[0].Addr != 0 {
 			if strings.Contains(v.Type, "/") {
 				fmt.Fprintf(buf, "(%q)(%#x)", v.Type, v.Children[0].Addr)
@@ -62,7 +89,7 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			}
 		} else {
 			fmt.Fprint(buf, "*")
-			v.Children[0].writeTo(buf, false, newlines, includeType, indent)
+			v.Children[0].writeTo(buf, false, newlines, includeType, indent, fmtstr)
 		}
 	case reflect.UnsafePointer:
 		if len(v.Children) == 0 {
@@ -71,10 +98,10 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			fmt.Fprintf(buf, "unsafe.Pointer(%#x)", v.Children[0].Addr)
 		}
 	case reflect.String:
-		v.writeStringTo(buf)
+		v.writeStringTo(buf, fmtstr)
 	case reflect.Chan:
 		if newlines {
-			v.writeStructTo(buf, newlines, includeType, indent)
+			v.writeStructTo(buf, newlines, includeType, indent, fmtstr)
 		} else {
 			if len(v.Children) == 0 {
 				fmt.Fprintf(buf, "%s nil", v.Type)
@@ -83,7 +110,7 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			}
 		}
 	case reflect.Struct:
-		v.writeStructTo(buf, newlines, includeType, indent)
+		v.writeStructTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Interface:
 		if v.Addr == 0 {
 			// an escaped interface variable that points to nil, this shouldn't
@@ -111,7 +138,7 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			} else if data.Children[0].OnlyAddr {
 				fmt.Fprintf(buf, "0x%x", v.Children[0].Addr)
 			} else {
-				v.Children[0].writeTo(buf, false, newlines, !includeType, indent)
+				v.Children[0].writeTo(buf, false, newlines, !includeType, indent, fmtstr)
 			}
 		} else if data.OnlyAddr {
 			if strings.Contains(v.Type, "/") {
@@ -120,10 +147,10 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 				fmt.Fprintf(buf, "*(*%s)(%#x)", v.Type, v.Addr)
 			}
 		} else {
-			v.Children[0].writeTo(buf, false, newlines, !includeType, indent)
+			v.Children[0].writeTo(buf, false, newlines, !includeType, indent, fmtstr)
 		}
 	case reflect.Map:
-		v.writeMapTo(buf, newlines, includeType, indent)
+		v.writeMapTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Func:
 		if v.Value == "" {
 			fmt.Fprint(buf, "nil")
@@ -131,25 +158,111 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			fmt.Fprintf(buf, "%s", v.Value)
 		}
 	case reflect.Complex64, reflect.Complex128:
-		fmt.Fprintf(buf, "(%s + %si)", v.Children[0].Value, v.Children[1].Value)
+		fmt.Fprintf(buf, "(%s + %si)", v.Children[0].formatValue(fmtstr), v.Children[1].formatValue(fmtstr))
 	default:
 		if v.Value != "" {
-			buf.Write([]byte(v.Value))
+			buf.Write([]byte(v.formatValue(fmtstr)))
 		} else {
 			fmt.Fprintf(buf, "(unknown %s)", v.Kind)
 		}
 	}
 }
 
-func (v *Variable) writeStringTo(buf io.Writer) {
+// writePrettyTo writes the representation of v produced by a
+// pretty-printer: its display string followed by its synthetic children.
+func (v *Variable) writePrettyTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
+	if includeType {
+		fmt.Fprintf(buf, "%s ", v.Type)
+	}
+	fmt.Fprint(buf, v.Pretty.Value)
+	if len(v.Pretty.Children) == 0 {
+		return
+	}
+	if v.Pretty.Value != "" {
+		fmt.Fprint(buf, " ")
+	}
+	children := Variable{Type: v.Type, Kind: reflect.Struct, Len: int64(len(v.Pretty.Children)), Children: v.Pretty.Children}
+	children.writeStructTo(buf, newlines, false, indent, fmtstr)
+}
+
+func (v *Variable) writeStringTo(buf io.Writer, fmtstr string) {
 	s := v.Value
+	if fmtstr != "" && strings.IndexByte(stringVerbs, fmtstr[len(fmtstr)-1]) >= 0 {
+		fmt.Fprintf(buf, fmtstr, s)
+		if len(s) != int(v.Len) {
+			fmt.Fprintf(buf, "...+%d more", int(v.Len)-len(s))
+		}
+		return
+	}
 	if len(s) != int(v.Len) {
 		s = fmt.Sprintf("%s...+%d more", s, int(v.Len)-len(s))
 	}
 	fmt.Fprintf(buf, "%q", s)
 }
 
-func (v *Variable) writeSliceTo(buf io.Writer, newlines, includeType bool, indent string) {
+const (
+	intVerbs    = "bcdoOqxXU"
+	floatVerbs  = "beEfFgGxX"
+	stringVerbs = "sqxX"
+)
+
+// formatValue returns the value of v formatted using the fmt verb fmtstr
+// if v is an integer or a floating point number and fmtstr applies to it.
+// Integers formatted with a floating point verb are converted to float64.
+// Otherwise the value of v is returned unchanged.
+func (v *Variable) formatValue(fmtstr string) string {
+	if fmtstr == "" {
+		return v.Value
+	}
+	verb := fmtstr[len(fmtstr)-1]
+	switch v.Kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(v.Value, 10, 64)
+		if err != nil {
+			break
+		}
+		if strings.IndexByte(intVerbs, verb) >= 0 {
+			return fmt.Sprintf(fmtstr, n)
+		}
+		if strings.IndexByte(floatVerbs, verb) >= 0 {
+			return fmt.Sprintf(fmtstr, float64(n))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(v.Value, 10, 64)
+		if err != nil {
+			break
+		}
+		if strings.IndexByte(intVerbs, verb) >= 0 {
+			return fmt.Sprintf(fmtstr, n)
+		}
+		if strings.IndexByte(floatVerbs, verb) >= 0 {
+			return fmt.Sprintf(fmtstr, float64(n))
+		}
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(v.Value, 64)
+		if err != nil {
+			break
+		}
+		if strings.IndexByte(floatVerbs, verb) >= 0 {
+			return fmt.Sprintf(fmtstr, f)
+		}
+	}
+	return v.Value
+}
+
+// ValidFormatVerb returns an error if fmtstr is not a fmt verb, with
+// optional flags, width and precision, that can be used to format the
+// values of a Variable.
+func ValidFormatVerb(fmtstr string) error {
+	if !formatVerbRe.MatchString(fmtstr) {
+		return fmt.Errorf("invalid format %q", fmtstr)
+	}
+	return nil
+}
+
+var formatVerbRe = regexp.MustCompile(`^%[-+# 0]*[0-9]*(\.[0-9]*)?[bcdoOqxXUeEfFgGs]$`)
+
+func (v *Variable) writeSliceTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if includeType {
 		fmt.Fprintf(buf, "%s len: %d, cap: %d, ", v.Type, v.Len, v.Cap)
 	}
@@ -157,17 +270,17 @@ func (v *Variable) writeSliceTo(buf io.Writer, newlines, includeType bool, inden
 		fmt.Fprintf(buf, "nil")
 		return
 	}
-	v.writeSliceOrArrayTo(buf, newlines, indent)
+	v.writeSliceOrArrayTo(buf, newlines, indent, fmtstr)
 }
 
-func (v *Variable) writeArrayTo(buf io.Writer, newlines, includeType bool, indent string) {
+func (v *Variable) writeArrayTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if includeType {
 		fmt.Fprintf(buf, "%s ", v.Type)
 	}
-	v.writeSliceOrArrayTo(buf, newlines, indent)
+	v.writeSliceOrArrayTo(buf, newlines, indent, fmtstr)
 }
 
-func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, indent string) {
+func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if int(v.Len) != len(v.Children) && len(v.Children) == 0 {
 		if strings.Contains(v.Type, "/") {
 			fmt.Fprintf(buf, "(*%q)(%#x)", v.Type, v.Addr)
@@ -190,7 +303,7 @@ func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, inde
 			fmt.Fprintf(buf, "\n%s%s", indent, indentString)
 		}
 		fmt.Fprintf(buf, "%s: ", v.Children[i].Name)
-		v.Children[i].writeTo(buf, false, nl, true, indent+indentString)
+		v.Children[i].writeTo(buf, false, nl, true, indent+indentString, fmtstr)
 		if i != len(v.Children)-1 || nl {
 			fmt.Fprint(buf, ",")
 			if !nl {
@@ -211,7 +324,7 @@ func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, inde
 	fmt.Fprint(buf, "}")
 }
 
-func (v *Variable) writeMapTo(buf io.Writer, newlines, includeType bool, indent string) {
+func (v *Variable) writeMapTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if includeType {
 		fmt.Fprintf(buf, "%s ", v.Type)
 	}
@@ -232,9 +345,9 @@ func (v *Variable) writeMapTo(buf io.Writer, newlines, includeType bool, indent
 			fmt.Fprintf(buf, "\n%s%s", indent, indentString)
 		}
 
-		key.writeTo(buf, false, false, false, indent+indentString)
+		key.writeTo(buf, false, false, false, indent+indentString, fmtstr)
 		fmt.Fprint(buf, ": ")
-		value.writeTo(buf, false, nl, false, indent+indentString)
+		value.writeTo(buf, false, nl, false, indent+indentString, fmtstr)
 		if i != len(v.Children)-1 || nl {
 			fmt.Fprint(buf, ", ")
 		}
@@ -323,7 +436,7 @@ func (v *Variable) shouldNewlineStruct(newlines bool) bool {
 	return false
 }
 
-func (v *Variable) writeSliceOrArrayTo(buf io.Writer, newlines bool, indent string) {
+func (v *Variable) writeSliceOrArrayTo(buf io.Writer, newlines bool, indent, fmtstr string) {
 	nl := v.shouldNewlineArray(newlines)
 	fmt.Fprint(buf, "[")
 
@@ -331,7 +444,7 @@ func (v *Variable) writeSliceOrArrayTo(buf io.Writer, newlines bool, indent stri
 		if nl {
 			fmt.Fprintf(buf, "\n%s%s", indent, indentString)
 		}
-		v.Children[i].writeTo(buf, false, nl, false, indent+indentString)
+		v.Children[i].writeTo(buf, false, nl, false, indent+indentString, fmtstr)
 		if i != len(v.Children)-1 || nl {
 			fmt.Fprint(buf, ",")
 		}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -32,3 +33,60 @@ func TestPrettyExamineMemory(t *testing.T) {
 		}
 	}
 }
+
+func TestPrettyValue(t *testing.T) {
+	v := &Variable{
+		Type: "main.Money",
+		Kind: reflect.Struct,
+		Len:  2,
+		Children: []Variable{
+			{Name: "units", Type: "int", Kind: reflect.Int, Value: "12"},
+			{Name: "cents", Type: "int", Kind: reflect.Int, Value: "34"},
+		},
+		Pretty: &PrettyValue{Value: "12.34 EUR"},
+	}
+	if s := v.SinglelineString(); s != "main.Money 12.34 EUR" {
+		t.Errorf("wrong representation %q", s)
+	}
+	v.Pretty.Children = []Variable{{Name: "cents", Type: "int", Kind: reflect.Int, Value: "1234"}}
+	if s := v.SinglelineString(); s != "main.Money 12.34 EUR {cents: 1234}" {
+		t.Errorf("wrong representation %q", s)
+	}
+}
+
+func TestFormattedValue(t *testing.T) {
+	v := &Variable{
+		Type: "main.T",
+		Kind: reflect.Struct,
+		Len:  4,
+		Children: []Variable{
+			{Name: "A", Type: "int", Kind: reflect.Int, Value: "255"},
+			{Name: "B", Type: "float64", Kind: reflect.Float64, Value: "1.5"},
+			{Name: "C", Type: "string", Kind: reflect.String, Value: "hi", Len: 2},
+			{Name: "D", Type: "[]uint8", Kind: reflect.Slice, Addr: 0x1000, Base: 0x2000, Len: 2, Cap: 2, Children: []Variable{
+				{Type: "uint8", Kind: reflect.Uint8, Value: "65"},
+				{Type: "uint8", Kind: reflect.Uint8, Value: "66"},
+			}},
+		},
+	}
+	for _, tc := range []struct {
+		fmtstr, tgt string
+	}{
+		{"", `main.T {A: 255, B: 1.5, C: "hi", D: []uint8 len: 2, cap: 2, [65,66]}`},
+		{"%x", `main.T {A: ff, B: 0x1.8p+00, C: 6869, D: []uint8 len: 2, cap: 2, [41,42]}`},
+		{"%#o", `main.T {A: 0377, B: 1.5, C: "hi", D: []uint8 len: 2, cap: 2, [0101,0102]}`},
+		{"%c", `main.T {A: ÿ, B: 1.5, C: "hi", D: []uint8 len: 2, cap: 2, [A,B]}`},
+		{"%08.3f", `main.T {A: 0255.000, B: 0001.500, C: "hi", D: []uint8 len: 2, cap: 2, [0065.000,0066.000]}`},
+	} {
+		if out := v.SinglelineStringFormatted(tc.fmtstr); out != tc.tgt {
+			t.Errorf("format %q:\ngot:\t%s\nexpected:\t%s", tc.fmtstr, out, tc.tgt)
+		}
+	}
+
+	for _, fmtstr := range []string{"%x", "%08.3f", "%-5d", "%#v", "x", "%"} {
+		err := ValidFormatVerb(fmtstr)
+		if valid := fmtstr == "%x" || fmtstr == "%08.3f" || fmtstr == "%-5d"; valid != (err == nil) {
+			t.Errorf("ValidFormatVerb(%q) = %v", fmtstr, err)
+		}
+	}
+}
@@ -40,10 +40,120 @@ type DebuggerState struct {
 	ExitStatus int  `json:"exitStatus"`
 	// When contains a description of the current position in a recording
 	When string
+	// StopReason is the reason why the target process is stopped.
+	StopReason StopReason `json:"stopReason,omitempty"`
+	// Syscall describes the system call that stopped the target process, if
+	// StopReason is StopSyscall.
+	Syscall *Syscall `json:"syscall,omitempty"`
+	// Signals lists the signals received by the target process, since it was
+	// last resumed, that have either the print or the stop policy.
+	Signals []Signal `json:"signals,omitempty"`
+	// ResolvedBreakpoints lists the pending breakpoints that were resolved,
+	// because a shared library or plugin was loaded, since the target
+	// process was last resumed.
+	ResolvedBreakpoints []*Breakpoint `json:"resolvedBreakpoints,omitempty"`
 	// Filled by RPCClient.Continue, indicates an error
 	Err error `json:"-"`
 }
 
+// StepInTarget is a function call made by the current line that can be
+// stepped into.
+type StepInTarget struct {
+	// CallPC is the address of the call instruction.
+	CallPC uint64 `json:"callPC"`
+	// Function is the function called.
+	Function *Function `json:"function,omitempty"`
+}
+
+// StopReason describes the reason why the target process is stopped.
+type StopReason uint8
+
+const (
+	StopUnknown             StopReason = iota
+	StopLaunched                       // The process was just launched
+	StopAttached                       // The debugger stopped the process after attaching
+	StopExited                         // The target process terminated
+	StopBreakpoint                     // The target process hit one or more software breakpoints
+	StopHardcodedBreakpoint            // The target process hit a hardcoded breakpoint (for example runtime.Breakpoint())
+	StopManual                         // A manual stop was requested
+	StopNextFinished                   // The next/step/stepout command terminated
+	StopCallReturned                   // An injected call completed
+	StopSyscall                        // A thread entered or exited a system call selected by a syscall catchpoint
+	StopSignal                         // A thread received a signal with the Stop policy
+)
+
+// Syscall describes a thread stopped at the entry or exit of a system call
+// by a syscall catchpoint.
+type Syscall struct {
+	// ThreadID is the ID of the thread stopped at the system call.
+	ThreadID int `json:"threadID"`
+	// Num is the system call number.
+	Num uint64 `json:"num"`
+	// Name is the name of the system call, empty if unknown.
+	Name string `json:"name"`
+	// Args are the arguments of the system call.
+	Args [6]uint64 `json:"args"`
+	// Exit is true if the thread is stopped at the exit of the system call.
+	Exit bool `json:"exit"`
+	// Ret is the return value of the system call, only valid if Exit is true.
+	Ret int64 `json:"ret"`
+}
+
+// Signal describes a signal received by a thread of the target process.
+type Signal struct {
+	ThreadID int    `json:"threadID"`
+	Signal   int    `json:"signal"`
+	Name     string `json:"name"`
+}
+
+// SignalPolicy describes how the debugger handles a signal received by the
+// target process.
+type SignalPolicy struct {
+	// Signal is the name or number of the signal.
+	Signal string `json:"signal"`
+	// Stop is true if the target process is stopped when it receives the signal.
+	Stop bool `json:"stop"`
+	// Print is true if receiving the signal is reported to the client.
+	Print bool `json:"print"`
+	// Pass is true if the signal is delivered to the target process.
+	Pass bool `json:"pass"`
+}
+
+// ParseSignalPolicy updates policy with keywords, valid keywords are stop,
+// nostop, print, noprint, pass and nopass. Stop implies print and noprint
+// implies nostop.
+func ParseSignalPolicy(policy SignalPolicy, keywords []string) (SignalPolicy, error) {
+	for _, kw := range keywords {
+		switch kw {
+		case "stop":
+			policy.Stop, policy.Print = true, true
+		case "nostop":
+			policy.Stop = false
+		case "print":
+			policy.Print = true
+		case "noprint":
+			policy.Stop, policy.Print = false, false
+		case "pass":
+			policy.Pass = true
+		case "nopass":
+			policy.Pass = false
+		default:
+			return policy, fmt.Errorf("unknown signal handling keyword %q", kw)
+		}
+	}
+	return policy, nil
+}
+
+func (policy SignalPolicy) String() string {
+	kw := func(b bool, s string) string {
+		if b {
+			return s
+		}
+		return "no" + s
+	}
+	return kw(policy.Stop, "stop") + " " + kw(policy.Print, "print") + " " + kw(policy.Pass, "pass")
+}
+
 // Breakpoint addresses a set of locations at which process execution may be
 // suspended.
 type Breakpoint struct {
@@ -62,10 +172,25 @@ type Breakpoint struct {
 	// FunctionName is the name of the function at the current breakpoint, and
 	// may not always be available.
 	FunctionName string `json:"functionName,omitempty"`
+	// LocExpr is the location expression (see Documentation/cli/locspec.md)
+	// used to create the breakpoint, only pending breakpoints use it.
+	LocExpr string `json:"locExpr,omitempty"`
+	// Pending is true if the location of the breakpoint could not be found in
+	// the code currently loaded by the target process. The location will be
+	// searched again every time a shared library or plugin is loaded.
+	Pending bool `json:"pending,omitempty"`
 
 	// Breakpoint condition
 	Cond string
 
+	// Panic signifies that this is a panic breakpoint, triggered every time
+	// runtime.gopanic is called.
+	Panic bool `json:"panic,omitempty"`
+	// PanicFilter is a regular expression, if it isn't empty a panic
+	// breakpoint will only be triggered when the dynamic type or the error
+	// message of the panic argument matches it.
+	PanicFilter string `json:"panicFilter,omitempty"`
+
 	// Tracepoint flag, signifying this is a tracepoint.
 	Tracepoint bool `json:"continue"`
 	// TraceReturn flag signifying this is a breakpoint set at a return
@@ -156,6 +281,11 @@ type Stackframe struct {
 
 	Bottom bool `json:"Bottom,omitempty"` // Bottom is true if this is the bottom frame of the stack
 
+	// UnwoundByFramePointer is true if this frame was found by following the
+	// frame pointer chain because the frame descriptor entry of the previous
+	// frame was missing or wrong, the frame could be incorrect.
+	UnwoundByFramePointer bool `json:"UnwoundByFramePointer,omitempty"`
+
 	Err string
 }
 
@@ -233,6 +363,9 @@ const (
 	// the variable is the return value of a function call and allocated on a
 	// frame that no longer exists)
 	VariableFakeAddress
+	// VariableFormatted means Pretty contains a representation of the
+	// variable produced by a pretty-printer or by a builtin formatter
+	VariableFormatted
 )
 
 // Variable describes a variable.
@@ -281,6 +414,86 @@ type Variable struct {
 	LocationExpr string
 	// DeclLine is the line number of this variable's declaration
 	DeclLine int64
+
+	// Pretty is the representation of this variable produced by a
+	// pretty-printer or by the builtin formatter for its type, nil if
+	// there is neither.
+	// The other fields always describe the raw value of the variable.
+	Pretty *PrettyValue `json:"pretty,omitempty"`
+}
+
+// PrettyValue is the representation of a variable produced by a
+// pretty-printer.
+type PrettyValue struct {
+	// Printer is the type pattern of the pretty-printer, empty for values
+	// produced by a builtin formatter.
+	Printer string `json:"printer"`
+	// Value is the display string of the variable.
+	Value string `json:"value"`
+	// Children are the synthetic children of the variable.
+	Children []Variable `json:"children"`
+}
+
+// PrettyPrinter describes how variables whose type name matches the
+// regular expression Type are displayed.
+// The expressions used by a pretty-printer are evaluated with the variable
+// being displayed bound to x, they can use the collection queries and
+// builtins of the expression evaluator but can not call functions.
+type PrettyPrinter struct {
+	// Type is a regular expression matched against the whole type name.
+	Type string `json:"type"`
+	// Value is the display string, each expression enclosed in curly
+	// braces is replaced by its value, for example "{x.units}.{x.cents}".
+	Value string `json:"value"`
+	// Children are the synthetic children of the variable.
+	Children []PrettyPrinterChild `json:"children,omitempty"`
+}
+
+// PrettyPrinterChild is a synthetic child of a variable displayed by a
+// pretty-printer.
+type PrettyPrinterChild struct {
+	Name string `json:"name"`
+	// Expr is the expression producing the value of the child.
+	Expr string `json:"expr"`
+}
+
+// DiffKind is the kind of a difference between two values.
+type DiffKind uint8
+
+const (
+	// DiffChanged means that the value at the path is different.
+	DiffChanged = DiffKind(proc.DiffChanged)
+	// DiffAdded means that the element at the path only exists in the new
+	// value.
+	DiffAdded = DiffKind(proc.DiffAdded)
+	// DiffRemoved means that the element at the path only exists in the old
+	// value.
+	DiffRemoved = DiffKind(proc.DiffRemoved)
+)
+
+// VariableDiff is a difference between two values.
+type VariableDiff struct {
+	// Path is the path from the root of the compared values to the differing
+	// element, for example `.Items[3].Name` or `["key"]`, it is empty if
+	// the root values are different.
+	Path string   `json:"path"`
+	Kind DiffKind `json:"kind"`
+	// Old is the element of the old value, nil if Kind is DiffAdded.
+	Old *Variable `json:"old,omitempty"`
+	// New is the element of the new value, nil if Kind is DiffRemoved.
+	New *Variable `json:"new,omitempty"`
+}
+
+// MapCursor is a position in the iteration order of a map, returned when
+// a page of the entries of a map is loaded, see RPCServer.EvalRange.
+// Clients should not interpret its fields, only pass it back to load the
+// next page.
+type MapCursor struct {
+	Index   int64  `json:"index"`
+	Buckets uint64 `json:"buckets"`
+	Bucket  uint64 `json:"bucket"`
+	Cur     uint64 `json:"cur"`
+	Slot    int64  `json:"slot"`
 }
 
 // LoadConfig describes how to load values from target's memory
@@ -295,6 +508,9 @@ type LoadConfig struct {
 	MaxArrayValues int
 	// MaxStructFields is the maximum number of fields read from a struct, -1 will read all fields.
 	MaxStructFields int
+	// DisableFormatters disables pretty-printers and builtin formatters,
+	// variables are returned without their Pretty representation.
+	DisableFormatters bool
 }
 
 // Goroutine represents the information relevant to Delve from the runtime's
@@ -381,6 +597,10 @@ const (
 	StepOut = "stepOut"
 	// ReverseStepOut continues backward to the calle rof the current function.
 	ReverseStepOut = "reverseStepOut"
+	// StepInto continues until the current goroutine enters the function
+	// called by the current line specified by Expr, either the name of the
+	// function or the address of the call instruction (as *<address>).
+	StepInto = "stepInto"
 	// StepInstruction continues for exactly 1 cpu instruction.
 	StepInstruction = "stepInstruction"
 	// ReverseStepInstruction reverses execution for exactly 1 cpu instruction.
@@ -389,6 +609,14 @@ const (
 	Next = "next"
 	// ReverseNext continues backward to the previous line of source code, not entering function calls.
 	ReverseNext = "reverseNext"
+	// Until continues until a location in the current frame is reached or the current frame returns.
+	Until = "until"
+	// ReverseUntil is like Until but executes backward.
+	ReverseUntil = "reverseUntil"
+	// Advance continues until the current goroutine reaches a location.
+	Advance = "advance"
+	// ReverseAdvance continues backward until the current goroutine reaches a location.
+	ReverseAdvance = "reverseAdvance"
 	// SwitchThread switches the debugger's current thread context.
 	SwitchThread = "switchThread"
 	// SwitchGoroutine switches the debugger's current thread context to the thread running the specified goroutine
@@ -0,0 +1,27 @@
+package api
+
+import "testing"
+
+func TestParseSignalPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		keywords []string
+		tgt      string
+	}{
+		{nil, "nostop noprint pass"},
+		{[]string{"stop"}, "stop print pass"},
+		{[]string{"stop", "nopass"}, "stop print nopass"},
+		{[]string{"stop", "noprint"}, "nostop noprint pass"},
+		{[]string{"print"}, "nostop print pass"},
+	} {
+		policy, err := ParseSignalPolicy(SignalPolicy{Pass: true}, tc.keywords)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.keywords, err)
+		}
+		if out := policy.String(); out != tc.tgt {
+			t.Errorf("%v: got %q expected %q", tc.keywords, out, tc.tgt)
+		}
+	}
+	if _, err := ParseSignalPolicy(SignalPolicy{}, []string{"ignore"}); err == nil {
+		t.Errorf("expected error for unknown keyword")
+	}
+}
@@ -46,6 +46,18 @@ type Client interface {
 	StepOut() (*api.DebuggerState, error)
 	// ReverseStepOut continues backward to the calle rof the current function.
 	ReverseStepOut() (*api.DebuggerState, error)
+	// StepInto continues until the function called by the current line, specified by name or by the address of the call instruction, is entered.
+	StepInto(target string) (*api.DebuggerState, error)
+	// ListStepInTargets returns the function calls made by the current line that can be stepped into.
+	ListStepInTargets() ([]api.StepInTarget, error)
+	// Until continues until the location is reached in the current frame or the current frame returns.
+	Until(loc string) (*api.DebuggerState, error)
+	// ReverseUntil is like Until but executes backward.
+	ReverseUntil(loc string) (*api.DebuggerState, error)
+	// Advance continues until the current goroutine reaches the location.
+	Advance(loc string) (*api.DebuggerState, error)
+	// ReverseAdvance continues backward until the current goroutine reaches the location.
+	ReverseAdvance(loc string) (*api.DebuggerState, error)
 	// Call resumes process execution while making a function call.
 	Call(goroutineID int, expr string, unsafe bool) (*api.DebuggerState, error)
 
@@ -87,6 +99,18 @@ type Client interface {
 	ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error)
 	// EvalVariable returns a variable in the context of the current thread.
 	EvalVariable(scope api.EvalScope, symbol string, cfg api.LoadConfig) (*api.Variable, error)
+	// EvalVariableRange returns count elements, starting at start, of the
+	// slice, array, string or map represented by symbol. For maps cursor
+	// is the cursor returned by the call that loaded the previous page, if
+	// any, and the returned cursor is the one to use for the next page.
+	EvalVariableRange(scope api.EvalScope, symbol string, start, count int, cursor *api.MapCursor, cfg api.LoadConfig) (*api.Variable, *api.MapCursor, error)
+	// DiffVariables returns the differences between the values of expr1
+	// and expr2.
+	DiffVariables(scope api.EvalScope, expr1, expr2 string, cfg api.LoadConfig) ([]api.VariableDiff, error)
+	// DiffVariableSinceLastStop returns the differences between the value
+	// of expr and the value it had at the previous stop, the first time it
+	// is called for expr it only records its value and returns true.
+	DiffVariableSinceLastStop(scope api.EvalScope, expr string, cfg api.LoadConfig) ([]api.VariableDiff, bool, error)
 
 	// SetVariable sets the value of a variable
 	SetVariable(scope api.EvalScope, symbol, value string) error
@@ -148,6 +172,24 @@ type Client interface {
 	// ClearCheckpoint removes a checkpoint
 	ClearCheckpoint(id int) error
 
+	// SetSyscallCatchpoint stops the target when it enters or exits one of the specified system calls.
+	SetSyscallCatchpoint(syscalls []string) error
+	// ClearSyscallCatchpoint removes the syscall catchpoint.
+	ClearSyscallCatchpoint() error
+
+	// SetSignalPolicy changes how the debugger handles a signal received by the target.
+	SetSignalPolicy(policy api.SignalPolicy) error
+	// ListSignalPolicies returns the policies of the specified signals.
+	// If no signal is specified it returns all policies that were changed from the default.
+	ListSignalPolicies(signals []string) ([]api.SignalPolicy, error)
+
+	// SetPrettyPrinter registers a pretty-printer, replacing the one with the same type pattern.
+	SetPrettyPrinter(pp api.PrettyPrinter) error
+	// ClearPrettyPrinter removes the pretty-printer with the specified type pattern.
+	ClearPrettyPrinter(typ string) error
+	// ListPrettyPrinters returns the registered pretty-printers.
+	ListPrettyPrinters() ([]api.PrettyPrinter, error)
+
 	// SetReturnValuesLoadConfig sets the load configuration for return values.
 	SetReturnValuesLoadConfig(*api.LoadConfig)
 
@@ -187,6 +187,21 @@ func (c *Client) ExpectTerminateThreadsResponse(t *testing.T) *dap.TerminateThre
 	return c.expectReadProtocolMessage(t).(*dap.TerminateThreadsResponse)
 }
 
+func (c *Client) ExpectEvaluateResponse(t *testing.T) *dap.EvaluateResponse {
+	t.Helper()
+	return c.expectReadProtocolMessage(t).(*dap.EvaluateResponse)
+}
+
+func (c *Client) ExpectVariablesResponse(t *testing.T) *dap.VariablesResponse {
+	t.Helper()
+	return c.expectReadProtocolMessage(t).(*dap.VariablesResponse)
+}
+
+func (c *Client) ExpectStepInResponse(t *testing.T) *dap.StepInResponse {
+	t.Helper()
+	return c.expectReadProtocolMessage(t).(*dap.StepInResponse)
+}
+
 func (c *Client) ExpectStepInTargetsResponse(t *testing.T) *dap.StepInTargetsResponse {
 	t.Helper()
 	return c.expectReadProtocolMessage(t).(*dap.StepInTargetsResponse)
@@ -320,6 +335,17 @@ func (c *Client) SetExceptionBreakpointsRequest() {
 	c.send(request)
 }
 
+// SetExceptionBreakpointsRequestWithArgs sends a 'setExceptionBreakpoints'
+// request with the given filters and exception options.
+func (c *Client) SetExceptionBreakpointsRequestWithArgs(filters []string, opts []dap.ExceptionOptions) {
+	request := &dap.SetExceptionBreakpointsRequest{Request: *c.newRequest("setExceptionBreakpoints")}
+	request.Arguments = dap.SetExceptionBreakpointsArguments{
+		Filters:          filters,
+		ExceptionOptions: opts,
+	}
+	c.send(request)
+}
+
 // ConfigurationDoneRequest sends a 'configurationDone' request.
 func (c *Client) ConfigurationDoneRequest() {
 	request := &dap.ConfigurationDoneRequest{Request: *c.newRequest("configurationDone")}
@@ -347,6 +373,14 @@ func (c *Client) StepInRequest() {
 	c.send(request)
 }
 
+// StepInTargetRequest sends a 'stepIn' request into the given target.
+func (c *Client) StepInTargetRequest(threadID, targetID int) {
+	request := &dap.StepInRequest{Request: *c.newRequest("stepIn")}
+	request.Arguments.ThreadId = threadID
+	request.Arguments.TargetId = targetID
+	c.send(request)
+}
+
 // StepOutRequest sends a 'stepOut' request.
 func (c *Client) StepOutRequest() {
 	request := &dap.NextRequest{Request: *c.newRequest("stepOut")}
@@ -379,9 +413,14 @@ func (c *Client) ScopesRequest() {
 	c.send(request)
 }
 
-// VariablesRequest sends a 'scopes' request.
-func (c *Client) VariablesRequest() {
-	request := &dap.ScopesRequest{Request: *c.newRequest("variables")}
+// VariablesRequest sends a 'variables' request for count children of the
+// variable identified by ref, starting at start. A count of 0 requests
+// all children.
+func (c *Client) VariablesRequest(ref, start, count int) {
+	request := &dap.VariablesRequest{Request: *c.newRequest("variables")}
+	request.Arguments.VariablesReference = ref
+	request.Arguments.Start = start
+	request.Arguments.Count = count
 	c.send(request)
 }
 
@@ -441,8 +480,11 @@ func (c *Client) TerminateThreadsRequest() {
 }
 
 // EvaluateRequest sends a 'evaluate' request.
-func (c *Client) EvaluateRequest() {
-	c.send(&dap.EvaluateRequest{Request: *c.newRequest("evaluate")})
+func (c *Client) EvaluateRequest(expr string, hex bool) {
+	request := &dap.EvaluateRequest{Request: *c.newRequest("evaluate")}
+	request.Arguments.Expression = expr
+	request.Arguments.Format.Hex = hex
+	c.send(request)
 }
 
 // StepInTargetsRequest sends a 'stepInTargets' request.
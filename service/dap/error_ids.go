@@ -12,7 +12,13 @@ const (
 	// TODO(polina): confirm if the extension expects specific ids
 	// for specific cases, and we must match the existing adaptor
 	// or if these codes can evolve.
-	FailedToContinue       = 3000
-	UnableToDisplayThreads = 2003
+	FailedToContinue           = 3000
+	UnableToSetBreakpoints     = 2002
+	UnableToDisplayThreads     = 2003
+	UnableToLookupVariable     = 2008
+	UnableToEvaluateExpression = 2009
+	// The values below are not used by the vscode-go debug adaptor.
+	UnableToStep              = 2100
+	UnableToListStepInTargets = 2101
 	// Add more codes as we support more requests
 )
@@ -0,0 +1,50 @@
+package dap
+
+import "github.com/go-delve/delve/service/api"
+
+// startHandle is the first variablesReference value handed out to the
+// client, 0 means that a variable has no children.
+const startHandle = 1000
+
+// variableHandle records how to load the children of a variable that was
+// sent to the client.
+type variableHandle struct {
+	// scope is the scope the expression was evaluated in.
+	scope api.EvalScope
+	// expr evaluates to the variable.
+	expr string
+	// cursors are the positions, in the iteration order of a map, where
+	// the pages of its entries start, indexed by the start of the page.
+	cursors map[int]*api.MapCursor
+}
+
+// variablesHandles maps the variablesReference values sent to the client
+// to the variables they stand for. References are only valid while the
+// target is stopped, reset must be called every time it resumes.
+type variablesHandles struct {
+	next    int
+	handles map[int]variableHandle
+}
+
+func newVariablesHandles() *variablesHandles {
+	return &variablesHandles{next: startHandle, handles: make(map[int]variableHandle)}
+}
+
+// create returns a new reference to the variable expr evaluates to.
+func (hs *variablesHandles) create(scope api.EvalScope, expr string) int {
+	hs.next++
+	hs.handles[hs.next] = variableHandle{scope: scope, expr: expr, cursors: make(map[int]*api.MapCursor)}
+	return hs.next
+}
+
+// get returns the variable that ref stands for.
+func (hs *variablesHandles) get(ref int) (variableHandle, bool) {
+	h, ok := hs.handles[ref]
+	return h, ok
+}
+
+// reset invalidates all references.
+func (hs *variablesHandles) reset() {
+	hs.next = startHandle
+	hs.handles = make(map[int]variableHandle)
+}
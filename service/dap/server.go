@@ -16,6 +16,9 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/logflags"
@@ -56,8 +59,31 @@ type Server struct {
 	stopOnEntry bool
 	// binaryToRemove is the compiled binary to be removed on disconnect.
 	binaryToRemove string
+	// panicBreakpoint is the breakpoint used for the "panic" exception
+	// filter, nil if the filter is not enabled.
+	panicBreakpoint *api.Breakpoint
+	// userPanicBreakpoint is the panic breakpoint that existed before the
+	// "panic" exception filter was enabled (for example one set with
+	// 'break -panic'). Since there can only be one breakpoint on
+	// runtime.gopanic panicBreakpoint extends it, and it is restored when
+	// the filter is disabled.
+	userPanicBreakpoint *api.Breakpoint
+	// stepInTargets are the targets returned by the last StepInTargets
+	// request, a StepIn request's targetId is an index into it, plus one.
+	stepInTargets []api.StepInTarget
+	// variableHandles maps the variablesReference values sent to the client
+	// to the variables they refer to.
+	variableHandles *variablesHandles
 }
 
+// panicExceptionFilter is the exception filter that stops on every panic,
+// recovered or not.
+const panicExceptionFilter = "panic"
+
+// evaluateLoadConfig is the configuration used to load the result of
+// evaluate requests.
+var evaluateLoadConfig = proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+
 // NewServer creates a new DAP Server. It takes an opened Listener
 // via config and assumes its ownership. config.disconnectChan has to be set;
 // it will be closed by the server when the client disconnects or requests
@@ -67,10 +93,11 @@ func NewServer(config *service.Config) *Server {
 	logflags.WriteDAPListeningMessage(config.Listener.Addr().String())
 	logger.Debug("DAP server pid = ", os.Getpid())
 	return &Server{
-		config:   config,
-		listener: config.Listener,
-		stopChan: make(chan struct{}),
-		log:      logger,
+		config:          config,
+		listener:        config.Listener,
+		stopChan:        make(chan struct{}),
+		log:             logger,
+		variableHandles: newVariablesHandles(),
 	}
 }
 
@@ -237,7 +264,6 @@ func (s *Server) handleRequest(request dap.Message) {
 		s.onNextRequest(request)
 	case *dap.StepInRequest:
 		// Required
-		// TODO: implement this request in V0
 		s.onStepInRequest(request)
 	case *dap.StepOutRequest:
 		// Required
@@ -299,7 +325,7 @@ func (s *Server) handleRequest(request dap.Message) {
 		s.onEvaluateRequest(request)
 	case *dap.StepInTargetsRequest:
 		// Optional (capability ‘supportsStepInTargetsRequest’)
-		s.sendUnsupportedErrorResponse(request.Request)
+		s.onStepInTargetsRequest(request)
 	case *dap.GotoTargetsRequest:
 		// Optional (capability ‘supportsGotoTargetsRequest’)
 		s.sendUnsupportedErrorResponse(request.Request)
@@ -357,6 +383,15 @@ func (s *Server) onInitializeRequest(request *dap.InitializeRequest) {
 	// TODO(polina): Respond with an error if debug session is in progress?
 	response := &dap.InitializeResponse{Response: *newResponse(request.Request)}
 	response.Body.SupportsConfigurationDoneRequest = true
+	response.Body.ExceptionBreakpointFilters = []dap.ExceptionBreakpointsFilter{
+		{Filter: panicExceptionFilter, Label: "All Panics"},
+	}
+	// The names in the path of exception options are used as regular
+	// expressions matched against the type or message of the panic argument.
+	response.Body.SupportsExceptionOptions = true
+	response.Body.SupportsStepInTargetsRequest = true
+	// The hex flag of the format argument of evaluate requests is supported.
+	response.Body.SupportsValueFormattingOptions = true
 	// TODO(polina): support this to match vscode-go functionality
 	response.Body.SupportsSetVariable = false
 	// TODO(polina): support these requests in addition to vscode-go feature parity
@@ -526,11 +561,92 @@ func (s *Server) onSetBreakpointsRequest(request *dap.SetBreakpointsRequest) {
 }
 
 func (s *Server) onSetExceptionBreakpointsRequest(request *dap.SetExceptionBreakpointsRequest) {
-	// Unlike what DAP documentation claims, this request is always sent
-	// even though we specified no filters at initialization. Handle as no-op.
+	if s.debugger == nil {
+		s.send(&dap.SetExceptionBreakpointsResponse{Response: *newResponse(request.Request)})
+		return
+	}
+	if s.panicBreakpoint != nil {
+		var err error
+		if s.userPanicBreakpoint != nil {
+			err = s.debugger.AmendBreakpoint(s.userPanicBreakpoint)
+		} else {
+			_, err = s.debugger.ClearBreakpoint(s.panicBreakpoint)
+		}
+		if err != nil {
+			s.log.Error("ERROR:", err)
+		}
+		s.panicBreakpoint = nil
+		s.userPanicBreakpoint = nil
+	}
+	for _, filter := range request.Arguments.Filters {
+		if filter != panicExceptionFilter || s.panicBreakpoint != nil {
+			continue
+		}
+		panicFilter := exceptionOptionsToPanicFilter(request.Arguments.ExceptionOptions)
+		var err error
+		if bp := s.findPanicBreakpoint(); bp != nil {
+			// There can only be one breakpoint on runtime.gopanic, the one
+			// set by the user is extended to also stop on the panics
+			// matching the filter.
+			amended := *bp
+			amended.PanicFilter = joinPanicFilters(bp.PanicFilter, panicFilter)
+			err = s.debugger.AmendBreakpoint(&amended)
+			if err == nil {
+				s.panicBreakpoint = &amended
+				s.userPanicBreakpoint = bp
+			}
+		} else {
+			s.panicBreakpoint, err = s.debugger.CreateBreakpoint(&api.Breakpoint{
+				Panic:       true,
+				PanicFilter: panicFilter,
+			})
+		}
+		if err != nil {
+			s.panicBreakpoint = nil
+			s.sendErrorResponse(request.Request, UnableToSetBreakpoints, "Unable to set exception breakpoints", err.Error())
+			return
+		}
+	}
 	s.send(&dap.SetExceptionBreakpointsResponse{Response: *newResponse(request.Request)})
 }
 
+// findPanicBreakpoint returns the panic breakpoint, if one is set.
+func (s *Server) findPanicBreakpoint() *api.Breakpoint {
+	for _, bp := range s.debugger.Breakpoints() {
+		if bp.Panic {
+			return bp
+		}
+	}
+	return nil
+}
+
+// exceptionOptionsToPanicFilter converts the names in the paths of opts
+// into a regular expression matching any of them.
+func exceptionOptionsToPanicFilter(opts []dap.ExceptionOptions) string {
+	var names []string
+	for _, opt := range opts {
+		if opt.BreakMode == "never" {
+			continue
+		}
+		for _, segment := range opt.Path {
+			if segment.Negate {
+				continue
+			}
+			names = append(names, segment.Names...)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// joinPanicFilters returns a panic filter matching the panics matched by
+// either a or b, an empty filter matches all panics.
+func joinPanicFilters(a, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	return "(?:" + a + ")|(?:" + b + ")"
+}
+
 func (s *Server) onConfigurationDoneRequest(request *dap.ConfigurationDoneRequest) {
 	if s.stopOnEntry {
 		e := &dap.StoppedEvent{
@@ -605,10 +721,55 @@ func (s *Server) onNextRequest(request *dap.NextRequest) { // TODO V0
 	s.sendNotYetImplementedErrorResponse(request.Request)
 }
 
-// onStepInRequest sends a not-yet-implemented error response.
+// onStepInRequest steps into the call returned by the last StepInTargets
+// request with id request.Arguments.TargetId. Stepping without a target
+// sends a not-yet-implemented error response.
 // This is a mandatory request to support.
 func (s *Server) onStepInRequest(request *dap.StepInRequest) { // TODO V0
-	s.sendNotYetImplementedErrorResponse(request.Request)
+	id := request.Arguments.TargetId
+	if id == 0 {
+		s.sendNotYetImplementedErrorResponse(request.Request)
+		return
+	}
+	if s.debugger == nil {
+		s.sendErrorResponse(request.Request, UnableToStep, "Unable to step", "debugger is nil")
+		return
+	}
+	if id < 0 || id > len(s.stepInTargets) {
+		s.sendErrorResponse(request.Request, UnableToStep, "Unable to step", fmt.Sprintf("unknown step in target %d", id))
+		return
+	}
+	s.send(&dap.StepInResponse{Response: *newResponse(request.Request)})
+	s.doCommand(&api.DebuggerCommand{Name: api.StepInto, Expr: fmt.Sprintf("*%#x", s.stepInTargets[id-1].CallPC)}, "step")
+}
+
+// onStepInTargetsRequest lists the function calls on the current line of
+// the selected goroutine that a subsequent StepIn request can target.
+// Only the topmost stack frame is supported.
+func (s *Server) onStepInTargetsRequest(request *dap.StepInTargetsRequest) {
+	if s.debugger == nil {
+		s.sendErrorResponse(request.Request, UnableToListStepInTargets, "Unable to list step in targets", "debugger is nil")
+		return
+	}
+	targets, err := s.debugger.StepInTargets()
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToListStepInTargets, "Unable to list step in targets", err.Error())
+		return
+	}
+	s.stepInTargets = targets
+	dapTargets := make([]dap.StepInTarget, len(targets))
+	for i, target := range targets {
+		label := fmt.Sprintf("%#x", target.CallPC)
+		if target.Function != nil {
+			label = target.Function.Name()
+		}
+		dapTargets[i] = dap.StepInTarget{Id: i + 1, Label: label}
+	}
+	response := &dap.StepInTargetsResponse{
+		Response: *newResponse(request.Request),
+		Body:     dap.StepInTargetsResponseBody{Targets: dapTargets},
+	}
+	s.send(response)
 }
 
 // onStepOutRequest sends a not-yet-implemented error response.
@@ -635,16 +796,179 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) { // TODO V0
 	s.sendNotYetImplementedErrorResponse(request.Request)
 }
 
-// onVariablesRequest sends a not-yet-implemented error response.
-// This is a mandatory request to support.
-func (s *Server) onVariablesRequest(request *dap.VariablesRequest) { // TODO V0
-	s.sendNotYetImplementedErrorResponse(request.Request)
+// onVariablesRequest returns the children of a variable previously sent
+// to the client. The elements of slices, arrays and maps are reported as
+// indexed variables, the client can request them one page at a time using
+// the start and count arguments, only the requested page is loaded from
+// the target.
+func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
+	args := request.Arguments
+	h, ok := s.variableHandles.get(args.VariablesReference)
+	if !ok {
+		s.sendErrorResponse(request.Request, UnableToLookupVariable, "Unable to lookup variable", fmt.Sprintf("unknown reference %d", args.VariablesReference))
+		return
+	}
+	if s.debugger == nil {
+		s.sendErrorResponse(request.Request, UnableToLookupVariable, "Unable to lookup variable", "debugger is nil")
+		return
+	}
+	var v *api.Variable
+	var err error
+	if args.Start > 0 || args.Count > 0 {
+		count := args.Count
+		if count <= 0 {
+			count = evaluateLoadConfig.MaxArrayValues
+		}
+		cfg := evaluateLoadConfig
+		cfg.MaxArrayValues = count
+		var next *api.MapCursor
+		v, next, err = s.debugger.EvalVariableRangeInScope(h.scope, h.expr, args.Start, count, h.cursors[args.Start], cfg)
+		if next != nil {
+			// the next page of map entries starts after the loaded ones
+			h.cursors[args.Start+len(v.Children)/2] = next
+		}
+	} else {
+		v, err = s.debugger.EvalVariableInScope(h.scope, h.expr, evaluateLoadConfig)
+	}
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToLookupVariable, "Unable to lookup variable", err.Error())
+		return
+	}
+	if args.Format.Hex {
+		hexIntegers(v)
+	}
+	if v.Pretty != nil && len(v.Pretty.Children) > 0 {
+		// the synthetic children produced by a pretty-printer are shown
+		// instead of the raw ones
+		v = &api.Variable{Kind: reflect.Struct, Children: v.Pretty.Children}
+	}
+	children := []dap.Variable{}
+	switch v.Kind {
+	case reflect.Slice, reflect.Array:
+		if args.Filter == "named" {
+			break
+		}
+		for i := range v.Children {
+			children = append(children, s.convertVariable(h.scope, &v.Children[i], fmt.Sprintf("[%d]", args.Start+i)))
+		}
+	case reflect.Map:
+		if args.Filter == "named" {
+			break
+		}
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			key, val := &v.Children[i], &v.Children[i+1]
+			children = append(children, s.convertVariable(h.scope, val, key.SinglelineString()))
+		}
+	case reflect.Ptr:
+		if len(v.Children) == 1 {
+			children = append(children, s.convertVariable(h.scope, &v.Children[0], "*"))
+		}
+	case reflect.Interface:
+		if len(v.Children) == 1 {
+			children = append(children, s.convertVariable(h.scope, &v.Children[0], "data"))
+		}
+	default:
+		if args.Filter == "indexed" {
+			break
+		}
+		for i := range v.Children {
+			children = append(children, s.convertVariable(h.scope, &v.Children[i], v.Children[i].Name))
+		}
+	}
+	response := &dap.VariablesResponse{
+		Response: *newResponse(request.Request),
+		Body:     dap.VariablesResponseBody{Variables: children},
+	}
+	s.send(response)
 }
 
-// onEvaluateRequest sends a not-yet-implemented error response.
-// This is a mandatory request to support.
-func (s *Server) onEvaluateRequest(request *dap.EvaluateRequest) { // TODO V0
-	s.sendNotYetImplementedErrorResponse(request.Request)
+// convertVariable converts v, a child of a variable evaluated in scope,
+// to a DAP variable. Children of v can be requested by the client if v is
+// addressable.
+func (s *Server) convertVariable(scope api.EvalScope, v *api.Variable, name string) dap.Variable {
+	dv := dap.Variable{Name: name, Value: v.SinglelineString(), Type: v.Type}
+	if v.Addr != 0 {
+		dv.VariablesReference, dv.IndexedVariables = s.childrenReference(scope, fmt.Sprintf("(*(*%q)(%#x))", v.Type, v.Addr), v)
+	}
+	return dv
+}
+
+// hexIntegers rewrites the values of v and of its loaded children that
+// are integers in hexadecimal, which is what clients expect when they
+// request hex formatting. Strings and floating point numbers are left
+// unchanged.
+func hexIntegers(v *api.Variable) {
+	switch v.Kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+			v.Value = fmt.Sprintf("%#x", n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, err := strconv.ParseUint(v.Value, 10, 64); err == nil {
+			v.Value = fmt.Sprintf("%#x", n)
+		}
+	}
+	for i := range v.Children {
+		hexIntegers(&v.Children[i])
+	}
+}
+
+// childrenReference returns a reference to the children of v, which is the
+// result of evaluating expr in scope, and the number of its indexed
+// children. The reference is 0 if v has no children. The children of a
+// variable displayed by a pretty-printer are its synthetic children.
+func (s *Server) childrenReference(scope api.EvalScope, expr string, v *api.Variable) (ref, indexed int) {
+	if v.Unreadable != "" {
+		return 0, 0
+	}
+	if v.Pretty != nil && len(v.Pretty.Children) > 0 {
+		return s.variableHandles.create(scope, expr), 0
+	}
+	switch v.Kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if v.Len == 0 {
+			return 0, 0
+		}
+		return s.variableHandles.create(scope, expr), int(v.Len)
+	case reflect.Struct:
+		if len(v.Children) == 0 {
+			return 0, 0
+		}
+		return s.variableHandles.create(scope, expr), 0
+	case reflect.Ptr, reflect.Interface:
+		if len(v.Children) != 1 || v.Children[0].Addr == 0 {
+			return 0, 0
+		}
+		return s.variableHandles.create(scope, expr), 0
+	}
+	return 0, 0
+}
+
+// onEvaluateRequest evaluates an expression in the topmost stack frame of
+// the selected goroutine. The result is returned as a string, integer
+// values are formatted in hexadecimal if the hex format flag is set.
+// TODO: use the frame of request.Arguments.FrameId once stackTrace
+// requests are supported.
+func (s *Server) onEvaluateRequest(request *dap.EvaluateRequest) {
+	if s.debugger == nil {
+		s.sendErrorResponse(request.Request, UnableToEvaluateExpression, "Unable to evaluate expression", "debugger is nil")
+		return
+	}
+	scope := api.EvalScope{GoroutineID: -1}
+	v, err := s.debugger.EvalVariableInScope(scope, request.Arguments.Expression, evaluateLoadConfig)
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToEvaluateExpression, "Unable to evaluate expression", err.Error())
+		return
+	}
+	if request.Arguments.Format.Hex {
+		hexIntegers(v)
+	}
+	response := &dap.EvaluateResponse{
+		Response: *newResponse(request.Request),
+		Body:     dap.EvaluateResponseBody{Result: v.SinglelineString(), Type: v.Type},
+	}
+	response.Body.VariablesReference, response.Body.IndexedVariables = s.childrenReference(scope, request.Arguments.Expression, v)
+	s.send(response)
 }
 
 // onTerminateRequest sends a not-yet-implemented error response.
@@ -774,10 +1098,19 @@ func newEvent(event string) *dap.Event {
 }
 
 func (s *Server) doContinue() {
+	s.doCommand(&api.DebuggerCommand{Name: api.Continue}, "breakpoint")
+}
+
+// doCommand runs cmd and sends a stopped event with the given reason, or a
+// terminated event if the target exited. Stopping on a breakpoint always
+// reports "breakpoint" or, for panics, "exception".
+func (s *Server) doCommand(cmd *api.DebuggerCommand, reason string) {
 	if s.debugger == nil {
 		return
 	}
-	state, err := s.debugger.Command(&api.DebuggerCommand{Name: api.Continue})
+	s.stepInTargets = nil
+	s.variableHandles.reset()
+	state, err := s.debugger.Command(cmd)
 	if err != nil {
 		s.log.Error(err)
 		switch err.(type) {
@@ -794,9 +1127,21 @@ func (s *Server) doContinue() {
 	} else {
 		e := &dap.StoppedEvent{Event: *newEvent("stopped")}
 		// TODO(polina): differentiate between breakpoint and pause on halt.
-		e.Body.Reason = "breakpoint"
+		e.Body.Reason = reason
+		if state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil {
+			e.Body.Reason = "breakpoint"
+		}
+		if state.CurrentThread != nil && isPanicBreakpoint(state.CurrentThread.Breakpoint) {
+			e.Body.Reason = "exception"
+			e.Body.Description = "panic"
+		}
 		e.Body.AllThreadsStopped = true
 		e.Body.ThreadId = state.SelectedGoroutine.ID
 		s.send(e)
 	}
 }
+
+// isPanicBreakpoint returns true if bp is triggered by a panic.
+func isPanicBreakpoint(bp *api.Breakpoint) bool {
+	return bp != nil && (bp.Panic || bp.Name == proc.UnrecoveredPanic)
+}
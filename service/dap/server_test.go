@@ -15,6 +15,7 @@ import (
 	"github.com/go-delve/delve/pkg/logflags"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/dap/daptest"
 	"github.com/go-delve/delve/service/debugger"
 	"github.com/google/go-dap"
@@ -32,6 +33,14 @@ func TestMain(m *testing.M) {
 
 // name is for _fixtures/<name>.go
 func runTest(t *testing.T, name string, test func(c *daptest.Client, f protest.Fixture)) {
+	runTestWithServer(t, name, func(_ *Server, c *daptest.Client, f protest.Fixture) {
+		test(c, f)
+	})
+}
+
+// runTestWithServer is like runTest but also passes the server to test,
+// for the tests that need to inspect the state of its debugger.
+func runTestWithServer(t *testing.T, name string, test func(s *Server, c *daptest.Client, f protest.Fixture)) {
 	var buildFlags protest.BuildFlags
 	fixture := protest.BuildFixture(name, buildFlags)
 
@@ -68,7 +77,7 @@ func runTest(t *testing.T, name string, test func(c *daptest.Client, f protest.F
 		stopOnce.Do(func() { server.Stop() })
 	}()
 
-	test(client, fixture)
+	test(server, client, fixture)
 }
 
 // TestStopOnEntry emulates the message exchange that can be observed with
@@ -319,6 +328,320 @@ func TestSetBreakpoint(t *testing.T) {
 	})
 }
 
+func TestEvaluateRequest(t *testing.T) {
+	runTest(t, "increment", func(client *daptest.Client, fixture protest.Fixture) {
+		client.InitializeRequest()
+		initResp := client.ExpectInitializeResponse(t)
+		if !initResp.Body.SupportsValueFormattingOptions {
+			t.Errorf("got %#v, want SupportsValueFormattingOptions=true", initResp)
+		}
+
+		client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+		client.ExpectInitializedEvent(t)
+		client.ExpectLaunchResponse(t)
+
+		client.SetBreakpointsRequest(fixture.Source, []int{8})
+		client.ExpectSetBreakpointsResponse(t)
+
+		client.ConfigurationDoneRequest()
+		client.ExpectConfigurationDoneResponse(t)
+		client.ExpectStoppedEvent(t)
+
+		client.EvaluateRequest("y+10", false)
+		eResp := client.ExpectEvaluateResponse(t)
+		if eResp.Body.Result != "10" || eResp.Body.Type != "uint" {
+			t.Errorf("got %#v, want Result=\"10\" Type=\"uint\"", eResp)
+		}
+
+		client.EvaluateRequest("y+10", true)
+		eResp = client.ExpectEvaluateResponse(t)
+		if eResp.Body.Result != "0xa" {
+			t.Errorf("got %#v, want Result=\"0xa\"", eResp)
+		}
+
+		// hex formatting only applies to integers
+		client.EvaluateRequest(`"hi"`, true)
+		eResp = client.ExpectEvaluateResponse(t)
+		if eResp.Body.Result != `"hi"` {
+			t.Errorf("got %#v, want Result=%q", eResp, `"hi"`)
+		}
+
+		client.EvaluateRequest("1.5", true)
+		eResp = client.ExpectEvaluateResponse(t)
+		if eResp.Body.Result != "1.5" {
+			t.Errorf("got %#v, want Result=\"1.5\"", eResp)
+		}
+
+		client.EvaluateRequest("nosuchvar", false)
+		er := client.ExpectErrorResponse(t)
+		if er.Body.Error.Id != 2009 {
+			t.Errorf("got %#v, want Id=2009", er)
+		}
+
+		client.ContinueRequest(1)
+		client.ExpectContinueResponse(t)
+		client.ExpectTerminatedEvent(t)
+		client.DisconnectRequest()
+		client.ExpectDisconnectResponse(t)
+	})
+}
+
+func TestStepInTargetsRequest(t *testing.T) {
+	runTest(t, "stepintotest", func(client *daptest.Client, fixture protest.Fixture) {
+		client.InitializeRequest()
+		initResp := client.ExpectInitializeResponse(t)
+		if !initResp.Body.SupportsStepInTargetsRequest {
+			t.Errorf("got %#v, want SupportsStepInTargetsRequest=true", initResp)
+		}
+
+		client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+		client.ExpectInitializedEvent(t)
+		client.ExpectLaunchResponse(t)
+
+		client.SetBreakpointsRequest(fixture.Source, []int{22})
+		client.ExpectSetBreakpointsResponse(t)
+
+		client.ConfigurationDoneRequest()
+		client.ExpectConfigurationDoneResponse(t)
+		client.ExpectStoppedEvent(t)
+
+		client.StepInTargetsRequest()
+		stResp := client.ExpectStepInTargetsResponse(t)
+		want := []dap.StepInTarget{
+			{Id: 1, Label: "main.double"},
+			{Id: 2, Label: "main.square"},
+			{Id: 3, Label: "main.sum"},
+		}
+		if !reflect.DeepEqual(stResp.Body.Targets, want) {
+			t.Errorf("\ngot  %#v\nwant %#v", stResp.Body.Targets, want)
+		}
+
+		client.StepInTargetRequest(1, 4)
+		er := client.ExpectErrorResponse(t)
+		if er.Body.Error.Id != UnableToStep {
+			t.Errorf("got %#v, want Id=%d", er, UnableToStep)
+		}
+
+		// Stepping into square must skip the call to double.
+		client.StepInTargetRequest(1, 2)
+		client.ExpectStepInResponse(t)
+		stopEvent := client.ExpectStoppedEvent(t)
+		if stopEvent.Body.Reason != "step" || stopEvent.Body.ThreadId != 1 {
+			t.Errorf("got %#v, want Body={Reason=\"step\", ThreadId=1}", stopEvent)
+		}
+
+		client.EvaluateRequest("x", false)
+		eResp := client.ExpectEvaluateResponse(t)
+		if eResp.Body.Result != "3" {
+			t.Errorf("got %#v, want Result=\"3\"", eResp)
+		}
+
+		client.ContinueRequest(1)
+		client.ExpectContinueResponse(t)
+		client.ExpectTerminatedEvent(t)
+		client.DisconnectRequest()
+		client.ExpectDisconnectResponse(t)
+	})
+}
+
+func TestSetExceptionBreakpoints(t *testing.T) {
+	runTest(t, "recoveredpanic", func(client *daptest.Client, fixture protest.Fixture) {
+		client.InitializeRequest()
+		initResp := client.ExpectInitializeResponse(t)
+		if len(initResp.Body.ExceptionBreakpointFilters) != 1 || initResp.Body.ExceptionBreakpointFilters[0].Filter != "panic" {
+			t.Errorf("got %#v, want ExceptionBreakpointFilters=[{Filter=\"panic\"}]", initResp.Body.ExceptionBreakpointFilters)
+		}
+
+		client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+		client.ExpectInitializedEvent(t)
+		client.ExpectLaunchResponse(t)
+
+		client.SetExceptionBreakpointsRequestWithArgs([]string{"panic"}, nil)
+		client.ExpectSetExceptionBreakpointsResponse(t)
+
+		// Replacing the filter, or listing it twice, must reuse the breakpoint
+		// on runtime.gopanic instead of creating a second one.
+		opts := []dap.ExceptionOptions{{Path: []dap.ExceptionPathSegment{{Names: []string{"myError"}}}, BreakMode: "always"}}
+		client.SetExceptionBreakpointsRequestWithArgs([]string{"panic", "panic"}, opts)
+		client.ExpectSetExceptionBreakpointsResponse(t)
+
+		client.ConfigurationDoneRequest()
+		client.ExpectConfigurationDoneResponse(t)
+
+		// Only the third panic, with a *main.myError argument, matches.
+		stopEvent := client.ExpectStoppedEvent(t)
+		if stopEvent.Body.Reason != "exception" || stopEvent.Body.Description != "panic" {
+			t.Errorf("got %#v, want Body={Reason=\"exception\", Description=\"panic\"}", stopEvent)
+		}
+
+		client.ContinueRequest(1)
+		client.ExpectContinueResponse(t)
+		client.ExpectTerminatedEvent(t)
+		client.DisconnectRequest()
+		client.ExpectDisconnectResponse(t)
+	})
+}
+
+func TestSetExceptionBreakpointsUserPanicBreakpoint(t *testing.T) {
+	runTestWithServer(t, "recoveredpanic", func(server *Server, client *daptest.Client, fixture protest.Fixture) {
+		panicBreakpoints := func() []*api.Breakpoint {
+			var r []*api.Breakpoint
+			for _, bp := range server.debugger.Breakpoints() {
+				if bp.Panic {
+					r = append(r, bp)
+				}
+			}
+			return r
+		}
+
+		client.InitializeRequest()
+		client.ExpectInitializeResponse(t)
+		client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+		client.ExpectInitializedEvent(t)
+		client.ExpectLaunchResponse(t)
+
+		// Breakpoint set by the user, for example with 'break -panic'.
+		userBp, err := server.debugger.CreateBreakpoint(&api.Breakpoint{Panic: true, PanicFilter: "second"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := []dap.ExceptionOptions{{Path: []dap.ExceptionPathSegment{{Names: []string{"myError"}}}, BreakMode: "always"}}
+		client.SetExceptionBreakpointsRequestWithArgs([]string{"panic"}, opts)
+		client.ExpectSetExceptionBreakpointsResponse(t)
+		if bps := panicBreakpoints(); len(bps) != 1 || bps[0].ID != userBp.ID {
+			t.Fatalf("got %d panic breakpoints, want only breakpoint %d", len(bps), userBp.ID)
+		}
+
+		client.ConfigurationDoneRequest()
+		client.ExpectConfigurationDoneResponse(t)
+
+		// The second panic matches the filter of the user's breakpoint.
+		stopEvent := client.ExpectStoppedEvent(t)
+		if stopEvent.Body.Reason != "exception" {
+			t.Errorf("got %#v, want Body={Reason=\"exception\"}", stopEvent)
+		}
+
+		// Disabling the exception filter restores the user's breakpoint.
+		client.SetExceptionBreakpointsRequestWithArgs(nil, nil)
+		client.ExpectSetExceptionBreakpointsResponse(t)
+		if bps := panicBreakpoints(); len(bps) != 1 || bps[0].ID != userBp.ID || bps[0].PanicFilter != "second" {
+			t.Fatalf("got %#v, want only breakpoint %d with PanicFilter=\"second\"", bps, userBp.ID)
+		}
+
+		// The third panic does not match the user's breakpoint anymore.
+		client.ContinueRequest(1)
+		client.ExpectContinueResponse(t)
+		client.ExpectTerminatedEvent(t)
+		client.DisconnectRequest()
+		client.ExpectDisconnectResponse(t)
+	})
+}
+
+func TestVariablesRequest(t *testing.T) {
+	runTest(t, "largecollections", func(client *daptest.Client, fixture protest.Fixture) {
+		client.InitializeRequest()
+		client.ExpectInitializeResponse(t)
+
+		client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+		client.ExpectInitializedEvent(t)
+		client.ExpectLaunchResponse(t)
+
+		client.ConfigurationDoneRequest()
+		client.ExpectConfigurationDoneResponse(t)
+		client.ExpectStoppedEvent(t)
+
+		client.EvaluateRequest("s", false)
+		eResp := client.ExpectEvaluateResponse(t)
+		if eResp.Body.VariablesReference == 0 || eResp.Body.IndexedVariables != 1000 {
+			t.Fatalf("got %#v, want VariablesReference!=0 IndexedVariables=1000", eResp)
+		}
+
+		client.VariablesRequest(eResp.Body.VariablesReference, 500, 100)
+		vResp := client.ExpectVariablesResponse(t)
+		if len(vResp.Body.Variables) != 100 {
+			t.Fatalf("got %d variables, want 100", len(vResp.Body.Variables))
+		}
+		if v := vResp.Body.Variables[0]; v.Name != "[500]" || v.Value != "500" {
+			t.Errorf("got %#v, want Name=\"[500]\" Value=\"500\"", v)
+		}
+		if v := vResp.Body.Variables[99]; v.Name != "[599]" || v.Value != "599" {
+			t.Errorf("got %#v, want Name=\"[599]\" Value=\"599\"", v)
+		}
+
+		client.EvaluateRequest("m", false)
+		eResp = client.ExpectEvaluateResponse(t)
+		if eResp.Body.VariablesReference == 0 || eResp.Body.IndexedVariables != 300 {
+			t.Fatalf("got %#v, want VariablesReference!=0 IndexedVariables=300", eResp)
+		}
+
+		client.VariablesRequest(eResp.Body.VariablesReference, 280, 50)
+		vResp = client.ExpectVariablesResponse(t)
+		if len(vResp.Body.Variables) != 20 {
+			t.Fatalf("got %d variables, want 20", len(vResp.Body.Variables))
+		}
+		v := vResp.Body.Variables[0]
+		if v.Type != "main.point" || v.VariablesReference == 0 {
+			t.Fatalf("got %#v, want Type=\"main.point\" VariablesReference!=0", v)
+		}
+
+		client.VariablesRequest(v.VariablesReference, 0, 0)
+		vResp = client.ExpectVariablesResponse(t)
+		if len(vResp.Body.Variables) != 2 || vResp.Body.Variables[0].Name != "X" || vResp.Body.Variables[0].Value != v.Name {
+			t.Errorf("got %#v, want fields X=%s and Y", vResp, v.Name)
+		}
+
+		client.VariablesRequest(12345, 0, 0)
+		er := client.ExpectErrorResponse(t)
+		if er.Body.Error.Id != 2008 {
+			t.Errorf("got %#v, want Id=2008", er)
+		}
+
+		client.ContinueRequest(1)
+		client.ExpectContinueResponse(t)
+		client.ExpectTerminatedEvent(t)
+		client.DisconnectRequest()
+		client.ExpectDisconnectResponse(t)
+	})
+}
+
+func TestVariablesRequestPretty(t *testing.T) {
+	runTest(t, "stdformat", func(client *daptest.Client, fixture protest.Fixture) {
+		client.InitializeRequest()
+		client.ExpectInitializeResponse(t)
+
+		client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+		client.ExpectInitializedEvent(t)
+		client.ExpectLaunchResponse(t)
+
+		client.ConfigurationDoneRequest()
+		client.ExpectConfigurationDoneResponse(t)
+		client.ExpectStoppedEvent(t)
+
+		client.EvaluateRequest("*l", false)
+		eResp := client.ExpectEvaluateResponse(t)
+		if !strings.HasPrefix(eResp.Body.Result, "container/list.List len: 2 {[0]: ") {
+			t.Errorf("got %#v, want pretty-printed list", eResp)
+		}
+		if eResp.Body.VariablesReference == 0 || eResp.Body.IndexedVariables != 0 {
+			t.Fatalf("got %#v, want VariablesReference!=0 IndexedVariables=0", eResp)
+		}
+
+		// the synthetic children of the list are its elements
+		client.VariablesRequest(eResp.Body.VariablesReference, 0, 0)
+		vResp := client.ExpectVariablesResponse(t)
+		if len(vResp.Body.Variables) != 2 || vResp.Body.Variables[0].Name != "[0]" || vResp.Body.Variables[1].Name != "[1]" {
+			t.Errorf("got %#v, want children [0] and [1]", vResp)
+		}
+
+		client.ContinueRequest(1)
+		client.ExpectContinueResponse(t)
+		client.ExpectTerminatedEvent(t)
+		client.DisconnectRequest()
+		client.ExpectDisconnectResponse(t)
+	})
+}
+
 // runDebugSesion is a helper for executing the standard init and shutdown
 // sequences for a program that does not stop on entry
 // while specifying unique launch criteria via parameters.
@@ -423,9 +746,6 @@ func TestUnupportedCommandResponses(t *testing.T) {
 		client.TerminateThreadsRequest()
 		expectUnsupportedCommand("terminateThreads")
 
-		client.StepInTargetsRequest()
-		expectUnsupportedCommand("stepInTargets")
-
 		client.GotoTargetsRequest()
 		expectUnsupportedCommand("gotoTargets")
 
@@ -482,12 +802,6 @@ func TestRequiredNotYetImplementedResponses(t *testing.T) {
 
 		client.ScopesRequest()
 		expectNotYetImplemented("scopes")
-
-		client.VariablesRequest()
-		expectNotYetImplemented("variables")
-
-		client.EvaluateRequest()
-		expectNotYetImplemented("evaluate")
 	})
 }
 
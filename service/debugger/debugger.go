@@ -5,12 +5,12 @@ import (
 	"debug/dwarf"
 	"errors"
 	"fmt"
-	"go/parser"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -22,6 +22,7 @@ import (
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/core"
 	"github.com/go-delve/delve/pkg/proc/gdbserial"
+	"github.com/go-delve/delve/pkg/proc/linutil"
 	"github.com/go-delve/delve/pkg/proc/native"
 	"github.com/go-delve/delve/service/api"
 	"github.com/sirupsen/logrus"
@@ -54,6 +55,35 @@ type Debugger struct {
 	targetMutex sync.Mutex
 	target      *proc.Target
 
+	// signalPolicies are the signal handling policies, they are applied to
+	// every new target process.
+	signalPolicies map[int]proc.SignalPolicy
+
+	// prettyPrinters are the registered pretty-printers, in the order they
+	// are tried, prettyPrintersCompiled holds their compiled form.
+	prettyPrinters         []api.PrettyPrinter
+	prettyPrintersCompiled []*proc.PrettyPrinter
+
+	// pendingBreakpoints are the breakpoints whose location could not be
+	// found in the images loaded by the target process, their location
+	// expression is evaluated again every time new images are loaded.
+	pendingBreakpoints []*api.Breakpoint
+	// resolvedBreakpoints are the pending breakpoints that were created since
+	// the target process was last resumed.
+	resolvedBreakpoints []*api.Breakpoint
+	// regexBreakpoints maps the IDs of the pending breakpoints with a regular
+	// expression location to the location, it is evaluated again every time
+	// new images are loaded to set the breakpoint on the new matching
+	// functions.
+	regexBreakpoints map[int]string
+
+	// stopCount is incremented every time the target process stops after
+	// being resumed.
+	stopCount int
+	// diffSnapshots are the values of the expressions compared by
+	// DiffVariableSinceLastStop, recorded at every stop.
+	diffSnapshots map[diffSnapshotKey]*diffSnapshot
+
 	log *logrus.Entry
 
 	running      bool
@@ -90,6 +120,15 @@ type Config struct {
 	// when resolving external debug info files.
 	DebugInfoDirectories []string
 
+	// DebugInfoSources are queried for external debug info files that can
+	// not be found in DebugInfoDirectories.
+	DebugInfoSources []proc.DebugInfoSource
+
+	// IndexCacheDir is the directory where the indexes of the debug info of
+	// executables are saved, to speed up loading them again. An empty
+	// string disables the cache.
+	IndexCacheDir string
+
 	// CheckGoVersion is true if the debugger should check the version of Go
 	// used to compile the executable and refuse to work on incompatible
 	// versions.
@@ -98,6 +137,13 @@ type Config struct {
 	// TTY is passed along to the target process on creation. Used to specify a
 	// TTY for that process.
 	TTY string
+
+	// Signals maps signal names to the keywords describing how they should
+	// be handled by the debugger, see api.ParseSignalPolicy.
+	Signals map[string]string
+
+	// PrettyPrinters are the pretty-printers used to display variables.
+	PrettyPrinters []api.PrettyPrinter
 }
 
 // New creates a new Debugger. ProcessArgs specify the commandline arguments for the
@@ -110,6 +156,16 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 		log:         logger,
 	}
 
+	if err := d.loadSignalPolicies(); err != nil {
+		return nil, err
+	}
+
+	for _, pp := range config.PrettyPrinters {
+		if err := d.setPrettyPrinter(pp); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the process by either attaching or launching.
 	switch {
 	case d.config.AttachPid > 0:
@@ -124,6 +180,7 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 			return nil, attachErrorMessage(d.config.AttachPid, err)
 		}
 		d.target = p
+		d.applySignalPolicies()
 
 	case d.config.CoreFile != "":
 		var p *proc.Target
@@ -131,10 +188,10 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 		switch d.config.Backend {
 		case "rr":
 			d.log.Infof("opening trace %s", d.config.CoreFile)
-			p, err = gdbserial.Replay(d.config.CoreFile, false, false, d.config.DebugInfoDirectories)
+			p, err = gdbserial.Replay(d.config.CoreFile, false, false, d.debugInfoConfig())
 		default:
 			d.log.Infof("opening core file %s (executable %s)", d.config.CoreFile, d.processArgs[0])
-			p, err = core.OpenCore(d.config.CoreFile, d.processArgs[0], d.config.DebugInfoDirectories)
+			p, err = core.OpenCore(d.config.CoreFile, d.processArgs[0], d.debugInfoConfig())
 		}
 		if err != nil {
 			err = go11DecodeErrorCheck(err)
@@ -159,6 +216,7 @@ func New(config *Config, processArgs []string) (*Debugger, error) {
 		if p != nil {
 			// if p == nil and err == nil then we are doing a recording, don't touch d.target
 			d.target = p
+			d.applySignalPolicies()
 		}
 		if err := d.checkGoVersion(); err != nil {
 			d.target.Detach(true)
@@ -202,9 +260,9 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 	}
 	switch d.config.Backend {
 	case "native":
-		return native.Launch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY)
+		return native.Launch(processArgs, wd, d.config.Foreground, d.debugInfoConfig(), d.config.TTY)
 	case "lldb":
-		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY))
+		return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.debugInfoConfig(), d.config.TTY))
 	case "rr":
 		if d.target != nil {
 			// restart should not call us if the backend is 'rr'
@@ -246,9 +304,9 @@ func (d *Debugger) Launch(processArgs []string, wd string) (*proc.Target, error)
 
 	case "default":
 		if runtime.GOOS == "darwin" {
-			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY))
+			return betterGdbserialLaunchError(gdbserial.LLDBLaunch(processArgs, wd, d.config.Foreground, d.debugInfoConfig(), d.config.TTY))
 		}
-		return native.Launch(processArgs, wd, d.config.Foreground, d.config.DebugInfoDirectories, d.config.TTY)
+		return native.Launch(processArgs, wd, d.config.Foreground, d.debugInfoConfig(), d.config.TTY)
 	default:
 		return nil, fmt.Errorf("unknown backend %q", d.config.Backend)
 	}
@@ -278,21 +336,21 @@ func (d *Debugger) recordingRun(run func() (string, error)) (*proc.Target, error
 		return nil, err
 	}
 
-	return gdbserial.Replay(tracedir, false, true, d.config.DebugInfoDirectories)
+	return gdbserial.Replay(tracedir, false, true, d.debugInfoConfig())
 }
 
 // Attach will attach to the process specified by 'pid'.
 func (d *Debugger) Attach(pid int, path string) (*proc.Target, error) {
 	switch d.config.Backend {
 	case "native":
-		return native.Attach(pid, d.config.DebugInfoDirectories)
+		return native.Attach(pid, d.debugInfoConfig())
 	case "lldb":
-		return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.config.DebugInfoDirectories))
+		return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.debugInfoConfig()))
 	case "default":
 		if runtime.GOOS == "darwin" {
-			return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.config.DebugInfoDirectories))
+			return betterGdbserialLaunchError(gdbserial.LLDBAttach(pid, path, d.debugInfoConfig()))
 		}
-		return native.Attach(pid, d.config.DebugInfoDirectories)
+		return native.Attach(pid, d.debugInfoConfig())
 	default:
 		return nil, fmt.Errorf("unknown backend %q", d.config.Backend)
 	}
@@ -300,6 +358,16 @@ func (d *Debugger) Attach(pid int, path string) (*proc.Target, error) {
 
 var errMacOSBackendUnavailable = errors.New("debugserver or lldb-server not found: install Xcode's command line tools or lldb-server")
 
+// debugInfoConfig returns the configuration used to find and cache the
+// debug info of the target process.
+func (d *Debugger) debugInfoConfig() proc.DebugInfoConfig {
+	return proc.DebugInfoConfig{
+		Dirs:          d.config.DebugInfoDirectories,
+		Sources:       d.config.DebugInfoSources,
+		IndexCacheDir: d.config.IndexCacheDir,
+	}
+}
+
 func betterGdbserialLaunchError(p *proc.Target, err error) (*proc.Target, error) {
 	if runtime.GOOS != "darwin" {
 		return p, err
@@ -407,6 +475,15 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 		return nil, ErrCanNotRestart
 	}
 
+	inPlugin := make(map[int]bool)
+	if bi := d.target.BinInfo(); len(bi.Images) > 1 {
+		for _, bp := range d.breakpoints() {
+			if bi.PCToImage(bp.Addr) != bi.Images[0] {
+				inPlugin[bp.LogicalID] = true
+			}
+		}
+	}
+
 	if valid, _ := d.target.Valid(); valid && !recorded {
 		// Ensure the process is in a PTRACE_STOP.
 		if err := stopProcess(d.target.Pid()); err != nil {
@@ -437,20 +514,39 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 		return nil, fmt.Errorf("could not launch process: %s", err)
 	}
 
+	d.resolvedBreakpoints = nil
+	d.diffSnapshots = nil
 	discarded := []api.DiscardedBreakpoint{}
+	// Breakpoints, including pending ones, keep their IDs so that clients can
+	// still refer to them.
+	maxID := 0
+	for _, bp := range d.pendingBreakpoints {
+		if bp.ID > maxID {
+			maxID = bp.ID
+		}
+	}
 	for _, oldBp := range api.ConvertBreakpoints(d.breakpoints()) {
 		if oldBp.ID < 0 {
 			continue
 		}
+		if oldBp.ID > maxID {
+			maxID = oldBp.ID
+		}
 		if len(oldBp.File) > 0 {
 			addrs, err := proc.FindFileLocation(p, oldBp.File, oldBp.Line)
 			if err != nil {
+				if inPlugin[oldBp.ID] {
+					// the plugin has not been loaded yet
+					oldBp.LocExpr = fmt.Sprintf("%s:%d", oldBp.File, oldBp.Line)
+					d.pendingBreakpoints = append(d.pendingBreakpoints, oldBp)
+					continue
+				}
 				discarded = append(discarded, api.DiscardedBreakpoint{Breakpoint: oldBp, Reason: err.Error()})
 				continue
 			}
-			createLogicalBreakpoint(p, addrs, oldBp)
+			createLogicalBreakpoint(p, addrs, oldBp, oldBp.ID)
 		} else {
-			newBp, err := p.SetBreakpoint(oldBp.Addr, proc.UserBreakpoint, nil)
+			newBp, err := p.SetBreakpointWithID(oldBp.ID, oldBp.Addr)
 			if err != nil {
 				return nil, err
 			}
@@ -459,10 +555,96 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 			}
 		}
 	}
+	for _, bp := range d.pendingBreakpoints {
+		setPending(bp, bp.ID)
+	}
+	p.Breakpoints().ReserveLogicalIDs(maxID)
 	d.target = p
+	d.watchPendingBreakpoints()
+	d.applySignalPolicies()
 	return discarded, nil
 }
 
+// watchPendingBreakpoints arranges for the pending breakpoints to be
+// resolved every time the target process loads new images.
+func (d *Debugger) watchPendingBreakpoints() {
+	p := d.target
+	p.SetNewImagesCallback(func([]*proc.Image) {
+		d.resolvePendingBreakpoints(p)
+	})
+}
+
+// resolvePendingBreakpoints creates the pending breakpoints whose location
+// can be found in the images currently loaded by p.
+func (d *Debugger) resolvePendingBreakpoints(p *proc.Target) {
+	d.resolveRegexBreakpoints(p)
+	pendingBreakpoints := d.pendingBreakpoints
+	d.pendingBreakpoints = nil
+	for _, pendingBp := range pendingBreakpoints {
+		addrs, err := d.findLocationPCs(pendingBp.LocExpr)
+		if err != nil {
+			d.pendingBreakpoints = append(d.pendingBreakpoints, pendingBp)
+			continue
+		}
+		createdBp, err := createLogicalBreakpoint(p, addrs, pendingBp, pendingBp.ID)
+		if err != nil {
+			d.log.Errorf("could not create pending breakpoint %d at %s: %v", pendingBp.ID, pendingBp.LocExpr, err)
+			continue
+		}
+		d.log.Infof("resolved pending breakpoint: %#v", createdBp)
+		d.resolvedBreakpoints = append(d.resolvedBreakpoints, createdBp)
+		d.watchRegexBreakpoint(createdBp.ID, pendingBp.LocExpr)
+	}
+}
+
+// watchRegexBreakpoint records the location of the pending breakpoint id if
+// it is a regular expression, so that functions matching it in images
+// loaded later are added to the breakpoint.
+func (d *Debugger) watchRegexBreakpoint(id int, locExpr string) {
+	if loc, err := locspec.Parse(locExpr); err != nil {
+		return
+	} else if _, ok := loc.(*locspec.RegexLocationSpec); !ok {
+		return
+	}
+	if d.regexBreakpoints == nil {
+		d.regexBreakpoints = make(map[int]string)
+	}
+	d.regexBreakpoints[id] = locExpr
+}
+
+// resolveRegexBreakpoints sets the breakpoints recorded by
+// watchRegexBreakpoint on the functions matching their location that are
+// not part of the breakpoint yet.
+func (d *Debugger) resolveRegexBreakpoints(p *proc.Target) {
+	for id, locExpr := range d.regexBreakpoints {
+		bps := d.findBreakpoint(id)
+		if len(bps) == 0 {
+			// cleared
+			delete(d.regexBreakpoints, id)
+			continue
+		}
+		addrs, err := d.findLocationPCs(locExpr)
+		if err != nil {
+			continue
+		}
+		requestedBp := api.ConvertBreakpoints(bps)[0]
+		for _, addr := range addrs {
+			if bp := p.Breakpoints().M[addr]; bp != nil && bp.IsUser() {
+				continue
+			}
+			bp, err := p.SetBreakpointWithID(id, addr)
+			if err == nil {
+				err = copyBreakpointInfo(bp, requestedBp)
+			}
+			if err != nil {
+				d.log.Errorf("could not add %#x to breakpoint %d at %s: %v", addr, id, locExpr, err)
+				continue
+			}
+			d.log.Infof("added %#x to breakpoint %d at %s", addr, id, locExpr)
+		}
+	}
+}
+
 // State returns the current state of the debugger.
 func (d *Debugger) State(nowait bool) (*api.DebuggerState, error) {
 	if d.isRunning() && nowait {
@@ -500,13 +682,21 @@ func (d *Debugger) state(retLoadCfg *proc.LoadConfig) (*api.DebuggerState, error
 	state = &api.DebuggerState{
 		SelectedGoroutine: goroutine,
 		Exited:            exited,
+		StopReason:        api.ConvertStopReason(d.target.StopReason),
+		Syscall:           api.ConvertSyscall(d.target.CurrentSyscall()),
+	}
+
+	state.ResolvedBreakpoints = d.resolvedBreakpoints
+
+	for _, sig := range d.target.ReceivedSignals() {
+		state.Signals = append(state.Signals, api.Signal{ThreadID: sig.ThreadID, Signal: sig.Signal, Name: linutil.SignalName(sig.Signal)})
 	}
 
 	for _, thread := range d.target.ThreadList() {
 		th := api.ConvertThread(thread)
 
 		if retLoadCfg != nil {
-			th.ReturnValues = convertVars(thread.Common().ReturnValues(*retLoadCfg))
+			th.ReturnValues = d.convertVars(thread.Common().ReturnValues(*retLoadCfg), *retLoadCfg)
 		}
 
 		state.Threads = append(state.Threads, th)
@@ -530,8 +720,9 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 	defer d.targetMutex.Unlock()
 
 	var (
-		addrs []uint64
-		err   error
+		addrs        []uint64
+		err          error
+		watchLocExpr bool
 	)
 
 	if requestedBp.Name != "" {
@@ -546,6 +737,8 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 	switch {
 	case requestedBp.TraceReturn:
 		addrs = []uint64{requestedBp.Addr}
+	case requestedBp.Panic:
+		addrs, err = proc.FindPanicLocation(d.target)
 	case len(requestedBp.File) > 0:
 		fileName := requestedBp.File
 		if runtime.GOOS == "windows" {
@@ -563,6 +756,12 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 		addrs, err = proc.FindFunctionLocation(d.target, requestedBp.FunctionName, requestedBp.Line)
 	case len(requestedBp.Addrs) > 0:
 		addrs = requestedBp.Addrs
+	case requestedBp.LocExpr != "":
+		addrs, err = d.findLocationPCs(requestedBp.LocExpr)
+		if err != nil && requestedBp.Pending {
+			return d.createPendingBreakpoint(requestedBp, err)
+		}
+		watchLocExpr = requestedBp.Pending
 	default:
 		addrs = []uint64{requestedBp.Addr}
 	}
@@ -571,21 +770,70 @@ func (d *Debugger) CreateBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoin
 		return nil, err
 	}
 
-	createdBp, err := createLogicalBreakpoint(d.target, addrs, requestedBp)
+	createdBp, err := createLogicalBreakpoint(d.target, addrs, requestedBp, 0)
 	if err != nil {
 		return nil, err
 	}
+	if watchLocExpr {
+		d.watchRegexBreakpoint(createdBp.ID, requestedBp.LocExpr)
+		d.watchPendingBreakpoints()
+	}
 	d.log.Infof("created breakpoint: %#v", createdBp)
 	return createdBp, nil
 }
 
+// createPendingBreakpoint creates a breakpoint that will be set once its
+// location expression can be resolved, findErr is the error returned
+// trying to resolve it in the images currently loaded.
+func (d *Debugger) createPendingBreakpoint(requestedBp *api.Breakpoint, findErr error) (*api.Breakpoint, error) {
+	loc, err := locspec.Parse(requestedBp.LocExpr)
+	if err != nil {
+		return nil, err
+	}
+	switch loc := loc.(type) {
+	case *locspec.NormalLocationSpec:
+		// only locations that do not depend on the current position
+		if loc.Base == "" {
+			return nil, findErr
+		}
+	case *locspec.RegexLocationSpec:
+	default:
+		return nil, findErr
+	}
+	// check that the breakpoint attributes are valid now
+	if err := copyBreakpointInfo(&proc.Breakpoint{}, requestedBp); err != nil {
+		return nil, err
+	}
+
+	bp := *requestedBp
+	setPending(&bp, d.target.Breakpoints().NewLogicalID())
+	d.pendingBreakpoints = append(d.pendingBreakpoints, &bp)
+	d.watchPendingBreakpoints()
+	d.log.Infof("created pending breakpoint: %#v", bp)
+	return &bp, nil
+}
+
+// setPending turns bp into a pending breakpoint with the specified ID.
+func setPending(bp *api.Breakpoint, id int) {
+	bp.ID = id
+	bp.Pending = true
+	bp.Addr, bp.Addrs = 0, nil
+	bp.File, bp.Line, bp.FunctionName = "", 0, ""
+	bp.HitCount, bp.TotalHitCount = map[string]uint64{}, 0
+}
+
 // createLogicalBreakpoint creates one physical breakpoint for each address
 // in addrs and associates all of them with the same logical breakpoint.
-func createLogicalBreakpoint(p *proc.Target, addrs []uint64, requestedBp *api.Breakpoint) (*api.Breakpoint, error) {
+// If id is not zero it will be used as the ID of the logical breakpoint.
+func createLogicalBreakpoint(p *proc.Target, addrs []uint64, requestedBp *api.Breakpoint, id int) (*api.Breakpoint, error) {
 	bps := make([]*proc.Breakpoint, len(addrs))
 	var err error
 	for i := range addrs {
-		bps[i], err = p.SetBreakpoint(addrs[i], proc.UserBreakpoint, nil)
+		if id != 0 {
+			bps[i], err = p.SetBreakpointWithID(id, addrs[i])
+		} else {
+			bps[i], err = p.SetBreakpoint(addrs[i], proc.UserBreakpoint, nil)
+		}
 		if err != nil {
 			break
 		}
@@ -626,13 +874,23 @@ func (d *Debugger) AmendBreakpoint(amend *api.Breakpoint) error {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
+	if err := api.ValidBreakpointName(amend.Name); err != nil {
+		return err
+	}
+	if i := d.findPendingBreakpoint(amend.ID); i >= 0 {
+		if err := copyBreakpointInfo(&proc.Breakpoint{}, amend); err != nil {
+			return err
+		}
+		pendingBp := *amend
+		setPending(&pendingBp, amend.ID)
+		pendingBp.LocExpr = d.pendingBreakpoints[i].LocExpr
+		d.pendingBreakpoints[i] = &pendingBp
+		return nil
+	}
 	originals := d.findBreakpoint(amend.ID)
 	if originals == nil {
 		return fmt.Errorf("no breakpoint with ID %d", amend.ID)
 	}
-	if err := api.ValidBreakpointName(amend.Name); err != nil {
-		return err
-	}
 	for _, original := range originals {
 		if err := copyBreakpointInfo(original, amend); err != nil {
 			return err
@@ -660,9 +918,19 @@ func copyBreakpointInfo(bp *proc.Breakpoint, requested *api.Breakpoint) (err err
 	bp.LoadLocals = api.LoadConfigToProc(requested.LoadLocals)
 	bp.Cond = nil
 	if requested.Cond != "" {
-		bp.Cond, err = parser.ParseExpr(requested.Cond)
+		bp.Cond, err = proc.ParseExpr(requested.Cond)
+		if err != nil {
+			return err
+		}
+	}
+	bp.PanicFilter = nil
+	if requested.Panic {
+		bp.PanicFilter, err = regexp.Compile(requested.PanicFilter)
+		if err != nil {
+			return fmt.Errorf("invalid panic filter: %v", err)
+		}
 	}
-	return err
+	return nil
 }
 
 // ClearBreakpoint clears a breakpoint.
@@ -670,6 +938,13 @@ func (d *Debugger) ClearBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoint
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 
+	if i := d.findPendingBreakpoint(requestedBp.ID); i >= 0 && len(requestedBp.Addrs) == 0 && requestedBp.Addr == 0 {
+		clearedBp := d.pendingBreakpoints[i]
+		d.pendingBreakpoints = append(d.pendingBreakpoints[:i], d.pendingBreakpoints[i+1:]...)
+		d.log.Infof("cleared pending breakpoint: %#v", clearedBp)
+		return clearedBp, nil
+	}
+
 	var bps []*proc.Breakpoint
 	var errs []error
 
@@ -717,11 +992,12 @@ func (d *Debugger) ClearBreakpoint(requestedBp *api.Breakpoint) (*api.Breakpoint
 	return clearedBp[0], nil
 }
 
-// Breakpoints returns the list of current breakpoints.
+// Breakpoints returns the list of current breakpoints, including pending
+// breakpoints.
 func (d *Debugger) Breakpoints() []*api.Breakpoint {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
-	return api.ConvertBreakpoints(d.breakpoints())
+	return append(api.ConvertBreakpoints(d.breakpoints()), d.pendingBreakpoints...)
 }
 
 func (d *Debugger) breakpoints() []*proc.Breakpoint {
@@ -739,6 +1015,9 @@ func (d *Debugger) breakpoints() []*proc.Breakpoint {
 func (d *Debugger) FindBreakpoint(id int) *api.Breakpoint {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
+	if i := d.findPendingBreakpoint(id); i >= 0 {
+		return d.pendingBreakpoints[i]
+	}
 	bps := api.ConvertBreakpoints(d.findBreakpoint(id))
 	if len(bps) <= 0 {
 		return nil
@@ -746,10 +1025,21 @@ func (d *Debugger) FindBreakpoint(id int) *api.Breakpoint {
 	return bps[0]
 }
 
+// findPendingBreakpoint returns the index in d.pendingBreakpoints of the
+// pending breakpoint with the specified ID, or -1.
+func (d *Debugger) findPendingBreakpoint(id int) int {
+	for i, bp := range d.pendingBreakpoints {
+		if bp.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
 func (d *Debugger) findBreakpoint(id int) []*proc.Breakpoint {
 	var bps []*proc.Breakpoint
 	for _, bp := range d.target.Breakpoints().M {
-		if bp.LogicalID == id {
+		if bp.IsUser() && bp.LogicalID == id {
 			bps = append(bps, bp)
 		}
 	}
@@ -764,6 +1054,11 @@ func (d *Debugger) FindBreakpointByName(name string) *api.Breakpoint {
 }
 
 func (d *Debugger) findBreakpointByName(name string) *api.Breakpoint {
+	for _, bp := range d.pendingBreakpoints {
+		if bp.Name == name {
+			return bp
+		}
+	}
 	var bps []*proc.Breakpoint
 	for _, bp := range d.breakpoints() {
 		if bp.Name == name {
@@ -840,6 +1135,8 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 	}
 
 	withBreakpointInfo := true
+	// resumed is false for the commands that do not resume the target
+	resumed := true
 
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
@@ -847,6 +1144,8 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 	d.setRunning(true)
 	defer d.setRunning(false)
 
+	d.resolvedBreakpoints = nil
+
 	switch command.Name {
 	case api.Continue:
 		d.log.Debug("continuing")
@@ -859,8 +1158,12 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 		err = d.target.Continue()
 	case api.Call:
 		d.log.Debugf("function call %s", command.Expr)
-		if err := d.target.ChangeDirection(proc.Forward); err != nil {
-			return nil, err
+		// Function calls on targets that can not execute code are
+		// interpreted and do not resume the target.
+		if d.target.SupportsFunctionCalls() {
+			if err := d.target.ChangeDirection(proc.Forward); err != nil {
+				return nil, err
+			}
 		}
 		if command.ReturnInfoLoadConfig == nil {
 			return nil, errors.New("can not call function with nil ReturnInfoLoadConfig")
@@ -873,6 +1176,7 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 			}
 		}
 		err = proc.EvalExpressionWithCalls(d.target, g, command.Expr, *api.LoadConfigToProc(command.ReturnInfoLoadConfig), !command.UnsafeCall)
+		resumed = false
 	case api.Rewind:
 		d.log.Debug("rewinding")
 		if err := d.target.ChangeDirection(proc.Backward); err != nil {
@@ -927,10 +1231,41 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 			return nil, err
 		}
 		err = d.target.StepOut()
+	case api.Until, api.ReverseUntil, api.Advance, api.ReverseAdvance:
+		d.log.Debugf("%s %s", command.Name, command.Expr)
+		direction := proc.Forward
+		if command.Name == api.ReverseUntil || command.Name == api.ReverseAdvance {
+			direction = proc.Backward
+		}
+		if err := d.target.ChangeDirection(direction); err != nil {
+			return nil, err
+		}
+		var pcs []uint64
+		pcs, err = d.findLocationPCs(command.Expr)
+		if err != nil {
+			return nil, err
+		}
+		if command.Name == api.Until || command.Name == api.ReverseUntil {
+			err = d.target.Until(pcs)
+		} else {
+			err = d.target.Advance(pcs)
+		}
+	case api.StepInto:
+		d.log.Debugf("step into %s", command.Expr)
+		if err := d.target.ChangeDirection(proc.Forward); err != nil {
+			return nil, err
+		}
+		var callPC uint64
+		callPC, err = d.findStepInTarget(command.Expr)
+		if err != nil {
+			return nil, err
+		}
+		err = d.target.StepInto(callPC)
 	case api.SwitchThread:
 		d.log.Debugf("switching to thread %d", command.ThreadID)
 		err = d.target.SwitchThread(command.ThreadID)
 		withBreakpointInfo = false
+		resumed = false
 	case api.SwitchGoroutine:
 		d.log.Debugf("switching to goroutine %d", command.GoroutineID)
 		g, err := proc.FindGoroutine(d.target, command.GoroutineID)
@@ -938,9 +1273,11 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 			err = d.target.SwitchGoroutine(g)
 		}
 		withBreakpointInfo = false
+		resumed = false
 	case api.Halt:
 		// RequestManualStop already called
 		withBreakpointInfo = false
+		resumed = false
 	}
 
 	if err != nil {
@@ -953,6 +1290,10 @@ func (d *Debugger) Command(command *api.DebuggerCommand) (*api.DebuggerState, er
 		}
 		return nil, err
 	}
+	if resumed {
+		d.stopCount++
+		d.recordDiffSnapshots()
+	}
 	state, stateErr := d.state(api.LoadConfigToProc(command.ReturnInfoLoadConfig))
 	if stateErr != nil {
 		return state, stateErr
@@ -1024,21 +1365,22 @@ func (d *Debugger) collectBreakpointInformation(state *api.DebuggerState) error
 			bpi.Variables = make([]api.Variable, len(bp.Variables))
 		}
 		for i := range bp.Variables {
-			v, err := s.EvalVariable(bp.Variables[i], proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1})
+			cfg := proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+			v, err := s.EvalVariable(bp.Variables[i], cfg)
 			if err != nil {
 				bpi.Variables[i] = api.Variable{Name: bp.Variables[i], Unreadable: fmt.Sprintf("eval error: %v", err)}
 			} else {
-				bpi.Variables[i] = *api.ConvertVar(v)
+				bpi.Variables[i] = *d.convertVar(v, cfg)
 			}
 		}
 		if bp.LoadArgs != nil {
 			if vars, err := s.FunctionArguments(*api.LoadConfigToProc(bp.LoadArgs)); err == nil {
-				bpi.Arguments = convertVars(vars)
+				bpi.Arguments = d.convertVars(vars, *api.LoadConfigToProc(bp.LoadArgs))
 			}
 		}
 		if bp.LoadLocals != nil {
 			if locals, err := s.LocalVariables(*api.LoadConfigToProc(bp.LoadLocals)); err == nil {
-				bpi.Locals = convertVars(locals)
+				bpi.Locals = d.convertVars(locals, *api.LoadConfigToProc(bp.LoadLocals))
 			}
 		}
 	}
@@ -1065,6 +1407,57 @@ func (d *Debugger) Sources(filter string) ([]string, error) {
 	return files, nil
 }
 
+// StepInTargets returns the function calls, made by the current line of
+// the selected goroutine, that can be stepped into.
+func (d *Debugger) StepInTargets() ([]api.StepInTarget, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	tgts, err := proc.StepInTargets(d.target)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]api.StepInTarget, len(tgts))
+	for i := range tgts {
+		r[i] = api.StepInTarget{CallPC: tgts[i].CallPC, Function: api.ConvertFunction(tgts[i].Fn)}
+	}
+	return r, nil
+}
+
+// findStepInTarget returns the address of the call instruction specified
+// by expr, either the address itself (as *<address>) or the name of the
+// called function. The package of the function can be omitted, if more
+// than one call matches the first one is used.
+func (d *Debugger) findStepInTarget(expr string) (uint64, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "*") {
+		return strconv.ParseUint(expr[1:], 0, 64)
+	}
+	tgts, err := proc.StepInTargets(d.target)
+	if err != nil {
+		return 0, err
+	}
+	if len(tgts) == 0 {
+		return 0, errors.New("no function calls on the current line")
+	}
+	var names []string
+	for _, tgt := range tgts {
+		if tgt.Fn.Name == expr {
+			return tgt.CallPC, nil
+		}
+		names = append(names, tgt.Fn.Name)
+	}
+	var r []uint64
+	for _, tgt := range tgts {
+		if strings.HasSuffix(tgt.Fn.Name, "."+expr) {
+			r = append(r, tgt.CallPC)
+		}
+	}
+	if len(r) == 0 {
+		return 0, fmt.Errorf("no call to %s on the current line, calls are: %s", expr, strings.Join(names, ", "))
+	}
+	return r[0], nil
+}
+
 // Functions returns a list of functions in the target process.
 func (d *Debugger) Functions(filter string) ([]string, error) {
 	d.targetMutex.Lock()
@@ -1135,7 +1528,7 @@ func (d *Debugger) PackageVariables(threadID int, filter string, cfg proc.LoadCo
 	}
 	for _, v := range pv {
 		if regex.Match([]byte(v.Name)) {
-			vars = append(vars, *api.ConvertVar(v))
+			vars = append(vars, *d.convertVar(v, cfg))
 		}
 	}
 	return vars, err
@@ -1206,10 +1599,11 @@ var canonicalRegisterOrder = map[string]int{
 	"sp": 1,
 }
 
-func convertVars(pv []*proc.Variable) []api.Variable {
+func (d *Debugger) convertVars(pv []*proc.Variable, cfg proc.LoadConfig) []api.Variable {
 	if pv == nil {
 		return nil
 	}
+	proc.ApplyPrettyPrinters(pv, d.prettyPrintersCompiled, cfg)
 	vars := make([]api.Variable, 0, len(pv))
 	for _, v := range pv {
 		vars = append(vars, *api.ConvertVar(v))
@@ -1217,6 +1611,13 @@ func convertVars(pv []*proc.Variable) []api.Variable {
 	return vars
 }
 
+// convertVar applies the registered pretty-printers to v and converts it
+// to api.Variable.
+func (d *Debugger) convertVar(v *proc.Variable, cfg proc.LoadConfig) *api.Variable {
+	proc.ApplyPrettyPrinters([]*proc.Variable{v}, d.prettyPrintersCompiled, cfg)
+	return api.ConvertVar(v)
+}
+
 // LocalVariables returns a list of the local variables.
 func (d *Debugger) LocalVariables(scope api.EvalScope, cfg proc.LoadConfig) ([]api.Variable, error) {
 	d.targetMutex.Lock()
@@ -1230,7 +1631,7 @@ func (d *Debugger) LocalVariables(scope api.EvalScope, cfg proc.LoadConfig) ([]a
 	if err != nil {
 		return nil, err
 	}
-	return convertVars(pv), err
+	return d.convertVars(pv, cfg), err
 }
 
 // FunctionArguments returns the arguments to the current function.
@@ -1246,7 +1647,7 @@ func (d *Debugger) FunctionArguments(scope api.EvalScope, cfg proc.LoadConfig) (
 	if err != nil {
 		return nil, err
 	}
-	return convertVars(pv), nil
+	return d.convertVars(pv, cfg), nil
 }
 
 // EvalVariableInScope will attempt to evaluate the variable represented by 'symbol'
@@ -1263,7 +1664,145 @@ func (d *Debugger) EvalVariableInScope(scope api.EvalScope, symbol string, cfg p
 	if err != nil {
 		return nil, err
 	}
-	return api.ConvertVar(v), err
+	return d.convertVar(v, cfg), err
+}
+
+// EvalVariableRangeInScope evaluates the slice, array, string or map
+// represented by 'symbol' in the scope provided and loads count of its
+// elements, starting at start.
+// For maps, cursor is the position where the previous page ended, if
+// any, and the returned cursor is the position where the next page starts.
+func (d *Debugger) EvalVariableRangeInScope(scope api.EvalScope, symbol string, start, count int, cursor *api.MapCursor, cfg proc.LoadConfig) (*api.Variable, *api.MapCursor, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, scope.GoroutineID, scope.Frame, scope.DeferredCall)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, next, err := s.EvalVariableRange(symbol, int64(start), int64(count), api.MapCursorToProc(cursor), cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.convertVar(v, cfg), api.ConvertMapCursor(next), nil
+}
+
+// maxDiffSnapshots is the maximum number of expressions compared by
+// DiffVariableSinceLastStop, when it is exceeded the expression that was
+// compared least recently is forgotten.
+const maxDiffSnapshots = 8
+
+// diffSnapshotKey identifies an expression compared by
+// DiffVariableSinceLastStop.
+type diffSnapshotKey struct {
+	scope api.EvalScope
+	expr  string
+}
+
+// diffSnapshot holds the values of an expression compared by
+// DiffVariableSinceLastStop: cur is its value at the current stop and prev
+// its value at the previous stop, either is nil if the expression could
+// not be evaluated. Used is the stop at which the expression was last
+// compared.
+type diffSnapshot struct {
+	cfg       proc.LoadConfig
+	used      int
+	prev, cur *proc.Variable
+}
+
+// recordDiffSnapshots records the values of the expressions compared by
+// DiffVariableSinceLastStop at the current stop.
+func (d *Debugger) recordDiffSnapshots() {
+	for key, snap := range d.diffSnapshots {
+		snap.prev, snap.cur = snap.cur, nil
+		s, err := proc.ConvertEvalScope(d.target, key.scope.GoroutineID, key.scope.Frame, key.scope.DeferredCall)
+		if err != nil {
+			continue
+		}
+		if v, err := s.EvalVariable(key.expr, snap.cfg); err == nil {
+			snap.cur = v
+		}
+	}
+}
+
+// DiffVariablesInScope evaluates expr1 and expr2 in the scope provided and
+// returns the differences between their values.
+func (d *Debugger) DiffVariablesInScope(scope api.EvalScope, expr1, expr2 string, cfg proc.LoadConfig) ([]api.VariableDiff, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, scope.GoroutineID, scope.Frame, scope.DeferredCall)
+	if err != nil {
+		return nil, err
+	}
+	oldv, err := s.EvalVariable(expr1, cfg)
+	if err != nil {
+		return nil, err
+	}
+	newv, err := s.EvalVariable(expr2, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return d.convertDiffs(proc.DiffVariables(oldv, newv), cfg), nil
+}
+
+// DiffVariableSinceLastStop evaluates expr in the scope provided and
+// returns the differences between its value and the value it had at the
+// previous stop. The values of expr are recorded at every stop after the
+// first time it is compared: if there is no previous value of expr ok is
+// false.
+func (d *Debugger) DiffVariableSinceLastStop(scope api.EvalScope, expr string, cfg proc.LoadConfig) (diffs []api.VariableDiff, ok bool, err error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, scope.GoroutineID, scope.Frame, scope.DeferredCall)
+	if err != nil {
+		return nil, false, err
+	}
+	v, err := s.EvalVariable(expr, cfg)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := diffSnapshotKey{scope, expr}
+	snap := d.diffSnapshots[key]
+	if snap == nil {
+		if d.diffSnapshots == nil {
+			d.diffSnapshots = make(map[diffSnapshotKey]*diffSnapshot)
+		}
+		if len(d.diffSnapshots) >= maxDiffSnapshots {
+			lru, first := diffSnapshotKey{}, true
+			for k, snap := range d.diffSnapshots {
+				if first || snap.used < d.diffSnapshots[lru].used {
+					lru, first = k, false
+				}
+			}
+			delete(d.diffSnapshots, lru)
+		}
+		d.diffSnapshots[key] = &diffSnapshot{cfg: cfg, used: d.stopCount, cur: v}
+		return nil, false, nil
+	}
+	snap.cfg, snap.used = cfg, d.stopCount
+	if snap.prev == nil {
+		return nil, false, nil
+	}
+	return d.convertDiffs(proc.DiffVariables(snap.prev, v), cfg), true, nil
+}
+
+// convertDiffs applies the registered pretty-printers to the values in
+// diffs and converts them to api.VariableDiff.
+func (d *Debugger) convertDiffs(diffs []proc.VariableDiff, cfg proc.LoadConfig) []api.VariableDiff {
+	r := make([]api.VariableDiff, len(diffs))
+	for i := range diffs {
+		r[i] = api.VariableDiff{Path: diffs[i].Path, Kind: api.DiffKind(diffs[i].Kind)}
+		if diffs[i].Old != nil {
+			r[i].Old = d.convertVar(diffs[i].Old, cfg)
+		}
+		if diffs[i].New != nil {
+			r[i].New = d.convertVar(diffs[i].New, cfg)
+		}
+	}
+	return r
 }
 
 // SetVariableInScope will set the value of the variable represented by
@@ -1379,6 +1918,8 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 			Defers: d.convertDefers(rawlocs[i].Defers),
 
 			Bottom: rawlocs[i].Bottom,
+
+			UnwoundByFramePointer: rawlocs[i].UnwoundByFramePointer,
 		}
 		if rawlocs[i].Err != nil {
 			frame.Err = rawlocs[i].Err.Error()
@@ -1395,8 +1936,8 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 				return nil, err
 			}
 
-			frame.Locals = convertVars(locals)
-			frame.Arguments = convertVars(arguments)
+			frame.Locals = d.convertVars(locals, *cfg)
+			frame.Arguments = d.convertVars(arguments, *cfg)
 		}
 		locations = append(locations, frame)
 	}
@@ -1463,6 +2004,32 @@ func (d *Debugger) FindLocation(scope api.EvalScope, locStr string, includeNonEx
 	return locs, err
 }
 
+// findLocationPCs returns the addresses of the location specified by
+// locStr, evaluated in the scope of the selected goroutine.
+func (d *Debugger) findLocationPCs(locStr string) ([]uint64, error) {
+	loc, err := locspec.Parse(locStr)
+	if err != nil {
+		return nil, err
+	}
+	s, _ := proc.ConvertEvalScope(d.target, -1, 0, 0)
+	locs, err := loc.Find(d.target, d.processArgs, s, locStr, false)
+	if err != nil {
+		return nil, err
+	}
+	var pcs []uint64
+	for _, loc := range locs {
+		if len(loc.PCs) > 0 {
+			pcs = append(pcs, loc.PCs...)
+		} else if loc.PC != 0 {
+			pcs = append(pcs, loc.PC)
+		}
+	}
+	if len(pcs) == 0 {
+		return nil, fmt.Errorf("location %q not found", locStr)
+	}
+	return pcs, nil
+}
+
 // Disassemble code between startPC and endPC.
 // if endPC == 0 it will find the function containing startPC and disassemble the whole function.
 func (d *Debugger) Disassemble(goroutineID int, addr1, addr2 uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error) {
@@ -1542,6 +2109,159 @@ func (d *Debugger) ClearCheckpoint(id int) error {
 	return d.target.ClearCheckpoint(id)
 }
 
+// SetSyscallCatchpoint stops the target process when any of its threads
+// enters or exits one of the specified system calls. System calls can be
+// specified either by name or by number, if syscalls is empty all system
+// calls are caught.
+func (d *Debugger) SetSyscallCatchpoint(syscalls []string) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	cp := &proc.SyscallCatchpoint{}
+	goarch := d.target.BinInfo().Arch.Name
+	for _, name := range syscalls {
+		num, err := linutil.SyscallNumber(goarch, name)
+		if err != nil {
+			return err
+		}
+		cp.Syscalls = append(cp.Syscalls, num)
+	}
+	return d.target.SetSyscallCatchpoint(cp)
+}
+
+// ClearSyscallCatchpoint removes the syscall catchpoint.
+func (d *Debugger) ClearSyscallCatchpoint() error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.target.SetSyscallCatchpoint(nil)
+}
+
+// loadSignalPolicies parses the signal handling policies specified in the
+// configuration.
+func (d *Debugger) loadSignalPolicies() error {
+	for name, keywords := range d.config.Signals {
+		sig, err := linutil.SignalNumber(name)
+		if err != nil {
+			return err
+		}
+		policy, err := api.ParseSignalPolicy(convertSignalPolicy(name, proc.DefaultSignalPolicy), strings.Fields(keywords))
+		if err != nil {
+			return fmt.Errorf("invalid policy for signal %s: %v", name, err)
+		}
+		if d.signalPolicies == nil {
+			d.signalPolicies = make(map[int]proc.SignalPolicy)
+		}
+		d.signalPolicies[sig] = proc.SignalPolicy{Stop: policy.Stop, Print: policy.Print, Pass: policy.Pass}
+	}
+	return nil
+}
+
+// applySignalPolicies sets the signal handling policies on the current target.
+func (d *Debugger) applySignalPolicies() {
+	for sig, policy := range d.signalPolicies {
+		if err := d.target.SetSignalPolicy(sig, policy); err != nil {
+			d.log.Warnf("could not set policy for signal %s: %v", linutil.SignalName(sig), err)
+		}
+	}
+}
+
+// SetSignalPolicy changes how the debugger handles a signal received by
+// the target process.
+func (d *Debugger) SetSignalPolicy(policy api.SignalPolicy) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	sig, err := linutil.SignalNumber(policy.Signal)
+	if err != nil {
+		return err
+	}
+	p := proc.SignalPolicy{Stop: policy.Stop, Print: policy.Print, Pass: policy.Pass}
+	if err := d.target.SetSignalPolicy(sig, p); err != nil {
+		return err
+	}
+	if d.signalPolicies == nil {
+		d.signalPolicies = make(map[int]proc.SignalPolicy)
+	}
+	d.signalPolicies[sig] = p
+	return nil
+}
+
+// SignalPolicies returns the policies of the specified signals, if no
+// signal is specified it returns the policies that were changed from the
+// default one.
+func (d *Debugger) SignalPolicies(signals []string) ([]api.SignalPolicy, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	var sigs []int
+	if len(signals) == 0 {
+		for sig := range d.signalPolicies {
+			sigs = append(sigs, sig)
+		}
+		sort.Ints(sigs)
+	}
+	for _, name := range signals {
+		sig, err := linutil.SignalNumber(name)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, sig)
+	}
+	r := make([]api.SignalPolicy, 0, len(sigs))
+	for _, sig := range sigs {
+		r = append(r, convertSignalPolicy(linutil.SignalName(sig), d.target.SignalPolicy(sig)))
+	}
+	return r, nil
+}
+
+func convertSignalPolicy(name string, policy proc.SignalPolicy) api.SignalPolicy {
+	return api.SignalPolicy{Signal: name, Stop: policy.Stop, Print: policy.Print, Pass: policy.Pass}
+}
+
+// SetPrettyPrinter registers a pretty-printer, replacing the one with the
+// same type pattern if it exists.
+func (d *Debugger) SetPrettyPrinter(pp api.PrettyPrinter) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return d.setPrettyPrinter(pp)
+}
+
+func (d *Debugger) setPrettyPrinter(pp api.PrettyPrinter) error {
+	compiled, err := api.ConvertPrettyPrinter(pp)
+	if err != nil {
+		return err
+	}
+	for i := range d.prettyPrinters {
+		if d.prettyPrinters[i].Type == pp.Type {
+			d.prettyPrinters[i] = pp
+			d.prettyPrintersCompiled[i] = compiled
+			return nil
+		}
+	}
+	d.prettyPrinters = append(d.prettyPrinters, pp)
+	d.prettyPrintersCompiled = append(d.prettyPrintersCompiled, compiled)
+	return nil
+}
+
+// ClearPrettyPrinter removes the pretty-printer with the specified type
+// pattern.
+func (d *Debugger) ClearPrettyPrinter(typ string) error {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	for i := range d.prettyPrinters {
+		if d.prettyPrinters[i].Type == typ {
+			d.prettyPrinters = append(d.prettyPrinters[:i], d.prettyPrinters[i+1:]...)
+			d.prettyPrintersCompiled = append(d.prettyPrintersCompiled[:i], d.prettyPrintersCompiled[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no pretty-printer for %q", typ)
+}
+
+// PrettyPrinters returns the registered pretty-printers.
+func (d *Debugger) PrettyPrinters() []api.PrettyPrinter {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	return append([]api.PrettyPrinter(nil), d.prettyPrinters...)
+}
+
 // ListDynamicLibraries returns a list of loaded dynamic libraries.
 func (d *Debugger) ListDynamicLibraries() []api.Image {
 	d.targetMutex.Lock()
@@ -170,6 +170,43 @@ func (c *RPCClient) ReverseStepOut() (*api.DebuggerState, error) {
 	return &out.State, err
 }
 
+func (c *RPCClient) StepInto(target string) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.StepInto, ReturnInfoLoadConfig: c.retValLoadCfg, Expr: target}, &out)
+	return &out.State, err
+}
+
+// ListStepInTargets returns the function calls made by the current line that can be stepped into.
+func (c *RPCClient) ListStepInTargets() ([]api.StepInTarget, error) {
+	var out ListStepInTargetsOut
+	err := c.call("ListStepInTargets", ListStepInTargetsIn{}, &out)
+	return out.Targets, err
+}
+
+func (c *RPCClient) Until(loc string) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.Until, ReturnInfoLoadConfig: c.retValLoadCfg, Expr: loc}, &out)
+	return &out.State, err
+}
+
+func (c *RPCClient) ReverseUntil(loc string) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.ReverseUntil, ReturnInfoLoadConfig: c.retValLoadCfg, Expr: loc}, &out)
+	return &out.State, err
+}
+
+func (c *RPCClient) Advance(loc string) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.Advance, ReturnInfoLoadConfig: c.retValLoadCfg, Expr: loc}, &out)
+	return &out.State, err
+}
+
+func (c *RPCClient) ReverseAdvance(loc string) (*api.DebuggerState, error) {
+	var out CommandOut
+	err := c.call("Command", api.DebuggerCommand{Name: api.ReverseAdvance, ReturnInfoLoadConfig: c.retValLoadCfg, Expr: loc}, &out)
+	return &out.State, err
+}
+
 func (c *RPCClient) Call(goroutineID int, expr string, unsafe bool) (*api.DebuggerState, error) {
 	var out CommandOut
 	err := c.call("Command", api.DebuggerCommand{Name: api.Call, ReturnInfoLoadConfig: c.retValLoadCfg, Expr: expr, UnsafeCall: unsafe, GoroutineID: goroutineID}, &out)
@@ -279,6 +316,24 @@ func (c *RPCClient) EvalVariable(scope api.EvalScope, expr string, cfg api.LoadC
 	return out.Variable, err
 }
 
+func (c *RPCClient) EvalVariableRange(scope api.EvalScope, expr string, start, count int, cursor *api.MapCursor, cfg api.LoadConfig) (*api.Variable, *api.MapCursor, error) {
+	var out EvalRangeOut
+	err := c.call("EvalRange", EvalRangeIn{scope, expr, start, count, &cfg, cursor}, &out)
+	return out.Variable, out.Next, err
+}
+
+func (c *RPCClient) DiffVariables(scope api.EvalScope, expr1, expr2 string, cfg api.LoadConfig) ([]api.VariableDiff, error) {
+	var out DiffOut
+	err := c.call("Diff", DiffIn{scope, expr1, expr2, &cfg}, &out)
+	return out.Diffs, err
+}
+
+func (c *RPCClient) DiffVariableSinceLastStop(scope api.EvalScope, expr string, cfg api.LoadConfig) ([]api.VariableDiff, bool, error) {
+	var out DiffSinceLastStopOut
+	err := c.call("DiffSinceLastStop", DiffSinceLastStopIn{scope, expr, &cfg}, &out)
+	return out.Diffs, out.Recorded, err
+}
+
 func (c *RPCClient) SetVariable(scope api.EvalScope, symbol, value string) error {
 	out := new(SetOut)
 	return c.call("Set", SetIn{scope, symbol, value}, out)
@@ -411,6 +466,50 @@ func (c *RPCClient) ClearCheckpoint(id int) error {
 	return err
 }
 
+// SetSyscallCatchpoint stops the target when it enters or exits one of the specified system calls.
+func (c *RPCClient) SetSyscallCatchpoint(syscalls []string) error {
+	var out SetSyscallCatchpointOut
+	return c.call("SetSyscallCatchpoint", SetSyscallCatchpointIn{syscalls}, &out)
+}
+
+// ClearSyscallCatchpoint removes the syscall catchpoint.
+func (c *RPCClient) ClearSyscallCatchpoint() error {
+	var out ClearSyscallCatchpointOut
+	return c.call("ClearSyscallCatchpoint", ClearSyscallCatchpointIn{}, &out)
+}
+
+// SetSignalPolicy changes how the debugger handles a signal received by the target.
+func (c *RPCClient) SetSignalPolicy(policy api.SignalPolicy) error {
+	var out SetSignalPolicyOut
+	return c.call("SetSignalPolicy", SetSignalPolicyIn{policy}, &out)
+}
+
+// ListSignalPolicies returns the policies of the specified signals.
+func (c *RPCClient) ListSignalPolicies(signals []string) ([]api.SignalPolicy, error) {
+	var out ListSignalPoliciesOut
+	err := c.call("ListSignalPolicies", ListSignalPoliciesIn{signals}, &out)
+	return out.Policies, err
+}
+
+// SetPrettyPrinter registers a pretty-printer.
+func (c *RPCClient) SetPrettyPrinter(pp api.PrettyPrinter) error {
+	var out SetPrettyPrinterOut
+	return c.call("SetPrettyPrinter", SetPrettyPrinterIn{pp}, &out)
+}
+
+// ClearPrettyPrinter removes the pretty-printer with the specified type pattern.
+func (c *RPCClient) ClearPrettyPrinter(typ string) error {
+	var out ClearPrettyPrinterOut
+	return c.call("ClearPrettyPrinter", ClearPrettyPrinterIn{typ}, &out)
+}
+
+// ListPrettyPrinters returns the registered pretty-printers.
+func (c *RPCClient) ListPrettyPrinters() ([]api.PrettyPrinter, error) {
+	var out ListPrettyPrintersOut
+	err := c.call("ListPrettyPrinters", ListPrettyPrintersIn{}, &out)
+	return out.Printers, err
+}
+
 func (c *RPCClient) SetReturnValuesLoadConfig(cfg *api.LoadConfig) {
 	c.retValLoadCfg = cfg
 }
@@ -238,6 +238,12 @@ type CreateBreakpointOut struct {
 // - If arg.Breakpoint.Addrs is filled it will create a logical breakpoint
 // corresponding to all specified addresses.
 //
+// - If arg.Breakpoint.LocExpr is not an empty string the breakpoint will be
+// created on the location it specifies. If the location can not be found
+// and arg.Breakpoint.Pending is true a pending breakpoint is created
+// instead, it will be set when a shared library or plugin containing the
+// location is loaded and then reported in DebuggerState.ResolvedBreakpoints.
+//
 // - Otherwise the value specified by arg.Breakpoint.Addr will be used.
 func (s *RPCServer) CreateBreakpoint(arg CreateBreakpointIn, out *CreateBreakpointOut) error {
 	createdbp, err := s.debugger.CreateBreakpoint(&arg.Breakpoint)
@@ -467,6 +473,115 @@ func (s *RPCServer) Eval(arg EvalIn, out *EvalOut) error {
 	return nil
 }
 
+type EvalRangeIn struct {
+	Scope api.EvalScope
+	Expr  string
+	Start int
+	Count int
+	Cfg   *api.LoadConfig
+	// Cursor is the Next cursor returned by the call that loaded the
+	// entries of a map up to Start, if any.
+	Cursor *api.MapCursor
+}
+
+type EvalRangeOut struct {
+	Variable *api.Variable
+	// Next is the position of the entry following the loaded ones, for
+	// maps, nil if there are no more entries.
+	Next *api.MapCursor
+}
+
+// EvalRange evaluates arg.Expr, which must be a slice, array, string or
+// map, and returns arg.Count of its elements starting at arg.Start. The
+// returned variable is equivalent to the result of evaluating
+// expr[start:start+count], truncated to the length of the value.
+//
+// Children of the returned variable are limited by arg.Cfg as usual, only
+// the elements in the requested range are read from the target.
+//
+// Map entries are counted in iteration order, to load the entries of a
+// map one page at a time pass the cursor returned in out.Next as
+// arg.Cursor of the call loading the next page, so that the iteration
+// resumes from there instead of starting again from the first entry.
+func (s *RPCServer) EvalRange(arg EvalRangeIn, out *EvalRangeOut) error {
+	cfg := arg.Cfg
+	if cfg == nil {
+		cfg = &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	}
+	v, next, err := s.debugger.EvalVariableRangeInScope(arg.Scope, arg.Expr, arg.Start, arg.Count, arg.Cursor, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	out.Variable = v
+	out.Next = next
+	return nil
+}
+
+type DiffIn struct {
+	Scope api.EvalScope
+	Expr1 string
+	Expr2 string
+	Cfg   *api.LoadConfig
+}
+
+type DiffOut struct {
+	Diffs []api.VariableDiff
+}
+
+// diffLoadConfig is the default load configuration of Diff and
+// DiffSinceLastStop, it loads values deeply so that nested differences
+// can be found.
+var diffLoadConfig = api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 8, MaxStringLen: 1024, MaxArrayValues: 1024, MaxStructFields: -1}
+
+// Diff evaluates arg.Expr1 and arg.Expr2 and returns the structural
+// differences between their values: changed struct fields, slice and array
+// elements that differ by index and added, removed or changed map keys.
+func (s *RPCServer) Diff(arg DiffIn, out *DiffOut) error {
+	cfg := arg.Cfg
+	if cfg == nil {
+		cfg = &diffLoadConfig
+	}
+	diffs, err := s.debugger.DiffVariablesInScope(arg.Scope, arg.Expr1, arg.Expr2, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	out.Diffs = diffs
+	return nil
+}
+
+type DiffSinceLastStopIn struct {
+	Scope api.EvalScope
+	Expr  string
+	Cfg   *api.LoadConfig
+}
+
+type DiffSinceLastStopOut struct {
+	Diffs []api.VariableDiff
+	// Recorded is true if there was no value of Expr to compare with and
+	// its current value was recorded instead.
+	Recorded bool
+}
+
+// DiffSinceLastStop evaluates arg.Expr and returns the structural
+// differences between its value and the value it had at the previous stop.
+// The first time it is called for an expression its value is recorded
+// and out.Recorded is set, from then on the value of the expression is
+// recorded every time the target stops. Only the most recently compared
+// expressions are recorded.
+func (s *RPCServer) DiffSinceLastStop(arg DiffSinceLastStopIn, out *DiffSinceLastStopOut) error {
+	cfg := arg.Cfg
+	if cfg == nil {
+		cfg = &diffLoadConfig
+	}
+	diffs, ok, err := s.debugger.DiffVariableSinceLastStop(arg.Scope, arg.Expr, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	out.Diffs = diffs
+	out.Recorded = !ok
+	return nil
+}
+
 type SetIn struct {
 	Scope  api.EvalScope
 	Symbol string
@@ -500,6 +615,21 @@ func (s *RPCServer) ListSources(arg ListSourcesIn, out *ListSourcesOut) error {
 	return nil
 }
 
+type ListStepInTargetsIn struct {
+}
+
+type ListStepInTargetsOut struct {
+	Targets []api.StepInTarget
+}
+
+// ListStepInTargets lists the function calls made by the current line of
+// the selected goroutine that can be stepped into.
+func (s *RPCServer) ListStepInTargets(arg ListStepInTargetsIn, out *ListStepInTargetsOut) error {
+	var err error
+	out.Targets, err = s.debugger.StepInTargets()
+	return err
+}
+
 type ListFunctionsIn struct {
 	Filter string
 }
@@ -680,6 +810,105 @@ func (s *RPCServer) ClearCheckpoint(arg ClearCheckpointIn, out *ClearCheckpointO
 	return s.debugger.ClearCheckpoint(arg.ID)
 }
 
+type SetSyscallCatchpointIn struct {
+	// Syscalls is the list of system calls, by name or number, that
+	// should be caught. If it is empty all system calls are caught.
+	Syscalls []string
+}
+
+type SetSyscallCatchpointOut struct {
+}
+
+// SetSyscallCatchpoint stops the target process when one of its threads
+// enters or exits one of the specified system calls.
+// Only supported on linux's native backend.
+func (s *RPCServer) SetSyscallCatchpoint(arg SetSyscallCatchpointIn, out *SetSyscallCatchpointOut) error {
+	return s.debugger.SetSyscallCatchpoint(arg.Syscalls)
+}
+
+type ClearSyscallCatchpointIn struct {
+}
+
+type ClearSyscallCatchpointOut struct {
+}
+
+// ClearSyscallCatchpoint removes the syscall catchpoint.
+func (s *RPCServer) ClearSyscallCatchpoint(arg ClearSyscallCatchpointIn, out *ClearSyscallCatchpointOut) error {
+	return s.debugger.ClearSyscallCatchpoint()
+}
+
+type SetSignalPolicyIn struct {
+	Policy api.SignalPolicy
+}
+
+type SetSignalPolicyOut struct {
+}
+
+// SetSignalPolicy changes how the debugger handles a signal received by
+// the target process.
+// Only supported on linux's native backend.
+func (s *RPCServer) SetSignalPolicy(arg SetSignalPolicyIn, out *SetSignalPolicyOut) error {
+	return s.debugger.SetSignalPolicy(arg.Policy)
+}
+
+type ListSignalPoliciesIn struct {
+	// Signals is the list of signals, by name or number, to return. If it
+	// is empty the policies that were changed from the default are
+	// returned.
+	Signals []string
+}
+
+type ListSignalPoliciesOut struct {
+	Policies []api.SignalPolicy
+}
+
+// ListSignalPolicies returns the signal handling policies.
+func (s *RPCServer) ListSignalPolicies(arg ListSignalPoliciesIn, out *ListSignalPoliciesOut) error {
+	var err error
+	out.Policies, err = s.debugger.SignalPolicies(arg.Signals)
+	return err
+}
+
+type SetPrettyPrinterIn struct {
+	Printer api.PrettyPrinter
+}
+
+type SetPrettyPrinterOut struct {
+}
+
+// SetPrettyPrinter registers a pretty-printer for the types matching
+// Printer.Type, replacing the existing pretty-printer with the same type
+// pattern.
+func (s *RPCServer) SetPrettyPrinter(arg SetPrettyPrinterIn, out *SetPrettyPrinterOut) error {
+	return s.debugger.SetPrettyPrinter(arg.Printer)
+}
+
+type ClearPrettyPrinterIn struct {
+	// Type is the type pattern of the pretty-printer to remove.
+	Type string
+}
+
+type ClearPrettyPrinterOut struct {
+}
+
+// ClearPrettyPrinter removes a pretty-printer.
+func (s *RPCServer) ClearPrettyPrinter(arg ClearPrettyPrinterIn, out *ClearPrettyPrinterOut) error {
+	return s.debugger.ClearPrettyPrinter(arg.Type)
+}
+
+type ListPrettyPrintersIn struct {
+}
+
+type ListPrettyPrintersOut struct {
+	Printers []api.PrettyPrinter
+}
+
+// ListPrettyPrinters returns the registered pretty-printers.
+func (s *RPCServer) ListPrettyPrinters(arg ListPrettyPrintersIn, out *ListPrettyPrintersOut) error {
+	out.Printers = s.debugger.PrettyPrinters()
+	return nil
+}
+
 type IsMulticlientIn struct {
 }
 
@@ -167,6 +167,192 @@ func TestRestart_breakpointPreservation(t *testing.T) {
 	})
 }
 
+func TestRestart_pluginBreakpoint(t *testing.T) {
+	// Breakpoints inside plugins are restored when the plugin is loaded
+	// again after a restart.
+	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
+	fixture := protest.BuildFixture("plugintest2", protest.AllNonOptimized)
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: []string{fixture.Path, pluginFixtures[0].Path, pluginFixtures[1].Path},
+		Debugger: debugger.Config{
+			Backend: testBackend,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := rpc2.NewClientFromConn(clientConn)
+	defer c.Detach(true)
+
+	_, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 41})
+	assertNoError(err, t, "CreateBreakpoint()")
+	state := <-c.Continue()
+	assertNoError(state.Err, t, "Continue()")
+	bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "github.com/go-delve/delve/_fixtures/plugin1.HelloFn"})
+	assertNoError(err, t, "CreateBreakpoint(HelloFn)")
+	pendingBp, err := c.CreateBreakpoint(&api.Breakpoint{LocExpr: "main.nonexistent", Pending: true})
+	assertNoError(err, t, "CreateBreakpoint(pending)")
+
+	discarded, err := c.Restart()
+	assertNoError(err, t, "Restart()")
+	if len(discarded) != 0 {
+		t.Fatalf("breakpoints discarded: %v", discarded)
+	}
+
+	// Pending breakpoints, and breakpoints that are pending again until the
+	// plugin is loaded, keep their IDs.
+	for _, id := range []int{bp.ID, pendingBp.ID} {
+		bp2, err := c.GetBreakpoint(id)
+		assertNoError(err, t, fmt.Sprintf("GetBreakpoint(%d)", id))
+		if !bp2.Pending {
+			t.Fatalf("breakpoint %d not pending after restart: %#v", id, bp2)
+		}
+	}
+	newBp, err := c.CreateBreakpoint(&api.Breakpoint{File: fixture.Source, Line: 42})
+	assertNoError(err, t, "CreateBreakpoint()")
+	if newBp.ID <= pendingBp.ID {
+		t.Fatalf("ID of pending breakpoint reused: %d", newBp.ID)
+	}
+	_, err = c.ClearBreakpoint(newBp.ID)
+	assertNoError(err, t, "ClearBreakpoint()")
+
+	state = <-c.Continue()
+	assertNoError(state.Err, t, "Continue()")
+	if state.CurrentThread.Line != 41 {
+		t.Fatalf("wrong location after restart %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+	}
+	state = <-c.Continue()
+	assertNoError(state.Err, t, "Continue()")
+	if state.CurrentThread.Function == nil || state.CurrentThread.Function.Name() != "github.com/go-delve/delve/_fixtures/plugin1.HelloFn" || state.CurrentThread.Line != bp.Line {
+		t.Fatalf("plugin breakpoint not restored, stopped at %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+	}
+	if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+		t.Fatalf("stopped at wrong breakpoint %v", state.CurrentThread.Breakpoint)
+	}
+}
+
+func TestPendingBreakpoint(t *testing.T) {
+	// A breakpoint on a function of a plugin that hasn't been loaded yet is
+	// kept pending until the plugin is opened.
+	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
+	fixture := protest.BuildFixture("plugintest2", protest.AllNonOptimized)
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: []string{fixture.Path, pluginFixtures[0].Path, pluginFixtures[1].Path},
+		Debugger: debugger.Config{
+			Backend: testBackend,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := rpc2.NewClientFromConn(clientConn)
+	defer c.Detach(true)
+
+	const fnname = "github.com/go-delve/delve/_fixtures/plugin1.HelloFn"
+
+	_, err := c.CreateBreakpoint(&api.Breakpoint{LocExpr: fnname})
+	if err == nil {
+		t.Fatal("breakpoint on missing function created without Pending")
+	}
+	_, err = c.CreateBreakpoint(&api.Breakpoint{LocExpr: "+1", Pending: true})
+	if err == nil {
+		t.Fatal("pending breakpoint created on relative location")
+	}
+
+	bp, err := c.CreateBreakpoint(&api.Breakpoint{LocExpr: fnname, Pending: true})
+	assertNoError(err, t, "CreateBreakpoint(pending)")
+	if !bp.Pending || bp.Addr != 0 {
+		t.Fatalf("breakpoint not pending: %#v", bp)
+	}
+	other, err := c.CreateBreakpoint(&api.Breakpoint{LocExpr: "main.nonexistent", Pending: true})
+	assertNoError(err, t, "CreateBreakpoint(pending)")
+	_, err = c.ClearBreakpoint(other.ID)
+	assertNoError(err, t, "ClearBreakpoint(pending)")
+
+	bps, err := c.ListBreakpoints()
+	assertNoError(err, t, "ListBreakpoints()")
+	found := false
+	for _, lbp := range bps {
+		if lbp.ID == other.ID {
+			t.Fatalf("cleared pending breakpoint still listed")
+		}
+		if lbp.ID == bp.ID {
+			found = lbp.Pending
+		}
+	}
+	if !found {
+		t.Fatalf("pending breakpoint not listed: %v", bps)
+	}
+
+	state := <-c.Continue()
+	assertNoError(state.Err, t, "Continue()")
+	if state.CurrentThread.Function == nil || state.CurrentThread.Function.Name() != fnname {
+		t.Fatalf("pending breakpoint not hit, stopped at %s:%d", state.CurrentThread.File, state.CurrentThread.Line)
+	}
+	if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+		t.Fatalf("stopped at wrong breakpoint %v", state.CurrentThread.Breakpoint)
+	}
+	if len(state.ResolvedBreakpoints) != 1 || state.ResolvedBreakpoints[0].ID != bp.ID || state.ResolvedBreakpoints[0].Pending {
+		t.Fatalf("wrong resolved breakpoints %v", state.ResolvedBreakpoints)
+	}
+
+	bp2, err := c.GetBreakpoint(bp.ID)
+	assertNoError(err, t, "GetBreakpoint()")
+	if bp2.Pending || bp2.Addr == 0 {
+		t.Fatalf("breakpoint still pending after being resolved: %#v", bp2)
+	}
+}
+
+func TestPendingRegexBreakpoint(t *testing.T) {
+	// A pending breakpoint with a regular expression location is set on the
+	// matching functions of every plugin loaded after it was resolved.
+	pluginFixtures := protest.WithPlugins(t, protest.AllNonOptimized, "plugin1/", "plugin2/")
+	fixture := protest.BuildFixture("plugintest2", protest.AllNonOptimized)
+	listener, clientConn := service.ListenerPipe()
+	defer listener.Close()
+	server := rpccommon.NewServer(&service.Config{
+		Listener:    listener,
+		ProcessArgs: []string{fixture.Path, pluginFixtures[0].Path, pluginFixtures[1].Path},
+		Debugger: debugger.Config{
+			Backend: testBackend,
+		},
+	})
+	if err := server.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := rpc2.NewClientFromConn(clientConn)
+	defer c.Detach(true)
+
+	bp, err := c.CreateBreakpoint(&api.Breakpoint{LocExpr: `/plugin[12]\.(HelloFn|TypesTest)$/`, Pending: true})
+	assertNoError(err, t, "CreateBreakpoint(pending)")
+	if !bp.Pending {
+		t.Fatalf("breakpoint not pending: %#v", bp)
+	}
+
+	for _, fnname := range []string{"github.com/go-delve/delve/_fixtures/plugin1.HelloFn", "github.com/go-delve/delve/_fixtures/plugin2.TypesTest"} {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+		if state.CurrentThread.Function == nil || state.CurrentThread.Function.Name() != fnname {
+			t.Fatalf("expected to stop at %s, stopped at %s:%d", fnname, state.CurrentThread.File, state.CurrentThread.Line)
+		}
+		if state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != bp.ID {
+			t.Fatalf("stopped at wrong breakpoint %v", state.CurrentThread.Breakpoint)
+		}
+	}
+
+	bp2, err := c.GetBreakpoint(bp.ID)
+	assertNoError(err, t, "GetBreakpoint()")
+	if bp2.Pending || len(bp2.Addrs) != 2 {
+		t.Fatalf("wrong addresses for breakpoint: %#v", bp2)
+	}
+}
+
 func TestRestart_duringStop(t *testing.T) {
 	withTestClient2("continuetestprog", t, func(c service.Client) {
 		origPid := c.ProcessPid()
@@ -1095,6 +1281,25 @@ func TestNegativeStackDepthBug(t *testing.T) {
 	})
 }
 
+func TestClientServer_CondBreakpointQuery(t *testing.T) {
+	// Breakpoint conditions are parsed with proc.ParseExpr and can use
+	// collection queries.
+	protest.AllowRecording(t)
+	withTestClient2("largecollections", t, func(c service.Client) {
+		fp := testProgPath(t, "largecollections")
+		_, err := c.CreateBreakpoint(&api.Breakpoint{File: fp, Line: 16, Cond: "count(s[:i], x => x > 0) == 3"})
+		assertNoError(err, t, "CreateBreakpoint()")
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		ivar, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "i", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable()")
+		if ivar.SinglelineString() != "4" {
+			t.Fatalf("stopped at the wrong iteration, i = %s", ivar.SinglelineString())
+		}
+	})
+}
+
 func TestClientServer_CondBreakpoint(t *testing.T) {
 	if runtime.GOOS == "freebsd" {
 		t.Skip("test is not valid on FreeBSD")
@@ -1934,3 +2139,62 @@ func TestClearLogicalBreakpoint(t *testing.T) {
 		}
 	})
 }
+
+func TestPrettyPrinter(t *testing.T) {
+	withTestClient2("testvariables2", t, func(c service.Client) {
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		assertNoError(c.SetPrettyPrinter(api.PrettyPrinter{
+			Type:     `^main\.astruct$`,
+			Value:    "A={x.A} B={x.B}",
+			Children: []api.PrettyPrinterChild{{Name: "sum", Expr: "x.A + x.B"}},
+		}), t, "SetPrettyPrinter()")
+		if _, err := c.ListPrettyPrinters(); err != nil {
+			t.Fatalf("ListPrettyPrinters(): %v", err)
+		}
+
+		v, err := c.EvalVariable(api.EvalScope{GoroutineID: -1}, "as1", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(as1)")
+		if v.Pretty == nil {
+			t.Fatal("pretty-printer not applied")
+		}
+		if v.Pretty.Value != "A=1 B=1" || len(v.Pretty.Children) != 1 || v.Pretty.Children[0].Value != "2" {
+			t.Fatalf("wrong pretty value %#v", v.Pretty)
+		}
+		if len(v.Children) != 2 {
+			t.Fatalf("raw value missing %#v", v)
+		}
+
+		// pretty-printers also apply to nested variables
+		v, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "c1.pb.a", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(c1.pb.a)")
+		if s := v.SinglelineString(); s != "main.astruct A=1 B=2 {sum: 3}" {
+			t.Fatalf("wrong representation %q", s)
+		}
+
+		// formatters are not applied when they are disabled in the load configuration
+		rawLoadConfig := normalLoadConfig
+		rawLoadConfig.DisableFormatters = true
+		v, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "as1", rawLoadConfig)
+		assertNoError(err, t, "EvalVariable(as1)")
+		if v.Pretty != nil || v.Flags&api.VariableFormatted != 0 {
+			t.Fatalf("pretty-printer applied with formatters disabled %#v", v.Pretty)
+		}
+
+		assertNoError(c.ClearPrettyPrinter(`^main\.astruct$`), t, "ClearPrettyPrinter()")
+		v, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "as1", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(as1)")
+		if v.Pretty != nil {
+			t.Fatal("pretty-printer applied after being cleared")
+		}
+
+		// the type pattern must match the whole type name
+		assertNoError(c.SetPrettyPrinter(api.PrettyPrinter{Type: `main\.astr`, Value: "{x.A}"}), t, "SetPrettyPrinter()")
+		v, err = c.EvalVariable(api.EvalScope{GoroutineID: -1}, "as1", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(as1)")
+		if v.Pretty != nil {
+			t.Fatal("pretty-printer applied to a partial match of the type name")
+		}
+	})
+}
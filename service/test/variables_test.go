@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go/constant"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"sort"
 	"strings"
@@ -131,13 +132,13 @@ func withTestProcessArgs(name string, t *testing.T, wd string, args []string, bu
 	var tracedir string
 	switch testBackend {
 	case "native":
-		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, []string{}, "")
+		p, err = native.Launch(append([]string{fixture.Path}, args...), wd, false, proc.DebugInfoConfig{}, "")
 	case "lldb":
-		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, []string{}, "")
+		p, err = gdbserial.LLDBLaunch(append([]string{fixture.Path}, args...), wd, false, proc.DebugInfoConfig{}, "")
 	case "rr":
 		protest.MustHaveRecordingAllowed(t)
 		t.Log("recording")
-		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, []string{})
+		p, tracedir, err = gdbserial.RecordAndReplay(append([]string{fixture.Path}, args...), wd, true, proc.DebugInfoConfig{})
 		t.Logf("replaying %q", tracedir)
 	default:
 		t.Fatalf("unknown backend %q", testBackend)
@@ -702,6 +703,42 @@ func TestEvalExpression(t *testing.T) {
 		{"real(cpx1)", false, "1", "1", "", nil},
 		{"imag(3i)", false, "3", "3", "", nil},
 		{"real(4)", false, "4", "4", "", nil},
+		{"min(i2, i1, 3)", false, "1", "1", "int", nil},
+		{"max(i1, f1, i2)", false, "3", "3", "float64", nil},
+		{`max("a", str1)`, false, `"a"`, `"a"`, "", nil},
+		{"min(i1, str1)", false, "", "", "", fmt.Errorf("mismatched types int and string for min")},
+		{"hex(255)", false, `"0xff"`, `"0xff"`, "", nil},
+		{"hex(ni8)", false, `"-0x5"`, `"-0x5"`, "", nil},
+		{"hex(str1)", false, "", "", "", fmt.Errorf("invalid argument str1 (type string) to hex")},
+		{"runtime.typeof(as1)", false, `"main.astruct"`, `"main.astruct"`, "", nil},
+		{"runtime.typeof(iface1)", false, `"*main.astruct"`, `"*main.astruct"`, "", nil},
+		{"sizeof(main.astruct)", false, "16", "16", "", nil},
+		{"sizeof(c1)", false, "32", "32", "", nil},
+		{"offsetof(main.astruct.B)", false, "8", "8", "", nil},
+		{"offsetof(as1.B)", false, "8", "8", "", nil},
+		{"offsetof(main.bstruct.a.B)", false, "8", "8", "", nil},
+		{"offsetof(as1.C)", false, "", "", "", fmt.Errorf("main.astruct has no field C")},
+		{"deref(&arr1[1], 2)", false, "[2]int [1,2]", "[2]int [...]", "[2]int", nil},
+		{"deref(&arr1[0], -1)", false, "", "", "", fmt.Errorf("invalid argument 2 -1 to deref, must not be negative")},
+		{"deref(&arr1[0], 65)", false, "", "", "", fmt.Errorf("invalid argument 2 65 to deref, must not be greater than 64")},
+		{"deref(&arr1[0], 1<<64)", false, "", "", "", fmt.Errorf("invalid argument 2 1 << 64 to deref, must not be greater than 64")},
+		{`string(byteslice) == "tèst"`, false, "true", "true", "", nil},
+		{`string(runearray[:2]) == "tè"`, false, "true", "true", "", nil},
+		{`string(filter(byteslice, x => x != 0)) == "tèst"`, false, "true", "true", "", nil},
+
+		// collection queries
+		{"count(a1, x => len(x) == 3)", false, "2", "2", "", nil},
+		{"filter(a1, x => len(x) > 3)", false, `[]string len: 3, cap: 3, ["three","four","five"]`, "[]string len: 3, cap: 3, [...]", "[]string", nil},
+		{"filter(a1, x => len(x) > 3)[1]", false, `"four"`, `"four"`, "string", nil},
+		{"filter(a1, (i, x) => i < 2)", false, `[]string len: 2, cap: 2, ["one","two"]`, "[]string len: 2, cap: 2, [...]", "[]string", nil},
+		{"any(c1.sa, x => x.A == 4)", false, "true", "true", "", nil},
+		{"all(c1.sa, x => x.A > 1)", false, "false", "false", "", nil},
+		{"any(s3, x => x > 0)", false, "false", "false", "", nil},
+		{"c1.sa[?].A > 1", false, "[]*main.astruct len: 2, cap: 2, …", "[]*main.astruct len: 2, cap: 2, …", "[]*main.astruct", nil},
+		{"count(m1, (k, v) => k == \"Malone\")", false, "1", "1", "", nil},
+		{"map(filter(m1, (k, v) => k == \"Malone\"), x => x.B)[0]", false, "3", "3", "int", nil},
+		{"filter(a1, x => len(x))", false, "", "", "", fmt.Errorf("lambda of filter must return a boolean, len(x) is int")},
+		{"filter(i1, x => true)", false, "", "", "", fmt.Errorf("invalid argument i1 (type int) to filter, must be a slice, array or map")},
 
 		// nil
 		{"nil", false, "nil", "nil", "", nil},
@@ -928,6 +965,229 @@ func TestMapEvaluation(t *testing.T) {
 	})
 }
 
+func TestStdlibFormatters(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("stdformat", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue() returned an error")
+		for _, tc := range []struct {
+			expr, value string
+		}{
+			{"tm", "2021-03-04 05:06:07.000000008 +0000 UTC"},
+			{"d", "1m30s"},
+			{"bi", "-123456789012345678901234567890"},
+			{"ip", "192.168.1.1"},
+			{"ip6", "2001:db8::1"},
+			{"mu", "locked"},
+			{"sb", `"hello"`},
+			{"buf", `"world"`},
+			{"rv", "int"},
+			{"sm", "len: 1"},
+			{"*l", "len: 2"},
+		} {
+			v, err := evalVariable(p, tc.expr, pnormalLoadConfig)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", tc.expr))
+			proc.ApplyPrettyPrinters([]*proc.Variable{v}, nil, pnormalLoadConfig)
+			av := api.ConvertVar(v)
+			if av.Pretty == nil {
+				t.Errorf("%s: not formatted", tc.expr)
+				continue
+			}
+			if av.Pretty.Value != tc.value {
+				t.Errorf("%s: got %q expected %q", tc.expr, av.Pretty.Value, tc.value)
+			}
+			if av.Flags&api.VariableFormatted == 0 {
+				t.Errorf("%s: VariableFormatted not set", tc.expr)
+			}
+		}
+	})
+}
+
+func TestEvalVariableRange(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("largecollections", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue() returned an error")
+		scope, err := proc.GoroutineScope(p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+
+		for _, tc := range []struct {
+			expr         string
+			start, count int64
+			len          int
+			first        string
+		}{
+			{"s", 500, 10, 10, "500"},
+			{"s", 995, 10, 5, "995"},
+			{"s[100:]", 10, 3, 3, "110"},
+			{"str", 10, 5, 5, ""},
+			{"m", 290, 20, 10, ""},
+			{"m", 0, 5, 5, ""},
+		} {
+			v, _, err := scope.EvalVariableRange(tc.expr, tc.start, tc.count, nil, pnormalLoadConfig)
+			assertNoError(err, t, fmt.Sprintf("EvalVariableRange(%s, %d, %d)", tc.expr, tc.start, tc.count))
+			n := len(v.Children)
+			switch v.Kind {
+			case reflect.String:
+				n = len(constant.StringVal(v.Value))
+			case reflect.Map:
+				n /= 2
+			}
+			if n != tc.len {
+				t.Errorf("%s[%d:%d]: got %d elements, expected %d", tc.expr, tc.start, tc.start+tc.count, n, tc.len)
+			}
+			if tc.first != "" && api.ConvertVar(&v.Children[0]).SinglelineString() != tc.first {
+				t.Errorf("%s[%d:%d]: got first element %s, expected %s", tc.expr, tc.start, tc.start+tc.count, api.ConvertVar(&v.Children[0]).SinglelineString(), tc.first)
+			}
+		}
+
+		_, _, err = scope.EvalVariableRange("m", 300, 10, nil, pnormalLoadConfig)
+		if err == nil {
+			t.Errorf("EvalVariableRange(m, 300, 10) did not return an error")
+		}
+
+		// loading the pages of a map using the returned cursors produces
+		// the same entries as loading the map at once
+		cfg := pnormalLoadConfig
+		cfg.MaxArrayValues = 300
+		all, err := evalVariable(p, "m", cfg)
+		assertNoError(err, t, "EvalVariable(m)")
+		var cursor *proc.MapCursor
+		for start := int64(0); start < 300; start += 64 {
+			page, next, err := scope.EvalVariableRange("m", start, 64, cursor, pnormalLoadConfig)
+			assertNoError(err, t, fmt.Sprintf("EvalVariableRange(m, %d, 64)", start))
+			for i := range page.Children {
+				k := 2*int(start) + i
+				if api.ConvertVar(&page.Children[i]).SinglelineString() != api.ConvertVar(&all.Children[k]).SinglelineString() {
+					t.Errorf("page at %d: child %d differs from child %d of m", start, i, k)
+				}
+			}
+			if (next == nil) != (start+64 >= 300) {
+				t.Errorf("page at %d: unexpected cursor %v", start, next)
+			}
+			cursor = next
+		}
+
+		// maps can be windowed with a two index slice expression
+		v, err := evalVariable(p, "m[10:15]", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(m[10:15])")
+		if len(v.Children) != 10 {
+			t.Errorf("m[10:15]: got %d children, expected 10", len(v.Children))
+		}
+		v, err = evalVariable(p, "m[10:][5:6]", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(m[10:][5:6])")
+		if len(v.Children) != 2 {
+			t.Errorf("m[10:][5:6]: got %d children, expected 2", len(v.Children))
+		}
+	})
+}
+
+func TestQueryResultTruncated(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("largecollections", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue() returned an error")
+
+		// the result has more elements than the ones that are kept, it is
+		// loaded like a partially loaded slice
+		v, err := evalVariable(p, "filter(big, x => x == 0)", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(filter(big, x => x == 0))")
+		if v.Len != 100000 || len(v.Children) != pnormalLoadConfig.MaxArrayValues {
+			t.Errorf("got len %d and %d children, expected len 100000 and %d children", v.Len, len(v.Children), pnormalLoadConfig.MaxArrayValues)
+		}
+
+		for _, tc := range []struct {
+			expr string
+			len  int64
+			err  bool
+		}{
+			{"len(filter(big, x => x == 0))", 100000, false},
+			{"len(map(big, x => x + 1))", 100000, false},
+			{"filter(big, x => x == 0)[100]", 0, false},
+			{"filter(big, x => x == 0)[99999]", 0, true},
+			{"filter(big, x => x == 0)[65530:65540]", 10, false},
+		} {
+			v, err := evalVariable(p, tc.expr, pnormalLoadConfig)
+			if tc.err {
+				if err == nil {
+					t.Errorf("%s: expected an error", tc.expr)
+				}
+				continue
+			}
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", tc.expr))
+			switch v.Kind {
+			case reflect.Slice:
+				if v.Len != tc.len || len(v.Children) != 6 {
+					t.Errorf("%s: got len %d and %d children, expected len %d and 6 children", tc.expr, v.Len, len(v.Children), tc.len)
+				}
+			default:
+				if n, _ := constant.Int64Val(v.Value); n != tc.len {
+					t.Errorf("%s: got %d expected %d", tc.expr, n, tc.len)
+				}
+			}
+		}
+	})
+}
+
+func TestDiffVariables(t *testing.T) {
+	protest.AllowRecording(t)
+	withTestProcess("largecollections", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue() returned an error")
+		cfg := proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 8, MaxStringLen: 1024, MaxArrayValues: 1024, MaxStructFields: -1}
+
+		diff := func(expr1, expr2 string) []string {
+			t.Helper()
+			v1, err := evalVariable(p, expr1, cfg)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", expr1))
+			v2, err := evalVariable(p, expr2, cfg)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", expr2))
+			var r []string
+			for _, d := range proc.DiffVariables(v1, v2) {
+				switch d.Kind {
+				case proc.DiffAdded:
+					r = append(r, d.Path+" added")
+				case proc.DiffRemoved:
+					r = append(r, d.Path+" removed")
+				default:
+					r = append(r, d.Path+" changed")
+				}
+			}
+			return r
+		}
+
+		for _, tc := range []struct {
+			expr1, expr2 string
+			tgt          []string
+		}{
+			{"s", "s", nil},
+			{"m", "m", nil},
+			{"s[:3]", "s[1:4]", []string{"[0] changed", "[1] changed", "[2] changed"}},
+			{"s[:3]", "s[:2]", []string{"[2] removed"}},
+			{"s[:2]", "s[:3]", []string{"[2] added"}},
+			{"m[1]", "m[2]", []string{".X changed", ".Y changed"}},
+			{"m[1]", "m[1]", nil},
+			{"s", "str", []string{" changed"}},
+		} {
+			if out := diff(tc.expr1, tc.expr2); !reflect.DeepEqual(out, tc.tgt) {
+				t.Errorf("diff %s %s: got %q expected %q", tc.expr1, tc.expr2, out, tc.tgt)
+			}
+		}
+
+		// m[10:15] and m[12:17] are windows over the same iteration order,
+		// two keys are only in the first and two only in the second.
+		out := diff("m[10:15]", "m[12:17]")
+		added, removed := 0, 0
+		for _, d := range out {
+			switch {
+			case strings.HasSuffix(d, " added"):
+				added++
+			case strings.HasSuffix(d, " removed"):
+				removed++
+			}
+		}
+		if len(out) != 4 || added != 2 || removed != 2 {
+			t.Errorf("diff m[10:15] m[12:17]: got %q", out)
+		}
+	})
+}
+
 func TestUnsafePointer(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -1519,3 +1779,48 @@ func TestPluginVariables(t *testing.T) {
 		assertVariable(t, vb, varTest{"b", true, `github.com/go-delve/delve/_fixtures/internal/pluginsupport.SomethingElse(*github.com/go-delve/delve/_fixtures/plugin2.asomethingelse) *{x: 1, y: 4}`, ``, `github.com/go-delve/delve/_fixtures/internal/pluginsupport.SomethingElse`, nil})
 	})
 }
+
+func TestCgoVariables(t *testing.T) {
+	protest.MustHaveCgo(t)
+	if runtime.GOARCH == "386" {
+		t.Skip("cgo not supported on i386 for now")
+	}
+	testcases := []varTest{
+		{"*f", true, "struct flags {a: 5, b: 17, c: -3, d: 120}", "", "struct flags", nil},
+		{"f.a", true, "5", "", "unsigned int", nil},
+		{"f.c", true, "-3", "", "int", nil},
+		{"n.bytes[3]", false, "63", "", "unsigned char", nil},
+		{"*n", true, "union num {i: 1065353216, f: 1, bytes: [4]unsigned char [0,0,128,63]}", "", "union num", nil},
+		{"c", true, "GREEN (5)", "", "enum color", nil},
+		{"b.len", true, "4", "", "int", nil},
+		{"b.data[1]", true, "98", "", "char", nil},
+		{"b.data[0:3]", false, "[]char len: 3, cap: 3, [97,98,99]", "", "[]char", nil},
+		{"op", true, "C.add", "", "binop", nil},
+		{"*(struct flags*)p", false, "struct flags {a: 5, b: 17, c: -3, d: 120}", "", "struct flags", nil},
+		{"((struct buf *)b).len", false, "4", "", "int", nil},
+		{"(enum color)6", false, "BLUE (6)", "", "enum color", nil},
+		{"(unsigned long)c", false, "5", "", "long unsigned int", nil},
+	}
+	withTestProcess("cgovariables", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFileBreakpoint(p, t, fixture, 35)
+		assertNoError(p.Continue(), t, "Continue()")
+		for _, tc := range testcases {
+			variable, err := evalVariable(p, tc.name, pnormalLoadConfig)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", tc.name))
+			assertVariable(t, variable, tc)
+		}
+
+		s, err := evalVariable(p, "s", pnormalLoadConfig)
+		assertNoError(err, t, "EvalVariable(s)")
+		if cv := api.ConvertVar(s); cv.Value != "hello" {
+			t.Errorf("wrong value for C string: %q", cv.Value)
+		}
+
+		if _, err := evalVariable(p, "&f.a", pnormalLoadConfig); err == nil {
+			t.Errorf("could take the address of a bit field")
+		}
+		if err := setVariable(p, "f.b", "1"); err == nil {
+			t.Errorf("could set a bit field")
+		}
+	})
+}